@@ -59,6 +59,7 @@ func newCommand(opts ...option) (c *command, err error) {
 		return nil, err
 	}
 
+	c.initDBCmd()
 	c.initVersionCmd()
 	return c, nil
 }
@@ -0,0 +1,420 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/validator"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// the options shared by the start and db commands, so that the same
+// configuration is used by both
+const (
+	optionNameDataDir    = "data-dir"
+	optionNameNetworkID  = "network-id"
+	optionNameDBCapacity = "db-capacity"
+	optionNameDBGCPolicy = "db-gc-policy"
+	optionNameDBGCTTL    = "db-gc-ttl"
+)
+
+const (
+	optionNameExportPinned = "pinned"
+	optionNameExportBins   = "bin"
+	optionNameSkipValidate = "skip-validation"
+	optionNameIndexesOnly  = "indexes-only"
+	optionNameRepair       = "repair"
+)
+
+// progressInterval is the minimal time between the progress outputs of the
+// export and import.
+const progressInterval = time.Second
+
+func (c *command) initDBCmd() {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the local store of a stopped node",
+	}
+
+	cmd.PersistentFlags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
+	cmd.PersistentFlags().Uint64(optionNameNetworkID, 1, "ID of the Swarm network")
+	c.setDBFlags(cmd.PersistentFlags())
+
+	c.initDBExportCmd(cmd)
+	c.initDBImportCmd(cmd)
+	c.initDBInfoCmd(cmd)
+	c.initDBValidateCmd(cmd)
+
+	c.root.AddCommand(cmd)
+}
+
+func (c *command) initDBExportCmd(parent *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "export <file>",
+		Short: "Export the chunks of the local store to a tar file, - for the standard output",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			var bins []uint8
+			for _, b := range c.config.GetIntSlice(optionNameExportBins) {
+				if b < 0 || b > int(swarm.MaxPO) {
+					return fmt.Errorf("invalid bin %d", b)
+				}
+				bins = append(bins, uint8(b))
+			}
+
+			db, err := c.openDB(cmd, false)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			var w io.Writer = cmd.OutOrStdout()
+			if args[0] != "-" {
+				f, err := os.Create(args[0])
+				if err != nil {
+					return fmt.Errorf("create export file: %w", err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			count, err := db.Export(w, &localstore.ExportOptions{
+				PinnedOnly: c.config.GetBool(optionNameExportPinned),
+				Bins:       bins,
+				Progress:   progress(cmd, "exported"),
+			})
+			if err != nil {
+				return fmt.Errorf("export: %w", err)
+			}
+			cmd.PrintErrf("exported %d chunks\n", count)
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().Bool(optionNameExportPinned, false, "export only the pinned chunks")
+	cmd.Flags().IntSlice(optionNameExportBins, nil, "export only the chunks in the proximity order bins")
+
+	parent.AddCommand(cmd)
+}
+
+func (c *command) initDBImportCmd(parent *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import the chunks from a tar file to the local store, - for the standard input",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			db, err := c.openDB(cmd, true)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("open import file: %w", err)
+				}
+				defer f.Close()
+				r = f
+			}
+
+			o := &localstore.ImportOptions{
+				Progress: progress(cmd, "imported"),
+			}
+			if !c.config.GetBool(optionNameSkipValidate) {
+				o.Validators = []swarm.ChunkValidator{validator.NewContentAddressValidator(), soc.NewValidator()}
+			}
+			count, err := db.Import(r, o)
+			if err != nil {
+				return fmt.Errorf("import after %d chunks: %w", count, err)
+			}
+			cmd.PrintErrf("imported %d chunks\n", count)
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().Bool(optionNameSkipValidate, false, "import the chunks without validating them")
+
+	parent.AddCommand(cmd)
+}
+
+func (c *command) initDBInfoCmd(parent *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "info",
+		Short: "Print the number of items in the indexes of the local store",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			db, err := c.openDB(cmd, false)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			indexes, err := db.DebugIndices()
+			if err != nil {
+				return fmt.Errorf("indexes: %w", err)
+			}
+			names := make([]string, 0, len(indexes))
+			for name := range indexes {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				cmd.Printf("%s: %d\n", name, indexes[name])
+			}
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	parent.AddCommand(cmd)
+}
+
+func (c *command) initDBValidateCmd(parent *cobra.Command) {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the chunks and the indexes of the local store and optionally repair them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			db, err := c.openDB(cmd, false)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+
+			o := &localstore.ValidateOptions{
+				Repair:   c.config.GetBool(optionNameRepair),
+				Progress: progress(cmd, "validated"),
+			}
+			if !c.config.GetBool(optionNameIndexesOnly) {
+				o.Validators = []swarm.ChunkValidator{validator.NewContentAddressValidator(), soc.NewValidator()}
+			}
+			report, err := db.Validate(o)
+			if err != nil {
+				return fmt.Errorf("validate: %w", err)
+			}
+			for _, addr := range report.InvalidChunks {
+				cmd.Printf("invalid chunk %s\n", addr)
+			}
+			for _, s := range report.Inconsistencies {
+				cmd.Println(s)
+			}
+			cmd.Printf("validated %d chunks: %d invalid chunks, %d inconsistencies\n", report.Chunks, len(report.InvalidChunks), report.InconsistencyCount)
+			if report.Valid() {
+				return nil
+			}
+			if !report.Repaired {
+				return errors.New("local store is not valid")
+			}
+
+			// validate the repaired database again
+			// to report the remaining problems
+			o.Repair = false
+			o.Progress = progress(cmd, "validated")
+			report, err = db.Validate(o)
+			if err != nil {
+				return fmt.Errorf("validate repaired: %w", err)
+			}
+			if !report.Valid() {
+				return fmt.Errorf("repaired local store is not valid: %d invalid chunks, %d inconsistencies", len(report.InvalidChunks), report.InconsistencyCount)
+			}
+			cmd.Printf("repaired %d chunks\n", report.Chunks)
+			return nil
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return c.config.BindPFlags(cmd.Flags())
+		},
+	}
+
+	cmd.Flags().Bool(optionNameIndexesOnly, false, "validate only the indexes without validating the chunks")
+	cmd.Flags().Bool(optionNameRepair, false, "remove the invalid chunks and rebuild the indexes if the validation fails")
+
+	parent.AddCommand(cmd)
+}
+
+// openDB opens the local store in the data directory, which is created only
+// if create is true. The overlay address, which is the base key of the local
+// store, is derived from the swarm key without the password. An existing
+// local store is opened only with the garbage collection policy of the node,
+// as its garbage collection index would be rebuilt otherwise.
+func (c *command) openDB(cmd *cobra.Command, create bool) (*localstore.DB, error) {
+	dataDir := c.config.GetString(optionNameDataDir)
+	path := filepath.Join(dataDir, "localstore")
+	if !create {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("localstore: %w", err)
+		}
+	}
+
+	ethAddr, err := filekeystore.New(filepath.Join(dataDir, "keys")).EthereumAddress("swarm")
+	if err != nil {
+		return nil, fmt.Errorf("swarm key: %w", err)
+	}
+	address := crypto.NewOverlayFromEthereumAddress(ethAddr, c.config.GetUint64(optionNameNetworkID))
+
+	logger := logging.New(cmd.ErrOrStderr(), logrus.WarnLevel)
+
+	capacity, err := c.dbCapacity(logger)
+	if err != nil {
+		return nil, err
+	}
+	gcPolicy, err := c.gcPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := localstore.New(path, address.Bytes(), &localstore.Options{
+		Capacity: capacity / swarm.ChunkSize,
+		MaxSize:  capacity,
+		GCPolicy:     gcPolicy,
+		KeepGCPolicy: true,
+	}, logger)
+	if errors.Is(err, localstore.ErrGCPolicyChanged) {
+		return nil, fmt.Errorf("localstore: %w, set %s to the policy of the node", err, optionNameDBGCPolicy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("localstore: %w", err)
+	}
+	return db, nil
+}
+
+// setDBFlags adds the flags of the capacity and the garbage collection policy
+// of the local store. The garbage collection index is rebuilt when the start
+// command changes the policy, while the db commands refuse to open the local
+// store with a different policy.
+func (c *command) setDBFlags(flags *pflag.FlagSet) {
+	flags.String(optionNameDBCapacity, "20GB", "db capacity in bytes with a unit, B, kB, MB, GB, TB, KiB, MiB, GiB or TiB, a number without a unit is the deprecated capacity in chunks")
+	flags.String(optionNameDBGCPolicy, "lru", "garbage collection policy of the db, lru for the least recently used, lfu for the least frequently used or ttl for the oldest chunks first")
+	flags.Duration(optionNameDBGCTTL, 24*time.Hour, "time after which the chunks are garbage collected with the ttl garbage collection policy")
+}
+
+// dbCapacity returns the capacity of the local store in bytes. The deprecated
+// capacity in chunks is converted to bytes with a warning.
+func (c *command) dbCapacity(logger logging.Logger) (uint64, error) {
+	v := c.config.GetString(optionNameDBCapacity)
+	capacity, inChunks, err := parseDBCapacity(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", optionNameDBCapacity, err)
+	}
+	if inChunks {
+		logger.Warningf("%s %s without a unit is deprecated as the number of chunks, use %dB instead", optionNameDBCapacity, v, capacity)
+	}
+	if capacity < swarm.ChunkSize {
+		return 0, fmt.Errorf("%s: lower than the chunk size %d", optionNameDBCapacity, swarm.ChunkSize)
+	}
+	return capacity, nil
+}
+
+// gcPolicy returns the garbage collection policy of the local store
+// configured by the flags.
+func (c *command) gcPolicy() (localstore.GCPolicy, error) {
+	switch p := c.config.GetString(optionNameDBGCPolicy); strings.ToLower(p) {
+	case "lru":
+		return localstore.NewLRUGCPolicy(), nil
+	case "lfu":
+		return localstore.NewLFUGCPolicy(), nil
+	case "ttl":
+		ttl := c.config.GetDuration(optionNameDBGCTTL)
+		if ttl <= 0 {
+			return nil, fmt.Errorf("invalid %s %v", optionNameDBGCTTL, ttl)
+		}
+		return localstore.NewTTLGCPolicy(ttl), nil
+	default:
+		return nil, fmt.Errorf("unknown %s %q", optionNameDBGCPolicy, p)
+	}
+}
+
+// byteSizeUnits are the multipliers of the units of the byte sizes.
+var byteSizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+}
+
+// parseByteSize parses the number of bytes with an optional unit, like 50GB
+// or 1.5TiB. The units are not case sensitive.
+func parseByteSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	value, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	unit, ok := byteSizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q", s[i:])
+	}
+	size := value * unit
+	if size >= math.MaxUint64 {
+		return 0, fmt.Errorf("size %q too large", s)
+	}
+	return uint64(size), nil
+}
+
+// parseDBCapacity parses the capacity of the local store in bytes. The number
+// without a unit is the deprecated capacity in chunks, which is converted to
+// bytes and inChunks is true.
+func parseDBCapacity(s string) (capacity uint64, inChunks bool, err error) {
+	if chunks, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64); err == nil {
+		if chunks > math.MaxUint64/swarm.ChunkSize {
+			return 0, false, fmt.Errorf("size %q too large", s)
+		}
+		return chunks * swarm.ChunkSize, true, nil
+	}
+	capacity, err = parseByteSize(s)
+	return capacity, false, err
+}
+
+// progress returns the function which prints the number of the processed
+// chunks at most once per progressInterval.
+func progress(cmd *cobra.Command, action string) func(count int64) {
+	last := time.Now()
+	return func(count int64) {
+		if time.Since(last) < progressInterval {
+			return
+		}
+		last = time.Now()
+		cmd.PrintErrf("%s %d chunks\n", action, count)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmd_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethersphere/bee/cmd/bee/cmd"
+	"github.com/ethersphere/bee/pkg/crypto"
+	filekeystore "github.com/ethersphere/bee/pkg/keystore/file"
+	"github.com/ethersphere/bee/pkg/localstore"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want uint64
+		err  bool
+	}{
+		{in: "4096", want: 4096},
+		{in: "100B", want: 100},
+		{in: "50GB", want: 50e9},
+		{in: "50gb", want: 50e9},
+		{in: "1.5 TB", want: 15e11},
+		{in: "10kB", want: 10e3},
+		{in: "512MiB", want: 512 << 20},
+		{in: "2GiB", want: 2 << 30},
+		{in: " 1TiB ", want: 1 << 40},
+		{in: "", err: true},
+		{in: "GB", err: true},
+		{in: "10XB", err: true},
+		{in: "-1GB", err: true},
+		{in: "1e30TB", err: true},
+	} {
+		got, err := cmd.ParseByteSize(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: got %v, want error", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseDBCapacity(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		want     uint64
+		inChunks bool
+		err      bool
+	}{
+		{in: "20GB", want: 20e9},
+		{in: "4096B", want: 4096},
+		{in: "5000000", want: 5000000 * swarm.ChunkSize, inChunks: true},
+		{in: " 10 ", want: 10 * swarm.ChunkSize, inChunks: true},
+		{in: "1.5", want: 1},
+		{in: "18446744073709551615", err: true},
+		{in: "GB", err: true},
+	} {
+		got, inChunks, err := cmd.ParseDBCapacity(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: got %v, want error", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("%q: got %v, want %v", tc.in, got, tc.want)
+		}
+		if inChunks != tc.inChunks {
+			t.Errorf("%q: got in chunks %v, want %v", tc.in, inChunks, tc.inChunks)
+		}
+	}
+}
+
+// TestDBGCPolicy verifies that the db commands do not open the local store
+// with a different garbage collection policy than the one of the node.
+func TestDBGCPolicy(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "bee-db-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	key, _, err := filekeystore.New(filepath.Join(dataDir, "keys")).Key("swarm", "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethAddr, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := crypto.NewOverlayFromEthereumAddress(ethAddr, 1)
+	db, err := localstore.New(filepath.Join(dataDir, "localstore"), address.Bytes(), &localstore.Options{
+		GCPolicy: localstore.NewLFUGCPolicy(),
+	}, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	err = newCommand(t,
+		cmd.WithArgs("db", "info", "--data-dir", dataDir),
+		cmd.WithOutput(ioutil.Discard),
+		cmd.WithErrorOutput(ioutil.Discard),
+	).Execute()
+	if !errors.Is(err, localstore.ErrGCPolicyChanged) {
+		t.Fatalf("got error %v, want %v", err, localstore.ErrGCPolicyChanged)
+	}
+
+	if err := newCommand(t,
+		cmd.WithArgs("db", "info", "--data-dir", dataDir, "--db-gc-policy", "lfu"),
+		cmd.WithOutput(ioutil.Discard),
+	).Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
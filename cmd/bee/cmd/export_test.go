@@ -13,12 +13,13 @@ type (
 )
 
 var (
-	NewCommand = newCommand
+	NewCommand      = newCommand
+	ParseByteSize   = parseByteSize
+	ParseDBCapacity = parseDBCapacity
 
 	// avoid unused lint errors until the functions are used
 	_ = WithCfgFile
 	_ = WithInput
-	_ = WithErrorOutput
 	_ = WithPasswordReader
 )
 
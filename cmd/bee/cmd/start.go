@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -16,9 +17,14 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/file/joiner"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/node"
-	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/postage"
+	postagemock "github.com/ethersphere/bee/pkg/postage/mock"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/simulated"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
@@ -26,25 +32,34 @@ import (
 func (c *command) initStartCmd() (err error) {
 
 	const (
-		optionNameDataDir            = "data-dir"
-		optionNameDBCapacity         = "db-capacity"
-		optionNamePassword           = "password"
-		optionNamePasswordFile       = "password-file"
-		optionNameAPIAddr            = "api-addr"
-		optionNameP2PAddr            = "p2p-addr"
-		optionNameNATAddr            = "nat-addr"
-		optionNameP2PEnableWS        = "p2p-enable-ws"
-		optionNameP2PEnableQUIC      = "p2p-enable-quic"
-		optionNameEnableDebugAPI     = "enable-debug-api"
-		optionNameDebugAPIAddr       = "debug-api-addr"
-		optionNameBootnodes          = "bootnode"
-		optionNameNetworkID          = "network-id"
-		optionWelcomeMessage         = "welcome-message"
-		optionCORSAllowedOrigins     = "cors-allowed-origins"
-		optionNameTracingEnabled     = "tracing"
-		optionNameTracingEndpoint    = "tracing-endpoint"
-		optionNameTracingServiceName = "tracing-service-name"
-		optionNameVerbosity          = "verbosity"
+		optionNamePassword            = "password"
+		optionNamePasswordFile        = "password-file"
+		optionNameAPIAddr             = "api-addr"
+		optionNameP2PAddr             = "p2p-addr"
+		optionNameNATAddr             = "nat-addr"
+		optionNameP2PEnableWS         = "p2p-enable-ws"
+		optionNameP2PEnableQUIC       = "p2p-enable-quic"
+		optionNameEnableDebugAPI      = "enable-debug-api"
+		optionNameDebugAPIAddr        = "debug-api-addr"
+		optionNameBootnodes           = "bootnode"
+		optionWelcomeMessage          = "welcome-message"
+		optionCORSAllowedOrigins      = "cors-allowed-origins"
+		optionNameTracingEnabled      = "tracing"
+		optionNameTracingEndpoint     = "tracing-endpoint"
+		optionNameTracingServiceName  = "tracing-service-name"
+		optionNameVerbosity           = "verbosity"
+		optionNamePaymentThreshold    = "payment-threshold"
+		optionNamePaymentTolerance    = "payment-tolerance"
+		optionNamePaymentRefreshRate  = "payment-refresh-rate"
+		optionNamePricePerPO          = "price-per-po"
+		optionNameTagsRetention       = "tags-retention"
+		optionNamePrefetchWindow      = "download-prefetch-window"
+		optionNameDirectUploadTimeout = "direct-upload-timeout"
+		optionNameDBReserveRatio      = "db-reserve-ratio"
+		optionNameDBMinFreeDiskSpace  = "db-min-free-disk-space"
+		optionNamePostageDevChain     = "postage-dev-chain"
+		optionNameSwapDevChain        = "swap-dev-chain"
+		optionNameSwapInitialDeposit  = "swap-initial-deposit"
 	)
 
 	cmd := &cobra.Command{
@@ -111,24 +126,65 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 				password = p
 			}
 
+			dbCapacity, err := c.dbCapacity(logger)
+			if err != nil {
+				return err
+			}
+			dbMinFreeDiskSpace, err := parseByteSize(c.config.GetString(optionNameDBMinFreeDiskSpace))
+			if err != nil {
+				return fmt.Errorf("%s: %w", optionNameDBMinFreeDiskSpace, err)
+			}
+			gcPolicy, err := c.gcPolicy()
+			if err != nil {
+				return err
+			}
+
+			var batchListener postage.Listener
+			if c.config.GetBool(optionNamePostageDevChain) {
+				batchListener = postagemock.NewChain()
+				logger.Warning("using in-memory postage chain. batches are not shared with other nodes")
+			}
+
+			var swapBackend chequebook.Backend
+			if c.config.GetBool(optionNameSwapDevChain) {
+				swapBackend = &devSwapBackend{
+					Backend: simulated.NewBackend(int64(c.config.GetUint64(optionNameNetworkID))),
+					funds:   new(big.Int).SetUint64(c.config.GetUint64(optionNameSwapInitialDeposit)),
+				}
+				logger.Warning("using in-memory swap chain. chequebooks are not shared with other nodes")
+			}
+
 			b, err := node.NewBee(node.Options{
-				DataDir:            c.config.GetString(optionNameDataDir),
-				DBCapacity:         c.config.GetUint64(optionNameDBCapacity),
-				Password:           password,
-				APIAddr:            c.config.GetString(optionNameAPIAddr),
-				DebugAPIAddr:       debugAPIAddr,
-				Addr:               c.config.GetString(optionNameP2PAddr),
-				NATAddr:            c.config.GetString(optionNameNATAddr),
-				EnableWS:           c.config.GetBool(optionNameP2PEnableWS),
-				EnableQUIC:         c.config.GetBool(optionNameP2PEnableQUIC),
-				NetworkID:          c.config.GetUint64(optionNameNetworkID),
-				WelcomeMessage:     c.config.GetString(optionWelcomeMessage),
-				Bootnodes:          c.config.GetStringSlice(optionNameBootnodes),
-				CORSAllowedOrigins: c.config.GetStringSlice(optionCORSAllowedOrigins),
-				TracingEnabled:     c.config.GetBool(optionNameTracingEnabled),
-				TracingEndpoint:    c.config.GetString(optionNameTracingEndpoint),
-				TracingServiceName: c.config.GetString(optionNameTracingServiceName),
-				Logger:             logger,
+				DataDir:                c.config.GetString(optionNameDataDir),
+				DBCapacity:             dbCapacity,
+				DBMinFreeDiskSpace:     dbMinFreeDiskSpace,
+				DBReserveRatio:         c.config.GetFloat64(optionNameDBReserveRatio),
+				DBGCPolicy:             gcPolicy,
+				Password:               password,
+				APIAddr:                c.config.GetString(optionNameAPIAddr),
+				DebugAPIAddr:           debugAPIAddr,
+				Addr:                   c.config.GetString(optionNameP2PAddr),
+				NATAddr:                c.config.GetString(optionNameNATAddr),
+				EnableWS:               c.config.GetBool(optionNameP2PEnableWS),
+				EnableQUIC:             c.config.GetBool(optionNameP2PEnableQUIC),
+				NetworkID:              c.config.GetUint64(optionNameNetworkID),
+				WelcomeMessage:         c.config.GetString(optionWelcomeMessage),
+				Bootnodes:              c.config.GetStringSlice(optionNameBootnodes),
+				CORSAllowedOrigins:     c.config.GetStringSlice(optionCORSAllowedOrigins),
+				TracingEnabled:         c.config.GetBool(optionNameTracingEnabled),
+				TracingEndpoint:        c.config.GetString(optionNameTracingEndpoint),
+				TracingServiceName:     c.config.GetString(optionNameTracingServiceName),
+				PaymentThreshold:       c.config.GetUint64(optionNamePaymentThreshold),
+				PaymentTolerance:       c.config.GetUint64(optionNamePaymentTolerance),
+				PaymentRefreshRate:     c.config.GetUint64(optionNamePaymentRefreshRate),
+				PricePerPO:             c.config.GetUint64(optionNamePricePerPO),
+				TagsRetention:          c.config.GetDuration(optionNameTagsRetention),
+				DownloadPrefetchWindow: c.config.GetInt(optionNamePrefetchWindow),
+				DirectUploadTimeout:    c.config.GetDuration(optionNameDirectUploadTimeout),
+				BatchListener:          batchListener,
+				SwapBackend:            swapBackend,
+				SwapInitialDeposit:     c.config.GetUint64(optionNameSwapInitialDeposit),
+				Logger:                 logger,
 			})
 			if err != nil {
 				return err
@@ -174,7 +230,9 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 	}
 
 	cmd.Flags().String(optionNameDataDir, filepath.Join(c.homeDir, ".bee"), "data directory")
-	cmd.Flags().Uint64(optionNameDBCapacity, 5000000, fmt.Sprintf("db capacity in chunks, multiply by %d to get approximate capacity in bytes", swarm.ChunkSize))
+	c.setDBFlags(cmd.Flags())
+	cmd.Flags().String(optionNameDBMinFreeDiskSpace, "1GB", "free disk space below which the chunks from the network are not stored, 0 to disable")
+	cmd.Flags().Float64(optionNameDBReserveRatio, 0.5, "part of the db capacity reserved for the chunks within the storage radius, which are not garbage collected, 0 to disable")
 	cmd.Flags().String(optionNamePassword, "", "password for decrypting keys")
 	cmd.Flags().String(optionNamePasswordFile, "", "path to a file that contains password for decrypting keys")
 	cmd.Flags().String(optionNameAPIAddr, ":8080", "HTTP API listen address")
@@ -192,7 +250,29 @@ Welcome to the Swarm.... Bzzz Bzzzz Bzzzz
 	cmd.Flags().String(optionNameTracingServiceName, "bee", "service name identifier for tracing")
 	cmd.Flags().String(optionNameVerbosity, "info", "log verbosity level 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=trace")
 	cmd.Flags().String(optionWelcomeMessage, "", "send a welcome message string during handshakes")
+	cmd.Flags().Uint64(optionNamePaymentThreshold, 100000, "debt of a peer at which it is expected to pay")
+	cmd.Flags().Uint64(optionNamePaymentTolerance, 10000, "debt of a peer over the payment threshold before it is disconnected")
+	cmd.Flags().Uint64(optionNamePaymentRefreshRate, 10000, "amount per second of the payments accepted from a peer without a blockchain")
+	cmd.Flags().Uint64(optionNamePricePerPO, 10, "price of a chunk per proximity order between the serving peer and the chunk")
+	cmd.Flags().Duration(optionNameTagsRetention, 24*time.Hour, "time the completed upload tags and the chunk receipts are kept for, zero keeps them forever")
+	cmd.Flags().Int(optionNamePrefetchWindow, joiner.DefaultPrefetchWindow, "number of data chunks fetched ahead of the reads of the downloads")
+	cmd.Flags().Duration(optionNameDirectUploadTimeout, api.DefaultDirectUploadTimeout, "deadline for the chunks of the direct uploads to be synced")
+	cmd.Flags().Bool(optionNamePostageDevChain, false, "listen to postage batches on an in-memory chain for development and reject chunks without a valid stamp")
+	cmd.Flags().Bool(optionNameSwapDevChain, false, "settle the debts with swap cheques from a chequebook on an in-memory chain for development")
+	cmd.Flags().Uint64(optionNameSwapInitialDeposit, 0, "amount deposited to the swap chequebook when it is deployed")
 
 	c.root.AddCommand(cmd)
 	return nil
 }
+
+// devSwapBackend is the in-memory swap chain of the development nodes, which
+// funds the chequebook issuer with the initial deposit on the deployment.
+type devSwapBackend struct {
+	*simulated.Backend
+	funds *big.Int
+}
+
+func (b *devSwapBackend) Deploy(ctx context.Context, issuer []byte) ([]byte, error) {
+	b.Mint(issuer, b.funds)
+	return b.Backend.Deploy(ctx, issuer)
+}
@@ -0,0 +1,345 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package accounting provides functionalities needed
+// to do per-peer accounting.
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var (
+	_ Interface                  = (*Accounting)(nil)
+	_ settlement.PaymentObserver = (*Accounting)(nil)
+)
+
+const balancesPrefix = "balance_"
+
+var (
+	// ErrOverdraft is returned when the peer interaction would make our
+	// debt to the peer exceed the payment threshold.
+	ErrOverdraft = errors.New("attempted overdraft")
+	// ErrDisconnectThresholdExceeded is returned when the debt of the peer
+	// exceeds the payment threshold increased by the payment tolerance.
+	ErrDisconnectThresholdExceeded = errors.New("disconnect threshold exceeded")
+	// ErrOverpayment is returned when the payment of the peer is larger than
+	// its debt.
+	ErrOverpayment = errors.New("attempted overpayment")
+	// ErrPeerNoBalance is returned when no balance is recorded for the peer.
+	ErrPeerNoBalance = errors.New("no balance for peer")
+	// ErrInvalidPaymentTolerance is returned when the payment tolerance is
+	// not less than the half of the payment threshold.
+	ErrInvalidPaymentTolerance = errors.New("payment tolerance must be less than half the payment threshold")
+)
+
+// Interface is the main interface for Accounting.
+type Interface interface {
+	// Reserve reserves a portion of the balance for the peer. If the
+	// operation would risk exceeding the payment threshold, the debt to the
+	// peer is settled first, and an error is returned if it is still
+	// exceeded.
+	// It should be called, always in combination with Release, before a
+	// Credit action to prevent overspending by the concurrent requests.
+	Reserve(ctx context.Context, peer swarm.Address, price uint64) error
+	// Release releases the reserved funds.
+	Release(peer swarm.Address, price uint64)
+	// Credit decreases the balance of the peer, as we received a service
+	// from the peer.
+	Credit(peer swarm.Address, price uint64) error
+	// Debit increases the balance of the peer, as we provided a service to
+	// the peer.
+	Debit(peer swarm.Address, price uint64) error
+	// Balance returns the current balance for the given peer.
+	Balance(peer swarm.Address) (int64, error)
+	// Balances returns the balances of all known peers.
+	Balances() (map[string]int64, error)
+}
+
+// peerBalance holds all relevant accounting information for one peer.
+type peerBalance struct {
+	lock     sync.Mutex
+	balance  int64  // amount that the peer owes us if positive, our debt if negative
+	reserved uint64 // amount currently reserved for active peer interaction
+}
+
+// Options for accounting.
+type Options struct {
+	PaymentThreshold uint64
+	PaymentTolerance uint64
+	Logger           logging.Logger
+	Store            storage.StateStorer
+	Settlement       settlement.Interface
+}
+
+// Accounting is the main implementation of the accounting interface.
+type Accounting struct {
+	balancesMu       sync.Mutex // mutex for accessing the balances map
+	balances         map[string]*peerBalance
+	logger           logging.Logger
+	store            storage.StateStorer
+	settlement       settlement.Interface
+	paymentThreshold uint64 // the debt at which the peer is expected to pay
+	paymentTolerance uint64 // the debt over the payment threshold before the peer is disconnected
+}
+
+// NewAccounting creates a new Accounting instance with the provided options.
+func NewAccounting(o Options) (*Accounting, error) {
+	if o.PaymentTolerance > o.PaymentThreshold/2 {
+		return nil, ErrInvalidPaymentTolerance
+	}
+
+	return &Accounting{
+		balances:         make(map[string]*peerBalance),
+		paymentThreshold: o.PaymentThreshold,
+		paymentTolerance: o.PaymentTolerance,
+		logger:           o.Logger,
+		store:            o.Store,
+		settlement:       o.Settlement,
+	}, nil
+}
+
+// Reserve reserves a portion of the balance for the peer.
+func (a *Accounting) Reserve(ctx context.Context, peer swarm.Address, price uint64) error {
+	balance, err := a.getPeerBalance(peer)
+	if err != nil {
+		return err
+	}
+
+	balance.lock.Lock()
+	defer balance.lock.Unlock()
+
+	// the expected balance is the balance if all reserved funds were
+	// credited, which must not exceed the payment threshold
+	if balance.expectedBalance(price) < -int64(a.paymentThreshold) {
+		if err := a.settle(ctx, peer, balance); err != nil {
+			return fmt.Errorf("settle: %w", err)
+		}
+		if balance.expectedBalance(price) < -int64(a.paymentThreshold) {
+			return ErrOverdraft
+		}
+	}
+
+	balance.reserved += price
+
+	return nil
+}
+
+// Release releases the reserved funds.
+func (a *Accounting) Release(peer swarm.Address, price uint64) {
+	balance, err := a.getPeerBalance(peer)
+	if err != nil {
+		a.logger.Errorf("accounting: cannot release balance for peer: %v", err)
+		return
+	}
+
+	balance.lock.Lock()
+	defer balance.lock.Unlock()
+
+	// this should never happen if Reserve and Release calls are paired
+	if price > balance.reserved {
+		a.logger.Error("accounting: attempting to release more balance than was reserved for peer")
+		balance.reserved = 0
+	} else {
+		balance.reserved -= price
+	}
+}
+
+// Credit decreases the balance of the peer.
+func (a *Accounting) Credit(peer swarm.Address, price uint64) error {
+	balance, err := a.getPeerBalance(peer)
+	if err != nil {
+		return err
+	}
+
+	balance.lock.Lock()
+	defer balance.lock.Unlock()
+
+	nextBalance := balance.balance - int64(price)
+
+	a.logger.Tracef("accounting: crediting peer %v with price %d, new balance is %d", peer, price, nextBalance)
+
+	if err := a.store.Put(peerBalanceKey(peer), nextBalance); err != nil {
+		return fmt.Errorf("failed to persist balance: %w", err)
+	}
+
+	balance.balance = nextBalance
+
+	return nil
+}
+
+// Debit increases the balance of the peer. If the debt of the peer exceeds
+// the payment threshold by more than the payment tolerance, the returned
+// error causes the peer to be disconnected.
+func (a *Accounting) Debit(peer swarm.Address, price uint64) error {
+	balance, err := a.getPeerBalance(peer)
+	if err != nil {
+		return err
+	}
+
+	balance.lock.Lock()
+	defer balance.lock.Unlock()
+
+	nextBalance := balance.balance + int64(price)
+
+	a.logger.Tracef("accounting: debiting peer %v with price %d, new balance is %d", peer, price, nextBalance)
+
+	if err := a.store.Put(peerBalanceKey(peer), nextBalance); err != nil {
+		return fmt.Errorf("failed to persist balance: %w", err)
+	}
+
+	balance.balance = nextBalance
+
+	if nextBalance >= int64(a.paymentThreshold+a.paymentTolerance) {
+		// peer too much in debt
+		return p2p.NewDisconnectError(ErrDisconnectThresholdExceeded)
+	}
+
+	return nil
+}
+
+// settle pays the debt to the peer and adds the amount accepted by the peer
+// to the balance. It must be called with the balance lock held.
+func (a *Accounting) settle(ctx context.Context, peer swarm.Address, balance *peerBalance) error {
+	if balance.balance >= 0 {
+		// the reserved funds are not owed yet
+		return nil
+	}
+
+	accepted, err := a.settlement.Pay(ctx, peer, uint64(-balance.balance))
+	if err != nil {
+		return err
+	}
+
+	nextBalance := balance.balance + int64(accepted)
+
+	a.logger.Tracef("accounting: settled %d with peer %v, new balance is %d", accepted, peer, nextBalance)
+
+	if err := a.store.Put(peerBalanceKey(peer), nextBalance); err != nil {
+		return fmt.Errorf("failed to persist balance: %w", err)
+	}
+
+	balance.balance = nextBalance
+
+	return nil
+}
+
+// NotifyPayment implements the settlement.PaymentObserver interface. It
+// decreases the balance of the peer by the received amount.
+func (a *Accounting) NotifyPayment(peer swarm.Address, amount uint64) error {
+	balance, err := a.getPeerBalance(peer)
+	if err != nil {
+		return err
+	}
+
+	balance.lock.Lock()
+	defer balance.lock.Unlock()
+
+	nextBalance := balance.balance - int64(amount)
+
+	// don't allow a payment to put us into debt
+	if nextBalance < 0 {
+		return ErrOverpayment
+	}
+
+	a.logger.Tracef("accounting: received payment of %d from peer %v, new balance is %d", amount, peer, nextBalance)
+
+	if err := a.store.Put(peerBalanceKey(peer), nextBalance); err != nil {
+		return fmt.Errorf("failed to persist balance: %w", err)
+	}
+
+	balance.balance = nextBalance
+
+	return nil
+}
+
+// Balance returns the current balance for the given peer.
+func (a *Accounting) Balance(peer swarm.Address) (balance int64, err error) {
+	// every balance change is persisted before it is applied
+	if err := a.store.Get(peerBalanceKey(peer), &balance); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, ErrPeerNoBalance
+		}
+		return 0, err
+	}
+	return balance, nil
+}
+
+// Balances returns the balances of all known peers.
+func (a *Accounting) Balances() (map[string]int64, error) {
+	s := make(map[string]int64)
+
+	err := a.store.Iterate(balancesPrefix, func(key, val []byte) (stop bool, err error) {
+		addr, err := balanceKeyPeer(key)
+		if err != nil {
+			return false, fmt.Errorf("parse address from key: %s: %w", string(key), err)
+		}
+
+		var balance int64
+		if err := json.Unmarshal(val, &balance); err != nil {
+			return false, fmt.Errorf("unmarshal balance: %s: %w", string(key), err)
+		}
+		s[addr.String()] = balance
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// getPeerBalance returns the balance of the peer, loading it from the store
+// on first access.
+func (a *Accounting) getPeerBalance(peer swarm.Address) (*peerBalance, error) {
+	a.balancesMu.Lock()
+	defer a.balancesMu.Unlock()
+
+	balance, ok := a.balances[peer.String()]
+	if !ok {
+		var stored int64
+		err := a.store.Get(peerBalanceKey(peer), &stored)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("failed to load balance: %w", err)
+		}
+
+		balance = &peerBalance{
+			balance: stored,
+		}
+		a.balances[peer.String()] = balance
+	}
+
+	return balance, nil
+}
+
+// expectedBalance returns the balance after all reserved funds and the
+// additional price were credited.
+func (pb *peerBalance) expectedBalance(additionalDebt uint64) int64 {
+	return pb.balance - int64(pb.reserved) - int64(additionalDebt)
+}
+
+// peerBalanceKey returns the balance storage key for the given peer.
+func peerBalanceKey(peer swarm.Address) string {
+	return balancesPrefix + peer.String()
+}
+
+// balanceKeyPeer returns the embedded peer from the balance storage key.
+func balanceKeyPeer(key []byte) (swarm.Address, error) {
+	k := string(key)
+	if !strings.HasPrefix(k, balancesPrefix) {
+		return swarm.ZeroAddress, fmt.Errorf("invalid balance key %q", k)
+	}
+	return swarm.ParseHexAddress(strings.TrimPrefix(k, balancesPrefix))
+}
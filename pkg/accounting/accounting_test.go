@@ -0,0 +1,318 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package accounting_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	testPaymentThreshold = 10000
+	testPaymentTolerance = 1000
+	testPrice            = uint64(10)
+)
+
+// booking represents an accounting action and the expected result afterwards
+type booking struct {
+	peer            swarm.Address
+	price           int64 // Credit if <0, Debit otherwise
+	expectedBalance int64
+}
+
+// TestAccountingAddBalance does several accounting actions and verifies the balance after each step
+func TestAccountingAddBalance(t *testing.T) {
+	acc := newTestAccounting(t, mock.NewStateStore())
+
+	peer1Addr := swarm.MustParseHexAddress("00112233")
+	peer2Addr := swarm.MustParseHexAddress("00112244")
+
+	bookings := []booking{
+		{peer: peer1Addr, price: 100, expectedBalance: 100},
+		{peer: peer2Addr, price: 200, expectedBalance: 200},
+		{peer: peer1Addr, price: 300, expectedBalance: 400},
+		{peer: peer1Addr, price: -100, expectedBalance: 300},
+		{peer: peer2Addr, price: -1000, expectedBalance: -800},
+	}
+
+	for i, booking := range bookings {
+		if booking.price < 0 {
+			err := acc.Reserve(context.Background(), booking.peer, uint64(-booking.price))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = acc.Credit(booking.peer, uint64(-booking.price))
+			if err != nil {
+				t.Fatal(err)
+			}
+			acc.Release(booking.peer, uint64(-booking.price))
+		} else {
+			err := acc.Debit(booking.peer, uint64(booking.price))
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		balance, err := acc.Balance(booking.peer)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if balance != booking.expectedBalance {
+			t.Fatalf("balance for peer %v not as expected after booking %d. got %d, wanted %d", booking.peer.String(), i, balance, booking.expectedBalance)
+		}
+	}
+}
+
+// TestAccountingAdd_persistentBalances tests that balances are actually persisted
+// It creates an accounting instance, does some accounting
+// Then it creates a new accounting instance with the same store and verifies the balances
+func TestAccountingAdd_persistentBalances(t *testing.T) {
+	store := mock.NewStateStore()
+	defer store.Close()
+
+	acc := newTestAccounting(t, store)
+
+	peer1Addr := swarm.MustParseHexAddress("00112233")
+	peer2Addr := swarm.MustParseHexAddress("00112244")
+
+	peer1DebitAmount := testPrice
+	if err := acc.Debit(peer1Addr, peer1DebitAmount); err != nil {
+		t.Fatal(err)
+	}
+
+	peer2CreditAmount := 2 * testPrice
+	if err := acc.Credit(peer2Addr, peer2CreditAmount); err != nil {
+		t.Fatal(err)
+	}
+
+	acc = newTestAccounting(t, store)
+
+	peer1Balance, err := acc.Balance(peer1Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peer1Balance != int64(peer1DebitAmount) {
+		t.Fatalf("peer1Balance not loaded correctly. got %d, wanted %d", peer1Balance, peer1DebitAmount)
+	}
+
+	peer2Balance, err := acc.Balance(peer2Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peer2Balance != -int64(peer2CreditAmount) {
+		t.Fatalf("peer2Balance not loaded correctly. got %d, wanted %d", peer2Balance, -int64(peer2CreditAmount))
+	}
+
+	// the loaded balance is the base for the following bookings
+	if err := acc.Debit(peer1Addr, peer1DebitAmount); err != nil {
+		t.Fatal(err)
+	}
+	balances, err := acc.Balances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := balances[peer1Addr.String()]; got != 2*int64(peer1DebitAmount) {
+		t.Fatalf("got balance %d, want %d", got, 2*peer1DebitAmount)
+	}
+	if got := balances[peer2Addr.String()]; got != -int64(peer2CreditAmount) {
+		t.Fatalf("got balance %d, want %d", got, -int64(peer2CreditAmount))
+	}
+	if len(balances) != 2 {
+		t.Fatalf("got %d balances, want 2", len(balances))
+	}
+}
+
+// TestAccountingReserve tests that reserve returns an error if the payment threshold would be exceeded
+func TestAccountingReserve(t *testing.T) {
+	acc := newTestAccounting(t, mock.NewStateStore())
+
+	peer1Addr := swarm.MustParseHexAddress("00112233")
+
+	// a single reservation cannot exceed the threshold
+	if err := acc.Reserve(context.Background(), peer1Addr, testPaymentThreshold+1); err == nil {
+		t.Fatal("expected error from reserve")
+	}
+
+	if err := acc.Reserve(context.Background(), peer1Addr, testPaymentThreshold/2); err != nil {
+		t.Fatal(err)
+	}
+	// the reserved funds count towards the threshold
+	if err := acc.Reserve(context.Background(), peer1Addr, testPaymentThreshold/2+1); !errors.Is(err, accounting.ErrOverdraft) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrOverdraft)
+	}
+	acc.Release(peer1Addr, testPaymentThreshold/2)
+	if err := acc.Reserve(context.Background(), peer1Addr, testPaymentThreshold/2+1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestAccountingDisconnect tests that exceeding the disconnect threshold with Debit returns a p2p.DisconnectError
+func TestAccountingDisconnect(t *testing.T) {
+	acc := newTestAccounting(t, mock.NewStateStore())
+
+	peer1Addr := swarm.MustParseHexAddress("00112233")
+
+	// put the peer 1 unit away from disconnect
+	if err := acc.Debit(peer1Addr, testPaymentThreshold+testPaymentTolerance-1); err != nil {
+		t.Fatal("expected no error while still within tolerance")
+	}
+
+	// put the peer over the threshold
+	err := acc.Debit(peer1Addr, 1)
+	if err == nil {
+		t.Fatal("expected Debit to return error")
+	}
+
+	var e *p2p.DisconnectError
+	if !errors.As(err, &e) {
+		t.Fatalf("expected DisconnectError, got %v", err)
+	}
+	if !errors.Is(err, accounting.ErrDisconnectThresholdExceeded) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrDisconnectThresholdExceeded)
+	}
+}
+
+func TestAccountingNoBalance(t *testing.T) {
+	acc := newTestAccounting(t, mock.NewStateStore())
+
+	if _, err := acc.Balance(swarm.MustParseHexAddress("00112233")); !errors.Is(err, accounting.ErrPeerNoBalance) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrPeerNoBalance)
+	}
+}
+
+func TestAccountingInvalidPaymentTolerance(t *testing.T) {
+	_, err := accounting.NewAccounting(accounting.Options{
+		PaymentThreshold: testPaymentThreshold,
+		PaymentTolerance: testPaymentThreshold/2 + 1,
+		Logger:           logging.New(ioutil.Discard, 0),
+		Store:            mock.NewStateStore(),
+	})
+	if !errors.Is(err, accounting.ErrInvalidPaymentTolerance) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrInvalidPaymentTolerance)
+	}
+}
+
+// TestAccountingSettle tests that the debt is settled when a reservation
+// would exceed the payment threshold, and that the reservation fails if the
+// peer accepts only a part of the payment.
+func TestAccountingSettle(t *testing.T) {
+	settlement := &settlementMock{acceptFunc: func(amount uint64) uint64 { return amount }}
+	acc := newTestAccountingWithSettlement(t, mock.NewStateStore(), settlement)
+
+	peer1Addr := swarm.MustParseHexAddress("00112233")
+
+	if err := acc.Credit(peer1Addr, testPaymentThreshold); err != nil {
+		t.Fatal(err)
+	}
+
+	// the debt is settled in full before the reservation
+	if err := acc.Reserve(context.Background(), peer1Addr, testPrice); err != nil {
+		t.Fatal(err)
+	}
+	acc.Release(peer1Addr, testPrice)
+	if settlement.paid != testPaymentThreshold {
+		t.Fatalf("got paid %d, want %d", settlement.paid, testPaymentThreshold)
+	}
+	balance, err := acc.Balance(peer1Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 0 {
+		t.Fatalf("got balance %d, want 0", balance)
+	}
+
+	// the peer accepts only a half of the debt
+	settlement.acceptFunc = func(amount uint64) uint64 { return amount / 2 }
+	if err := acc.Credit(peer1Addr, testPaymentThreshold); err != nil {
+		t.Fatal(err)
+	}
+	if err := acc.Reserve(context.Background(), peer1Addr, testPaymentThreshold/2+1); !errors.Is(err, accounting.ErrOverdraft) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrOverdraft)
+	}
+	balance, err = acc.Balance(peer1Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != -testPaymentThreshold/2 {
+		t.Fatalf("got balance %d, want %d", balance, -testPaymentThreshold/2)
+	}
+}
+
+// TestAccountingNotifyPayment tests that the received payments decrease the
+// debt of the peer and that a payment larger than the debt is refused.
+func TestAccountingNotifyPayment(t *testing.T) {
+	acc := newTestAccounting(t, mock.NewStateStore())
+
+	peer1Addr := swarm.MustParseHexAddress("00112233")
+
+	if err := acc.Debit(peer1Addr, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acc.NotifyPayment(peer1Addr, 60); err != nil {
+		t.Fatal(err)
+	}
+	balance, err := acc.Balance(peer1Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != 40 {
+		t.Fatalf("got balance %d, want 40", balance)
+	}
+
+	if err := acc.NotifyPayment(peer1Addr, 41); !errors.Is(err, accounting.ErrOverpayment) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrOverpayment)
+	}
+}
+
+func newTestAccounting(t *testing.T, store storage.StateStorer) *accounting.Accounting {
+	t.Helper()
+
+	return newTestAccountingWithSettlement(t, store, &settlementMock{})
+}
+
+func newTestAccountingWithSettlement(t *testing.T, store storage.StateStorer, settlement settlement.Interface) *accounting.Accounting {
+	t.Helper()
+
+	acc, err := accounting.NewAccounting(accounting.Options{
+		PaymentThreshold: testPaymentThreshold,
+		PaymentTolerance: testPaymentTolerance,
+		Logger:           logging.New(ioutil.Discard, 0),
+		Store:            store,
+		Settlement:       settlement,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acc
+}
+
+// settlementMock records the payments and accepts the amount returned by the
+// acceptFunc, nothing if it is not set.
+type settlementMock struct {
+	settlement.Interface
+	acceptFunc func(amount uint64) uint64
+	paid       uint64
+}
+
+func (s *settlementMock) Pay(_ context.Context, _ swarm.Address, amount uint64) (uint64, error) {
+	if s.acceptFunc == nil {
+		return 0, nil
+	}
+	accepted := s.acceptFunc(amount)
+	s.paid += accepted
+	return accepted, nil
+}
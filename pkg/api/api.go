@@ -5,41 +5,160 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/redundancy"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/logging"
 	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/tags/tagsapi"
 	"github.com/ethersphere/bee/pkg/tracing"
 )
 
+// Presence of this header with the value true in the HTTP request indicates
+// that the uploaded data needs to be encrypted.
+const EncryptHeader = "swarm-encrypt"
+
+// RedundancyLevelHeader sets the redundancy level of the uploaded data, the
+// number of the parity chunks added to the chunk tree. Redundancy is not
+// supported for the encrypted data.
+const RedundancyLevelHeader = "swarm-redundancy-level"
+
 type Service interface {
 	http.Handler
 	m.Collector
+	io.Closer
 }
 
 type server struct {
 	Options
 	http.Handler
 	metrics metrics
+	// prefetchMetrics are shared by the readers of all the downloads
+	prefetchMetrics *joiner.PrefetchMetrics
+
+	tags *tagsapi.Handler
+
+	feedLocks feedLocks // serialize the updates of the same feed
+
+	wsWg sync.WaitGroup // wait for all websockets to close on exit
+	quit chan struct{}
 }
 
 type Options struct {
 	Tags               *tags.Tags
 	Storer             storage.Storer
+	Signer             crypto.Signer
+	Pss                pss.Interface
+	Post               postage.Service
 	CORSAllowedOrigins []string
 	Logger             logging.Logger
 	Tracer             *tracing.Tracer
+	WsPingPeriod       time.Duration
+	// DownloadPrefetchWindow is the number of the data chunks fetched ahead
+	// of the reads of the downloaded data. The default window of the joiner
+	// is used if it is zero.
+	DownloadPrefetchWindow int
+	// PushSyncer pushes the chunks of the direct uploads. The direct upload
+	// is not available if it is nil.
+	PushSyncer pushsync.PushSyncer
+	// DirectUploadTimeout is the deadline for the chunks of the direct upload
+	// to be synced. The DefaultDirectUploadTimeout is used if it is zero.
+	DirectUploadTimeout time.Duration
+	// StampsRequired rejects the uploads and the pss messages without the
+	// PostageBatchIdHeader, as the chunks without postage stamps are not
+	// synced when the stamps are enforced by the network.
+	StampsRequired bool
 }
 
 func New(o Options) Service {
 	s := &server{
-		Options: o,
-		metrics: newMetrics(),
+		Options:         o,
+		metrics:         newMetrics(),
+		prefetchMetrics: joiner.NewPrefetchMetrics(),
+		quit:            make(chan struct{}),
+	}
+	if s.WsPingPeriod == 0 {
+		s.WsPingPeriod = 60 * time.Second
 	}
+	if s.DirectUploadTimeout == 0 {
+		s.DirectUploadTimeout = DefaultDirectUploadTimeout
+	}
+	s.tags = tagsapi.New(o.Tags, o.Logger, s.quit)
 
 	s.setupRouting()
 
 	return s
 }
+
+// Close hangs up running websockets on shutdown.
+func (s *server) Close() error {
+	close(s.quit)
+
+	c := make(chan struct{})
+	go func() {
+		defer close(c)
+		s.wsWg.Wait()
+	}()
+
+	select {
+	case <-c:
+	case <-time.After(1 * time.Second):
+		return errors.New("api shutting down with open websockets")
+	}
+
+	return nil
+}
+
+// requestEncrypt returns true if the encryption of the uploaded data is
+// requested with the EncryptHeader.
+func requestEncrypt(r *http.Request) bool {
+	return strings.ToLower(r.Header.Get(EncryptHeader)) == "true"
+}
+
+// newReader returns the reader of the downloaded data, which fetches the
+// chunks ahead of the reads.
+func (s *server) newReader(ctx context.Context, address swarm.Address) (*joiner.Reader, error) {
+	return joiner.NewPrefetchingReader(ctx, s.Storer, address, joiner.PrefetchOptions{
+		Window:  s.DownloadPrefetchWindow,
+		Metrics: s.prefetchMetrics,
+	})
+}
+
+// requestRedundancyLevel returns the redundancy level of the uploaded data
+// requested with the RedundancyLevelHeader. If the level is invalid or it is
+// requested for the encrypted data, the bad request response is written and
+// false is returned.
+func (s *server) requestRedundancyLevel(w http.ResponseWriter, r *http.Request, logPrefix string) (redundancy.Level, bool) {
+	v := r.Header.Get(RedundancyLevelHeader)
+	if v == "" {
+		return redundancy.None, true
+	}
+	level, err := redundancy.ParseLevel(v)
+	if err != nil {
+		s.Logger.Debugf("%s: parse redundancy level %q: %v", logPrefix, v, err)
+		s.Logger.Errorf("%s: parse redundancy level", logPrefix)
+		jsonhttp.BadRequest(w, "invalid redundancy level")
+		return redundancy.None, false
+	}
+	if level != redundancy.None && requestEncrypt(r) {
+		s.Logger.Errorf("%s: redundancy of encrypted data", logPrefix)
+		jsonhttp.BadRequest(w, "redundancy not supported for encrypted data")
+		return redundancy.None, false
+	}
+	return level, true
+}
@@ -10,20 +10,29 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/tags"
 	"resenje.org/web"
 )
 
 type testServerOptions struct {
-	Pingpong pingpong.Interface
-	Storer   storage.Storer
-	Tags     *tags.Tags
-	Logger   logging.Logger
+	Pingpong            pingpong.Interface
+	Storer              storage.Storer
+	Signer              crypto.Signer
+	Tags                *tags.Tags
+	Post                postage.Service
+	Logger              logging.Logger
+	PushSyncer          pushsync.PushSyncer
+	DirectUploadTimeout time.Duration
+	StampsRequired      bool
 }
 
 func newTestServer(t *testing.T, o testServerOptions) *http.Client {
@@ -31,9 +40,14 @@ func newTestServer(t *testing.T, o testServerOptions) *http.Client {
 		o.Logger = logging.New(ioutil.Discard, 0)
 	}
 	s := api.New(api.Options{
-		Tags:   o.Tags,
-		Storer: o.Storer,
-		Logger: o.Logger,
+		Tags:                o.Tags,
+		Storer:              o.Storer,
+		Signer:              o.Signer,
+		Post:                o.Post,
+		Logger:              o.Logger,
+		PushSyncer:          o.PushSyncer,
+		DirectUploadTimeout: o.DirectUploadTimeout,
+		StampsRequired:      o.StampsRequired,
 	})
 	ts := httptest.NewServer(s)
 	t.Cleanup(ts.Close)
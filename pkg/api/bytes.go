@@ -5,13 +5,12 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/ethersphere/bee/pkg/file"
-	"github.com/ethersphere/bee/pkg/file/joiner"
-	"github.com/ethersphere/bee/pkg/file/splitter"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -20,24 +19,55 @@ import (
 
 type bytesPostResponse struct {
 	Reference swarm.Address `json:"reference"`
+	// Sync are the statistics of the direct upload.
+	Sync *syncStats `json:"sync,omitempty"`
 }
 
 // bytesUploadHandler handles upload of raw binary data of arbitrary length.
 func (s *server) bytesUploadHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	sp := splitter.NewSimpleSplitter(s.Storer)
-	address, err := file.SplitWriteAll(ctx, sp, r.Body, r.ContentLength)
+	level, ok := s.requestRedundancyLevel(w, r, "bytes upload")
+	if !ok {
+		return
+	}
+	deferred, ok := s.requestDeferredUpload(w, r, "bytes upload")
+	if !ok {
+		return
+	}
+	putter, ok := s.stamperPutter(w, r, "bytes upload")
+	if !ok {
+		return
+	}
+	var direct *pushPutter
+	if !deferred {
+		var cancel context.CancelFunc
+		direct, cancel = s.newDirectUpload(ctx, putter)
+		defer cancel()
+		putter = direct
+	}
+
+	sp := newSplitter(putter, requestEncrypt(r), level)
+	// the content length is -1 for the chunked transfer encoding
+	address, err := sp.Split(ctx, r.Body, r.ContentLength)
 	if err != nil {
 		s.Logger.Debugf("bytes upload: %v", err)
 		jsonhttp.InternalServerError(w, nil)
 		return
 	}
-	jsonhttp.OK(w, bytesPostResponse{
+
+	resp := bytesPostResponse{
 		Reference: address,
-	})
+	}
+	if direct != nil {
+		if resp.Sync, ok = s.waitDirectUpload(w, direct, "bytes upload"); !ok {
+			return
+		}
+	}
+	jsonhttp.OK(w, resp)
 }
 
 // bytesGetHandler handles retrieval of raw binary data of arbitrary length.
+// Range requests are served with only the chunks that cover the range.
 func (s *server) bytesGetHandler(w http.ResponseWriter, r *http.Request) {
 	addressHex := mux.Vars(r)["address"]
 	ctx := r.Context()
@@ -50,9 +80,7 @@ func (s *server) bytesGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	j := joiner.NewSimpleJoiner(s.Storer)
-
-	dataSize, err := j.Size(ctx, address)
+	reader, err := s.newReader(ctx, address)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			s.Logger.Debugf("bytes: not found %s: %v", address, err)
@@ -66,12 +94,10 @@ func (s *server) bytesGetHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	w.Header().Set("ETag", fmt.Sprintf("%q", address))
 	w.Header().Set("Content-Type", "application/octet-stream")
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", dataSize))
-	c, err := file.JoinReadAll(j, address, w)
-	if err != nil && c == 0 {
-		s.Logger.Errorf("bytes: data write %s: %v", address, err)
-		s.Logger.Error("bytes: data input error")
-		jsonhttp.InternalServerError(w, "retrieval fail")
+	http.ServeContent(w, r, "", time.Time{}, reader)
+	if recovered := reader.Recovered(); len(recovered) > 0 {
+		s.Logger.Debugf("bytes: %s: recovered %d chunks", address, len(recovered))
 	}
 }
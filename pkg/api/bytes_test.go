@@ -6,11 +6,16 @@ package api_test
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/pkg/logging"
@@ -45,6 +50,13 @@ func TestBytes(t *testing.T) {
 		})
 	})
 
+	t.Run("upload without content length", func(t *testing.T) {
+		// the request body of unknown length is sent with the chunked transfer encoding
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource, io.MultiReader(bytes.NewReader(content)), http.StatusOK, api.BytesPostResponse{
+			Reference: swarm.MustParseHexAddress(expHash),
+		})
+	})
+
 	t.Run("download", func(t *testing.T) {
 		resp := request(t, client, http.MethodGet, resource+"/"+expHash, nil, http.StatusOK)
 		data, err := ioutil.ReadAll(resp.Body)
@@ -57,6 +69,147 @@ func TestBytes(t *testing.T) {
 		}
 	})
 
+	t.Run("encrypted", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Add(api.EncryptHeader, "true")
+
+		var resp api.BytesPostResponse
+		jsonhttptest.ResponseUnmarshalSendHeaders(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusOK, &resp, headers)
+		if l := len(resp.Reference.Bytes()); l != encryption.ReferenceSize {
+			t.Fatalf("got reference length %d, want %d", l, encryption.ReferenceSize)
+		}
+
+		_ = jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, resource+"/"+resp.Reference.String(), nil, http.StatusOK, content, nil)
+	})
+
+	t.Run("redundancy", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Add(api.RedundancyLevelHeader, "2")
+
+		var resp api.BytesPostResponse
+		jsonhttptest.ResponseUnmarshalSendHeaders(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusOK, &resp, headers)
+		if resp.Reference.Equal(swarm.MustParseHexAddress(expHash)) {
+			t.Fatal("got the reference of the data without redundancy")
+		}
+
+		_ = jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, resource+"/"+resp.Reference.String(), nil, http.StatusOK, content, nil)
+	})
+
+	t.Run("invalid redundancy level", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Add(api.RedundancyLevelHeader, "5")
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid redundancy level",
+			Code:    http.StatusBadRequest,
+		}, headers)
+	})
+
+	t.Run("encrypted redundancy", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Add(api.EncryptHeader, "true")
+		headers.Add(api.RedundancyLevelHeader, "1")
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "redundancy not supported for encrypted data",
+			Code:    http.StatusBadRequest,
+		}, headers)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		get := func(t *testing.T, headers http.Header, wantStatus int) (http.Header, []byte) {
+			t.Helper()
+
+			req, err := http.NewRequest(http.MethodGet, resource+"/"+expHash, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header = headers
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != wantStatus {
+				t.Fatalf("got response status %s, want %v", resp.Status, wantStatus)
+			}
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return resp.Header, data
+		}
+
+		for _, tc := range []struct {
+			name      string
+			header    string
+			wantRange string
+			want      []byte
+		}{
+			{name: "start", header: "bytes=0-9", wantRange: "bytes 0-9/8192", want: content[:10]},
+			{name: "across chunks", header: "bytes=4090-4105", wantRange: "bytes 4090-4105/8192", want: content[4090:4106]},
+			{name: "suffix", header: "bytes=-5", wantRange: "bytes 8187-8191/8192", want: content[8187:]},
+			{name: "open end", header: "bytes=8000-", wantRange: "bytes 8000-8191/8192", want: content[8000:]},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				headers := make(http.Header)
+				headers.Set("Range", tc.header)
+				header, data := get(t, headers, http.StatusPartialContent)
+				if !bytes.Equal(data, tc.want) {
+					t.Fatalf("got data %v, want %v", data, tc.want)
+				}
+				if got := header.Get("Content-Range"); got != tc.wantRange {
+					t.Fatalf("got content range %q, want %q", got, tc.wantRange)
+				}
+			})
+		}
+
+		t.Run("multipart", func(t *testing.T) {
+			headers := make(http.Header)
+			headers.Set("Range", "bytes=0-9,5000-5009")
+			header, data := get(t, headers, http.StatusPartialContent)
+			mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mediaType != "multipart/byteranges" {
+				t.Fatalf("got media type %q, want %q", mediaType, "multipart/byteranges")
+			}
+			mr := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+			for _, want := range [][]byte{content[:10], content[5000:5010]} {
+				part, err := mr.NextPart()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := ioutil.ReadAll(part)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("got part %v, want %v", got, want)
+				}
+			}
+		})
+
+		t.Run("if-range", func(t *testing.T) {
+			headers := make(http.Header)
+			headers.Set("Range", "bytes=0-9")
+			headers.Set("If-Range", fmt.Sprintf("%q", expHash))
+			if _, data := get(t, headers, http.StatusPartialContent); !bytes.Equal(data, content[:10]) {
+				t.Fatal("data mismatch for matching if-range")
+			}
+
+			headers.Set("If-Range", `"other"`)
+			if _, data := get(t, headers, http.StatusOK); !bytes.Equal(data, content) {
+				t.Fatal("data mismatch for not matching if-range")
+			}
+		})
+
+		t.Run("not satisfiable", func(t *testing.T) {
+			headers := make(http.Header)
+			headers.Set("Range", "bytes=9000-9010")
+			_, _ = get(t, headers, http.StatusRequestedRangeNotSatisfiable)
+		})
+	})
+
 	t.Run("not found", func(t *testing.T) {
 		jsonhttptest.ResponseDirect(t, client, http.MethodGet, resource+"/abcd", nil, http.StatusNotFound, jsonhttp.StatusResponse{
 			Message: "not found",
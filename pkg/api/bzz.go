@@ -0,0 +1,100 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/collection/entry"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+// indexDocument is served when the requested path refers to a directory.
+const indexDocument = "index.html"
+
+// bzzDownloadHandler resolves the path through the manifest referenced by
+// the manifest entry address and serves the file found under it.
+func (s *server) bzzDownloadHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["address"]
+	path := mux.Vars(r)["path"]
+	ctx := r.Context()
+
+	address, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("bzz download: parse address %s: %v", addr, err)
+		s.Logger.Error("bzz download: parse address")
+		jsonhttp.BadRequest(w, "invalid address")
+		return
+	}
+
+	// read manifest entry
+	j := joiner.NewSimpleJoiner(s.Storer)
+	buf := bytes.NewBuffer(nil)
+	_, err = file.JoinReadAll(j, address, buf)
+	if err != nil {
+		s.Logger.Debugf("bzz download: read entry %s: %v", address, err)
+		s.Logger.Errorf("bzz download: read entry %s", address)
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+	e := &entry.Entry{}
+	if err := e.UnmarshalBinary(buf.Bytes()); err != nil {
+		s.Logger.Debugf("bzz download: unmarshal entry %s: %v", address, err)
+		s.Logger.Errorf("bzz download: unmarshal entry %s", address)
+		jsonhttp.BadRequest(w, "not a manifest")
+		return
+	}
+
+	// read metadata to check that the entry references a manifest
+	buf = bytes.NewBuffer(nil)
+	_, err = file.JoinReadAll(j, e.Metadata(), buf)
+	if err != nil {
+		s.Logger.Debugf("bzz download: read metadata %s: %v", address, err)
+		s.Logger.Errorf("bzz download: read metadata %s", address)
+		jsonhttp.NotFound(w, nil)
+		return
+	}
+	metadata := &entry.Metadata{}
+	if err := json.Unmarshal(buf.Bytes(), metadata); err != nil {
+		s.Logger.Debugf("bzz download: unmarshal metadata %s: %v", address, err)
+		s.Logger.Errorf("bzz download: unmarshal metadata %s", address)
+		jsonhttp.BadRequest(w, "not a manifest")
+		return
+	}
+	if metadata.MimeType != manifest.ContentType {
+		s.Logger.Debugf("bzz download: not a manifest %s: mime type %q", address, metadata.MimeType)
+		s.Logger.Errorf("bzz download: not a manifest %s", address)
+		jsonhttp.BadRequest(w, "not a manifest")
+		return
+	}
+
+	if path == "" || strings.HasSuffix(path, "/") {
+		path += indexDocument
+	}
+
+	m := manifest.NewFromReference(manifest.NewLoadSaver(s.Storer), e.Reference())
+	fileReference, err := m.Lookup(ctx, path)
+	if err != nil {
+		s.Logger.Debugf("bzz download: lookup %s/%s: %v", address, path, err)
+		s.Logger.Errorf("bzz download: lookup %s/%s", address, path)
+		if errors.Is(err, manifest.ErrNotFound) || errors.Is(err, manifest.ErrInvalidPath) {
+			jsonhttp.NotFound(w, "path not found")
+			return
+		}
+		jsonhttp.InternalServerError(w, "manifest lookup")
+		return
+	}
+
+	s.downloadHandler(w, r, fileReference)
+}
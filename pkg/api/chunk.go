@@ -40,6 +40,11 @@ func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	putter, ok := s.stamperPutter(w, r, "chunk upload")
+	if !ok {
+		return
+	}
+
 	// if tag header is not there create a new one
 	var tag *tags.Tag
 	tagUidStr := r.Header.Get(TagHeaderUid)
@@ -84,7 +89,9 @@ func (s *server) chunkUploadHandler(w http.ResponseWriter, r *http.Request) {
 
 	}
 
-	seen, err := s.Storer.Put(ctx, storage.ModePutUpload, swarm.NewChunk(address, data))
+	// the tag uid is kept with the chunk in the push index to count the
+	// synced chunks of the tag
+	seen, err := putter.Put(ctx, storage.ModePutUpload, swarm.NewChunk(address, data).WithTagID(tag.Uid))
 	if err != nil {
 		s.Logger.Debugf("chunk upload: chunk write error: %v, addr %s", err, address)
 		s.Logger.Error("chunk upload: chunk write error")
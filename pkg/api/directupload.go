@@ -0,0 +1,198 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// DeferredUploadHeader with the value false in the HTTP request indicates
+// that the uploaded chunks need to be pushed to the network before the
+// response, instead of being synced later by the pusher.
+const DeferredUploadHeader = "swarm-deferred-upload"
+
+const (
+	// DefaultDirectUploadTimeout is the default deadline for the uploaded
+	// chunks to be synced in the direct upload.
+	DefaultDirectUploadTimeout = 5 * time.Minute
+	// directUploadConcurrency is the maximal number of the chunks of a single
+	// direct upload which are pushed at the same time.
+	directUploadConcurrency = 16
+	// directUploadRetryInterval is the time between the pushes of the chunk
+	// which was not synced.
+	directUploadRetryInterval = 500 * time.Millisecond
+)
+
+// syncStats are the statistics of the chunks synced by the direct upload.
+type syncStats struct {
+	Total  int64 `json:"total"`
+	Synced int64 `json:"synced"`
+	Failed int64 `json:"failed"`
+	// Retries is the number of the pushes of the chunks which were repeated.
+	Retries int64 `json:"retries"`
+}
+
+// requestDeferredUpload returns false if the direct upload is requested with
+// the DeferredUploadHeader. If the header is invalid or the direct upload is
+// not available, the bad request response is written and ok is false.
+func (s *server) requestDeferredUpload(w http.ResponseWriter, r *http.Request, logPrefix string) (deferred, ok bool) {
+	switch v := strings.ToLower(r.Header.Get(DeferredUploadHeader)); v {
+	case "", "true":
+		return true, true
+	case "false":
+		if s.PushSyncer == nil {
+			s.Logger.Errorf("%s: direct upload not available", logPrefix)
+			jsonhttp.BadRequest(w, "direct upload not supported")
+			return false, false
+		}
+		return false, true
+	default:
+		s.Logger.Debugf("%s: parse deferred upload %q", logPrefix, v)
+		s.Logger.Errorf("%s: parse deferred upload", logPrefix)
+		jsonhttp.BadRequest(w, "invalid deferred upload")
+		return false, false
+	}
+}
+
+// pushPutter pushes the chunks to the network as they are stored, for the
+// direct upload. The uploaded chunks are stored with ModePutUploadDirect, so
+// they are not pushed again by the pusher. The chunks are not pushed after the
+// deadline of its context, and the upload needs to be repeated to sync them.
+type pushPutter struct {
+	storage.Storer
+	ctx    context.Context
+	pusher pushsync.PushSyncer
+	sem    chan struct{} // limits the number of the pushed chunks
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	stats  syncStats
+	err    error // last error of the chunks which were not synced
+}
+
+// newPushPutter creates the putter which stores the chunks with the storer
+// and pushes them with the push syncer until the context is done.
+func newPushPutter(ctx context.Context, storer storage.Storer, pusher pushsync.PushSyncer) *pushPutter {
+	return &pushPutter{
+		Storer: storer,
+		ctx:    ctx,
+		pusher: pusher,
+		sem:    make(chan struct{}, directUploadConcurrency),
+	}
+}
+
+// Put stores the chunks and starts pushing them, blocking while too many
+// chunks are being pushed.
+func (p *pushPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exists []bool, err error) {
+	if mode == storage.ModePutUpload {
+		mode = storage.ModePutUploadDirect
+	}
+	exists, err = p.Storer.Put(ctx, mode, chs...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ch := range chs {
+		p.mu.Lock()
+		p.stats.Total++
+		p.mu.Unlock()
+
+		select {
+		case p.sem <- struct{}{}:
+		case <-p.ctx.Done():
+			p.failed(p.ctx.Err())
+			continue
+		}
+		p.wg.Add(1)
+		go p.push(ch)
+	}
+	return exists, nil
+}
+
+// push pushes the chunk until it is synced or the context is done. The synced
+// chunk is set as such in the storer, so that it can be garbage collected.
+func (p *pushPutter) push(ch swarm.Chunk) {
+	defer p.wg.Done()
+	defer func() { <-p.sem }()
+
+	for {
+		_, err := p.pusher.PushChunkToClosest(p.ctx, ch)
+		if err == nil {
+			break
+		}
+		select {
+		case <-time.After(directUploadRetryInterval):
+		case <-p.ctx.Done():
+			p.failed(err)
+			return
+		}
+		p.mu.Lock()
+		p.stats.Retries++
+		p.mu.Unlock()
+	}
+
+	if err := p.Storer.Set(p.ctx, storage.ModeSetSyncPush, ch.Address()); err != nil {
+		p.failed(fmt.Errorf("set chunk %s as synced: %w", ch.Address(), err))
+		return
+	}
+	p.mu.Lock()
+	p.stats.Synced++
+	p.mu.Unlock()
+}
+
+// failed records the chunk which was not synced.
+func (p *pushPutter) failed(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stats.Failed++
+	p.err = err
+}
+
+// wait waits for all the stored chunks to be pushed and returns the
+// statistics of the sync. The error is returned if any of the chunks was not
+// synced.
+func (p *pushPutter) wait() (syncStats, error) {
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stats.Failed > 0 {
+		return p.stats, fmt.Errorf("%d of %d chunks not synced: %w", p.stats.Failed, p.stats.Total, p.err)
+	}
+	return p.stats, nil
+}
+
+// newDirectUpload wraps the putter, so that the chunks are pushed until the
+// DirectUploadTimeout. The returned cancel function must be called when the
+// upload is done.
+func (s *server) newDirectUpload(ctx context.Context, putter storage.Storer) (*pushPutter, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, s.DirectUploadTimeout)
+	return newPushPutter(ctx, putter, s.PushSyncer), cancel
+}
+
+// waitDirectUpload waits for the chunks of the direct upload to be synced. If
+// any of them was not synced, the gateway timeout response is written and ok
+// is false.
+func (s *server) waitDirectUpload(w http.ResponseWriter, p *pushPutter, logPrefix string) (stats *syncStats, ok bool) {
+	st, err := p.wait()
+	if err != nil {
+		s.Logger.Debugf("%s: direct upload: %v", logPrefix, err)
+		s.Logger.Errorf("%s: direct upload", logPrefix)
+		jsonhttp.GatewayTimeout(w, fmt.Sprintf("%d of %d chunks not synced within the deadline", st.Failed, st.Total))
+		return nil, false
+	}
+	return &st, true
+}
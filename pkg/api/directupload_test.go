@@ -0,0 +1,145 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	mockbytes "gitlab.com/nolash/go-mockbytes"
+)
+
+// TestDirectUpload tests that the chunks of the direct uploads are pushed
+// before the response with the sync statistics.
+func TestDirectUpload(t *testing.T) {
+	g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
+	content, err := g.SequentialBytes(swarm.ChunkSize * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reference of the content, as in TestBytes
+	reference := swarm.MustParseHexAddress("29a5fb121ce96194ba8b7b823a1f9c6af87e1791f824940a53b5a7efe3f790d9")
+
+	var (
+		mu     sync.Mutex
+		pushed = make(map[string]int)
+	)
+	pushSyncer := pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		pushed[ch.Address().String()]++
+		return &pushsync.Receipt{Address: ch.Address()}, nil
+	})
+	mockStorer := mock.NewStorer()
+	client := newTestServer(t, testServerOptions{
+		Storer:     mockStorer,
+		Tags:       tags.NewTags(),
+		Logger:     logging.New(ioutil.Discard, 5),
+		PushSyncer: pushSyncer,
+	})
+
+	directUpload := make(http.Header)
+	directUpload.Add(api.DeferredUploadHeader, "false")
+
+	t.Run("bytes", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, "/bytes", bytes.NewReader(content), http.StatusOK, api.BytesPostResponse{
+			Reference: reference,
+			Sync: &api.SyncStats{
+				Total:  3,
+				Synced: 3,
+			},
+		}, directUpload)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(pushed) != 3 {
+			t.Fatalf("got %v pushed chunks, want 3", len(pushed))
+		}
+		for a := range pushed {
+			if m := mockStorer.GetModePut(swarm.MustParseHexAddress(a)); m != storage.ModePutUploadDirect {
+				t.Fatalf("chunk %s: got mode put %v, want %v", a, m, storage.ModePutUploadDirect)
+			}
+			if m := mockStorer.GetModeSet(swarm.MustParseHexAddress(a)); m != storage.ModeSetSyncPush {
+				t.Fatalf("chunk %s: got mode set %v, want %v", a, m, storage.ModeSetSyncPush)
+			}
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Add(api.DeferredUploadHeader, "false")
+		headers.Add("Content-Type", "application/octet-stream")
+
+		var resp api.FileUploadResponse
+		jsonhttptest.ResponseUnmarshalSendHeaders(t, client, http.MethodPost, "/files?name=file.bin", bytes.NewReader(content), http.StatusOK, &resp, headers)
+		// the data chunks with the metadata and the entry
+		want := api.SyncStats{
+			Total:  5,
+			Synced: 5,
+		}
+		if resp.Sync == nil || *resp.Sync != want {
+			t.Fatalf("got sync stats %+v, want %+v", resp.Sync, want)
+		}
+	})
+
+	t.Run("deferred", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Add(api.DeferredUploadHeader, "true")
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, "/bytes", bytes.NewReader(content), http.StatusOK, api.BytesPostResponse{
+			Reference: reference,
+		}, headers)
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Add(api.DeferredUploadHeader, "no")
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, "/bytes", bytes.NewReader(content), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid deferred upload",
+			Code:    http.StatusBadRequest,
+		}, headers)
+	})
+
+	t.Run("not synced", func(t *testing.T) {
+		client := newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tags.NewTags(),
+			PushSyncer: pushsyncmock.New(func(context.Context, swarm.Chunk) (*pushsync.Receipt, error) {
+				return nil, errors.New("no peers")
+			}),
+			DirectUploadTimeout: 100 * time.Millisecond,
+		})
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, "/bytes", bytes.NewReader(content), http.StatusGatewayTimeout, jsonhttp.StatusResponse{
+			Message: "3 of 3 chunks not synced within the deadline",
+			Code:    http.StatusGatewayTimeout,
+		}, directUpload)
+	})
+
+	t.Run("not available", func(t *testing.T) {
+		client := newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tags.NewTags(),
+		})
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, "/bytes", bytes.NewReader(content), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "direct upload not supported",
+			Code:    http.StatusBadRequest,
+		}, directUpload)
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"archive/tar"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/collection/entry"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const contentTypeTar = "application/x-tar"
+
+// dirUploadHandler uploads a directory supplied as a tar stream. Every
+// regular file is stored as a file entry and added to a manifest under its
+// path in the archive. The returned reference is of the entry that
+// references the manifest.
+func (s *server) dirUploadHandler(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != contentTypeTar {
+		s.Logger.Debugf("dir upload: invalid content type %q: %v", contentType, err)
+		s.Logger.Errorf("dir upload: invalid content type %q", contentType)
+		jsonhttp.BadRequest(w, "invalid content-type header")
+		return
+	}
+
+	putter, ok := s.stamperPutter(w, r, "dir upload")
+	if !ok {
+		return
+	}
+
+	reference, err := storeDir(r.Context(), r.Body, putter, requestEncrypt(r))
+	if err != nil {
+		s.Logger.Debugf("dir upload: store dir: %v", err)
+		s.Logger.Error("dir upload: store dir")
+		var e *tarError
+		if errors.As(err, &e) {
+			jsonhttp.BadRequest(w, "invalid tar stream")
+			return
+		}
+		jsonhttp.InternalServerError(w, "could not store dir")
+		return
+	}
+
+	w.Header().Set("ETag", fmt.Sprintf("%q", reference.String()))
+	jsonhttp.OK(w, fileUploadResponse{
+		Reference: reference,
+	})
+}
+
+// storeDir stores all regular files from the tar stream with their paths
+// in a manifest, returning the reference of the manifest entry.
+func storeDir(ctx context.Context, reader io.Reader, s storage.Storer, encrypt bool) (swarm.Address, error) {
+	ls := manifest.NewLoadSaver(s)
+	if encrypt {
+		ls = manifest.NewEncryptingLoadSaver(s)
+	}
+	m := manifest.New(ls)
+
+	tr := tar.NewReader(reader)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return swarm.ZeroAddress, &tarError{err: err}
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		filePath := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if filePath == "" {
+			continue
+		}
+
+		fileReader := io.Reader(tr)
+		contentType := mime.TypeByExtension(filepath.Ext(filePath))
+		if contentType == "" {
+			br := bufio.NewReader(tr)
+			buf, err := br.Peek(512)
+			if err != nil && err != io.EOF {
+				return swarm.ZeroAddress, &tarError{err: fmt.Errorf("read content type, file %q: %w", filePath, err)}
+			}
+			contentType = http.DetectContentType(buf)
+			fileReader = br
+		}
+
+		fileReference, err := storeFile(ctx, fileInfo{
+			name:        path.Base(filePath),
+			contentType: contentType,
+			size:        hdr.Size,
+			reader:      fileReader,
+		}, s, encrypt)
+		if err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("store file %q: %w", filePath, err)
+		}
+
+		if err := m.Add(ctx, filePath, fileReference); err != nil {
+			return swarm.ZeroAddress, fmt.Errorf("add file %q to manifest: %w", filePath, err)
+		}
+	}
+
+	manifestReference, err := m.Store(ctx)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	md := entry.NewMetadata(manifestReference.String())
+	md.MimeType = manifest.ContentType
+	return storeEntry(ctx, manifestReference, md, s, encrypt)
+}
+
+// tarError is returned by storeDir when the tar stream can not be read.
+type tarError struct {
+	err error
+}
+
+// Unwrap returns an underlying error.
+func (e *tarError) Unwrap() error {
+	return e.err
+}
+
+// Error implements standard go error interface.
+func (e *tarError) Error() string {
+	return e.err.Error()
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestDirs(t *testing.T) {
+	var (
+		dirUploadResource   = "/dirs"
+		bzzDownloadResource = func(addr, path string) string { return "/bzz/" + addr + "/" + path }
+		client              = newTestServer(t, testServerOptions{
+			Storer: mock.NewStorer(),
+			Tags:   tags.NewTags(),
+			Logger: logging.New(ioutil.Discard, 5),
+		})
+	)
+
+	files := []struct {
+		path        string
+		data        []byte
+		contentType string
+	}{
+		{
+			path:        "index.html",
+			data:        []byte("<h1>Swarm</h1>"),
+			contentType: "text/html; charset=utf-8",
+		},
+		{
+			path:        "./img/logo.svg",
+			data:        []byte("<svg></svg>"),
+			contentType: "image/svg+xml",
+		},
+		{
+			path:        "docs/README",
+			data:        []byte("plain text readme"),
+			contentType: "text/plain; charset=utf-8",
+		},
+		{
+			path:        "docs/index.html",
+			data:        []byte("<h1>Docs</h1>"),
+			contentType: "text/html; charset=utf-8",
+		},
+	}
+
+	var tarBuffer bytes.Buffer
+	tw := tar.NewWriter(&tarBuffer)
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name: f.path,
+			Mode: 0600,
+			Size: int64(len(f.data)),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("invalid-content-type", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, dirUploadResource, bytes.NewReader(tarBuffer.Bytes()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid content-type header",
+			Code:    http.StatusBadRequest,
+		}, nil)
+	})
+
+	t.Run("invalid-tar", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Set("Content-Type", "application/x-tar")
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, dirUploadResource, bytes.NewReader([]byte("not a tar stream")), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid tar stream",
+			Code:    http.StatusBadRequest,
+		}, headers)
+	})
+
+	req, err := http.NewRequest(http.MethodPost, dirUploadResource, bytes.NewReader(tarBuffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-tar")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got response status %s, want %v", resp.Status, http.StatusOK)
+	}
+	var upload api.FileUploadResponse
+	if err := json.NewDecoder(resp.Body).Decode(&upload); err != nil {
+		t.Fatal(err)
+	}
+	reference := upload.Reference.String()
+
+	t.Run("download", func(t *testing.T) {
+		for _, f := range []struct {
+			path        string
+			data        []byte
+			contentType string
+		}{
+			{path: "index.html", data: files[0].data, contentType: files[0].contentType},
+			{path: "", data: files[0].data, contentType: files[0].contentType},
+			{path: "img/logo.svg", data: files[1].data, contentType: files[1].contentType},
+			{path: "docs/README", data: files[2].data, contentType: files[2].contentType},
+			{path: "docs/", data: files[3].data, contentType: files[3].contentType},
+		} {
+			header := jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, bzzDownloadResource(reference, f.path), nil, http.StatusOK, f.data, nil)
+			if got := header.Get("Content-Type"); got != f.contentType {
+				t.Errorf("path %q: got content type %q, want %q", f.path, got, f.contentType)
+			}
+		}
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		headers := make(http.Header)
+		headers.Set("Content-Type", "application/x-tar")
+		headers.Set(api.EncryptHeader, "true")
+
+		var resp api.FileUploadResponse
+		jsonhttptest.ResponseUnmarshalSendHeaders(t, client, http.MethodPost, dirUploadResource, bytes.NewReader(tarBuffer.Bytes()), http.StatusOK, &resp, headers)
+
+		_ = jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, bzzDownloadResource(resp.Reference.String(), "docs/README"), nil, http.StatusOK, files[2].data, nil)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, bzzDownloadResource(reference, "img/missing.png"), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "path not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("not-a-manifest", func(t *testing.T) {
+		fileRef := "f2e761160deda91c1fbfab065a5abf530b0766b3e102b51fbd626ba37c3bc581"
+		headers := make(http.Header)
+		headers.Add("Content-Type", "image/jpeg; charset=utf-8")
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, "/files?name=my-pictures.jpeg", bytes.NewReader([]byte("this is a simple text")), http.StatusOK, api.FileUploadResponse{
+			Reference: swarm.MustParseHexAddress(fileRef),
+		}, headers)
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, bzzDownloadResource(fileRef, ""), nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "not a manifest",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
@@ -4,7 +4,15 @@
 
 package api
 
+import "github.com/ethersphere/bee/pkg/tags/tagsapi"
+
 type (
-	BytesPostResponse  = bytesPostResponse
-	FileUploadResponse = fileUploadResponse
+	BytesPostResponse     = bytesPostResponse
+	FileUploadResponse    = fileUploadResponse
+	SocPostResponse       = socPostResponse
+	FeedReferenceResponse = feedReferenceResponse
+	TagResponse           = tagsapi.Response
+	ListTagsResponse      = tagsapi.ListResponse
+	TagRequest            = tagsapi.Request
+	SyncStats             = syncStats
 )
@@ -0,0 +1,271 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/feeds"
+	"github.com/ethersphere/bee/pkg/feeds/epochs"
+	"github.com/ethersphere/bee/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+// The index of the returned feed update, hex encoded.
+const FeedIndexHeader = "swarm-feed-index"
+
+type feedReferenceResponse struct {
+	Reference swarm.Address `json:"reference"`
+}
+
+type feedUpdateRequest struct {
+	Reference swarm.Address `json:"reference"`
+}
+
+// feedUpdateHandler publishes the reference from the request body as the
+// update of the feed owned by the node. The updates of the same feed are
+// serialized, as the concurrent updates would be stored with the same index
+// and all but one of them would be lost.
+func (s *server) feedUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	owner, topic, feedType, ok := s.parseFeed(w, r, "feed update")
+	if !ok {
+		return
+	}
+
+	if s.Signer == nil {
+		s.Logger.Error("feed update: no signer")
+		jsonhttp.InternalServerError(w, "cannot sign feed update")
+		return
+	}
+	publicKey, err := s.Signer.PublicKey()
+	if err != nil {
+		s.Logger.Debugf("feed update: signer public key: %v", err)
+		s.Logger.Error("feed update: signer public key")
+		jsonhttp.InternalServerError(w, "cannot sign feed update")
+		return
+	}
+	signerAddress, err := crypto.NewEthereumAddress(*publicKey)
+	if err != nil {
+		s.Logger.Debugf("feed update: signer address: %v", err)
+		s.Logger.Error("feed update: signer address")
+		jsonhttp.InternalServerError(w, "cannot sign feed update")
+		return
+	}
+	if !bytes.Equal(signerAddress, owner.Bytes()) {
+		s.Logger.Debugf("feed update: owner %x, node address %x", owner.Bytes(), signerAddress)
+		s.Logger.Error("feed update: not the feed owner")
+		jsonhttp.Forbidden(w, "not the feed owner")
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, swarm.ChunkSize))
+	if err != nil {
+		s.Logger.Debugf("feed update: read request body: %v", err)
+		s.Logger.Error("feed update: read request body")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+	var req feedUpdateRequest
+	if err := json.Unmarshal(body, &req); err != nil || req.Reference.IsZero() {
+		s.Logger.Debugf("feed update: unmarshal request: %v", err)
+		s.Logger.Error("feed update: unmarshal request")
+		jsonhttp.BadRequest(w, "invalid reference")
+		return
+	}
+
+	putter, ok := s.stamperPutter(w, r, "feed update")
+	if !ok {
+		return
+	}
+
+	unlock := s.feedLocks.lock(owner, topic, feedType)
+	defer unlock()
+
+	var updater feeds.Updater
+	switch feedType {
+	case feeds.Epoch:
+		updater, err = epochs.NewUpdater(putter, s.Signer, topic)
+	default:
+		updater, err = sequence.NewUpdater(putter, s.Signer, topic)
+	}
+	if err != nil {
+		s.Logger.Debugf("feed update: new updater: %v", err)
+		s.Logger.Error("feed update: new updater")
+		jsonhttp.InternalServerError(w, "cannot update feed")
+		return
+	}
+
+	if err := updater.Update(r.Context(), time.Now().Unix(), req.Reference.Bytes()); err != nil {
+		s.Logger.Debugf("feed update: owner %x topic %x: %v", owner.Bytes(), topic, err)
+		s.Logger.Error("feed update: update")
+		if errors.Is(err, epochs.ErrUpdateTooEarly) {
+			jsonhttp.Conflict(w, "update too early")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot update feed")
+		return
+	}
+
+	jsonhttp.OK(w, feedReferenceResponse{
+		Reference: req.Reference,
+	})
+}
+
+// feedLocks are the locks of the feeds being updated.
+type feedLocks struct {
+	mu    sync.Mutex
+	locks map[string]*feedLock
+}
+
+// feedLock is the lock of a feed with the number of the updates holding or
+// waiting for it, so that it is removed after the last one.
+type feedLock struct {
+	sync.Mutex
+	count int
+}
+
+// lock locks the feed with the owner, topic and type, returning the function
+// which unlocks it.
+func (l *feedLocks) lock(owner *soc.Owner, topic []byte, t feeds.Type) (unlock func()) {
+	key := string(owner.Bytes()) + string(rune(t)) + string(topic)
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*feedLock)
+	}
+	fl, ok := l.locks[key]
+	if !ok {
+		fl = new(feedLock)
+		l.locks[key] = fl
+	}
+	fl.count++
+	l.mu.Unlock()
+
+	fl.Lock()
+	return func() {
+		fl.Unlock()
+
+		l.mu.Lock()
+		fl.count--
+		if fl.count == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// feedGetHandler returns the reference of the latest feed update, or the
+// update valid at the time from the at query parameter.
+func (s *server) feedGetHandler(w http.ResponseWriter, r *http.Request) {
+	owner, topic, feedType, ok := s.parseFeed(w, r, "feed get")
+	if !ok {
+		return
+	}
+
+	at := time.Now().Unix()
+	if v := r.URL.Query().Get("at"); v != "" {
+		var err error
+		at, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || at < 0 {
+			s.Logger.Debugf("feed get: parse at %s: %v", v, err)
+			s.Logger.Error("feed get: parse at")
+			jsonhttp.BadRequest(w, "invalid at")
+			return
+		}
+	}
+
+	feed := feeds.New(topic, owner, feedType)
+	var lookup feeds.Lookup
+	switch feedType {
+	case feeds.Epoch:
+		lookup = epochs.NewFinder(s.Storer, feed)
+	default:
+		lookup = sequence.NewFinder(s.Storer, feed)
+	}
+
+	ch, current, err := lookup.At(r.Context(), at, 0)
+	if err != nil {
+		s.Logger.Debugf("feed get: owner %x topic %x lookup: %v", owner.Bytes(), topic, err)
+		s.Logger.Error("feed get: lookup")
+		jsonhttp.InternalServerError(w, "feed lookup")
+		return
+	}
+	if ch == nil {
+		jsonhttp.NotFound(w, "feed update not found")
+		return
+	}
+
+	_, payload, err := feeds.FromChunk(ch)
+	if err != nil {
+		s.Logger.Debugf("feed get: owner %x topic %x update %s: %v", owner.Bytes(), topic, ch.Address(), err)
+		s.Logger.Error("feed get: invalid update")
+		jsonhttp.InternalServerError(w, "invalid feed update")
+		return
+	}
+	index, err := current.MarshalBinary()
+	if err != nil {
+		s.Logger.Debugf("feed get: marshal index: %v", err)
+		s.Logger.Error("feed get: marshal index")
+		jsonhttp.InternalServerError(w, "invalid feed update")
+		return
+	}
+
+	w.Header().Set(FeedIndexHeader, hex.EncodeToString(index))
+	w.Header().Set("Access-Control-Expose-Headers", FeedIndexHeader)
+	jsonhttp.OK(w, feedReferenceResponse{
+		Reference: swarm.NewAddress(payload),
+	})
+}
+
+// parseFeed parses the feed owner and topic from the path and the feed type
+// from the type query parameter. If the request is invalid, the response is
+// written and false is returned.
+func (s *server) parseFeed(w http.ResponseWriter, r *http.Request, logPrefix string) (owner *soc.Owner, topic []byte, t feeds.Type, ok bool) {
+	ownerHex := mux.Vars(r)["owner"]
+	ownerAddress, err := hex.DecodeString(ownerHex)
+	if err == nil {
+		owner, err = soc.NewOwner(ownerAddress)
+	}
+	if err != nil {
+		s.Logger.Debugf("%s: parse owner %s: %v", logPrefix, ownerHex, err)
+		s.Logger.Errorf("%s: parse owner", logPrefix)
+		jsonhttp.BadRequest(w, "invalid owner")
+		return nil, nil, 0, false
+	}
+
+	topicHex := mux.Vars(r)["topic"]
+	topic, err = hex.DecodeString(topicHex)
+	if err != nil || len(topic) == 0 {
+		s.Logger.Debugf("%s: parse topic %s: %v", logPrefix, topicHex, err)
+		s.Logger.Errorf("%s: parse topic", logPrefix)
+		jsonhttp.BadRequest(w, "invalid topic")
+		return nil, nil, 0, false
+	}
+
+	t = feeds.Sequence
+	if v := r.URL.Query().Get("type"); v != "" {
+		t, err = feeds.ParseType(v)
+		if err != nil {
+			s.Logger.Debugf("%s: parse type %s: %v", logPrefix, v, err)
+			s.Logger.Errorf("%s: parse type", logPrefix)
+			jsonhttp.BadRequest(w, "invalid feed type")
+			return nil, nil, 0, false
+		}
+	}
+	return owner, topic, t, true
+}
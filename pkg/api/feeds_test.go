@@ -0,0 +1,189 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestFeeds(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ownerAddress, err := crypto.NewEthereumAddress(privKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		owner        = hex.EncodeToString(ownerAddress)
+		topic        = hex.EncodeToString([]byte("topic"))
+		feedResource = func(owner, topic, query string) string { return "/feeds/" + owner + "/" + topic + query }
+		updateBody   = func(ref swarm.Address) *strings.Reader {
+			return strings.NewReader(`{"reference":"` + ref.String() + `"}`)
+		}
+		firstRef  = swarm.MustParseHexAddress("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+		secondRef = swarm.MustParseHexAddress("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")
+		client    = newTestServer(t, testServerOptions{
+			Storer: mock.NewValidatingStorer(soc.NewValidator(), tags.NewTags()),
+			Signer: crypto.NewDefaultSigner(privKey),
+			Tags:   tags.NewTags(),
+		})
+	)
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, feedResource(owner, topic, ""), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "feed update not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("sequence", func(t *testing.T) {
+		for _, ref := range []swarm.Address{firstRef, secondRef} {
+			jsonhttptest.ResponseDirect(t, client, http.MethodPost, feedResource(owner, topic, ""), updateBody(ref), http.StatusOK, api.FeedReferenceResponse{
+				Reference: ref,
+			})
+			jsonhttptest.ResponseDirect(t, client, http.MethodGet, feedResource(owner, topic, ""), nil, http.StatusOK, api.FeedReferenceResponse{
+				Reference: ref,
+			})
+		}
+
+		headers := jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodGet, feedResource(owner, topic, "?type=sequence"), nil, http.StatusOK, api.FeedReferenceResponse{
+			Reference: secondRef,
+		}, nil)
+		if got, want := headers.Get(api.FeedIndexHeader), "0000000000000001"; got != want {
+			t.Fatalf("got index %s, want %s", got, want)
+		}
+
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, feedResource(owner, topic, "?at=1"), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "feed update not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("epoch", func(t *testing.T) {
+		otherTopic := hex.EncodeToString([]byte("other topic"))
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, feedResource(owner, otherTopic, "?type=epoch"), updateBody(firstRef), http.StatusOK, api.FeedReferenceResponse{
+			Reference: firstRef,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, feedResource(owner, otherTopic, "?type=epoch"), nil, http.StatusOK, api.FeedReferenceResponse{
+			Reference: firstRef,
+		})
+		// the sequence feed with the same topic has no updates
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, feedResource(owner, otherTopic, ""), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "feed update not found",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("concurrent sequence updates", func(t *testing.T) {
+		// the lookups of the concurrent updates overlap with the slow
+		// retrieval of the chunks
+		client := newTestServer(t, testServerOptions{
+			Storer: slowGetStorer{Storer: mock.NewValidatingStorer(soc.NewValidator(), tags.NewTags())},
+			Signer: crypto.NewDefaultSigner(privKey),
+			Tags:   tags.NewTags(),
+		})
+		concurrentTopic := hex.EncodeToString([]byte("concurrent topic"))
+		const count = 10
+		var wg sync.WaitGroup
+		codes := make(chan int, count)
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ref := swarm.NewAddress(bytes.Repeat([]byte{byte(i + 1)}, swarm.HashSize))
+				resp, err := client.Post(feedResource(owner, concurrentTopic, ""), "application/json", updateBody(ref))
+				if err != nil {
+					codes <- 0
+					return
+				}
+				resp.Body.Close()
+				codes <- resp.StatusCode
+			}(i)
+		}
+		wg.Wait()
+		close(codes)
+		for code := range codes {
+			if code != http.StatusOK {
+				t.Fatalf("got status %d, want %d", code, http.StatusOK)
+			}
+		}
+
+		// every update is stored with its own index
+		resp, err := client.Get(feedResource(owner, concurrentTopic, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if got, want := resp.Header.Get(api.FeedIndexHeader), fmt.Sprintf("%016x", count-1); got != want {
+			t.Fatalf("got index %s, want %s", got, want)
+		}
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		other := hex.EncodeToString(make([]byte, soc.AddressSize))
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, feedResource(other, topic, ""), updateBody(firstRef), http.StatusForbidden, jsonhttp.StatusResponse{
+			Message: "not the feed owner",
+			Code:    http.StatusForbidden,
+		})
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, tc := range []struct {
+			name    string
+			method  string
+			url     string
+			body    string
+			message string
+		}{
+			{"owner", http.MethodGet, feedResource("abcd", topic, ""), "", "invalid owner"},
+			{"topic", http.MethodGet, feedResource(owner, "xyz", ""), "", "invalid topic"},
+			{"type", http.MethodGet, feedResource(owner, topic, "?type=foo"), "", "invalid feed type"},
+			{"at", http.MethodGet, feedResource(owner, topic, "?at=foo"), "", "invalid at"},
+			{"reference", http.MethodPost, feedResource(owner, topic, ""), `{"reference":"xyz"}`, "invalid reference"},
+			{"no reference", http.MethodPost, feedResource(owner, topic, ""), `{}`, "invalid reference"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				jsonhttptest.ResponseDirect(t, client, tc.method, tc.url, strings.NewReader(tc.body), http.StatusBadRequest, jsonhttp.StatusResponse{
+					Message: tc.message,
+					Code:    http.StatusBadRequest,
+				})
+			})
+		}
+	})
+}
+
+// slowGetStorer delays the retrieval of the chunks.
+type slowGetStorer struct {
+	storage.Storer
+}
+
+func (s slowGetStorer) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	time.Sleep(10 * time.Millisecond)
+	return s.Storer.Get(ctx, mode, addr)
+}
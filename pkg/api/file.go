@@ -7,6 +7,7 @@ package api
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,12 +16,13 @@ import (
 	"mime"
 	"mime/multipart"
 	"net/http"
-	"os"
 	"strconv"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/collection/entry"
 	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/redundancy"
 	"github.com/ethersphere/bee/pkg/file/splitter"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -32,6 +34,8 @@ const multipartFormDataMediaType = "multipart/form-data"
 
 type fileUploadResponse struct {
 	Reference swarm.Address `json:"reference"`
+	// Sync are the statistics of the direct upload.
+	Sync *syncStats `json:"sync,omitempty"`
 }
 
 // fileUploadHandler uploads the file and its metadata supplied as:
@@ -47,7 +51,27 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	level, ok := s.requestRedundancyLevel(w, r, "file upload")
+	if !ok {
+		return
+	}
+	deferred, ok := s.requestDeferredUpload(w, r, "file upload")
+	if !ok {
+		return
+	}
+	putter, ok := s.stamperPutter(w, r, "file upload")
+	if !ok {
+		return
+	}
+
 	ctx := r.Context()
+	var direct *pushPutter
+	if !deferred {
+		var cancel context.CancelFunc
+		direct, cancel = s.newDirectUpload(ctx, putter)
+		defer cancel()
+		putter = direct
+	}
 	var reader io.Reader
 	var fileName, contentLength string
 	var fileSize uint64
@@ -103,37 +127,16 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request) {
 			jsonhttp.BadRequest(w, "invalid content length header")
 			return
 		}
-	} else {
-		// copy the part to a tmp file to get its size
-		tmp, err := ioutil.TempFile("", "bee-multipart")
-		if err != nil {
-			s.Logger.Debugf("file upload: create temporary file: %v", err)
-			s.Logger.Errorf("file upload: create temporary file")
-			jsonhttp.InternalServerError(w, nil)
-			return
-		}
-		defer os.Remove(tmp.Name())
-		defer tmp.Close()
-		n, err := io.Copy(tmp, reader)
-		if err != nil {
-			s.Logger.Debugf("file upload: write temporary file: %v", err)
-			s.Logger.Error("file upload: write temporary file")
-			jsonhttp.InternalServerError(w, nil)
-			return
-		}
-		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
-			s.Logger.Debugf("file upload: seek to beginning of temporary file: %v", err)
-			s.Logger.Error("file upload: seek to beginning of temporary file")
-			jsonhttp.InternalServerError(w, nil)
-			return
-		}
-		fileSize = uint64(n)
-		reader = tmp
 	}
 
 	// first store the file and get its reference
-	sp := splitter.NewSimpleSplitter(s.Storer)
-	fr, err := file.SplitWriteAll(ctx, sp, reader, int64(fileSize))
+	reference, err := storeFile(ctx, fileInfo{
+		name:        fileName,
+		contentType: contentType,
+		size:        int64(fileSize),
+		reader:      reader,
+		redundancy:  level,
+	}, putter, requestEncrypt(r))
 	if err != nil {
 		s.Logger.Debugf("file upload: file store, file %q: %v", fileName, err)
 		s.Logger.Errorf("file upload: file store, file %q", fileName)
@@ -141,52 +144,16 @@ func (s *server) fileUploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If filename is still empty, use the file hash as the filename
-	if fileName == "" {
-		fileName = fr.String()
-	}
-
-	// then store the metadata and get its reference
-	m := entry.NewMetadata(fileName)
-	m.MimeType = contentType
-	metadataBytes, err := json.Marshal(m)
-	if err != nil {
-		s.Logger.Debugf("file upload: metadata marshal, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: metadata marshal, file %q", fileName)
-		jsonhttp.InternalServerError(w, "metadata marshal error")
-		return
-	}
-	sp = splitter.NewSimpleSplitter(s.Storer)
-	mr, err := file.SplitWriteAll(ctx, sp, bytes.NewReader(metadataBytes), int64(len(metadataBytes)))
-	if err != nil {
-		s.Logger.Debugf("file upload: metadata store, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: metadata store, file %q", fileName)
-		jsonhttp.InternalServerError(w, "could not store metadata")
-		return
-	}
-
-	// now join both references (mr,fr) to create an entry and store it.
-	entrie := entry.New(fr, mr)
-	fileEntryBytes, err := entrie.MarshalBinary()
-	if err != nil {
-		s.Logger.Debugf("file upload: entry marshal, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: entry marshal, file %q", fileName)
-		jsonhttp.InternalServerError(w, "entry marshal error")
-		return
+	resp := fileUploadResponse{
+		Reference: reference,
 	}
-
-	sp = splitter.NewSimpleSplitter(s.Storer)
-	reference, err := file.SplitWriteAll(ctx, sp, bytes.NewReader(fileEntryBytes), int64(len(fileEntryBytes)))
-	if err != nil {
-		s.Logger.Debugf("file upload: entry store, file %q: %v", fileName, err)
-		s.Logger.Errorf("file upload: entry store, file %q", fileName)
-		jsonhttp.InternalServerError(w, "could not store entry")
-		return
+	if direct != nil {
+		if resp.Sync, ok = s.waitDirectUpload(w, direct, "file upload"); !ok {
+			return
+		}
 	}
 	w.Header().Set("ETag", fmt.Sprintf("%q", reference.String()))
-	jsonhttp.OK(w, fileUploadResponse{
-		Reference: reference,
-	})
+	jsonhttp.OK(w, resp)
 }
 
 // fileDownloadHandler downloads the file given the entry's reference.
@@ -200,10 +167,18 @@ func (s *server) fileDownloadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.downloadHandler(w, r, address)
+}
+
+// downloadHandler serves the file referenced by the given entry reference.
+// Range requests are served with only the chunks that cover the range.
+func (s *server) downloadHandler(w http.ResponseWriter, r *http.Request, address swarm.Address) {
+	addr := address.String()
+
 	// read entry.
 	j := joiner.NewSimpleJoiner(s.Storer)
 	buf := bytes.NewBuffer(nil)
-	_, err = file.JoinReadAll(j, address, buf)
+	_, err := file.JoinReadAll(j, address, buf)
 	if err != nil {
 		s.Logger.Debugf("file download: read entry %s: %v", addr, err)
 		s.Logger.Errorf("file download: read entry %s", addr)
@@ -248,7 +223,7 @@ func (s *server) fileDownloadHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// send the file data back in the response
-	dataSize, err := j.Size(r.Context(), e.Reference())
+	reader, err := s.newReader(r.Context(), e.Reference())
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
 			s.Logger.Debugf("file download: not found %s: %v", e.Reference(), err)
@@ -262,43 +237,82 @@ func (s *server) fileDownloadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, pw := io.Pipe()
-	defer pr.Close()
-	go func() {
-		ctx := r.Context()
-		<-ctx.Done()
-		if err := ctx.Err(); err != nil {
-			if err := pr.CloseWithError(err); err != nil {
-				s.Logger.Debugf("file download: data join close %s: %v", addr, err)
-				s.Logger.Errorf("file download: data join close %s", addr)
-			}
-		}
-	}()
+	w.Header().Set("ETag", fmt.Sprintf("%q", e.Reference()))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", metaData.Filename))
+	w.Header().Set("Content-Type", metaData.MimeType)
+	w.Header().Set("Decompressed-Content-Length", fmt.Sprintf("%d", reader.Size()))
+	http.ServeContent(w, r, metaData.Filename, time.Time{}, reader)
+	if recovered := reader.Recovered(); len(recovered) > 0 {
+		s.Logger.Debugf("file download: %s: recovered %d chunks", e.Reference(), len(recovered))
+	}
+}
 
-	go func() {
-		_, err := file.JoinReadAll(j, e.Reference(), pw)
-		if err := pw.CloseWithError(err); err != nil {
-			s.Logger.Debugf("file download: data join close %s: %v", addr, err)
-			s.Logger.Errorf("file download: data join close %s", addr)
-		}
-	}()
+// fileInfo describes a file to be stored by storeFile.
+type fileInfo struct {
+	name        string
+	contentType string
+	size        int64 // zero if the size is not known upfront
+	reader      io.Reader
+	redundancy  redundancy.Level // redundancy level of the file data
+}
 
-	bpr := bufio.NewReader(pr)
+// storeFile stores the file data, its metadata and the entry that joins them,
+// returning the reference of the entry. If encrypt is true, all of them are
+// stored encrypted.
+func storeFile(ctx context.Context, fi fileInfo, s storage.Storer, encrypt bool) (swarm.Address, error) {
+	sp := newSplitter(s, encrypt, fi.redundancy)
+	fr, err := sp.Split(ctx, ioutil.NopCloser(fi.reader), fi.size)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("split file: %w", err)
+	}
 
-	if b, err := bpr.Peek(4096); err != nil && err != io.EOF && len(b) == 0 {
-		s.Logger.Debugf("file download: data join %s: %v", addr, err)
-		s.Logger.Errorf("file download: data join %s", addr)
-		jsonhttp.NotFound(w, nil)
-		return
+	// If filename is still empty, use the file hash as the filename
+	if fi.name == "" {
+		fi.name = fr.String()
 	}
 
-	w.Header().Set("ETag", fmt.Sprintf("%q", e.Reference()))
-	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", metaData.Filename))
-	w.Header().Set("Content-Type", metaData.MimeType)
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", dataSize))
-	w.Header().Set("Decompressed-Content-Length", fmt.Sprintf("%d", dataSize))
-	if _, err = io.Copy(w, bpr); err != nil {
-		s.Logger.Debugf("file download: data read %s: %v", addr, err)
-		s.Logger.Errorf("file download: data read %s", addr)
+	// then store the metadata and the entry that joins them
+	m := entry.NewMetadata(fi.name)
+	m.MimeType = fi.contentType
+	return storeEntry(ctx, fr, m, s, encrypt)
+}
+
+// storeEntry stores the metadata and the entry which joins it with the
+// reference, returning the reference of the entry.
+func storeEntry(ctx context.Context, reference swarm.Address, m *entry.Metadata, s storage.Storer, encrypt bool) (swarm.Address, error) {
+	metadataBytes, err := json.Marshal(m)
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("metadata marshal: %w", err)
+	}
+	sp := newSplitter(s, encrypt, redundancy.None)
+	mr, err := file.SplitWriteAll(ctx, sp, bytes.NewReader(metadataBytes), int64(len(metadataBytes)))
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("split metadata: %w", err)
+	}
+
+	// now join both references (mr,reference) to create an entry and store it.
+	e := entry.New(reference, mr)
+	fileEntryBytes, err := e.MarshalBinary()
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("entry marshal: %w", err)
+	}
+	sp = newSplitter(s, encrypt, redundancy.None)
+	er, err := file.SplitWriteAll(ctx, sp, bytes.NewReader(fileEntryBytes), int64(len(fileEntryBytes)))
+	if err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("split entry: %w", err)
+	}
+	return er, nil
+}
+
+// newSplitter returns the splitter that stores the data, encrypted or with
+// the parity chunks of the redundancy level. The data length does not need to
+// be known upfront.
+func newSplitter(s storage.Storer, encrypt bool, level redundancy.Level) file.Splitter {
+	if encrypt {
+		return splitter.NewEncryptingStreamingSplitter(s)
+	}
+	if level != redundancy.None {
+		return redundancy.NewSplitter(s, level)
 	}
+	return splitter.NewStreamingSplitter(s)
 }
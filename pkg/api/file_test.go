@@ -7,6 +7,7 @@ package api_test
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"mime"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
 	"github.com/ethersphere/bee/pkg/logging"
@@ -138,6 +140,18 @@ func TestFiles(t *testing.T) {
 			}
 		})
 
+		t.Run("binary without content length", func(t *testing.T) {
+			headers := make(http.Header)
+			headers.Add("Content-Type", "text/html; charset=utf-8")
+
+			// the request body of unknown length is sent with the chunked transfer encoding
+			_ = jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, fileUploadResource+"?name="+fileName, io.MultiReader(strings.NewReader(sampleHtml)), http.StatusOK, api.FileUploadResponse{
+				Reference: swarm.MustParseHexAddress(rootHash),
+			}, headers)
+
+			_ = jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, fileDownloadResource(rootHash), nil, http.StatusOK, []byte(sampleHtml), nil)
+		})
+
 		t.Run("multipart", func(t *testing.T) {
 			rcvdHeader := jsonhttptest.ResponseDirectWithMultiPart(t, client, http.MethodPost, fileUploadResource, fileName, []byte(sampleHtml), http.StatusOK, "", api.FileUploadResponse{
 				Reference: swarm.MustParseHexAddress(rootHash),
@@ -165,4 +179,56 @@ func TestFiles(t *testing.T) {
 		})
 	})
 
+	t.Run("encrypt-decrypt", func(t *testing.T) {
+		fileName := "my-pictures.jpeg"
+		headers := make(http.Header)
+		headers.Add("Content-Type", "image/jpeg; charset=utf-8")
+		headers.Add(api.EncryptHeader, "True")
+
+		var resp api.FileUploadResponse
+		jsonhttptest.ResponseUnmarshalSendHeaders(t, client, http.MethodPost, fileUploadResource+"?name="+fileName, bytes.NewReader(simpleData), http.StatusOK, &resp, headers)
+		if l := len(resp.Reference.Bytes()); l != encryption.ReferenceSize {
+			t.Fatalf("got reference length %d, want %d", l, encryption.ReferenceSize)
+		}
+		rootHash := resp.Reference.String()
+
+		rcvdHeader := jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, fileDownloadResource(rootHash), nil, http.StatusOK, simpleData, nil)
+		cd := rcvdHeader.Get("Content-Disposition")
+		_, params, err := mime.ParseMediaType(cd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if params["filename"] != fileName {
+			t.Fatal("Invalid file name detected")
+		}
+		if rcvdHeader.Get("Content-Type") != "image/jpeg; charset=utf-8" {
+			t.Fatal("Invalid content type detected")
+		}
+	})
+
+	t.Run("redundancy", func(t *testing.T) {
+		data := bytes.Repeat([]byte("redundancy"), 3*swarm.ChunkSize/10)
+		headers := make(http.Header)
+		headers.Add("Content-Type", "application/octet-stream")
+		headers.Add(api.RedundancyLevelHeader, "3")
+
+		var resp api.FileUploadResponse
+		jsonhttptest.ResponseUnmarshalSendHeaders(t, client, http.MethodPost, fileUploadResource+"?name=data.bin", bytes.NewReader(data), http.StatusOK, &resp, headers)
+
+		_ = jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, fileDownloadResource(resp.Reference.String()), nil, http.StatusOK, data, nil)
+	})
+
+	t.Run("range", func(t *testing.T) {
+		rootHash := "f2e761160deda91c1fbfab065a5abf530b0766b3e102b51fbd626ba37c3bc581"
+		headers := make(http.Header)
+		headers.Set("Range", "bytes=5-6")
+
+		rcvdHeader := jsonhttptest.ResponseDirectCheckBinaryResponse(t, client, http.MethodGet, fileDownloadResource(rootHash), nil, http.StatusPartialContent, []byte("is"), headers)
+		if got, want := rcvdHeader.Get("Content-Range"), fmt.Sprintf("bytes 5-6/%d", len(simpleData)); got != want {
+			t.Fatalf("got content range %q, want %q", got, want)
+		}
+		if rcvdHeader.Get("Content-Type") != "image/jpeg; charset=utf-8" {
+			t.Fatal("Invalid content type detected")
+		}
+	})
 }
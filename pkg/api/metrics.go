@@ -42,7 +42,7 @@ func newMetrics() metrics {
 }
 
 func (s *server) Metrics() []prometheus.Collector {
-	return m.PrometheusCollectorsFromFields(s.metrics)
+	return append(m.PrometheusCollectorsFromFields(s.metrics), s.prefetchMetrics.Metrics()...)
 }
 
 func (s *server) pageviewMetricsHandler(h http.Handler) http.Handler {
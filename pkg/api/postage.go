@@ -0,0 +1,95 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// PostageBatchIdHeader is the header with the hex encoded id of the postage
+// batch the uploaded chunks are stamped with.
+const PostageBatchIdHeader = "swarm-postage-batch-id"
+
+// stamperPutter returns the storer for the uploaded chunks. If the request
+// has the PostageBatchIdHeader, the chunks are stamped with the batch before
+// they are stored. If the batch is invalid, or it is missing while the stamps
+// are required, the response is written and false is returned.
+func (s *server) stamperPutter(w http.ResponseWriter, r *http.Request, logPrefix string) (storage.Storer, bool) {
+	stamper, ok := s.stamper(w, r, logPrefix)
+	if !ok {
+		return nil, false
+	}
+	if stamper == nil {
+		return s.Storer, true
+	}
+	return &stamperPutter{
+		Storer:  s.Storer,
+		stamper: stamper,
+	}, true
+}
+
+// stamper returns the stamper of the postage batch from the
+// PostageBatchIdHeader of the request, or nil if the request has no such
+// header and the stamps are not required. If the batch is invalid, or it is
+// missing while the stamps are required, the response is written and false
+// is returned.
+func (s *server) stamper(w http.ResponseWriter, r *http.Request, logPrefix string) (postage.Stamper, bool) {
+	batchHex := r.Header.Get(PostageBatchIdHeader)
+	if batchHex == "" {
+		if s.StampsRequired {
+			s.Logger.Errorf("%s: no postage batch id", logPrefix)
+			jsonhttp.BadRequest(w, "postage batch id required")
+			return nil, false
+		}
+		return nil, true
+	}
+
+	batchID, err := hex.DecodeString(batchHex)
+	if err != nil || len(batchID) != postage.BatchIDSize {
+		s.Logger.Debugf("%s: parse postage batch id %s: %v", logPrefix, batchHex, err)
+		s.Logger.Errorf("%s: parse postage batch id", logPrefix)
+		jsonhttp.BadRequest(w, "invalid postage batch id")
+		return nil, false
+	}
+
+	if s.Post == nil {
+		s.Logger.Errorf("%s: postage not available", logPrefix)
+		jsonhttp.BadRequest(w, "postage batch not found")
+		return nil, false
+	}
+	issuer, err := s.Post.GetStampIssuer(batchID)
+	if err != nil {
+		s.Logger.Debugf("%s: get stamp issuer %s: %v", logPrefix, batchHex, err)
+		s.Logger.Errorf("%s: get stamp issuer", logPrefix)
+		jsonhttp.BadRequest(w, "postage batch not found")
+		return nil, false
+	}
+
+	return postage.NewStamper(issuer, s.Signer), true
+}
+
+// stamperPutter stamps the chunks before they are stored.
+type stamperPutter struct {
+	storage.Storer
+	stamper postage.Stamper
+}
+
+func (p *stamperPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exists []bool, err error) {
+	for i, ch := range chs {
+		stamp, err := p.stamper.Stamp(ch.Address())
+		if err != nil {
+			return nil, err
+		}
+		chs[i] = ch.WithStamp(stamp)
+	}
+	return p.Storer.Put(ctx, mode, chs...)
+}
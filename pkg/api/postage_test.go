@@ -0,0 +1,184 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+// TestPostageBatchHeader tests that the chunks uploaded with the postage
+// batch header are stamped with the batch.
+func TestPostageBatchHeader(t *testing.T) {
+	var (
+		batch    = newTestBatch(t)
+		batchID  = batch.id
+		storer   = &recordingStorer{Storer: mock.NewStorer()}
+		resource = "/bytes"
+		content  = []byte("foo")
+		client   = newTestServer(t, testServerOptions{
+			Storer: storer,
+			Tags:   tags.NewTags(),
+			Signer: batch.signer,
+			Post:   batch.post,
+		})
+	)
+
+	t.Run("ok", func(t *testing.T) {
+		var resp api.BytesPostResponse
+		jsonhttptest.ResponseUnmarshalSendHeaders(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusOK, &resp, http.Header{
+			api.PostageBatchIdHeader: []string{hex.EncodeToString(batchID)},
+		})
+
+		chs := storer.chunks()
+		if len(chs) == 0 {
+			t.Fatal("no chunks stored")
+		}
+		var found bool
+		for _, ch := range chs {
+			found = found || ch.Address().Equal(resp.Reference)
+			if ch.Stamp() == nil {
+				t.Fatalf("chunk %s not stamped", ch.Address())
+			}
+			stampBytes, err := ch.Stamp().MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := postage.ValidStamp(batch.store)(ch, stampBytes); err != nil {
+				t.Fatalf("chunk %s: invalid stamp: %v", ch.Address(), err)
+			}
+		}
+		if !found {
+			t.Fatalf("chunk %s not stored", resp.Reference)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		batchID string
+		message string
+	}{
+		{"invalid batch id", "zz", "invalid postage batch id"},
+		{"short batch id", "1234", "invalid postage batch id"},
+		{"unknown batch", hex.EncodeToString(bytes.Repeat([]byte{2}, postage.BatchIDSize)), "postage batch not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, resource, bytes.NewReader(content), http.StatusBadRequest, jsonhttp.StatusResponse{
+				Message: tc.message,
+				Code:    http.StatusBadRequest,
+			}, http.Header{
+				api.PostageBatchIdHeader: []string{tc.batchID},
+			})
+		})
+	}
+}
+
+// TestPostageBatchHeaderRequired tests that the uploads without the postage
+// batch header are rejected when the stamps are required.
+func TestPostageBatchHeaderRequired(t *testing.T) {
+	var (
+		batch  = newTestBatch(t)
+		client = newTestServer(t, testServerOptions{
+			Storer:         mock.NewStorer(),
+			Tags:           tags.NewTags(),
+			Signer:         batch.signer,
+			Post:           batch.post,
+			StampsRequired: true,
+		})
+	)
+
+	jsonhttptest.ResponseDirect(t, client, http.MethodPost, "/bytes", bytes.NewReader([]byte("foo")), http.StatusBadRequest, jsonhttp.StatusResponse{
+		Message: "postage batch id required",
+		Code:    http.StatusBadRequest,
+	})
+
+	var resp api.BytesPostResponse
+	jsonhttptest.ResponseUnmarshalSendHeaders(t, client, http.MethodPost, "/bytes", bytes.NewReader([]byte("foo")), http.StatusOK, &resp, http.Header{
+		api.PostageBatchIdHeader: []string{hex.EncodeToString(batch.id)},
+	})
+}
+
+// testBatch is the postage batch of the node with the signer, which is the
+// owner of the batch, stored in the batch store.
+type testBatch struct {
+	id     []byte
+	signer crypto.Signer
+	post   postage.Service
+	store  postage.Storer
+}
+
+func newTestBatch(t *testing.T) testBatch {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, err := crypto.NewEthereumAddress(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batchID := bytes.Repeat([]byte{1}, postage.BatchIDSize)
+	batchStore := batchstore.New(statestore.NewStateStore())
+	if err := batchStore.Put(&postage.Batch{
+		ID:    batchID,
+		Value: big.NewInt(1),
+		Owner: owner,
+		Depth: 20,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	post, err := postage.NewService(statestore.NewStateStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := post.Add(postage.NewStampIssuer("label", batchID, 20, postage.BucketDepth)); err != nil {
+		t.Fatal(err)
+	}
+	return testBatch{
+		id:     batchID,
+		signer: crypto.NewDefaultSigner(key),
+		post:   post,
+		store:  batchStore,
+	}
+}
+
+// recordingStorer records the chunks that are put to the storer.
+type recordingStorer struct {
+	storage.Storer
+	mu  sync.Mutex
+	chs []swarm.Chunk
+}
+
+func (s *recordingStorer) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	s.mu.Lock()
+	s.chs = append(s.chs, chs...)
+	s.mu.Unlock()
+	return s.Storer.Put(ctx, mode, chs...)
+}
+
+func (s *recordingStorer) chunks() []swarm.Chunk {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]swarm.Chunk(nil), s.chs...)
+}
@@ -0,0 +1,204 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+var (
+	writeDeadline   = 4 * time.Second // write deadline. should be smaller than the shutdown timeout on api close
+	targetMaxLength = 2               // max target length in bytes, in order to prevent grieving by excess computation
+)
+
+// pssPostHandler sends the request body as a pss message on the topic to the
+// recipient from the recipient query parameter, with the trojan chunk mined
+// to match one of the comma separated targets from the targets query
+// parameter. The trojan chunk is stamped with the postage batch from the
+// PostageBatchIdHeader.
+func (s *server) pssPostHandler(w http.ResponseWriter, r *http.Request) {
+	topic := pss.NewTopic(mux.Vars(r)["topic"])
+
+	targetsStr := r.URL.Query().Get("targets")
+	if targetsStr == "" {
+		s.Logger.Error("pss send: no targets")
+		jsonhttp.BadRequest(w, "invalid targets")
+		return
+	}
+	var targets pss.Targets
+	for _, v := range strings.Split(targetsStr, ",") {
+		target, err := hex.DecodeString(v)
+		if err != nil || len(target) == 0 || len(target) > targetMaxLength {
+			s.Logger.Debugf("pss send: parse target %s: %v", v, err)
+			s.Logger.Error("pss send: parse target")
+			jsonhttp.BadRequest(w, "invalid targets")
+			return
+		}
+		targets = append(targets, target)
+	}
+
+	recipientStr := r.URL.Query().Get("recipient")
+	recipientBytes, err := hex.DecodeString(recipientStr)
+	if err != nil {
+		s.Logger.Debugf("pss send: parse recipient %s: %v", recipientStr, err)
+		s.Logger.Error("pss send: parse recipient")
+		jsonhttp.BadRequest(w, "invalid recipient")
+		return
+	}
+	recipient, err := crypto.DecodeSecp256k1PublicKey(recipientBytes)
+	if err != nil {
+		s.Logger.Debugf("pss send: decode recipient %s: %v", recipientStr, err)
+		s.Logger.Error("pss send: decode recipient")
+		jsonhttp.BadRequest(w, "invalid recipient")
+		return
+	}
+
+	stamper, ok := s.stamper(w, r, "pss send")
+	if !ok {
+		return
+	}
+
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, pss.MaxPayloadSize+1))
+	if err != nil {
+		s.Logger.Debugf("pss send: read body: %v", err)
+		s.Logger.Error("pss send: read body")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+	if len(payload) > pss.MaxPayloadSize {
+		s.Logger.Errorf("pss send: payload longer than %d bytes", pss.MaxPayloadSize)
+		jsonhttp.RequestEntityTooLarge(w, "payload too large")
+		return
+	}
+
+	if err := s.Pss.Send(r.Context(), topic, payload, stamper, recipient, targets); err != nil {
+		s.Logger.Debugf("pss send: %v", err)
+		s.Logger.Error("pss send")
+		jsonhttp.InternalServerError(w, "cannot send message")
+		return
+	}
+
+	jsonhttp.OK(w, nil)
+}
+
+// pssWebsocketHandler upgrades the connection to a websocket and sends the
+// received pss messages on the topic as binary messages.
+func (s *server) pssWebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  swarm.ChunkSize,
+		WriteBufferSize: swarm.ChunkSize,
+		CheckOrigin:     func(r *http.Request) bool { return true },
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.Logger.Debugf("pss ws: upgrade: %v", err)
+		s.Logger.Error("pss ws: cannot upgrade")
+		// the upgrader has already responded with the error
+		return
+	}
+
+	topic := pss.NewTopic(mux.Vars(r)["topic"])
+	s.wsWg.Add(1)
+	go s.pumpWs(conn, topic)
+}
+
+// pumpWs writes the messages on the topic to the websocket connection until
+// the connection is closed by the client or the server shuts down.
+func (s *server) pumpWs(conn *websocket.Conn, topic pss.Topic) {
+	defer s.wsWg.Done()
+
+	var (
+		dataC  = make(chan []byte)
+		gone   = make(chan struct{})
+		ticker = time.NewTicker(s.WsPingPeriod)
+		err    error
+	)
+	defer func() {
+		ticker.Stop()
+		_ = conn.Close()
+	}()
+
+	cleanup := s.Pss.Register(topic, func(_ context.Context, msg []byte) {
+		select {
+		case dataC <- msg:
+		case <-gone:
+		case <-s.quit:
+		}
+	})
+	defer cleanup()
+
+	// the messages from the client need to be read to handle the close
+	// and ping messages, reading fails when the client is gone
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				s.Logger.Debugf("pss ws: client gone: %v", err)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case b := <-dataC:
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.Logger.Debugf("pss set write deadline: %v", err)
+				return
+			}
+
+			err = conn.WriteMessage(websocket.BinaryMessage, b)
+			if err != nil {
+				s.Logger.Debugf("pss write to websocket: %v", err)
+				return
+			}
+
+		case <-s.quit:
+			// shutdown
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.Logger.Debugf("pss set write deadline: %v", err)
+				return
+			}
+			err = conn.WriteMessage(websocket.CloseMessage, []byte{})
+			if err != nil {
+				s.Logger.Debugf("pss write close message: %v", err)
+			}
+			return
+		case <-gone:
+			// client gone
+			return
+		case <-ticker.C:
+			err = conn.SetWriteDeadline(time.Now().Add(writeDeadline))
+			if err != nil {
+				s.Logger.Debugf("pss set write deadline: %v", err)
+				return
+			}
+			if err = conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				// error encountered while pinging client. client probably gone
+				if !errors.Is(err, websocket.ErrCloseSent) {
+					s.Logger.Debugf("pss ping: %v", err)
+				}
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/websocket"
+)
+
+func TestPssSend(t *testing.T) {
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodeKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		pushed     = make(chan swarm.Chunk, 1)
+		pssService = pss.New(nodeKey, logging.New(ioutil.Discard, 0))
+		recipient  = hex.EncodeToString(crypto.EncodeSecp256k1PublicKey(&recipientKey.PublicKey))
+		resource   = func(topic, query string) string { return "/pss/send/" + topic + query }
+		msg        = []byte("hello")
+	)
+	pssService.SetPushSyncer(pushsyncmock.New(func(_ context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		pushed <- ch
+		return &pushsync.Receipt{Address: ch.Address()}, nil
+	}))
+	batch := newTestBatch(t)
+	client, _, _ := newPssTestServer(t, api.Options{
+		Pss:    pssService,
+		Signer: batch.signer,
+		Post:   batch.post,
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource("testtopic", "?targets=1234,aa&recipient="+recipient), bytes.NewReader(msg), http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+
+		var ch swarm.Chunk
+		select {
+		case ch = <-pushed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("chunk not pushed")
+		}
+		if a := ch.Address().String(); !strings.HasPrefix(a, "1234") && !strings.HasPrefix(a, "aa") {
+			t.Fatalf("chunk address %s does not match targets", a)
+		}
+		topic, got, err := pss.Unwrap(recipientKey, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if topic != pss.NewTopic("testtopic") {
+			t.Fatalf("got topic %x", topic)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Fatalf("got message %q, want %q", got, msg)
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"no targets", "?recipient=" + recipient, "invalid targets"},
+		{"invalid target", "?targets=xyz&recipient=" + recipient, "invalid targets"},
+		{"long target", "?targets=123456&recipient=" + recipient, "invalid targets"},
+		{"no recipient", "?targets=12", "invalid recipient"},
+		{"invalid recipient", "?targets=12&recipient=1234", "invalid recipient"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource("testtopic", tc.query), bytes.NewReader(msg), http.StatusBadRequest, jsonhttp.StatusResponse{
+				Message: tc.message,
+				Code:    http.StatusBadRequest,
+			})
+		})
+	}
+
+	t.Run("stamped", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, resource("testtopic", "?targets=12&recipient="+recipient), bytes.NewReader(msg), http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		}, http.Header{
+			api.PostageBatchIdHeader: []string{hex.EncodeToString(batch.id)},
+		})
+
+		var ch swarm.Chunk
+		select {
+		case ch = <-pushed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("chunk not pushed")
+		}
+		if ch.Stamp() == nil {
+			t.Fatal("chunk not stamped")
+		}
+		stampBytes, err := ch.Stamp().MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := postage.ValidStamp(batch.store)(ch, stampBytes); err != nil {
+			t.Fatalf("invalid stamp: %v", err)
+		}
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		jsonhttptest.ResponseDirectSendHeadersAndReceiveHeaders(t, client, http.MethodPost, resource("testtopic", "?targets=12&recipient="+recipient), bytes.NewReader(msg), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "postage batch not found",
+			Code:    http.StatusBadRequest,
+		}, http.Header{
+			api.PostageBatchIdHeader: []string{hex.EncodeToString(bytes.Repeat([]byte{2}, postage.BatchIDSize))},
+		})
+	})
+
+	t.Run("stamps required", func(t *testing.T) {
+		client, _, _ := newPssTestServer(t, api.Options{
+			Pss:            pssService,
+			Signer:         batch.signer,
+			Post:           batch.post,
+			StampsRequired: true,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource("testtopic", "?targets=12&recipient="+recipient), bytes.NewReader(msg), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "postage batch id required",
+			Code:    http.StatusBadRequest,
+		})
+		select {
+		case <-pushed:
+			t.Fatal("chunk pushed")
+		default:
+		}
+	})
+
+	t.Run("payload too large", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, resource("testtopic", "?targets=12&recipient="+recipient), bytes.NewReader(make([]byte, pss.MaxPayloadSize+1)), http.StatusRequestEntityTooLarge, jsonhttp.StatusResponse{
+			Message: "payload too large",
+			Code:    http.StatusRequestEntityTooLarge,
+		})
+	})
+}
+
+func TestPssWebsocket(t *testing.T) {
+	nodeKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pssService := pss.New(nodeKey, logging.New(ioutil.Discard, 0))
+	registered := make(chan struct{}, 1)
+	_, addr, closer := newPssTestServer(t, api.Options{
+		Pss: registerNotifyingPss{Interface: pssService, registered: registered},
+	})
+
+	u := url.URL{Scheme: "ws", Host: addr, Path: "/pss/subscribe/testtopic"}
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the handler is registered after the connection is upgraded
+	select {
+	case <-registered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler not registered")
+	}
+
+	msg := []byte("hello")
+	ch, err := pss.Wrap(context.Background(), pss.NewTopic("testtopic"), msg, &nodeKey.PublicKey, pss.Targets{{0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pssService.TryUnwrap(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	_, got, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Fatalf("got message %q, want %q", got, msg)
+	}
+
+	// the websocket is closed on the server shutdown
+	if err := closer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNoStatusReceived) {
+		t.Fatalf("got error %v, want close error", err)
+	}
+}
+
+// newPssTestServer returns the http client, the address and the closer of
+// the api server with the options, which include the pss service.
+func newPssTestServer(t *testing.T, o api.Options) (*http.Client, string, interface{ Close() error }) {
+	o.Logger = logging.New(ioutil.Discard, 0)
+	o.WsPingPeriod = 60 * time.Second
+	s := api.New(o)
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	client := &http.Client{
+		Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			u, err := url.Parse(ts.URL + r.URL.String())
+			if err != nil {
+				return nil, err
+			}
+			r.URL = u
+			return ts.Client().Transport.RoundTrip(r)
+		}),
+	}
+	return client, ts.Listener.Addr().String(), s
+}
+
+// registerNotifyingPss signals on the registered channel when a handler is
+// registered.
+type registerNotifyingPss struct {
+	pss.Interface
+	registered chan struct{}
+}
+
+func (p registerNotifyingPss) Register(topic pss.Topic, h pss.Handler) func() {
+	cleanup := p.Interface.Register(topic, h)
+	p.registered <- struct{}{}
+	return cleanup
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
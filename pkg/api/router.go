@@ -42,6 +42,10 @@ func (s *server) setupRouting() {
 		"GET": http.HandlerFunc(s.fileDownloadHandler),
 	})
 
+	handle(router, "/dirs", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.dirUploadHandler),
+	})
+
 	handle(router, "/bytes", jsonhttp.MethodHandler{
 		"POST": http.HandlerFunc(s.bytesUploadHandler),
 	})
@@ -54,6 +58,37 @@ func (s *server) setupRouting() {
 		"POST": http.HandlerFunc(s.chunkUploadHandler),
 	})
 
+	handle(router, "/soc/{owner}/{id}", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.socUploadHandler),
+	})
+
+	handle(router, "/feeds/{owner}/{topic}", jsonhttp.MethodHandler{
+		"GET":  http.HandlerFunc(s.feedGetHandler),
+		"POST": http.HandlerFunc(s.feedUpdateHandler),
+	})
+
+	handle(router, "/tags", jsonhttp.MethodHandler{
+		"GET":  http.HandlerFunc(s.tags.List),
+		"POST": http.HandlerFunc(s.tags.Create),
+	})
+	handle(router, "/tags/{uid}", jsonhttp.MethodHandler{
+		"GET":    http.HandlerFunc(s.tags.Get),
+		"PATCH":  http.HandlerFunc(s.tags.Patch),
+		"DELETE": http.HandlerFunc(s.tags.Delete),
+	})
+	handle(router, "/tags/{uid}/stream", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.tags.Stream),
+	})
+
+	handle(router, "/pss/send/{topic}", jsonhttp.MethodHandler{
+		"POST": http.HandlerFunc(s.pssPostHandler),
+	})
+	handle(router, "/pss/subscribe/{topic}", http.HandlerFunc(s.pssWebsocketHandler))
+
+	handle(router, "/bzz/{address}/{path:.*}", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.bzzDownloadHandler),
+	})
+
 	s.Handler = web.ChainHandlers(
 		logging.NewHTTPAccessLogHandler(s.Logger, logrus.InfoLevel, "api access"),
 		handlers.CompressHandler,
@@ -65,7 +100,7 @@ func (s *server) setupRouting() {
 					w.Header().Set("Access-Control-Allow-Credentials", "true")
 					w.Header().Set("Access-Control-Allow-Origin", o)
 					w.Header().Set("Access-Control-Allow-Headers", "Origin, Accept, Authorization, Content-Type, X-Requested-With, Access-Control-Request-Headers, Access-Control-Request-Method")
-					w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS, POST, PUT, DELETE")
+					w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS, POST, PUT, PATCH, DELETE")
 					w.Header().Set("Access-Control-Max-Age", "3600")
 				}
 				h.ServeHTTP(w, r)
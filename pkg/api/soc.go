@@ -0,0 +1,114 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type socPostResponse struct {
+	Reference swarm.Address `json:"reference"`
+}
+
+// socUploadHandler uploads a single-owner chunk. The request body is the data
+// of the wrapped content addressed chunk, span included, and the signature
+// of the owner is provided with the sig query parameter.
+func (s *server) socUploadHandler(w http.ResponseWriter, r *http.Request) {
+	ownerHex := mux.Vars(r)["owner"]
+	owner, err := hex.DecodeString(ownerHex)
+	if err != nil || len(owner) != soc.AddressSize {
+		s.Logger.Debugf("soc upload: parse owner %s: %v", ownerHex, err)
+		s.Logger.Error("soc upload: parse owner")
+		jsonhttp.BadRequest(w, "invalid owner")
+		return
+	}
+	idHex := mux.Vars(r)["id"]
+	id, err := hex.DecodeString(idHex)
+	if err != nil || len(id) != soc.IdSize {
+		s.Logger.Debugf("soc upload: parse id %s: %v", idHex, err)
+		s.Logger.Error("soc upload: parse id")
+		jsonhttp.BadRequest(w, "invalid id")
+		return
+	}
+	sigHex := r.URL.Query().Get("sig")
+	signature, err := hex.DecodeString(sigHex)
+	if err != nil || len(signature) != soc.SignatureSize {
+		s.Logger.Debugf("soc upload: parse signature %s: %v", sigHex, err)
+		s.Logger.Error("soc upload: parse signature")
+		jsonhttp.BadRequest(w, "invalid signature")
+		return
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, swarm.ChunkSize+8+1))
+	if err != nil {
+		s.Logger.Debugf("soc upload: read chunk data: %v", err)
+		s.Logger.Error("soc upload: read chunk data")
+		jsonhttp.InternalServerError(w, "cannot read chunk data")
+		return
+	}
+
+	sch, err := soc.NewSigned(id, signature, data)
+	if err != nil {
+		s.Logger.Debugf("soc upload: create chunk: %v", err)
+		s.Logger.Error("soc upload: create chunk")
+		if errors.Is(err, soc.ErrInvalidChunk) {
+			jsonhttp.BadRequest(w, "invalid chunk")
+			return
+		}
+		jsonhttp.InternalServerError(w, "cannot create chunk")
+		return
+	}
+	if !bytes.Equal(sch.Owner().Bytes(), owner) {
+		s.Logger.Debugf("soc upload: owner %x, signed by %x", owner, sch.Owner().Bytes())
+		s.Logger.Error("soc upload: signature does not match owner")
+		jsonhttp.Unauthorized(w, "invalid signature")
+		return
+	}
+
+	ch, err := sch.ToChunk()
+	if err != nil {
+		s.Logger.Debugf("soc upload: serialize chunk: %v", err)
+		s.Logger.Error("soc upload: serialize chunk")
+		jsonhttp.InternalServerError(w, "cannot create chunk")
+		return
+	}
+
+	ctx := r.Context()
+	putter, ok := s.stamperPutter(w, r, "soc upload")
+	if !ok {
+		return
+	}
+	if _, err := putter.Put(ctx, storage.ModePutUpload, ch); err != nil {
+		s.Logger.Debugf("soc upload: chunk write error: %v, addr %s", err, ch.Address())
+		s.Logger.Error("soc upload: chunk write error")
+		jsonhttp.BadRequest(w, "chunk write error")
+		return
+	}
+
+	if strings.ToLower(r.Header.Get(PinHeaderName)) == "true" {
+		if err := s.Storer.Set(ctx, storage.ModeSetPin, ch.Address()); err != nil {
+			s.Logger.Debugf("soc upload: chunk pinning error: %v, addr %s", err, ch.Address())
+			s.Logger.Error("soc upload: chunk pinning error")
+			jsonhttp.InternalServerError(w, "cannot pin chunk")
+			return
+		}
+	}
+
+	jsonhttp.OK(w, socPostResponse{
+		Reference: ch.Address(),
+	})
+}
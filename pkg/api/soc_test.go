@@ -0,0 +1,123 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func TestSoc(t *testing.T) {
+	var (
+		socResource = func(owner, id, sig string) string { return "/soc/" + owner + "/" + id + "?sig=" + sig }
+		mockStorer  = mock.NewValidatingStorer(soc.NewValidator(), tags.NewTags())
+		client      = newTestServer(t, testServerOptions{
+			Storer: mockStorer,
+			Tags:   tags.NewTags(),
+		})
+	)
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	ownerAddress, err := crypto.NewEthereumAddress(privKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := hex.EncodeToString(ownerAddress)
+
+	id := make([]byte, soc.IdSize)
+	id[0] = 1
+	cac, err := content.NewChunk([]byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := soc.New(id, cac, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := s.ToChunk()
+	if err != nil {
+		t.Fatal(err)
+	}
+	idHex := hex.EncodeToString(id)
+	sig := hex.EncodeToString(s.Signature())
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, socResource(owner, idHex, sig), bytes.NewReader(cac.Data()), http.StatusOK, api.SocPostResponse{
+			Reference: ch.Address(),
+		})
+
+		got, err := mockStorer.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.Data(), ch.Data()) {
+			t.Fatalf("got data %x, want %x", got.Data(), ch.Data())
+		}
+	})
+
+	t.Run("invalid owner", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, socResource("abcd", idHex, sig), bytes.NewReader(cac.Data()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid owner",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, socResource(owner, "abcd", sig), bytes.NewReader(cac.Data()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid id",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, socResource(owner, idHex, "abcd"), bytes.NewReader(cac.Data()), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid signature",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("invalid chunk data", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, socResource(owner, idHex, sig), bytes.NewReader([]byte("foo")), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid chunk",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("other owner", func(t *testing.T) {
+		otherOwner := make([]byte, soc.AddressSize)
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, socResource(hex.EncodeToString(otherOwner), idHex, sig), bytes.NewReader(cac.Data()), http.StatusUnauthorized, jsonhttp.StatusResponse{
+			Message: "invalid signature",
+			Code:    http.StatusUnauthorized,
+		})
+	})
+
+	t.Run("signature of other data", func(t *testing.T) {
+		other, err := content.NewChunk([]byte("bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonhttptest.ResponseDirect(t, client, http.MethodPost, socResource(owner, idHex, sig), bytes.NewReader(other.Data()), http.StatusUnauthorized, jsonhttp.StatusResponse{
+			Message: "invalid signature",
+			Code:    http.StatusUnauthorized,
+		})
+	})
+}
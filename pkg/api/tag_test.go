@@ -0,0 +1,211 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/api"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+)
+
+func tagResource(uid uint32) string { return "/tags/" + strconv.FormatUint(uint64(uid), 10) }
+
+func TestTags(t *testing.T) {
+	tag := tags.NewTags()
+	client := newTestServer(t, testServerOptions{
+		Tags: tag,
+	})
+
+	var created []api.TagResponse
+	for i := 0; i < 3; i++ {
+		var tr api.TagResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodPost, "/tags?name=tag"+strconv.Itoa(i), nil, http.StatusOK, &tr)
+		created = append(created, tr)
+	}
+
+	t.Run("list", func(t *testing.T) {
+		var resp api.ListTagsResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodGet, "/tags", nil, http.StatusOK, &resp)
+		if len(resp.Tags) != len(created) {
+			t.Fatalf("got %d tags, want %d", len(resp.Tags), len(created))
+		}
+		for i := 1; i < len(resp.Tags); i++ {
+			if resp.Tags[i-1].Uid >= resp.Tags[i].Uid {
+				t.Fatal("tags not ordered by uid")
+			}
+		}
+	})
+
+	t.Run("list page", func(t *testing.T) {
+		var all, page api.ListTagsResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodGet, "/tags", nil, http.StatusOK, &all)
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodGet, "/tags?offset=1&limit=1", nil, http.StatusOK, &page)
+		if len(page.Tags) != 1 {
+			t.Fatalf("got %d tags, want 1", len(page.Tags))
+		}
+		if page.Tags[0].Uid != all.Tags[1].Uid {
+			t.Fatalf("got tag %d, want %d", page.Tags[0].Uid, all.Tags[1].Uid)
+		}
+
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodGet, "/tags?offset=10", nil, http.StatusOK, &page)
+		if len(page.Tags) != 0 {
+			t.Fatalf("got %d tags, want none", len(page.Tags))
+		}
+	})
+
+	t.Run("list invalid limit", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/tags?limit=0", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid limit",
+			Code:    http.StatusBadRequest,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/tags?offset=-1", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid offset",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		address := swarm.MustParseHexAddress("aabbcc")
+		body, err := json.Marshal(api.TagRequest{Address: address})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var tr api.TagResponse
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodPatch, tagResource(created[0].Uid), bytes.NewReader(body), http.StatusOK, &tr)
+		if !tr.Address.Equal(address) {
+			t.Fatalf("got address %s, want %s", tr.Address, address)
+		}
+
+		jsonhttptest.ResponseUnmarshal(t, client, http.MethodGet, tagResource(created[0].Uid), nil, http.StatusOK, &tr)
+		if !tr.Address.Equal(address) {
+			t.Fatalf("got address %s, want %s", tr.Address, address)
+		}
+	})
+
+	t.Run("patch invalid", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodPatch, tagResource(created[0].Uid), strings.NewReader("{}"), http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, tagResource(created[2].Uid), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, tagResource(created[2].Uid), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "tag not present",
+			Code:    http.StatusNotFound,
+		})
+		jsonhttptest.ResponseDirect(t, client, http.MethodDelete, tagResource(created[2].Uid), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "tag not present",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid uid", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, client, http.MethodGet, "/tags/abc", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid uid",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
+
+func TestTagStream(t *testing.T) {
+	tag := tags.NewTags()
+	client := newTestServer(t, testServerOptions{
+		Tags: tag,
+	})
+
+	ta, err := tag.Create("stream", 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tagResource(ta.Uid)+"/stream", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("got content type %q, want text/event-stream", ct)
+	}
+
+	events := make(chan streamEvent)
+	go func() {
+		defer close(events)
+		readStreamEvents(resp, events)
+	}()
+
+	// the current state is sent right away
+	e := <-events
+	if e.name != "progress" || e.data.Synced != 0 {
+		t.Fatalf("got event %s %+v, want progress with no synced chunks", e.name, e.data)
+	}
+
+	ta.IncN(tags.StateStored, 2)
+	ta.IncN(tags.StateSynced, 2)
+
+	// the stream ends with the done event with all the chunks synced
+	var last streamEvent
+	for e := range events {
+		last = e
+	}
+	if last.name != "done" {
+		t.Fatalf("got last event %q, want done", last.name)
+	}
+	if last.data.Synced != 2 || last.data.Stored != 2 {
+		t.Fatalf("got last event %+v, want 2 stored and synced chunks", last.data)
+	}
+}
+
+type streamEvent struct {
+	name string
+	data api.TagResponse
+}
+
+// readStreamEvents sends the server-sent events from the response until the
+// stream ends.
+func readStreamEvents(resp *http.Response, events chan<- streamEvent) {
+	var e streamEvent
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.data); err != nil {
+				return
+			}
+		case line == "":
+			events <- e
+			e = streamEvent{}
+		}
+	}
+}
@@ -8,12 +8,14 @@ import (
 	"errors"
 
 	"github.com/ethersphere/bee/pkg/collection"
+	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 var (
-	_                  = collection.Entry(&Entry{})
-	serializedDataSize = swarm.SectionSize * 2
+	_                           = collection.Entry(&Entry{})
+	serializedDataSize          = swarm.SectionSize * 2
+	encryptedSerializedDataSize = encryption.ReferenceSize * 2
 )
 
 // Entry provides addition of metadata to a data reference.
@@ -50,11 +52,19 @@ func (e *Entry) MarshalBinary() ([]byte, error) {
 }
 
 // UnmarshalBinary implements encoding.BinaryUnmarshaler
+//
+// Both the references of plain and of encrypted data are accepted.
 func (e *Entry) UnmarshalBinary(b []byte) error {
-	if len(b) != serializedDataSize {
+	var size int
+	switch len(b) {
+	case serializedDataSize:
+		size = swarm.SectionSize
+	case encryptedSerializedDataSize:
+		size = encryption.ReferenceSize
+	default:
 		return errors.New("invalid data length")
 	}
-	e.reference = swarm.NewAddress(b[:swarm.SectionSize])
-	e.metadata = swarm.NewAddress(b[swarm.SectionSize:])
+	e.reference = swarm.NewAddress(b[:size])
+	e.metadata = swarm.NewAddress(b[size:])
 	return nil
 }
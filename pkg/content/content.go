@@ -0,0 +1,60 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package content provides the construction of content addressed chunks.
+package content
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+	bmtlegacy "github.com/ethersphere/bmt/legacy"
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrInvalidData is returned when the chunk data can not be hashed into a
+// content addressed chunk.
+var ErrInvalidData = errors.New("invalid content addressed chunk data")
+
+// hasherPool is the pool of the bmt trees shared by the hashers of all the
+// created chunks.
+var hasherPool = bmtlegacy.NewTreePool(hashFunc, swarm.Branches, bmtlegacy.PoolSize)
+
+// NewChunk creates a new content addressed chunk from the payload, prefixed
+// with the span of the payload length.
+func NewChunk(payload []byte) (swarm.Chunk, error) {
+	if len(payload) > swarm.ChunkSize {
+		return nil, fmt.Errorf("%w: payload length %d", ErrInvalidData, len(payload))
+	}
+	data := make([]byte, 8+len(payload))
+	binary.LittleEndian.PutUint64(data, uint64(len(payload)))
+	copy(data[8:], payload)
+	return NewChunkWithSpan(data)
+}
+
+// NewChunkWithSpan creates a new content addressed chunk from the chunk data
+// that already starts with the span.
+func NewChunkWithSpan(data []byte) (swarm.Chunk, error) {
+	if len(data) < 8 || len(data) > swarm.ChunkSize+8 {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidData, len(data))
+	}
+	hasher := bmtlegacy.New(hasherPool)
+	// give the tree back to the pool also if the hashing fails
+	defer hasher.Reset()
+	if err := hasher.SetSpan(int64(binary.LittleEndian.Uint64(data[:8]))); err != nil {
+		return nil, err
+	}
+	if _, err := hasher.Write(data[8:]); err != nil {
+		return nil, err
+	}
+	return swarm.NewChunk(swarm.NewAddress(hasher.Sum(nil)), data), nil
+}
+
+// hashFunc is a hasher factory used by the bmt hasher
+func hashFunc() hash.Hash {
+	return sha3.NewLegacyKeccak256()
+}
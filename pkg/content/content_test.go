@@ -0,0 +1,67 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package content_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/validator"
+)
+
+// TestNewChunk checks that the chunk created from the payload has the content
+// address of its data.
+func TestNewChunk(t *testing.T) {
+	// pre-generated hex of 'foo' from legacy bmt
+	bmtHashOfFoo := "2387e8e7d8a48c2a9339c97c1dc3461a9a7aa07e994c5cb8b38fd7c1b3e6ea48"
+
+	ch, err := content.NewChunk([]byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch.Address().String() != bmtHashOfFoo {
+		t.Fatalf("got address %s, want %s", ch.Address(), bmtHashOfFoo)
+	}
+	if !validator.NewContentAddressValidator().Validate(ch) {
+		t.Fatal("chunk not valid")
+	}
+
+	_, err = content.NewChunk(make([]byte, swarm.ChunkSize+1))
+	if !errors.Is(err, content.ErrInvalidData) {
+		t.Fatalf("got error %v, want %v", err, content.ErrInvalidData)
+	}
+}
+
+// TestNewChunkConcurrent checks that the chunks created concurrently, more
+// than the trees of the shared bmt pool, have the content addresses of their
+// data.
+func TestNewChunkConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ch, err := content.NewChunk(bytes.Repeat([]byte{byte(i)}, i*40))
+			if err != nil {
+				errs <- err
+				return
+			}
+			if !validator.NewContentAddressValidator().Validate(ch) {
+				errs <- fmt.Errorf("chunk %d not valid", i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
@@ -23,10 +23,16 @@ func NewOverlayAddress(p ecdsa.PublicKey, networkID uint64) (swarm.Address, erro
 	if err != nil {
 		return swarm.ZeroAddress, err
 	}
+	return NewOverlayFromEthereumAddress(ethAddr, networkID), nil
+}
+
+// NewOverlayFromEthereumAddress constructs a Swarm Address for the ethereum
+// address of the public key.
+func NewOverlayFromEthereumAddress(ethAddr []byte, networkID uint64) swarm.Address {
 	netIDBytes := make([]byte, 8)
 	binary.LittleEndian.PutUint64(netIDBytes, networkID)
 	h := sha3.Sum256(append(ethAddr, netIDBytes...))
-	return swarm.NewAddress(h[:]), nil
+	return swarm.NewAddress(h[:])
 }
 
 // GenerateSecp256k1Key generates an ECDSA private key using
@@ -49,6 +55,21 @@ func DecodeSecp256k1PrivateKey(data []byte) (*ecdsa.PrivateKey, error) {
 	return (*ecdsa.PrivateKey)(privk), nil
 }
 
+// EncodeSecp256k1PublicKey encodes raw ECDSA public key in a 33-byte compressed format.
+func EncodeSecp256k1PublicKey(k *ecdsa.PublicKey) []byte {
+	return (*btcec.PublicKey)(k).SerializeCompressed()
+}
+
+// DecodeSecp256k1PublicKey decodes raw ECDSA public key in compressed or
+// uncompressed format.
+func DecodeSecp256k1PublicKey(data []byte) (*ecdsa.PublicKey, error) {
+	pubk, err := btcec.ParsePubKey(data, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	return (*ecdsa.PublicKey)(pubk), nil
+}
+
 // NewEthereumAddress returns a binary representation of ethereum blockchain address.
 // This function is based on github.com/ethereum/go-ethereum/crypto.PubkeyToAddress.
 func NewEthereumAddress(p ecdsa.PublicKey) ([]byte, error) {
@@ -62,6 +62,24 @@ func TestEncodeSecp256k1PrivateKey(t *testing.T) {
 	}
 }
 
+func TestEncodeSecp256k1PublicKey(t *testing.T) {
+	k, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := crypto.EncodeSecp256k1PublicKey(&k.PublicKey)
+	if l := len(d); l != 33 {
+		t.Fatalf("got encoded public key length %v, want %v", l, 33)
+	}
+	pub, err := crypto.DecodeSecp256k1PublicKey(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.X.Cmp(k.PublicKey.X) != 0 || pub.Y.Cmp(k.PublicKey.Y) != 0 {
+		t.Fatal("encoded and decoded keys are not equal")
+	}
+}
+
 func TestNewEthereumAddress(t *testing.T) {
 	privKeyHex := "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
 	privKeyBytes, err := hex.DecodeString(privKeyHex)
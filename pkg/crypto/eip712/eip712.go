@@ -0,0 +1,310 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package eip712 implements the hashing of the typed structured data as
+// specified by EIP-712 (https://eips.ethereum.org/EIPS/eip-712).
+//
+// The supported atomic types are address, bool, string, bytes, the fixed
+// size bytes and the signed and unsigned integers. Arrays are not supported.
+package eip712
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// DomainType is the name of the type of the domain separator.
+const DomainType = "EIP712Domain"
+
+var (
+	// ErrUnknownType is returned when the type is not defined in the types of
+	// the typed data and is not an atomic type.
+	ErrUnknownType = errors.New("unknown type")
+	// ErrInvalidValue is returned when the value of a field does not match its
+	// type.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// Type is a named field of a struct type.
+type Type struct {
+	Name string
+	Type string
+}
+
+// Types are the definitions of the struct types by their names.
+type Types map[string][]Type
+
+// Domain is the domain separator of the typed data. Only the set fields are
+// part of the domain and they must be listed in the DomainType of the types.
+type Domain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract []byte
+	Salt              []byte
+}
+
+// Map returns the set fields of the domain by their names.
+func (d Domain) Map() map[string]interface{} {
+	m := make(map[string]interface{})
+	if d.Name != "" {
+		m["name"] = d.Name
+	}
+	if d.Version != "" {
+		m["version"] = d.Version
+	}
+	if d.ChainID != nil {
+		m["chainId"] = d.ChainID
+	}
+	if d.VerifyingContract != nil {
+		m["verifyingContract"] = d.VerifyingContract
+	}
+	if d.Salt != nil {
+		m["salt"] = d.Salt
+	}
+	return m
+}
+
+// TypedData is the structured data of the primary type in the domain.
+type TypedData struct {
+	Types       Types
+	PrimaryType string
+	Domain      Domain
+	Message     map[string]interface{}
+}
+
+// SignDigest returns the digest of the typed data that is signed:
+//
+//	keccak256("\x19\x01" ‖ hashStruct(domain) ‖ hashStruct(message))
+func (td *TypedData) SignDigest() ([]byte, error) {
+	domainHash, err := td.HashStruct(DomainType, td.Domain.Map())
+	if err != nil {
+		return nil, fmt.Errorf("hash domain: %w", err)
+	}
+	messageHash, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, fmt.Errorf("hash message: %w", err)
+	}
+	return keccak256([]byte("\x19\x01"), domainHash, messageHash), nil
+}
+
+// HashStruct returns the hash of the data of the struct type:
+//
+//	keccak256(typeHash ‖ encodeData(data))
+func (td *TypedData) HashStruct(primaryType string, data map[string]interface{}) ([]byte, error) {
+	typeHash, err := td.TypeHash(primaryType)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := td.encodeData(primaryType, data)
+	if err != nil {
+		return nil, err
+	}
+	return keccak256(typeHash, encoded), nil
+}
+
+// TypeHash returns the hash of the encoded struct type.
+func (td *TypedData) TypeHash(primaryType string) ([]byte, error) {
+	encoded, err := td.EncodeType(primaryType)
+	if err != nil {
+		return nil, err
+	}
+	return keccak256([]byte(encoded)), nil
+}
+
+// EncodeType returns the encoding of the struct type followed by the
+// encodings of the referenced struct types sorted by their names, for
+// example:
+//
+//	Mail(Person from,Person to,string contents)Person(string name,address wallet)
+func (td *TypedData) EncodeType(primaryType string) (string, error) {
+	if _, ok := td.Types[primaryType]; !ok {
+		return "", fmt.Errorf("%s: %w", primaryType, ErrUnknownType)
+	}
+	deps := make(map[string]struct{})
+	td.dependencies(primaryType, deps)
+	delete(deps, primaryType)
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range append([]string{primaryType}, names...) {
+		b.WriteString(name)
+		b.WriteString("(")
+		for i, field := range td.Types[name] {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(field.Type)
+			b.WriteString(" ")
+			b.WriteString(field.Name)
+		}
+		b.WriteString(")")
+	}
+	return b.String(), nil
+}
+
+// dependencies adds the struct type and all struct types referenced by it to
+// the deps.
+func (td *TypedData) dependencies(primaryType string, deps map[string]struct{}) {
+	if _, ok := deps[primaryType]; ok {
+		return
+	}
+	fields, ok := td.Types[primaryType]
+	if !ok {
+		return
+	}
+	deps[primaryType] = struct{}{}
+	for _, field := range fields {
+		td.dependencies(field.Type, deps)
+	}
+}
+
+// encodeData returns the concatenated 32 byte encodings of the fields of the
+// struct type in the order of their definition.
+func (td *TypedData) encodeData(primaryType string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := td.Types[primaryType]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", primaryType, ErrUnknownType)
+	}
+	var buf bytes.Buffer
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s.%s: missing field: %w", primaryType, field.Name, ErrInvalidValue)
+		}
+		encoded, err := td.encodeValue(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", primaryType, field.Name, err)
+		}
+		buf.Write(encoded)
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeValue returns the 32 byte encoding of the value of the type.
+func (td *TypedData) encodeValue(typ string, value interface{}) ([]byte, error) {
+	if _, ok := td.Types[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", typ, ErrInvalidValue)
+		}
+		return td.HashStruct(typ, data)
+	}
+
+	switch {
+	case typ == "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", typ, ErrInvalidValue)
+		}
+		return keccak256([]byte(s)), nil
+	case typ == "bytes":
+		b, ok := value.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", typ, ErrInvalidValue)
+		}
+		return keccak256(b), nil
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", typ, ErrInvalidValue)
+		}
+		word := make([]byte, 32)
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+	case typ == "address":
+		b, ok := value.([]byte)
+		if !ok || len(b) != 20 {
+			return nil, fmt.Errorf("%s: %w", typ, ErrInvalidValue)
+		}
+		word := make([]byte, 32)
+		copy(word[12:], b)
+		return word, nil
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(typ, "bytes"))
+		if err != nil || size < 1 || size > 32 {
+			return nil, fmt.Errorf("%s: %w", typ, ErrUnknownType)
+		}
+		b, ok := value.([]byte)
+		if !ok || len(b) != size {
+			return nil, fmt.Errorf("%s: %w", typ, ErrInvalidValue)
+		}
+		word := make([]byte, 32)
+		copy(word, b)
+		return word, nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		signed := strings.HasPrefix(typ, "int")
+		bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("%s: %w", typ, ErrUnknownType)
+		}
+		n, err := toBigInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", typ, err)
+		}
+		return encodeInteger(n, bits, signed)
+	}
+	return nil, fmt.Errorf("%s: %w", typ, ErrUnknownType)
+}
+
+// encodeInteger returns the 32 byte two's complement big-endian encoding of
+// the integer, checking that it fits into the given number of bits.
+func encodeInteger(n *big.Int, bits int, signed bool) ([]byte, error) {
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	if signed {
+		limit.Rsh(limit, 1)
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return nil, ErrInvalidValue
+		}
+	} else if n.Sign() < 0 || n.Cmp(limit) >= 0 {
+		return nil, ErrInvalidValue
+	}
+	if n.Sign() < 0 {
+		// two's complement in 256 bits
+		n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	word := make([]byte, 32)
+	b := n.Bytes()
+	copy(word[32-len(b):], b)
+	return word, nil
+}
+
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil, ErrInvalidValue
+		}
+		return v, nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	}
+	return nil, ErrInvalidValue
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, d := range data {
+		_, _ = h.Write(d)
+	}
+	return h.Sum(nil)
+}
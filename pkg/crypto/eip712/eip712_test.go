@@ -0,0 +1,116 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package eip712_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// mailTypedData returns the example of the EIP-712 specification.
+func mailTypedData(t *testing.T) *eip712.TypedData {
+	return &eip712.TypedData{
+		Types: eip712.Types{
+			eip712.DomainType: {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: eip712.Domain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainID:           big.NewInt(1),
+			VerifyingContract: mustDecodeHex(t, "cccccccccccccccccccccccccccccccccccccccc"),
+		},
+		Message: map[string]interface{}{
+			"from": map[string]interface{}{
+				"name":   "Cow",
+				"wallet": mustDecodeHex(t, "cd2a3d9f938e13cd947ec05abc7fe734df8dd826"),
+			},
+			"to": map[string]interface{}{
+				"name":   "Bob",
+				"wallet": mustDecodeHex(t, "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"),
+			},
+			"contents": "Hello, Bob!",
+		},
+	}
+}
+
+func TestEncodeType(t *testing.T) {
+	td := mailTypedData(t)
+
+	encoded, err := td.EncodeType("Mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; encoded != want {
+		t.Fatalf("got %q, want %q", encoded, want)
+	}
+
+	typeHash, err := td.TypeHash("Mail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(typeHash), "a0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"; got != want {
+		t.Fatalf("got type hash %s, want %s", got, want)
+	}
+
+	if _, err := td.EncodeType("Unknown"); !errors.Is(err, eip712.ErrUnknownType) {
+		t.Fatalf("got error %v, want %v", err, eip712.ErrUnknownType)
+	}
+}
+
+func TestSignDigest(t *testing.T) {
+	td := mailTypedData(t)
+
+	digest, err := td.SignDigest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := hex.EncodeToString(digest), "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; got != want {
+		t.Fatalf("got digest %s, want %s", got, want)
+	}
+
+	t.Run("invalid value", func(t *testing.T) {
+		td := mailTypedData(t)
+		td.Message["contents"] = 1
+		if _, err := td.SignDigest(); !errors.Is(err, eip712.ErrInvalidValue) {
+			t.Fatalf("got error %v, want %v", err, eip712.ErrInvalidValue)
+		}
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		td := mailTypedData(t)
+		delete(td.Message, "to")
+		if _, err := td.SignDigest(); !errors.Is(err, eip712.ErrInvalidValue) {
+			t.Fatalf("got error %v, want %v", err, eip712.ErrInvalidValue)
+		}
+	})
+}
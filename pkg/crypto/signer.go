@@ -6,13 +6,24 @@ package crypto
 
 import (
 	"crypto/ecdsa"
+	"errors"
 
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
 )
 
+// ErrInvalidLength is returned when the signature has an invalid length.
+var ErrInvalidLength = errors.New("invalid signature length")
+
 type Signer interface {
 	Sign(data []byte) ([]byte, error)
+	// SignTypedData signs the EIP-712 digest of the typed data, returning
+	// the signature in the ethereum format: r (32 bytes) | s (32 bytes) | v
+	// (1 byte, 27 or 28).
+	SignTypedData(typedData *eip712.TypedData) ([]byte, error)
 	PublicKey() (*ecdsa.PublicKey, error)
+	// EthereumAddress returns the ethereum address of the signer.
+	EthereumAddress() ([]byte, error)
 }
 
 // Recover verifies signature with the data base provided.
@@ -22,6 +33,23 @@ func Recover(signature, data []byte) (*ecdsa.PublicKey, error) {
 	return (*ecdsa.PublicKey)(p), err
 }
 
+// RecoverEIP712 recovers the public key of the signer of the typed data from
+// the signature created by SignTypedData.
+func RecoverEIP712(signature []byte, typedData *eip712.TypedData) (*ecdsa.PublicKey, error) {
+	if len(signature) != 65 {
+		return nil, ErrInvalidLength
+	}
+	digest, err := typedData.SignDigest()
+	if err != nil {
+		return nil, err
+	}
+	// move the recovery id to the front as expected by btcec
+	compact := make([]byte, 65)
+	compact[0] = signature[64]
+	copy(compact[1:], signature[:64])
+	return Recover(compact, digest)
+}
+
 type defaultSigner struct {
 	key *ecdsa.PrivateKey
 }
@@ -39,3 +67,23 @@ func (d *defaultSigner) PublicKey() (*ecdsa.PublicKey, error) {
 func (d *defaultSigner) Sign(data []byte) (signature []byte, err error) {
 	return btcec.SignCompact(btcec.S256(), (*btcec.PrivateKey)(d.key), data, true)
 }
+
+func (d *defaultSigner) SignTypedData(typedData *eip712.TypedData) ([]byte, error) {
+	digest, err := typedData.SignDigest()
+	if err != nil {
+		return nil, err
+	}
+	compact, err := btcec.SignCompact(btcec.S256(), (*btcec.PrivateKey)(d.key), digest, false)
+	if err != nil {
+		return nil, err
+	}
+	// move the recovery id to the end as expected by ethereum
+	signature := make([]byte, 65)
+	copy(signature, compact[1:])
+	signature[64] = compact[0]
+	return signature, nil
+}
+
+func (d *defaultSigner) EthereumAddress() ([]byte, error) {
+	return NewEthereumAddress(d.key.PublicKey)
+}
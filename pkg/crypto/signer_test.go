@@ -5,9 +5,13 @@
 package crypto_test
 
 import (
+	"bytes"
+	"errors"
+	"math/big"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
 )
 
 func TestDefaultSigner(t *testing.T) {
@@ -45,3 +49,66 @@ func TestDefaultSigner(t *testing.T) {
 		}
 	})
 }
+
+func TestDefaultSignerSignTypedData(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+
+	typedData := &eip712.TypedData{
+		Types: eip712.Types{
+			eip712.DomainType: {
+				{Name: "name", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+			},
+			"Message": {
+				{Name: "value", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Message",
+		Domain: eip712.Domain{
+			Name:    "Test",
+			ChainID: big.NewInt(1),
+		},
+		Message: map[string]interface{}{
+			"value": big.NewInt(10),
+		},
+	}
+
+	signature, err := signer.SignTypedData(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signature) != 65 {
+		t.Fatalf("got signature length %d, want 65", len(signature))
+	}
+	if v := signature[64]; v != 27 && v != 28 {
+		t.Fatalf("got recovery id %d, want 27 or 28", v)
+	}
+
+	pubKey, err := crypto.RecoverEIP712(signature, typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubKey.X.Cmp(privKey.PublicKey.X) != 0 || pubKey.Y.Cmp(privKey.PublicKey.Y) != 0 {
+		t.Fatalf("wanted %v but got %v", &privKey.PublicKey, pubKey)
+	}
+
+	address, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := crypto.NewEthereumAddress(privKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(address, want) {
+		t.Fatalf("got ethereum address %x, want %x", address, want)
+	}
+
+	if _, err := crypto.RecoverEIP712(signature[:64], typedData); !errors.Is(err, crypto.ErrInvalidLength) {
+		t.Fatalf("got error %v, want %v", err, crypto.ErrInvalidLength)
+	}
+}
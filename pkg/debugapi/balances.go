@@ -0,0 +1,71 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type balanceResponse struct {
+	Peer    string `json:"peer"`
+	Balance int64  `json:"balance"`
+}
+
+type balancesResponse struct {
+	Balances []balanceResponse `json:"balances"`
+}
+
+func (s *server) balancesHandler(w http.ResponseWriter, r *http.Request) {
+	balances, err := s.Accounting.Balances()
+	if err != nil {
+		s.Logger.Debugf("debug api: balances: %v", err)
+		s.Logger.Error("debug api: can not get balances")
+		jsonhttp.InternalServerError(w, "cannot get balances")
+		return
+	}
+
+	balResponses := make([]balanceResponse, 0, len(balances))
+	for k, v := range balances {
+		balResponses = append(balResponses, balanceResponse{
+			Peer:    k,
+			Balance: v,
+		})
+	}
+
+	jsonhttp.OK(w, balancesResponse{Balances: balResponses})
+}
+
+func (s *server) peerBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["peer"]
+	peer, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: balances peer: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	balance, err := s.Accounting.Balance(peer)
+	if err != nil {
+		if errors.Is(err, accounting.ErrPeerNoBalance) {
+			jsonhttp.NotFound(w, "no balance for peer")
+			return
+		}
+		s.Logger.Debugf("debug api: balances peer: get peer %s balance: %v", peer.String(), err)
+		s.Logger.Errorf("debug api: balances peer: can't get peer %s balance", peer.String())
+		jsonhttp.InternalServerError(w, "cannot get balance")
+		return
+	}
+
+	jsonhttp.OK(w, balanceResponse{
+		Peer:    peer.String(),
+		Balance: balance,
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestBalances(t *testing.T) {
+	balancesFunc := func() (map[string]int64, error) {
+		return map[string]int64{
+			"DEAD":  1000000000000000000,
+			"BEEF":  -100000000000000000,
+			"PARTY": 0,
+		}, nil
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithBalancesFunc(balancesFunc)},
+	})
+
+	expected := &debugapi.BalancesResponse{
+		Balances: []debugapi.BalanceResponse{
+			{Peer: "DEAD", Balance: 1000000000000000000},
+			{Peer: "BEEF", Balance: -100000000000000000},
+			{Peer: "PARTY", Balance: 0},
+		},
+	}
+
+	var got debugapi.BalancesResponse
+	jsonhttptest.ResponseUnmarshal(t, testServer.Client, http.MethodGet, "/balances", nil, http.StatusOK, &got)
+
+	if !equalBalances(&got, expected) {
+		t.Errorf("got balances: %v, expected: %v", got, expected)
+	}
+}
+
+func TestBalancesError(t *testing.T) {
+	balancesFunc := func() (map[string]int64, error) {
+		return nil, errors.New("some error")
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithBalancesFunc(balancesFunc)},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+		Message: "cannot get balances",
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+func TestBalancesPeers(t *testing.T) {
+	peer := "bff2c89e85e78c38bd89fca1acc996afb876c21bf5a8482ad798ce15f1c223fa"
+	balanceFunc := func(swarm.Address) (int64, error) {
+		return 1000000000000000000, nil
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithBalanceFunc(balanceFunc)},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances/"+peer, nil, http.StatusOK, debugapi.BalanceResponse{
+		Peer:    peer,
+		Balance: 1000000000000000000,
+	})
+}
+
+func TestBalancesPeersError(t *testing.T) {
+	peer := "bff2c89e85e78c38bd89fca1acc996afb876c21bf5a8482ad798ce15f1c223fa"
+	balanceFunc := func(swarm.Address) (int64, error) {
+		return 0, errors.New("some error")
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithBalanceFunc(balanceFunc)},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances/"+peer, nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+		Message: "cannot get balance",
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+func TestBalancesPeersNoBalance(t *testing.T) {
+	peer := "bff2c89e85e78c38bd89fca1acc996afb876c21bf5a8482ad798ce15f1c223fa"
+	balanceFunc := func(swarm.Address) (int64, error) {
+		return 0, accounting.ErrPeerNoBalance
+	}
+	testServer := newTestServer(t, testServerOptions{
+		AccountingOpts: []mock.Option{mock.WithBalanceFunc(balanceFunc)},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances/"+peer, nil, http.StatusNotFound, jsonhttp.StatusResponse{
+		Message: "no balance for peer",
+		Code:    http.StatusNotFound,
+	})
+}
+
+func TestBalancesInvalidAddress(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/balances/invalid-address", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+		Message: "invalid peer address",
+		Code:    http.StatusBadRequest,
+	})
+}
+
+func equalBalances(a, b *debugapi.BalancesResponse) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	sortBalances := func(r *debugapi.BalancesResponse) {
+		sort.Slice(r.Balances, func(i, j int) bool {
+			return r.Balances[i].Peer < r.Balances[j].Peer
+		})
+	}
+	sortBalances(a)
+	sortBalances(b)
+	return reflect.DeepEqual(a, b)
+}
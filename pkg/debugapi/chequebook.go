@@ -0,0 +1,167 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type chequebookBalanceResponse struct {
+	TotalBalance     *big.Int `json:"totalBalance"`
+	AvailableBalance *big.Int `json:"availableBalance"`
+}
+
+type chequebookAddressResponse struct {
+	Address string `json:"chequebookaddress"`
+}
+
+type chequebookLastChequePeerResponse struct {
+	Beneficiary string   `json:"beneficiary"`
+	Chequebook  string   `json:"chequebook"`
+	Payout      *big.Int `json:"payout"`
+}
+
+type chequebookLastChequesPeerResponse struct {
+	Peer         string                            `json:"peer"`
+	LastReceived *chequebookLastChequePeerResponse `json:"lastreceived"`
+	LastSent     *chequebookLastChequePeerResponse `json:"lastsent"`
+}
+
+type chequebookLastChequesResponse struct {
+	LastCheques []chequebookLastChequesPeerResponse `json:"lastcheques"`
+}
+
+func (s *server) chequebookBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	balance, err := s.Chequebook.Balance(r.Context())
+	if err != nil {
+		s.Logger.Debugf("debug api: chequebook balance: %v", err)
+		s.Logger.Error("debug api: cannot get chequebook balance")
+		jsonhttp.InternalServerError(w, "cannot get chequebook balance")
+		return
+	}
+
+	availableBalance, err := s.Chequebook.AvailableBalance(r.Context())
+	if err != nil {
+		s.Logger.Debugf("debug api: chequebook available balance: %v", err)
+		s.Logger.Error("debug api: cannot get chequebook available balance")
+		jsonhttp.InternalServerError(w, "cannot get chequebook balance")
+		return
+	}
+
+	jsonhttp.OK(w, chequebookBalanceResponse{
+		TotalBalance:     balance,
+		AvailableBalance: availableBalance,
+	})
+}
+
+func (s *server) chequebookAddressHandler(w http.ResponseWriter, r *http.Request) {
+	jsonhttp.OK(w, chequebookAddressResponse{
+		Address: hex.EncodeToString(s.Chequebook.Address()),
+	})
+}
+
+func (s *server) chequebookAllLastHandler(w http.ResponseWriter, r *http.Request) {
+	lastSent, err := s.Swap.LastSentCheques()
+	if err != nil {
+		s.Logger.Debugf("debug api: chequebook cheques: sent cheques: %v", err)
+		s.Logger.Error("debug api: chequebook cheques: cannot get sent cheques")
+		jsonhttp.InternalServerError(w, "cannot get cheques")
+		return
+	}
+	lastReceived, err := s.Swap.LastReceivedCheques()
+	if err != nil {
+		s.Logger.Debugf("debug api: chequebook cheques: received cheques: %v", err)
+		s.Logger.Error("debug api: chequebook cheques: cannot get received cheques")
+		jsonhttp.InternalServerError(w, "cannot get cheques")
+		return
+	}
+
+	lcr := make(map[string]chequebookLastChequesPeerResponse)
+	for peer, cheque := range lastSent {
+		lcr[peer] = chequebookLastChequesPeerResponse{
+			Peer:     peer,
+			LastSent: newChequeResponse(cheque),
+		}
+	}
+	for peer, cheque := range lastReceived {
+		r := lcr[peer]
+		r.Peer = peer
+		r.LastReceived = newChequeResponse(cheque)
+		lcr[peer] = r
+	}
+
+	lcresponses := make([]chequebookLastChequesPeerResponse, 0, len(lcr))
+	for _, v := range lcr {
+		lcresponses = append(lcresponses, v)
+	}
+
+	jsonhttp.OK(w, chequebookLastChequesResponse{
+		LastCheques: lcresponses,
+	})
+}
+
+func (s *server) chequebookLastPeerHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["peer"]
+	peer, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: chequebook cheque peer: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	var response chequebookLastChequesPeerResponse
+	peerexists := false
+
+	lastSent, err := s.Swap.LastSentCheque(peer)
+	if err != nil {
+		if !errors.Is(err, settlement.ErrPeerNoSettlements) {
+			s.Logger.Debugf("debug api: chequebook cheque peer: get peer %s last sent cheque: %v", peer.String(), err)
+			s.Logger.Errorf("debug api: chequebook cheque peer: can't get peer %s last sent cheque", peer.String())
+			jsonhttp.InternalServerError(w, "cannot get cheques")
+			return
+		}
+	} else {
+		response.LastSent = newChequeResponse(lastSent)
+		peerexists = true
+	}
+
+	lastReceived, err := s.Swap.LastReceivedCheque(peer)
+	if err != nil {
+		if !errors.Is(err, settlement.ErrPeerNoSettlements) {
+			s.Logger.Debugf("debug api: chequebook cheque peer: get peer %s last received cheque: %v", peer.String(), err)
+			s.Logger.Errorf("debug api: chequebook cheque peer: can't get peer %s last received cheque", peer.String())
+			jsonhttp.InternalServerError(w, "cannot get cheques")
+			return
+		}
+	} else {
+		response.LastReceived = newChequeResponse(lastReceived)
+		peerexists = true
+	}
+
+	if !peerexists {
+		jsonhttp.NotFound(w, "no cheques for peer")
+		return
+	}
+
+	response.Peer = peer.String()
+	jsonhttp.OK(w, response)
+}
+
+func newChequeResponse(cheque *chequebook.SignedCheque) *chequebookLastChequePeerResponse {
+	return &chequebookLastChequePeerResponse{
+		Beneficiary: hex.EncodeToString(cheque.Beneficiary),
+		Chequebook:  hex.EncodeToString(cheque.Chequebook),
+		Payout:      cheque.CumulativePayout,
+	}
+}
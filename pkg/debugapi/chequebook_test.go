@@ -0,0 +1,176 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	chequebookmock "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestChequebookBalance(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		ChequebookOpts: []chequebookmock.Option{
+			chequebookmock.WithBalanceFunc(func(context.Context) (*big.Int, error) {
+				return big.NewInt(1000), nil
+			}),
+			chequebookmock.WithAvailableBalanceFunc(func(context.Context) (*big.Int, error) {
+				return big.NewInt(800), nil
+			}),
+		},
+	})
+
+	var got debugapi.ChequebookBalanceResponse
+	jsonhttptest.ResponseUnmarshal(t, testServer.Client, http.MethodGet, "/chequebook/balance", nil, http.StatusOK, &got)
+
+	if got.TotalBalance.Cmp(big.NewInt(1000)) != 0 || got.AvailableBalance.Cmp(big.NewInt(800)) != 0 {
+		t.Fatalf("got balance %+v", got)
+	}
+}
+
+func TestChequebookBalanceError(t *testing.T) {
+	testServer := newTestServer(t, testServerOptions{
+		ChequebookOpts: []chequebookmock.Option{
+			chequebookmock.WithBalanceFunc(func(context.Context) (*big.Int, error) {
+				return nil, errors.New("some error")
+			}),
+		},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chequebook/balance", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+		Message: "cannot get chequebook balance",
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+func TestChequebookAddress(t *testing.T) {
+	address := bytes.Repeat([]byte{0xab}, 20)
+	testServer := newTestServer(t, testServerOptions{
+		ChequebookOpts: []chequebookmock.Option{
+			chequebookmock.WithAddressFunc(func() []byte {
+				return address
+			}),
+		},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chequebook/address", nil, http.StatusOK, debugapi.ChequebookAddressResponse{
+		Address: hex.EncodeToString(address),
+	})
+}
+
+func TestChequebookLastCheques(t *testing.T) {
+	beneficiary := bytes.Repeat([]byte{1}, 20)
+	chequebookAddress := bytes.Repeat([]byte{2}, 20)
+	cheque := func(payout int64) *chequebook.SignedCheque {
+		return &chequebook.SignedCheque{
+			Cheque: chequebook.Cheque{
+				Chequebook:       chequebookAddress,
+				Beneficiary:      beneficiary,
+				CumulativePayout: big.NewInt(payout),
+			},
+		}
+	}
+
+	testServer := newTestServer(t, testServerOptions{
+		SwapOpts: []swapmock.Option{
+			swapmock.WithLastSentChequesFunc(func() (map[string]*chequebook.SignedCheque, error) {
+				return map[string]*chequebook.SignedCheque{
+					"dead": cheque(100),
+					"beef": cheque(200),
+				}, nil
+			}),
+			swapmock.WithLastReceivedChequesFunc(func() (map[string]*chequebook.SignedCheque, error) {
+				return map[string]*chequebook.SignedCheque{
+					"beef": cheque(300),
+				}, nil
+			}),
+		},
+	})
+
+	var got debugapi.ChequebookLastChequesResponse
+	jsonhttptest.ResponseUnmarshal(t, testServer.Client, http.MethodGet, "/chequebook/cheque", nil, http.StatusOK, &got)
+
+	if len(got.LastCheques) != 2 {
+		t.Fatalf("got %d peers, want 2", len(got.LastCheques))
+	}
+	for _, c := range got.LastCheques {
+		switch c.Peer {
+		case "dead":
+			if c.LastReceived != nil || !equalChequeResponse(c.LastSent, beneficiary, chequebookAddress, 100) {
+				t.Fatalf("got cheques %+v", c)
+			}
+		case "beef":
+			if !equalChequeResponse(c.LastSent, beneficiary, chequebookAddress, 200) || !equalChequeResponse(c.LastReceived, beneficiary, chequebookAddress, 300) {
+				t.Fatalf("got cheques %+v", c)
+			}
+		default:
+			t.Fatalf("got unexpected peer %s", c.Peer)
+		}
+	}
+}
+
+func TestChequebookLastChequesPeer(t *testing.T) {
+	peer := "bff2c89e85e78c38bd89fca1acc996afb876c21bf5a8482ad798ce15f1c223fa"
+	beneficiary := bytes.Repeat([]byte{1}, 20)
+	chequebookAddress := bytes.Repeat([]byte{2}, 20)
+
+	testServer := newTestServer(t, testServerOptions{
+		SwapOpts: []swapmock.Option{
+			swapmock.WithLastSentChequeFunc(func(addr swarm.Address) (*chequebook.SignedCheque, error) {
+				if addr.String() != peer {
+					return nil, settlement.ErrPeerNoSettlements
+				}
+				return &chequebook.SignedCheque{
+					Cheque: chequebook.Cheque{
+						Chequebook:       chequebookAddress,
+						Beneficiary:      beneficiary,
+						CumulativePayout: big.NewInt(100),
+					},
+				}, nil
+			}),
+		},
+	})
+
+	var got debugapi.ChequebookLastChequesPeerResponse
+	jsonhttptest.ResponseUnmarshal(t, testServer.Client, http.MethodGet, "/chequebook/cheque/"+peer, nil, http.StatusOK, &got)
+
+	if got.Peer != peer || got.LastReceived != nil || !equalChequeResponse(got.LastSent, beneficiary, chequebookAddress, 100) {
+		t.Fatalf("got cheques %+v", got)
+	}
+
+	t.Run("no cheques", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chequebook/cheque/"+swarm.MustParseHexAddress("ff").String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "no cheques for peer",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("invalid peer address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chequebook/cheque/invalid-address", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "invalid peer address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
+
+func equalChequeResponse(r *debugapi.ChequebookLastChequePeerResponse, beneficiary, chequebookAddress []byte, payout int64) bool {
+	return r != nil &&
+		r.Beneficiary == hex.EncodeToString(beneficiary) &&
+		r.Chequebook == hex.EncodeToString(chequebookAddress) &&
+		r.Payout.Cmp(big.NewInt(payout)) == 0
+}
@@ -5,9 +5,12 @@
 package debugapi
 
 import (
+	"encoding/hex"
+	"errors"
 	"net/http"
 
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/gorilla/mux"
 )
@@ -34,3 +37,38 @@ func (s *server) hasChunkHandler(w http.ResponseWriter, r *http.Request) {
 	jsonhttp.OK(w, nil)
 
 }
+
+type chunkReceiptResponse struct {
+	Address   swarm.Address `json:"address"`
+	Storer    swarm.Address `json:"storer"`
+	Timestamp int64         `json:"timestamp"`
+	Signature string        `json:"signature"`
+}
+
+func (s *server) chunkReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: chunk receipt: parse chunk address: %v", err)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+
+	receipt, err := s.Receipts.LastReceipt(addr)
+	if err != nil {
+		if errors.Is(err, pushsync.ErrNoReceipt) {
+			jsonhttp.NotFound(w, "no receipt for chunk")
+			return
+		}
+		s.Logger.Debugf("debug api: chunk receipt: get receipt of chunk %s: %v", addr, err)
+		s.Logger.Errorf("debug api: chunk receipt: can't get receipt of chunk %s", addr)
+		jsonhttp.InternalServerError(w, "cannot get receipt")
+		return
+	}
+
+	jsonhttp.OK(w, chunkReceiptResponse{
+		Address:   receipt.Address,
+		Storer:    receipt.Storer,
+		Timestamp: receipt.Timestamp,
+		Signature: hex.EncodeToString(receipt.Signature),
+	})
+}
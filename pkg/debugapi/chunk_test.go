@@ -9,8 +9,11 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/debugapi"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -51,3 +54,46 @@ func TestHasChunkHandler(t *testing.T) {
 		})
 	})
 }
+
+func TestChunkReceiptHandler(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("aabbcc")
+	receipt := &pushsync.Receipt{
+		Address:   chunkAddress,
+		Storer:    swarm.MustParseHexAddress("ddeeff"),
+		Timestamp: 1000,
+		Signature: []byte{1, 2, 3},
+	}
+	pushSync := pushsyncmock.New(func(context.Context, swarm.Chunk) (*pushsync.Receipt, error) {
+		return receipt, nil
+	})
+	if _, err := pushSync.PushChunkToClosest(context.Background(), swarm.NewChunk(chunkAddress, []byte("data"))); err != nil {
+		t.Fatal(err)
+	}
+
+	testServer := newTestServer(t, testServerOptions{
+		Receipts: pushSync,
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chunks/"+chunkAddress.String()+"/receipt", nil, http.StatusOK, debugapi.ChunkReceiptResponse{
+			Address:   receipt.Address,
+			Storer:    receipt.Storer,
+			Timestamp: receipt.Timestamp,
+			Signature: "010203",
+		})
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chunks/abbbbb/receipt", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "no receipt for chunk",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("bad address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/chunks/abcd1100zz/receipt", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "bad address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+}
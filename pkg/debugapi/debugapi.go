@@ -5,15 +5,24 @@
 package debugapi
 
 import (
+	"crypto/ecdsa"
+	"io"
 	"net/http"
 
+	"github.com/ethersphere/bee/pkg/accounting"
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/pinning"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/tags/tagsapi"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/tracing"
 	"github.com/prometheus/client_golang/prometheus"
@@ -21,6 +30,7 @@ import (
 
 type Service interface {
 	http.Handler
+	io.Closer
 	MustRegisterMetrics(cs ...prometheus.Collector)
 }
 
@@ -29,27 +39,51 @@ type server struct {
 	http.Handler
 
 	metricsRegistry *prometheus.Registry
+	tags            *tagsapi.Handler
+
+	quit chan struct{} // ends the running event streams on exit
 }
 
 type Options struct {
 	Overlay        swarm.Address
+	PublicKey      ecdsa.PublicKey
 	P2P            p2p.Service
 	Pingpong       pingpong.Interface
 	Addressbook    addressbook.GetPutter
 	TopologyDriver topology.Notifier
 	Storer         storage.Storer
+	Pinning        pinning.Interface
 	Logger         logging.Logger
 	Tracer         *tracing.Tracer
 	Tags           *tags.Tags
+	Accounting     accounting.Interface
+	Settlement     settlement.Interface
+	Receipts       pushsync.ReceiptGetter
+	// Reserve is set only when the local store keeps the reserve.
+	Reserve ReserveStater
+	// DiskSpace is set only when the free disk space is checked.
+	DiskSpace DiskSpaceChecker
+	// Chequebook and Swap are set only when the settlement is done with
+	// swap cheques.
+	Chequebook chequebook.Service
+	Swap       swap.ApiInterface
 }
 
 func New(o Options) Service {
 	s := &server{
 		Options:         o,
 		metricsRegistry: newMetricsRegistry(),
+		quit:            make(chan struct{}),
 	}
+	s.tags = tagsapi.New(o.Tags, o.Logger, s.quit)
 
 	s.setupRouting()
 
 	return s
 }
+
+// Close ends the running tag progress streams on shutdown.
+func (s *server) Close() error {
+	close(s.quit)
+	return nil
+}
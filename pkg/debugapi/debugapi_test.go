@@ -5,18 +5,25 @@
 package debugapi_test
 
 import (
+	"crypto/ecdsa"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/api"
 	"github.com/ethersphere/bee/pkg/debugapi"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/pinning"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	settlementmock "github.com/ethersphere/bee/pkg/settlement/mock"
+	chequebookmock "github.com/ethersphere/bee/pkg/settlement/swap/chequebook/mock"
+	swapmock "github.com/ethersphere/bee/pkg/settlement/swap/mock"
 	mockstore "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -28,12 +35,20 @@ import (
 )
 
 type testServerOptions struct {
-	Overlay      swarm.Address
-	P2P          p2p.Service
-	Pingpong     pingpong.Interface
-	Storer       storage.Storer
-	TopologyOpts []mock.Option
-	Tags         *tags.Tags
+	Overlay        swarm.Address
+	PublicKey      ecdsa.PublicKey
+	P2P            p2p.Service
+	Pingpong       pingpong.Interface
+	Storer         storage.Storer
+	TopologyOpts   []mock.Option
+	Tags           *tags.Tags
+	AccountingOpts []accountingmock.Option
+	SettlementOpts []settlementmock.Option
+	ChequebookOpts []chequebookmock.Option
+	SwapOpts       []swapmock.Option
+	Receipts       pushsync.ReceiptGetter
+	Reserve        debugapi.ReserveStater
+	DiskSpace      debugapi.DiskSpaceChecker
 }
 
 type testServer struct {
@@ -46,16 +61,29 @@ func newTestServer(t *testing.T, o testServerOptions) *testServer {
 	statestore := mockstore.NewStateStore()
 	addrbook := addressbook.New(statestore)
 	topologyDriver := mock.NewTopologyDriver(o.TopologyOpts...)
+	acc := accountingmock.NewAccounting(o.AccountingOpts...)
+	settlement := settlementmock.New(o.SettlementOpts...)
+	chequebook := chequebookmock.NewChequebook(o.ChequebookOpts...)
+	swapService := swapmock.New(o.SwapOpts...)
 
 	s := debugapi.New(debugapi.Options{
 		Overlay:        o.Overlay,
+		PublicKey:      o.PublicKey,
 		P2P:            o.P2P,
 		Pingpong:       o.Pingpong,
 		Tags:           o.Tags,
 		Logger:         logging.New(ioutil.Discard, 0),
 		Addressbook:    addrbook,
 		Storer:         o.Storer,
+		Pinning:        pinning.New(o.Storer, statestore),
 		TopologyDriver: topologyDriver,
+		Accounting:     acc,
+		Settlement:     settlement,
+		Chequebook:     chequebook,
+		Swap:           swapService,
+		Receipts:       o.Receipts,
+		Reserve:        o.Reserve,
+		DiskSpace:      o.DiskSpace,
 	})
 	ts := httptest.NewServer(s)
 	t.Cleanup(ts.Close)
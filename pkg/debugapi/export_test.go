@@ -4,6 +4,8 @@
 
 package debugapi
 
+import "github.com/ethersphere/bee/pkg/tags/tagsapi"
+
 type (
 	StatusResponse           = statusResponse
 	PingpongResponse         = pingpongResponse
@@ -12,5 +14,21 @@ type (
 	AddressesResponse        = addressesResponse
 	PinnedChunk              = pinnedChunk
 	ListPinnedChunksResponse = listPinnedChunksResponse
-	TagResponse              = tagResponse
+	PinnedRoot               = pinnedRoot
+	ListPinnedRootsResponse  = listPinnedRootsResponse
+	TagResponse              = tagsapi.Response
+	ListTagsResponse         = tagsapi.ListResponse
+	TagRequest               = tagsapi.Request
+	BalancesResponse         = balancesResponse
+	BalanceResponse          = balanceResponse
+	SettlementResponse       = settlementResponse
+	SettlementsResponse      = settlementsResponse
+	ChunkReceiptResponse     = chunkReceiptResponse
+	ReserveStateResponse     = reserveStateResponse
+
+	ChequebookBalanceResponse         = chequebookBalanceResponse
+	ChequebookAddressResponse         = chequebookAddressResponse
+	ChequebookLastChequePeerResponse  = chequebookLastChequePeerResponse
+	ChequebookLastChequesPeerResponse = chequebookLastChequesPeerResponse
+	ChequebookLastChequesResponse     = chequebookLastChequesResponse
 )
@@ -8,7 +8,9 @@ import (
 	"errors"
 	"net/http"
 
+	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/pinning"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/gorilla/mux"
@@ -146,3 +148,117 @@ func (s *server) getPinnedChunk(w http.ResponseWriter, r *http.Request) {
 		PinCounter: pinCounter,
 	})
 }
+
+type pinnedRoot struct {
+	Address    swarm.Address `json:"address"`
+	Type       pinning.Type  `json:"type"`
+	PinCounter uint64        `json:"pinCounter"`
+}
+
+type listPinnedRootsResponse struct {
+	Roots []pinnedRoot `json:"roots"`
+}
+
+// pinBytes pins all the chunks of the data with the root reference.
+func (s *server) pinBytes(w http.ResponseWriter, r *http.Request) {
+	s.pinRoot(w, r, pinning.TypeBytes)
+}
+
+// unpinBytes unpins all the chunks of the data pinned with the root
+// reference.
+func (s *server) unpinBytes(w http.ResponseWriter, r *http.Request) {
+	s.unpinRoot(w, r, pinning.TypeBytes)
+}
+
+// pinFile pins all the chunks of the file entry with the reference, of its
+// metadata and of its data. For the manifest entries, all the files of the
+// collection are pinned.
+func (s *server) pinFile(w http.ResponseWriter, r *http.Request) {
+	s.pinRoot(w, r, pinning.TypeFile)
+}
+
+// unpinFile unpins all the chunks of the file pinned with the reference.
+func (s *server) unpinFile(w http.ResponseWriter, r *http.Request) {
+	s.unpinRoot(w, r, pinning.TypeFile)
+}
+
+func (s *server) pinRoot(w http.ResponseWriter, r *http.Request, typ pinning.Type) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: pin %s: parse address: %v", typ, err)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+	if l := len(addr.Bytes()); l != swarm.HashSize && l != encryption.ReferenceSize {
+		s.Logger.Debugf("debug api: pin %s: invalid address length %d", typ, l)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+
+	if err := s.Pinning.Pin(r.Context(), addr, typ); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			s.Logger.Debugf("debug api: pin %s: %s: %v", typ, addr, err)
+			jsonhttp.NotFound(w, "chunk not present")
+			return
+		}
+		s.Logger.Debugf("debug api: pin %s: %s: %v", typ, addr, err)
+		s.Logger.Errorf("debug api: pin %s: %s", typ, addr)
+		jsonhttp.InternalServerError(w, "cannot pin")
+		return
+	}
+	jsonhttp.OK(w, nil)
+}
+
+func (s *server) unpinRoot(w http.ResponseWriter, r *http.Request, typ pinning.Type) {
+	addr, err := swarm.ParseHexAddress(mux.Vars(r)["address"])
+	if err != nil {
+		s.Logger.Debugf("debug api: unpin %s: parse address: %v", typ, err)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+	if l := len(addr.Bytes()); l != swarm.HashSize && l != encryption.ReferenceSize {
+		s.Logger.Debugf("debug api: unpin %s: invalid address length %d", typ, l)
+		jsonhttp.BadRequest(w, "bad address")
+		return
+	}
+
+	if err := s.Pinning.Unpin(r.Context(), addr, typ); err != nil {
+		if errors.Is(err, pinning.ErrNotPinned) {
+			jsonhttp.NotFound(w, "not pinned")
+			return
+		}
+		if errors.Is(err, storage.ErrNotFound) {
+			s.Logger.Debugf("debug api: unpin %s: %s: %v", typ, addr, err)
+			jsonhttp.NotFound(w, "chunk not present")
+			return
+		}
+		s.Logger.Debugf("debug api: unpin %s: %s: %v", typ, addr, err)
+		s.Logger.Errorf("debug api: unpin %s: %s", typ, addr)
+		jsonhttp.InternalServerError(w, "cannot unpin")
+		return
+	}
+	jsonhttp.OK(w, nil)
+}
+
+// listPinnedRoots lists the pinned root references of the data and files,
+// rather than all of their chunks.
+func (s *server) listPinnedRoots(w http.ResponseWriter, r *http.Request) {
+	pins, err := s.Pinning.Pins()
+	if err != nil {
+		s.Logger.Debugf("debug api: list pins: %v", err)
+		s.Logger.Error("debug api: list pins")
+		jsonhttp.InternalServerError(w, "cannot list pins")
+		return
+	}
+	roots := make([]pinnedRoot, 0, len(pins))
+	for _, p := range pins {
+		roots = append(roots, pinnedRoot{
+			Address:    p.Address,
+			Type:       p.Type,
+			PinCounter: p.Counter,
+		})
+	}
+	jsonhttp.OK(w, listPinnedRootsResponse{
+		Roots: roots,
+	})
+}
@@ -6,12 +6,14 @@ package debugapi_test
 
 import (
 	"bytes"
+	"math/rand"
 	"net/http"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/debugapi"
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/pinning"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/storage/mock/validator"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -172,3 +174,112 @@ func TestPinChunkHandler(t *testing.T) {
 		})
 	})
 }
+
+// TestPinRootHandlers checks the recursive pinning of the data and files by
+// their root references and the listing of the pinned roots.
+func TestPinRootHandlers(t *testing.T) {
+	storer := mock.NewStorer()
+	tag := tags.NewTags()
+	debugTestServer := newTestServer(t, testServerOptions{
+		Storer: storer,
+		Tags:   tag,
+	})
+	bzzTestServer := newBZZTestServer(t, testServerOptions{
+		Storer: storer,
+		Tags:   tag,
+	})
+
+	data := make([]byte, 3*swarm.ChunkSize+10)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// the upload responses of the api
+	var bytesResp, fileResp struct {
+		Reference swarm.Address `json:"reference"`
+	}
+	jsonhttptest.ResponseUnmarshal(t, bzzTestServer, http.MethodPost, "/bytes", bytes.NewReader(data), http.StatusOK, &bytesResp)
+	jsonhttptest.ResponseUnmarshalSendHeaders(t, bzzTestServer, http.MethodPost, "/files?name=file.bin", bytes.NewReader(data), http.StatusOK, &fileResp, http.Header{
+		"Content-Type": {"application/octet-stream"},
+	})
+
+	okResponse := jsonhttp.StatusResponse{
+		Message: http.StatusText(http.StatusOK),
+		Code:    http.StatusOK,
+	}
+
+	t.Run("pin-bad-address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodPost, "/pin/bytes/abcd1100zz", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "bad address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("pin-short-address", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodPost, "/pin/files/123456", nil, http.StatusBadRequest, jsonhttp.StatusResponse{
+			Message: "bad address",
+			Code:    http.StatusBadRequest,
+		})
+	})
+
+	t.Run("pin-absent-root", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodPost, "/pin/files/ca8d2d29466e017cba46d383e7e0794d99a141185ec525086037f25fc2093155", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "chunk not present",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("unpin-while-not-pinned", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodDelete, "/pin/bytes/"+bytesResp.Reference.String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: "not pinned",
+			Code:    http.StatusNotFound,
+		})
+	})
+
+	t.Run("pin-and-list", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodPost, "/pin/bytes/"+bytesResp.Reference.String(), nil, http.StatusOK, okResponse)
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodPost, "/pin/files/"+fileResp.Reference.String(), nil, http.StatusOK, okResponse)
+
+		var resp debugapi.ListPinnedRootsResponse
+		jsonhttptest.ResponseUnmarshal(t, debugTestServer.Client, http.MethodGet, "/pin", nil, http.StatusOK, &resp)
+		if len(resp.Roots) != 2 {
+			t.Fatalf("got %v pinned roots, want 2", len(resp.Roots))
+		}
+		for _, want := range []debugapi.PinnedRoot{
+			{Address: bytesResp.Reference, Type: pinning.TypeBytes, PinCounter: 1},
+			{Address: fileResp.Reference, Type: pinning.TypeFile, PinCounter: 1},
+		} {
+			var found bool
+			for _, r := range resp.Roots {
+				if r.Address.Equal(want.Address) {
+					found = true
+					if r.Type != want.Type || r.PinCounter != want.PinCounter {
+						t.Fatalf("got pinned root %+v, want %+v", r, want)
+					}
+				}
+			}
+			if !found {
+				t.Fatalf("pinned root %s not listed", want.Address)
+			}
+		}
+
+		// the data chunks are shared by both roots
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodGet, "/chunks-pin/"+bytesResp.Reference.String(), nil, http.StatusOK, debugapi.PinnedChunk{
+			Address:    bytesResp.Reference,
+			PinCounter: 2,
+		})
+	})
+
+	t.Run("unpin", func(t *testing.T) {
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodDelete, "/pin/files/"+fileResp.Reference.String(), nil, http.StatusOK, okResponse)
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodDelete, "/pin/bytes/"+bytesResp.Reference.String(), nil, http.StatusOK, okResponse)
+
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodGet, "/pin", nil, http.StatusOK, debugapi.ListPinnedRootsResponse{
+			Roots: []debugapi.PinnedRoot{},
+		})
+		jsonhttptest.ResponseDirect(t, debugTestServer.Client, http.MethodGet, "/chunks-pin/"+bytesResp.Reference.String(), nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusNotFound),
+			Code:    http.StatusNotFound,
+		})
+	})
+}
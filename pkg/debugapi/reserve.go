@@ -0,0 +1,40 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/localstore"
+)
+
+// ReserveStater provides the state of the reserve of the chunks within the
+// storage radius.
+type ReserveStater interface {
+	ReserveState() (*localstore.ReserveState, error)
+}
+
+type reserveStateResponse struct {
+	Radius   uint8  `json:"radius"`
+	Size     uint64 `json:"size"`
+	Capacity uint64 `json:"capacity"`
+}
+
+func (s *server) reserveStateHandler(w http.ResponseWriter, r *http.Request) {
+	state, err := s.Reserve.ReserveState()
+	if err != nil {
+		s.Logger.Debugf("debug api: reserve state: %v", err)
+		s.Logger.Error("debug api: reserve state")
+		jsonhttp.InternalServerError(w, "cannot get reserve state")
+		return
+	}
+
+	jsonhttp.OK(w, reserveStateResponse{
+		Radius:   state.Radius,
+		Size:     state.Size,
+		Capacity: state.Capacity,
+	})
+}
@@ -0,0 +1,64 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/localstore"
+)
+
+type reserveStaterFunc func() (*localstore.ReserveState, error)
+
+func (f reserveStaterFunc) ReserveState() (*localstore.ReserveState, error) {
+	return f()
+}
+
+func TestReserveState(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			Reserve: reserveStaterFunc(func() (*localstore.ReserveState, error) {
+				return &localstore.ReserveState{
+					Radius:   3,
+					Size:     1000,
+					Capacity: 2500,
+				}, nil
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/reserve", nil, http.StatusOK, debugapi.ReserveStateResponse{
+			Radius:   3,
+			Size:     1000,
+			Capacity: 2500,
+		})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			Reserve: reserveStaterFunc(func() (*localstore.ReserveState, error) {
+				return nil, errors.New("error")
+			}),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/reserve", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+			Message: "cannot get reserve state",
+			Code:    http.StatusInternalServerError,
+		})
+	})
+
+	t.Run("not available", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/reserve", nil, http.StatusNotFound, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusNotFound),
+			Code:    http.StatusNotFound,
+		})
+	})
+}
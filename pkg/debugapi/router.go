@@ -47,7 +47,7 @@ func (s *server) setupRouting() {
 	))
 	router.Handle("/readiness", web.ChainHandlers(
 		logging.SetAccessLogLevelHandler(0), // suppress access log messages
-		web.FinalHandlerFunc(s.statusHandler),
+		web.FinalHandlerFunc(s.readinessHandler),
 	))
 
 	router.Handle("/pingpong/{peer-id}", jsonhttp.MethodHandler{
@@ -69,6 +69,9 @@ func (s *server) setupRouting() {
 	router.Handle("/chunks/{address}", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.hasChunkHandler),
 	})
+	router.Handle("/chunks/{address}/receipt", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.chunkReceiptHandler),
+	})
 	router.Handle("/chunks-pin/{address}", jsonhttp.MethodHandler{
 		"GET":    http.HandlerFunc(s.getPinnedChunk),
 		"POST":   http.HandlerFunc(s.pinChunk),
@@ -77,15 +80,65 @@ func (s *server) setupRouting() {
 	router.Handle("/chunks-pin", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.listPinnedChunks),
 	})
+	router.Handle("/pin/bytes/{address}", jsonhttp.MethodHandler{
+		"POST":   http.HandlerFunc(s.pinBytes),
+		"DELETE": http.HandlerFunc(s.unpinBytes),
+	})
+	router.Handle("/pin/files/{address}", jsonhttp.MethodHandler{
+		"POST":   http.HandlerFunc(s.pinFile),
+		"DELETE": http.HandlerFunc(s.unpinFile),
+	})
+	router.Handle("/pin", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.listPinnedRoots),
+	})
 	router.Handle("/tags", jsonhttp.MethodHandler{
-		"POST": http.HandlerFunc(s.createTag),
+		"GET":  http.HandlerFunc(s.tags.List),
+		"POST": http.HandlerFunc(s.tags.Create),
 	})
 	router.Handle("/tags/{uid}", jsonhttp.MethodHandler{
-		"GET": http.HandlerFunc(s.getTag),
+		"GET":    http.HandlerFunc(s.tags.Get),
+		"PATCH":  http.HandlerFunc(s.tags.Patch),
+		"DELETE": http.HandlerFunc(s.tags.Delete),
+	})
+	router.Handle("/tags/{uid}/stream", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.tags.Stream),
 	})
 	router.Handle("/topology", jsonhttp.MethodHandler{
 		"GET": http.HandlerFunc(s.topologyHandler),
 	})
+	router.Handle("/balances", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.balancesHandler),
+	})
+	router.Handle("/balances/{peer}", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.peerBalanceHandler),
+	})
+	router.Handle("/settlements", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.settlementsHandler),
+	})
+	router.Handle("/settlements/{peer}", jsonhttp.MethodHandler{
+		"GET": http.HandlerFunc(s.peerSettlementsHandler),
+	})
+
+	if s.Reserve != nil {
+		router.Handle("/reserve", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.reserveStateHandler),
+		})
+	}
+
+	if s.Chequebook != nil {
+		router.Handle("/chequebook/balance", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookBalanceHandler),
+		})
+		router.Handle("/chequebook/address", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookAddressHandler),
+		})
+		router.Handle("/chequebook/cheque", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookAllLastHandler),
+		})
+		router.Handle("/chequebook/cheque/{peer}", jsonhttp.MethodHandler{
+			"GET": http.HandlerFunc(s.chequebookLastPeerHandler),
+		})
+	}
 
 	baseRouter.Handle("/", web.ChainHandlers(
 		logging.NewHTTPAccessLogHandler(s.Logger, logrus.InfoLevel, "debug api access"),
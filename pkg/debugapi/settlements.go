@@ -0,0 +1,123 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/gorilla/mux"
+)
+
+type settlementResponse struct {
+	Peer               string `json:"peer"`
+	SettlementReceived uint64 `json:"received"`
+	SettlementSent     uint64 `json:"sent"`
+}
+
+type settlementsResponse struct {
+	TotalSettlementReceived uint64               `json:"totalreceived"`
+	TotalSettlementSent     uint64               `json:"totalsent"`
+	Settlements             []settlementResponse `json:"settlements"`
+}
+
+func (s *server) settlementsHandler(w http.ResponseWriter, r *http.Request) {
+	settlementsSent, err := s.Settlement.SettlementsSent()
+	if err != nil {
+		s.Logger.Debugf("debug api: sent settlements: %v", err)
+		s.Logger.Error("debug api: can not get sent settlements")
+		jsonhttp.InternalServerError(w, "cannot get settlements")
+		return
+	}
+	settlementsReceived, err := s.Settlement.SettlementsReceived()
+	if err != nil {
+		s.Logger.Debugf("debug api: received settlements: %v", err)
+		s.Logger.Error("debug api: can not get received settlements")
+		jsonhttp.InternalServerError(w, "cannot get settlements")
+		return
+	}
+
+	totalReceived := uint64(0)
+	totalSent := uint64(0)
+
+	settlementResponses := make(map[string]settlementResponse)
+
+	for peer, sent := range settlementsSent {
+		settlementResponses[peer] = settlementResponse{
+			Peer:           peer,
+			SettlementSent: sent,
+		}
+		totalSent += sent
+	}
+
+	for peer, received := range settlementsReceived {
+		r := settlementResponses[peer]
+		r.Peer = peer
+		r.SettlementReceived = received
+		settlementResponses[peer] = r
+		totalReceived += received
+	}
+
+	settlementResponsesArray := make([]settlementResponse, 0, len(settlementResponses))
+	for _, v := range settlementResponses {
+		settlementResponsesArray = append(settlementResponsesArray, v)
+	}
+
+	jsonhttp.OK(w, settlementsResponse{
+		TotalSettlementReceived: totalReceived,
+		TotalSettlementSent:     totalSent,
+		Settlements:             settlementResponsesArray,
+	})
+}
+
+func (s *server) peerSettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	addr := mux.Vars(r)["peer"]
+	peer, err := swarm.ParseHexAddress(addr)
+	if err != nil {
+		s.Logger.Debugf("debug api: settlements peer: parse peer address %s: %v", addr, err)
+		jsonhttp.BadRequest(w, "invalid peer address")
+		return
+	}
+
+	peerexists := false
+
+	received, err := s.Settlement.TotalReceived(peer)
+	if err != nil {
+		if !errors.Is(err, settlement.ErrPeerNoSettlements) {
+			s.Logger.Debugf("debug api: settlements peer: get peer %s received settlement: %v", peer.String(), err)
+			s.Logger.Errorf("debug api: settlements peer: can't get peer %s received settlement", peer.String())
+			jsonhttp.InternalServerError(w, "cannot get settlements")
+			return
+		}
+	} else {
+		peerexists = true
+	}
+
+	sent, err := s.Settlement.TotalSent(peer)
+	if err != nil {
+		if !errors.Is(err, settlement.ErrPeerNoSettlements) {
+			s.Logger.Debugf("debug api: settlements peer: get peer %s sent settlement: %v", peer.String(), err)
+			s.Logger.Errorf("debug api: settlements peer: can't get peer %s sent settlement", peer.String())
+			jsonhttp.InternalServerError(w, "cannot get settlements")
+			return
+		}
+	} else {
+		peerexists = true
+	}
+
+	if !peerexists {
+		jsonhttp.NotFound(w, "no settlements for peer")
+		return
+	}
+
+	jsonhttp.OK(w, settlementResponse{
+		Peer:               peer.String(),
+		SettlementReceived: received,
+		SettlementSent:     sent,
+	})
+}
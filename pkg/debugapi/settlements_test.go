@@ -0,0 +1,137 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package debugapi_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/debugapi"
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/jsonhttp/jsonhttptest"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestSettlements(t *testing.T) {
+	settlementsSentFunc := func() (map[string]uint64, error) {
+		return map[string]uint64{
+			"DEAD": 10000,
+			"BEEF": 20000,
+			"FFFF": 50000,
+		}, nil
+	}
+	settlementsReceivedFunc := func() (map[string]uint64, error) {
+		return map[string]uint64{
+			"BEEF": 10000,
+			"EEEE": 5000,
+		}, nil
+	}
+	testServer := newTestServer(t, testServerOptions{
+		SettlementOpts: []mock.Option{
+			mock.WithSettlementsSentFunc(settlementsSentFunc),
+			mock.WithSettlementsReceivedFunc(settlementsReceivedFunc),
+		},
+	})
+
+	expected := &debugapi.SettlementsResponse{
+		TotalSettlementReceived: 15000,
+		TotalSettlementSent:     80000,
+		Settlements: []debugapi.SettlementResponse{
+			{Peer: "DEAD", SettlementReceived: 0, SettlementSent: 10000},
+			{Peer: "BEEF", SettlementReceived: 10000, SettlementSent: 20000},
+			{Peer: "FFFF", SettlementReceived: 0, SettlementSent: 50000},
+			{Peer: "EEEE", SettlementReceived: 5000, SettlementSent: 0},
+		},
+	}
+
+	var got debugapi.SettlementsResponse
+	jsonhttptest.ResponseUnmarshal(t, testServer.Client, http.MethodGet, "/settlements", nil, http.StatusOK, &got)
+
+	if !equalSettlements(&got, expected) {
+		t.Errorf("got settlements: %+v, expected: %+v", got, expected)
+	}
+}
+
+func TestSettlementsError(t *testing.T) {
+	settlementsSentFunc := func() (map[string]uint64, error) {
+		return nil, errors.New("some error")
+	}
+	testServer := newTestServer(t, testServerOptions{
+		SettlementOpts: []mock.Option{mock.WithSettlementsSentFunc(settlementsSentFunc)},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/settlements", nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+		Message: "cannot get settlements",
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+func TestSettlementsPeers(t *testing.T) {
+	peer := "bff2c89e85e78c38bd89fca1acc996afb876c21bf5a8482ad798ce15f1c223fa"
+	settlementSentFunc := func(swarm.Address) (uint64, error) {
+		return 1000000000000000000, nil
+	}
+	testServer := newTestServer(t, testServerOptions{
+		SettlementOpts: []mock.Option{mock.WithTotalSentFunc(settlementSentFunc)},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/settlements/"+peer, nil, http.StatusOK, debugapi.SettlementResponse{
+		Peer:               peer,
+		SettlementSent:     1000000000000000000,
+		SettlementReceived: 0,
+	})
+}
+
+func TestSettlementsPeersNoSettlements(t *testing.T) {
+	peer := "bff2c89e85e78c38bd89fca1acc996afb876c21bf5a8482ad798ce15f1c223fa"
+	noSettlementsFunc := func(swarm.Address) (uint64, error) {
+		return 0, settlement.ErrPeerNoSettlements
+	}
+	testServer := newTestServer(t, testServerOptions{
+		SettlementOpts: []mock.Option{
+			mock.WithTotalSentFunc(noSettlementsFunc),
+			mock.WithTotalReceivedFunc(noSettlementsFunc),
+		},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/settlements/"+peer, nil, http.StatusNotFound, jsonhttp.StatusResponse{
+		Message: "no settlements for peer",
+		Code:    http.StatusNotFound,
+	})
+}
+
+func TestSettlementsPeersError(t *testing.T) {
+	peer := "bff2c89e85e78c38bd89fca1acc996afb876c21bf5a8482ad798ce15f1c223fa"
+	errFunc := func(swarm.Address) (uint64, error) {
+		return 0, errors.New("some error")
+	}
+	testServer := newTestServer(t, testServerOptions{
+		SettlementOpts: []mock.Option{mock.WithTotalSentFunc(errFunc)},
+	})
+
+	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/settlements/"+peer, nil, http.StatusInternalServerError, jsonhttp.StatusResponse{
+		Message: "cannot get settlements",
+		Code:    http.StatusInternalServerError,
+	})
+}
+
+func equalSettlements(a, b *debugapi.SettlementsResponse) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	sortSettlements := func(r *debugapi.SettlementsResponse) {
+		sort.Slice(r.Settlements, func(i, j int) bool {
+			return r.Settlements[i].Peer < r.Settlements[j].Peer
+		})
+	}
+	sortSettlements(a)
+	sortSettlements(b)
+	return reflect.DeepEqual(a, b)
+}
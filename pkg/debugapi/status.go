@@ -10,6 +10,12 @@ import (
 	"github.com/ethersphere/bee/pkg/jsonhttp"
 )
 
+// DiskSpaceChecker reports if the free disk space is too low
+// for the node to store the chunks from the network.
+type DiskSpaceChecker interface {
+	LowDiskSpace() bool
+}
+
 type statusResponse struct {
 	Status string `json:"status"`
 }
@@ -19,3 +25,13 @@ func (s *server) statusHandler(w http.ResponseWriter, r *http.Request) {
 		Status: "ok",
 	})
 }
+
+func (s *server) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	if s.DiskSpace != nil && s.DiskSpace.LowDiskSpace() {
+		jsonhttp.ServiceUnavailable(w, statusResponse{
+			Status: "degraded",
+		})
+		return
+	}
+	s.statusHandler(w, r)
+}
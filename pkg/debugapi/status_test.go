@@ -21,9 +21,42 @@ func TestHealth(t *testing.T) {
 }
 
 func TestReadiness(t *testing.T) {
-	testServer := newTestServer(t, testServerOptions{})
+	t.Run("ok", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{})
 
-	jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/readiness", nil, http.StatusOK, debugapi.StatusResponse{
-		Status: "ok",
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/readiness", nil, http.StatusOK, debugapi.StatusResponse{
+			Status: "ok",
+		})
+	})
+
+	t.Run("enough disk space", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			DiskSpace: diskSpaceChecker(false),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/readiness", nil, http.StatusOK, debugapi.StatusResponse{
+			Status: "ok",
+		})
+	})
+
+	t.Run("low disk space", func(t *testing.T) {
+		testServer := newTestServer(t, testServerOptions{
+			DiskSpace: diskSpaceChecker(true),
+		})
+
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/readiness", nil, http.StatusServiceUnavailable, debugapi.StatusResponse{
+			Status: "degraded",
+		})
+
+		// the node is still alive
+		jsonhttptest.ResponseDirect(t, testServer.Client, http.MethodGet, "/health", nil, http.StatusOK, debugapi.StatusResponse{
+			Status: "ok",
+		})
 	})
 }
+
+type diskSpaceChecker bool
+
+func (c diskSpaceChecker) LowDiskSpace() bool {
+	return bool(c)
+}
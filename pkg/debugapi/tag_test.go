@@ -6,6 +6,7 @@ package debugapi_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http"
 	"strconv"
 	"testing"
@@ -202,6 +203,46 @@ func TestTags(t *testing.T) {
 			t.Errorf("tag synced count mismatch. got %d want %d", tagToVerify.Synced, finalTag.Synced)
 		}
 	})
+
+	t.Run("list-patch-delete", func(t *testing.T) {
+		ta := debugapi.TagResponse{}
+		jsonhttptest.ResponseUnmarshal(t, ts.Client, http.MethodPost, tagResourceUidCreate("managed"), nil, http.StatusOK, &ta)
+
+		list := debugapi.ListTagsResponse{}
+		jsonhttptest.ResponseUnmarshal(t, ts.Client, http.MethodGet, "/tags?limit=1000", nil, http.StatusOK, &list)
+		if !containsTag(list.Tags, ta.Uid) {
+			t.Fatalf("tag %d not listed", ta.Uid)
+		}
+
+		address := swarm.MustParseHexAddress("deadbeef")
+		body, err := json.Marshal(debugapi.TagRequest{Address: address})
+		if err != nil {
+			t.Fatal(err)
+		}
+		patched := debugapi.TagResponse{}
+		jsonhttptest.ResponseUnmarshal(t, ts.Client, http.MethodPatch, tagResourceUUid(uint64(ta.Uid)), bytes.NewReader(body), http.StatusOK, &patched)
+		if !patched.Address.Equal(address) {
+			t.Fatalf("got address %s, want %s", patched.Address, address)
+		}
+
+		jsonhttptest.ResponseDirect(t, ts.Client, http.MethodDelete, tagResourceUUid(uint64(ta.Uid)), nil, http.StatusOK, jsonhttp.StatusResponse{
+			Message: http.StatusText(http.StatusOK),
+			Code:    http.StatusOK,
+		})
+		jsonhttptest.ResponseUnmarshal(t, ts.Client, http.MethodGet, "/tags?limit=1000", nil, http.StatusOK, &list)
+		if containsTag(list.Tags, ta.Uid) {
+			t.Fatalf("deleted tag %d listed", ta.Uid)
+		}
+	})
+}
+
+func containsTag(list []debugapi.TagResponse, uid uint32) bool {
+	for _, t := range list {
+		if t.Uid == uid {
+			return true
+		}
+	}
+	return false
 }
 
 func isTagFoundInResponse(t *testing.T, headers http.Header, tag *debugapi.TagResponse) uint64 {
@@ -0,0 +1,94 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package encryption
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+// ReferenceSize is the length of a reference to an encrypted chunk,
+// the chunk address followed by the decryption key.
+const ReferenceSize = swarm.HashSize + KeyLength
+
+// spanSize is the length of the span prefix of the chunk data.
+const spanSize = 8
+
+// hashFunc is the hasher used for generating the keystream.
+func hashFunc() hash.Hash {
+	return sha3.NewLegacyKeccak256()
+}
+
+// newSpanEncryption returns the Encryption for the span prefix of the chunk.
+// The counter starts after the data segments so that the keystream of the
+// span and of the data never overlap.
+func newSpanEncryption(key Key) *Encryption {
+	return New(key, 0, uint32(swarm.ChunkSize/KeyLength), hashFunc)
+}
+
+// newDataEncryption returns the Encryption for the chunk payload which is
+// always padded to the full chunk size.
+func newDataEncryption(key Key) *Encryption {
+	return New(key, swarm.ChunkSize, 0, hashFunc)
+}
+
+// EncryptChunk encrypts the chunk data, span prefix included, with a newly
+// generated random key. It returns the key and the encrypted chunk data with
+// the payload padded to swarm.ChunkSize.
+func EncryptChunk(chunkData []byte) (Key, []byte, error) {
+	if len(chunkData) < spanSize {
+		return nil, nil, fmt.Errorf("invalid chunk data length %d", len(chunkData))
+	}
+	key, err := GenerateRandomKey(KeyLength)
+	if err != nil {
+		return nil, nil, err
+	}
+	encryptedSpan, err := newSpanEncryption(key).Encrypt(chunkData[:spanSize])
+	if err != nil {
+		return nil, nil, err
+	}
+	encryptedData, err := newDataEncryption(key).Encrypt(chunkData[spanSize:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, append(encryptedSpan, encryptedData...), nil
+}
+
+// DecryptChunkData decrypts the chunk data encrypted by EncryptChunk with the
+// key. The padding is removed using the decrypted span, for intermediate
+// chunks taking into account that they contain references of ReferenceSize.
+// The length of intermediate chunks is exact, as the splitter never wraps a
+// single reference in an intermediate chunk of an encrypted chunk tree, so
+// that only data chunks have a span of at most swarm.ChunkSize.
+func DecryptChunkData(chunkData []byte, key Key) ([]byte, error) {
+	if len(chunkData) != spanSize+swarm.ChunkSize {
+		return nil, fmt.Errorf("invalid encrypted chunk data length %d", len(chunkData))
+	}
+	decryptedSpan, err := newSpanEncryption(key).Decrypt(chunkData[:spanSize])
+	if err != nil {
+		return nil, err
+	}
+	decryptedData, err := newDataEncryption(key).Decrypt(chunkData[spanSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	// the length of the payload is the span for data chunks, for intermediate
+	// chunks it is the number of references to the chunks below it
+	length := binary.LittleEndian.Uint64(decryptedSpan)
+	for length > swarm.ChunkSize {
+		length = (length + swarm.ChunkSize - 1) / swarm.ChunkSize
+		length *= ReferenceSize
+	}
+	if length > uint64(len(decryptedData)) {
+		return nil, fmt.Errorf("invalid decrypted chunk length %d", length)
+	}
+
+	return append(decryptedSpan, decryptedData[:length]...), nil
+}
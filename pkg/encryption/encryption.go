@@ -0,0 +1,148 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package encryption provides symmetric encryption of data with a
+// counter mode keystream derived from hashing the key.
+package encryption
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// KeyLength is the length in bytes of the encryption key.
+const KeyLength = 32
+
+// Key is a symmetric encryption key.
+type Key []byte
+
+// Encryption encrypts and decrypts data with the key, segment by segment.
+// Every segment is xored with the hash of the key and the segment counter,
+// so that the same instance both encrypts and decrypts.
+type Encryption struct {
+	key      Key              // the encryption key (hashSize bytes long)
+	keyLen   int              // length of the key = length of blockcipher block
+	padding  int              // encryption will pad the data upto this if > 0
+	initCtr  uint32           // initial counter used for counter mode blockcipher
+	hashFunc func() hash.Hash // hasher constructor function
+}
+
+// New constructs a new Encryption. If padding is greater than zero,
+// the data is padded with random bytes to that length before encryption.
+func New(key Key, padding int, initCtr uint32, hashFunc func() hash.Hash) *Encryption {
+	return &Encryption{
+		key:      key,
+		keyLen:   len(key),
+		padding:  padding,
+		initCtr:  initCtr,
+		hashFunc: hashFunc,
+	}
+}
+
+// Key returns the encryption key.
+func (e *Encryption) Key() Key {
+	return e.key
+}
+
+// Encrypt encrypts the data and does padding if specified.
+func (e *Encryption) Encrypt(data []byte) ([]byte, error) {
+	length := len(data)
+	outLength := length
+	isFixedPadding := e.padding > 0
+	if isFixedPadding {
+		if length > e.padding {
+			return nil, fmt.Errorf("data length %d longer than padding %d", length, e.padding)
+		}
+		outLength = e.padding
+	}
+	out := make([]byte, outLength)
+	if err := e.transform(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Decrypt decrypts the data, the padding is not removed.
+func (e *Encryption) Decrypt(data []byte) ([]byte, error) {
+	length := len(data)
+	if e.padding > 0 && length != e.padding {
+		return nil, fmt.Errorf("data length %d different than padding %d", length, e.padding)
+	}
+	out := make([]byte, length)
+	if err := e.transform(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// transform xors the input with the keystream segment by segment, writing
+// random bytes to the part of the output that is longer than the input.
+func (e *Encryption) transform(in, out []byte) error {
+	inLength := len(in)
+	hasher := e.hashFunc()
+	for i := 0; i < (len(out)+e.keyLen-1)/e.keyLen; i++ {
+		start := i * e.keyLen
+		end := start + e.keyLen
+		if end > len(out) {
+			end = len(out)
+		}
+		inEnd := end
+		if inEnd > inLength {
+			inEnd = inLength
+		}
+		if start > inEnd {
+			start = inEnd
+		}
+		if err := e.transcrypt(hasher, i, in[start:inEnd], out[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transcrypt xors a single segment with the hash of the key and the
+// segment counter, and pads the rest of the out segment.
+func (e *Encryption) transcrypt(hasher hash.Hash, i int, in, out []byte) error {
+	// first hash key with counter (initial counter + i)
+	hasher.Reset()
+	if _, err := hasher.Write(e.key); err != nil {
+		return err
+	}
+	ctrBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(ctrBytes, uint32(i)+e.initCtr)
+	if _, err := hasher.Write(ctrBytes); err != nil {
+		return err
+	}
+	ctrHash := hasher.Sum(nil)
+
+	// second round of hashing for selective disclosure
+	hasher.Reset()
+	if _, err := hasher.Write(ctrHash); err != nil {
+		return err
+	}
+	segmentKey := hasher.Sum(nil)
+
+	// xor bytes up until length of in, out must be at least as long
+	for j := 0; j < len(in); j++ {
+		out[j] = in[j] ^ segmentKey[j]
+	}
+	// insert padding if out is longer
+	if len(out) > len(in) {
+		if _, err := rand.Read(out[len(in):]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GenerateRandomKey generates a random key of the given length.
+func GenerateRandomKey(l int) (Key, error) {
+	key := make([]byte, l)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
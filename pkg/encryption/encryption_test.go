@@ -0,0 +1,125 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package encryption_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+// TestEncryptDecrypt verifies that the decrypted data equals the original
+// data, with the padding appended.
+func TestEncryptDecrypt(t *testing.T) {
+	key, err := encryption.GenerateRandomKey(encryption.KeyLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("the data that needs to be encrypted, longer than one key")
+
+	for _, padding := range []int{0, 100} {
+		e := encryption.New(key, padding, 42, sha3.NewLegacyKeccak256)
+
+		encrypted, err := e.Encrypt(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantLength := len(data)
+		if padding > 0 {
+			wantLength = padding
+		}
+		if len(encrypted) != wantLength {
+			t.Fatalf("padding %d: got encrypted length %d, want %d", padding, len(encrypted), wantLength)
+		}
+		if bytes.Equal(encrypted[:len(data)], data) {
+			t.Fatalf("padding %d: data not encrypted", padding)
+		}
+
+		decrypted, err := e.Decrypt(encrypted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decrypted[:len(data)], data) {
+			t.Fatalf("padding %d: got decrypted data %q, want %q", padding, decrypted[:len(data)], data)
+		}
+	}
+}
+
+// TestEncryptPaddingTooShort verifies that data longer than the padding can
+// not be encrypted.
+func TestEncryptPaddingTooShort(t *testing.T) {
+	key, err := encryption.GenerateRandomKey(encryption.KeyLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := encryption.New(key, 4, 0, sha3.NewLegacyKeccak256)
+	if _, err := e.Encrypt([]byte("longer than padding")); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// TestEncryptChunk verifies that the chunk data is encrypted with its span
+// and that the decrypted chunk data is truncated to the length of the
+// payload both for data and intermediate chunks.
+func TestEncryptChunk(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		span    uint64
+		payload []byte
+	}{
+		{
+			name:    "data",
+			span:    100,
+			payload: bytes.Repeat([]byte{1}, 100),
+		},
+		{
+			name:    "short data",
+			span:    5,
+			payload: []byte("swarm"),
+		},
+		{
+			name:    "intermediate",
+			span:    swarm.ChunkSize + 1,
+			payload: bytes.Repeat([]byte{1}, 2*encryption.ReferenceSize),
+		},
+		{
+			name:    "intermediate above intermediate",
+			span:    swarm.ChunkSize*swarm.ChunkSize/encryption.ReferenceSize + 1,
+			payload: bytes.Repeat([]byte{1}, 2*encryption.ReferenceSize),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			chunkData := make([]byte, 8, 8+len(tc.payload))
+			binary.LittleEndian.PutUint64(chunkData, tc.span)
+			chunkData = append(chunkData, tc.payload...)
+
+			key, encrypted, err := encryption.EncryptChunk(chunkData)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(key) != encryption.KeyLength {
+				t.Fatalf("got key length %d, want %d", len(key), encryption.KeyLength)
+			}
+			if len(encrypted) != 8+swarm.ChunkSize {
+				t.Fatalf("got encrypted length %d, want %d", len(encrypted), 8+swarm.ChunkSize)
+			}
+			if bytes.Equal(encrypted[:8], chunkData[:8]) {
+				t.Fatal("span not encrypted")
+			}
+
+			decrypted, err := encryption.DecryptChunkData(encrypted, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, chunkData) {
+				t.Fatalf("got decrypted chunk data %x, want %x", decrypted, chunkData)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package epochs
+
+import (
+	"encoding/binary"
+
+	"github.com/ethersphere/bee/pkg/feeds"
+	"golang.org/x/crypto/sha3"
+)
+
+// maxLevel is the level of the epoch covering the whole time range.
+const maxLevel = 32
+
+var _ feeds.Index = (*epoch)(nil)
+
+// epoch is a time range with the length of 2^level seconds, starting at a
+// multiple of its length.
+type epoch struct {
+	start uint64
+	level uint8
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (e *epoch) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 9)
+	binary.BigEndian.PutUint64(b, e.start)
+	b[8] = e.level
+	h := sha3.NewLegacyKeccak256()
+	if _, err := h.Write(b); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Next implements the feeds.Index interface. The update at time at goes to
+// the child epoch containing it, if the epoch of the last update contains
+// the time, otherwise to the child epoch of the lowest common ancestor.
+func (e *epoch) Next(last int64, at uint64) feeds.Index {
+	if e.start+e.length() > at {
+		return e.childAt(at)
+	}
+	return lca(int64(at), last).childAt(at)
+}
+
+// length returns the length of the epoch in seconds.
+func (e *epoch) length() uint64 {
+	return 1 << e.level
+}
+
+// parent returns the epoch one level above containing the epoch.
+func (e *epoch) parent() *epoch {
+	length := e.length() << 1
+	return &epoch{
+		start: (e.start / length) * length,
+		level: e.level + 1,
+	}
+}
+
+// left returns the preceding epoch on the same level.
+func (e *epoch) left() *epoch {
+	return &epoch{
+		start: e.start - e.length(),
+		level: e.level,
+	}
+}
+
+// isLeft returns true if the epoch is the first child of its parent.
+func (e *epoch) isLeft() bool {
+	return e.start&e.length() == 0
+}
+
+// childAt returns the epoch one level below containing the time at.
+func (e *epoch) childAt(at uint64) *epoch {
+	level := e.level - 1
+	length := uint64(1) << level
+	return &epoch{
+		start: (at / length) * length,
+		level: level,
+	}
+}
+
+// lca returns the lowest common ancestor epoch of the times at and after. If
+// after is zero, the epoch covering the whole time range is returned.
+func lca(at, after int64) *epoch {
+	if after == 0 {
+		return &epoch{0, maxLevel}
+	}
+	diff := uint64(at - after)
+	length := uint64(1)
+	var level uint8
+	for level < maxLevel && (length < diff || uint64(at)/length != uint64(after)/length) {
+		length <<= 1
+		level++
+	}
+	return &epoch{
+		start: (uint64(after) / length) * length,
+		level: level,
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package epochs provides the epoch feeds, indexing the updates with epochs.
+//
+// The time is divided into epochs forming a binary tree, where each epoch is
+// twice as long as its children. The first update goes to the epoch covering
+// the whole time range, and every next update goes to the child of the
+// lowest common ancestor of its time and the time of the previous update.
+// The latest update is found by searching the tree from the lowest common
+// ancestor of the requested time and a hint, down to the finest resolution.
+package epochs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/feeds"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ErrUpdateTooEarly is returned when the time of the update is not after the
+// time of the last update.
+var ErrUpdateTooEarly = errors.New("update not after the last update")
+
+var _ feeds.Lookup = (*finder)(nil)
+
+// finder finds the latest update by searching the epochs.
+type finder struct {
+	getter *feeds.Getter
+}
+
+// NewFinder creates a new lookup of the epoch feed updates.
+func NewFinder(getter storage.Getter, feed *feeds.Feed) feeds.Lookup {
+	return &finder{feeds.NewGetter(getter, feed)}
+}
+
+// At implements the feeds.Lookup interface.
+func (f *finder) At(ctx context.Context, at, after int64) (ch swarm.Chunk, current feeds.Index, err error) {
+	e, ch, err := f.common(ctx, at, after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ch == nil {
+		return nil, nil, nil
+	}
+	if e.level == 0 {
+		return ch, e, nil
+	}
+	ch, e, err = f.at(ctx, uint64(at), e.childAt(uint64(at)), ch, e)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ch, e, nil
+}
+
+// common returns the lowest common ancestor epoch of the times at and after
+// that has an update at or before the time at, along with the update.
+func (f *finder) common(ctx context.Context, at, after int64) (*epoch, swarm.Chunk, error) {
+	for e := lca(at, after); ; e = e.parent() {
+		ch, err := f.getter.Get(ctx, e)
+		if err != nil {
+			if !errors.Is(err, storage.ErrNotFound) {
+				return nil, nil, err
+			}
+		} else {
+			ts, err := feeds.UpdatedAt(ch)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ts <= uint64(at) {
+				return e, ch, nil
+			}
+		}
+		if e.level == maxLevel {
+			return e, nil, nil
+		}
+	}
+}
+
+// at searches the epoch e and its descendants for the latest update at or
+// before the time at. The ch and ce are the latest update found so far and
+// its epoch.
+func (f *finder) at(ctx context.Context, at uint64, e *epoch, ch swarm.Chunk, ce *epoch) (swarm.Chunk, *epoch, error) {
+	u, err := f.getter.Get(ctx, e)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, err
+		}
+		// no update in the epoch, the preceding one may have a later update
+		// than the one already found
+		if e.isLeft() {
+			return ch, ce, nil
+		}
+		return f.at(ctx, e.start-1, e.left(), ch, ce)
+	}
+	ts, err := feeds.UpdatedAt(u)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ts > at {
+		if e.isLeft() {
+			return ch, ce, nil
+		}
+		return f.at(ctx, e.start-1, e.left(), ch, ce)
+	}
+	if e.level == 0 {
+		return u, e, nil
+	}
+	return f.at(ctx, at, e.childAt(at), u, e)
+}
+
+var _ feeds.Updater = (*updater)(nil)
+
+// updater publishes the epoch feed updates.
+type updater struct {
+	putter  *feeds.Putter
+	finder  feeds.Lookup
+	found   bool        // the last update is looked up
+	last    int64       // time of the last update
+	current feeds.Index // epoch of the last update
+}
+
+// NewUpdater creates a new updater of the epoch feed with the topic, owned by
+// the signer. The epoch of the first update is derived from the latest
+// existing update.
+func NewUpdater(storer storage.Storer, signer crypto.Signer, topic []byte) (feeds.Updater, error) {
+	p, err := feeds.NewPutter(storer, signer, topic, feeds.Epoch)
+	if err != nil {
+		return nil, err
+	}
+	return &updater{
+		putter: p,
+		finder: NewFinder(storer, p.Feed),
+	}, nil
+}
+
+// Update implements the feeds.Updater interface.
+func (u *updater) Update(ctx context.Context, at int64, payload []byte) error {
+	if !u.found {
+		ch, current, err := u.finder.At(ctx, at, 0)
+		if err != nil {
+			return err
+		}
+		if ch != nil {
+			ts, err := feeds.UpdatedAt(ch)
+			if err != nil {
+				return err
+			}
+			u.last = int64(ts)
+			u.current = current
+		}
+		u.found = true
+	}
+
+	var next feeds.Index = &epoch{0, maxLevel}
+	if u.current != nil {
+		if at <= u.last {
+			return fmt.Errorf("%w: update at %d, last update at %d", ErrUpdateTooEarly, at, u.last)
+		}
+		next = u.current.Next(u.last, uint64(at))
+	}
+	if err := u.putter.Put(ctx, next, at, payload); err != nil {
+		return err
+	}
+	u.last = at
+	u.current = next
+	return nil
+}
+
+// Feed implements the feeds.Updater interface.
+func (u *updater) Feed() *feeds.Feed {
+	return u.putter.Feed
+}
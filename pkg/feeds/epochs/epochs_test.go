@@ -0,0 +1,71 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package epochs_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/feeds/epochs"
+	"github.com/ethersphere/bee/pkg/feeds/test"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+)
+
+func TestFinder(t *testing.T) {
+	random := make([]int64, 50)
+	at := int64(1600000000)
+	r := rand.New(rand.NewSource(1))
+	for i := range random {
+		at += 1 + r.Int63n(100000)
+		random[i] = at
+	}
+
+	for _, tc := range []struct {
+		name  string
+		times []int64
+	}{
+		{
+			name:  "single update",
+			times: []int64{1600000000},
+		},
+		{
+			name:  "consecutive",
+			times: []int64{1600000000, 1600000001, 1600000002, 1600000003, 1600000004},
+		},
+		{
+			name:  "irregular",
+			times: []int64{5, 6, 100, 1000, 1600000000, 1600000017, 1600090000},
+		},
+		{
+			name:  "random",
+			times: random,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			test.Run(t, tc.times, epochs.NewUpdater, epochs.NewFinder)
+		})
+	}
+}
+
+func TestUpdateTooEarly(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	updater, err := epochs.NewUpdater(mock.NewStorer(), crypto.NewDefaultSigner(privKey), []byte("topic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := updater.Update(context.Background(), 1600000000, []byte("foo")); err != nil {
+		t.Fatal(err)
+	}
+	err = updater.Update(context.Background(), 1600000000, []byte("bar"))
+	if !errors.Is(err, epochs.ErrUpdateTooEarly) {
+		t.Fatalf("got error %v, want %v", err, epochs.ErrUpdateTooEarly)
+	}
+}
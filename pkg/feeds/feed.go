@@ -0,0 +1,165 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package feeds provides mutable pointers on top of single-owner chunks.
+//
+// A feed is identified by its owner and topic. Every update of the feed is a
+// single-owner chunk signed by the owner, with the identifier derived from
+// the topic and the index of the update. The content of the update is the
+// time of the update followed by the payload, usually a reference.
+//
+// The indexing scheme and the lookup of the latest update are provided by the
+// sequence and the epochs subpackages.
+package feeds
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrUnknownType is returned when the feed type is not known.
+var ErrUnknownType = errors.New("unknown feed type")
+
+// Type enumerates the indexing schemes of feeds.
+type Type int
+
+const (
+	// Sequence feeds index the updates with sequential numbers.
+	Sequence Type = iota
+	// Epoch feeds index the updates with the epochs of the update time.
+	Epoch
+)
+
+// String implements the fmt.Stringer interface.
+func (t Type) String() string {
+	switch t {
+	case Sequence:
+		return "sequence"
+	case Epoch:
+		return "epoch"
+	default:
+		return ""
+	}
+}
+
+// ParseType returns the feed type with the given name.
+func ParseType(s string) (Type, error) {
+	switch s {
+	case "sequence":
+		return Sequence, nil
+	case "epoch":
+		return Epoch, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrUnknownType, s)
+	}
+}
+
+// Index is the index of a feed update.
+type Index interface {
+	encoding.BinaryMarshaler
+	// Next returns the index of the update at time at, given the time of
+	// the last update.
+	Next(last int64, at uint64) Index
+}
+
+// Lookup finds the feed update valid at a given time.
+type Lookup interface {
+	// At returns the latest update chunk at or before the time at and its
+	// index. The after argument is the time of a known update used as a hint,
+	// or zero if unknown. The returned chunk is nil if there are no updates.
+	At(ctx context.Context, at, after int64) (ch swarm.Chunk, current Index, err error)
+}
+
+// Updater publishes feed updates.
+type Updater interface {
+	// Update publishes the payload as the update of the feed at time at.
+	Update(ctx context.Context, at int64, payload []byte) error
+	// Feed returns the updated feed.
+	Feed() *Feed
+}
+
+// Feed is a mutable pointer identified by its owner and topic.
+type Feed struct {
+	Topic []byte
+	Owner *soc.Owner
+	Type  Type
+}
+
+// New creates a new feed.
+func New(topic []byte, owner *soc.Owner, t Type) *Feed {
+	return &Feed{
+		Topic: topic,
+		Owner: owner,
+		Type:  t,
+	}
+}
+
+// Update returns the update of the feed with the given index.
+func (f *Feed) Update(index Index) *Update {
+	return &Update{
+		Feed:  f,
+		index: index,
+	}
+}
+
+// Update is a feed update with its index.
+type Update struct {
+	*Feed
+	index Index
+}
+
+// Id returns the single-owner chunk identifier of the update.
+func (u *Update) Id() (soc.Id, error) {
+	i, err := u.index.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	h := sha3.NewLegacyKeccak256()
+	if _, err := h.Write(u.Topic); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(i); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// Address returns the single-owner chunk address of the update.
+func (u *Update) Address() (swarm.Address, error) {
+	id, err := u.Id()
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return soc.CreateAddress(id, u.Owner)
+}
+
+// Getter retrieves the updates of a feed.
+type Getter struct {
+	getter storage.Getter
+	*Feed
+}
+
+// NewGetter creates a new Getter of the feed updates.
+func NewGetter(getter storage.Getter, feed *Feed) *Getter {
+	return &Getter{
+		getter: getter,
+		Feed:   feed,
+	}
+}
+
+// Get retrieves the update chunk with the given index.
+func (g *Getter) Get(ctx context.Context, i Index) (swarm.Chunk, error) {
+	addr, err := g.Update(i).Address()
+	if err != nil {
+		return nil, err
+	}
+	return g.getter.Get(ctx, storage.ModeGetRequest, addr)
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package feeds
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// timestampSize is the length of the update time at the start of the
+// update content.
+const timestampSize = 8
+
+// ErrInvalidUpdate is returned when a chunk is not a valid feed update.
+var ErrInvalidUpdate = errors.New("invalid feed update")
+
+// Putter publishes the updates of a feed owned by the signer.
+type Putter struct {
+	putter storage.Putter
+	signer crypto.Signer
+	*Feed
+}
+
+// NewPutter creates a new Putter of the updates of the feed with the topic,
+// owned by the signer.
+func NewPutter(putter storage.Putter, signer crypto.Signer, topic []byte, t Type) (*Putter, error) {
+	publicKey, err := signer.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	ownerAddress, err := crypto.NewEthereumAddress(*publicKey)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := soc.NewOwner(ownerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &Putter{
+		putter: putter,
+		signer: signer,
+		Feed:   New(topic, owner, t),
+	}, nil
+}
+
+// Put signs and stores the update with the given index, time and payload.
+func (p *Putter) Put(ctx context.Context, i Index, at int64, payload []byte) error {
+	id, err := p.Update(i).Id()
+	if err != nil {
+		return err
+	}
+	data := make([]byte, timestampSize+len(payload))
+	binary.BigEndian.PutUint64(data, uint64(at))
+	copy(data[timestampSize:], payload)
+	cac, err := content.NewChunk(data)
+	if err != nil {
+		return err
+	}
+	s, err := soc.New(id, cac, p.signer)
+	if err != nil {
+		return err
+	}
+	ch, err := s.ToChunk()
+	if err != nil {
+		return err
+	}
+	_, err = p.putter.Put(ctx, storage.ModePutUpload, ch)
+	return err
+}
+
+// UpdatedAt returns the time of the update chunk.
+func UpdatedAt(ch swarm.Chunk) (uint64, error) {
+	at, _, err := FromChunk(ch)
+	return at, err
+}
+
+// FromChunk returns the time and the payload of the update chunk.
+func FromChunk(ch swarm.Chunk) (uint64, []byte, error) {
+	s, err := soc.FromChunk(ch)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrInvalidUpdate, err)
+	}
+	data := s.WrappedChunk().Data()
+	if len(data) < 8+timestampSize {
+		return 0, nil, fmt.Errorf("%w: content length %d", ErrInvalidUpdate, len(data))
+	}
+	data = data[8:]
+	return binary.BigEndian.Uint64(data[:timestampSize]), data[timestampSize:], nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sequence provides the sequence feeds, indexing the updates with
+// sequential numbers.
+//
+// The latest update is found by probing the indexes with exponentially
+// growing steps, until an update is not found or it is later than the
+// requested time, followed by a binary search between the last two probes.
+package sequence
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/feeds"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ feeds.Index = (*index)(nil)
+
+// index is the sequential number of a feed update.
+type index struct {
+	index uint64
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (i *index) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, i.index)
+	return b, nil
+}
+
+// Next implements the feeds.Index interface.
+func (i *index) Next(last int64, at uint64) feeds.Index {
+	return &index{i.index + 1}
+}
+
+var _ feeds.Lookup = (*finder)(nil)
+
+// finder finds the latest update by probing the indexes.
+type finder struct {
+	getter *feeds.Getter
+}
+
+// NewFinder creates a new lookup of the sequence feed updates.
+func NewFinder(getter storage.Getter, feed *feeds.Feed) feeds.Lookup {
+	return &finder{feeds.NewGetter(getter, feed)}
+}
+
+// At implements the feeds.Lookup interface. The after hint is not used, as
+// the indexes are not related to the update time.
+func (f *finder) At(ctx context.Context, at, after int64) (ch swarm.Chunk, current feeds.Index, err error) {
+	ch, err = f.get(ctx, 0, at)
+	if err != nil || ch == nil {
+		return nil, nil, err
+	}
+
+	// the update at the index lo is found, while the one at hi is not
+	var lo, hi uint64
+	for step := uint64(1); ; step *= 2 {
+		u, err := f.get(ctx, lo+step, at)
+		if err != nil {
+			return nil, nil, err
+		}
+		if u == nil {
+			hi = lo + step
+			break
+		}
+		ch, lo = u, lo+step
+	}
+	for hi-lo > 1 {
+		i := lo + (hi-lo)/2
+		u, err := f.get(ctx, i, at)
+		if err != nil {
+			return nil, nil, err
+		}
+		if u == nil {
+			hi = i
+		} else {
+			ch, lo = u, i
+		}
+	}
+	return ch, &index{lo}, nil
+}
+
+// get returns the update at the index i, or nil if it is not found or it is
+// later than the time at.
+func (f *finder) get(ctx context.Context, i uint64, at int64) (swarm.Chunk, error) {
+	u, err := f.getter.Get(ctx, &index{i})
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ts, err := feeds.UpdatedAt(u)
+	if err != nil {
+		return nil, err
+	}
+	if ts > uint64(at) {
+		return nil, nil
+	}
+	return u, nil
+}
+
+var _ feeds.Updater = (*updater)(nil)
+
+// updater publishes the sequence feed updates.
+type updater struct {
+	putter *feeds.Putter
+	finder feeds.Lookup
+	next   feeds.Index
+}
+
+// NewUpdater creates a new updater of the sequence feed with the topic, owned
+// by the signer. The index of the first update follows the index of the last
+// existing update.
+func NewUpdater(storer storage.Storer, signer crypto.Signer, topic []byte) (feeds.Updater, error) {
+	p, err := feeds.NewPutter(storer, signer, topic, feeds.Sequence)
+	if err != nil {
+		return nil, err
+	}
+	return &updater{
+		putter: p,
+		finder: NewFinder(storer, p.Feed),
+	}, nil
+}
+
+// Update implements the feeds.Updater interface.
+func (u *updater) Update(ctx context.Context, at int64, payload []byte) error {
+	if u.next == nil {
+		_, current, err := u.finder.At(ctx, math.MaxInt64, 0)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			u.next = &index{0}
+		} else {
+			u.next = current.Next(at, uint64(at))
+		}
+	}
+	if err := u.putter.Put(ctx, u.next, at, payload); err != nil {
+		return err
+	}
+	u.next = u.next.Next(at, uint64(at))
+	return nil
+}
+
+// Feed implements the feeds.Updater interface.
+func (u *updater) Feed() *feeds.Feed {
+	return u.putter.Feed
+}
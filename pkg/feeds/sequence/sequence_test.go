@@ -0,0 +1,92 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sequence_test
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/feeds/sequence"
+	"github.com/ethersphere/bee/pkg/feeds/test"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestFinder(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		times []int64
+	}{
+		{
+			name:  "single update",
+			times: []int64{1600000000},
+		},
+		{
+			name:  "consecutive",
+			times: []int64{1600000000, 1600000001, 1600000002, 1600000003},
+		},
+		{
+			name:  "irregular",
+			times: []int64{5, 6, 100, 1000, 1600000000, 1600000017, 1600090000},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			test.Run(t, tc.times, sequence.NewUpdater, sequence.NewFinder)
+		})
+	}
+}
+
+// TestFinderProbes verifies that the latest of many updates is found with a
+// logarithmic number of probes.
+func TestFinderProbes(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	storer := mock.NewStorer()
+	updater, err := sequence.NewUpdater(storer, crypto.NewDefaultSigner(privKey), []byte("topic"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates := 1000
+	for i := 0; i < updates; i++ {
+		if err := updater.Update(context.Background(), int64(i), []byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	getter := &countingGetter{Getter: storer}
+	_, current, err := sequence.NewFinder(getter, updater.Feed()).At(context.Background(), math.MaxInt64, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := current.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := binary.BigEndian.Uint64(b); got != uint64(updates-1) {
+		t.Fatalf("got index %d, want %d", got, updates-1)
+	}
+	// exponential probes followed by a binary search
+	if max := 2*bits.Len(uint(updates)) + 1; getter.count > max {
+		t.Fatalf("got %d probes, want at most %d", getter.count, max)
+	}
+}
+
+// countingGetter counts the chunks retrieved from the underlying getter.
+type countingGetter struct {
+	storage.Getter
+	count int
+}
+
+func (g *countingGetter) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	g.count++
+	return g.Getter.Get(ctx, mode, addr)
+}
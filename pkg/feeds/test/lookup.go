@@ -0,0 +1,182 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package test provides the tests shared by the feed implementations.
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/feeds"
+	"github.com/ethersphere/bee/pkg/netstore"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// UpdaterFunc creates a new updater of the feed with the topic.
+type UpdaterFunc func(storer storage.Storer, signer crypto.Signer, topic []byte) (feeds.Updater, error)
+
+// FinderFunc creates a new lookup of the feed.
+type FinderFunc func(getter storage.Getter, feed *feeds.Feed) feeds.Lookup
+
+// Run runs the tests of the lookup of the updates published at the given
+// times, which need to be increasing.
+func Run(t *testing.T, times []int64, newUpdater UpdaterFunc, newFinder FinderFunc) {
+	t.Run("no updates", func(t *testing.T) {
+		storer, signer := newStorerSigner(t)
+		updater, err := newUpdater(storer, signer, []byte("topic"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch, current, err := newFinder(storer, updater.Feed()).At(context.Background(), times[len(times)-1], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ch != nil || current != nil {
+			t.Fatalf("got update %v at index %v, want none", ch, current)
+		}
+	})
+
+	t.Run("lookup", func(t *testing.T) {
+		storer, signer := newStorerSigner(t)
+		updater, err := newUpdater(storer, signer, []byte("topic"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, at := range times {
+			if err := updater.Update(context.Background(), at, payload(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		finder := newFinder(storer, updater.Feed())
+
+		for i, at := range times {
+			checkAt(t, finder, at, 0, payload(i))
+			checkAt(t, finder, at, times[0], payload(i))
+			if i+1 < len(times) && times[i+1]-at > 1 {
+				checkAt(t, finder, at+1, 0, payload(i))
+			}
+		}
+		checkAt(t, finder, times[len(times)-1]+1000, 0, payload(len(times)-1))
+
+		if times[0] > 1 {
+			ch, _, err := finder.At(context.Background(), times[0]-1, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ch != nil {
+				t.Fatalf("got update %v before the first update", ch)
+			}
+		}
+	})
+
+	t.Run("network lookup", func(t *testing.T) {
+		storer, signer := newStorerSigner(t)
+		updater, err := newUpdater(storer, signer, []byte("topic"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, at := range times {
+			if err := updater.Update(context.Background(), at, payload(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// the updates which are not stored locally are looked up
+		// in the network, where they are not found either
+		getter := netstore.New(storer, nil, noRetrieval{})
+		finder := newFinder(getter, updater.Feed())
+
+		for i, at := range times {
+			checkAt(t, finder, at, 0, payload(i))
+		}
+		checkAt(t, finder, times[len(times)-1]+1000, 0, payload(len(times)-1))
+	})
+
+	t.Run("resume updates", func(t *testing.T) {
+		storer, signer := newStorerSigner(t)
+		for i, at := range times {
+			// a new updater for every update needs to continue after the
+			// existing updates
+			updater, err := newUpdater(storer, signer, []byte("topic"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := updater.Update(context.Background(), at, payload(i)); err != nil {
+				t.Fatal(err)
+			}
+			checkAt(t, newFinder(storer, updater.Feed()), at, 0, payload(i))
+		}
+	})
+
+	t.Run("other topic", func(t *testing.T) {
+		storer, signer := newStorerSigner(t)
+		updater, err := newUpdater(storer, signer, []byte("topic"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := updater.Update(context.Background(), times[0], payload(0)); err != nil {
+			t.Fatal(err)
+		}
+		other, err := newUpdater(storer, signer, []byte("other topic"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ch, _, err := newFinder(storer, other.Feed()).At(context.Background(), times[0], 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ch != nil {
+			t.Fatalf("got update %v of other topic", ch)
+		}
+	})
+}
+
+func checkAt(t *testing.T, finder feeds.Lookup, at, after int64, want []byte) {
+	t.Helper()
+
+	ch, current, err := finder.At(context.Background(), at, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch == nil || current == nil {
+		t.Fatalf("no update at %d", at)
+	}
+	_, got, err := feeds.FromChunk(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("at %d after %d: got payload %x, want %x", at, after, got, want)
+	}
+}
+
+func newStorerSigner(t *testing.T) (storage.Storer, crypto.Signer) {
+	t.Helper()
+
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mock.NewStorer(), crypto.NewDefaultSigner(privKey)
+}
+
+func payload(i int) []byte {
+	b := make([]byte, 32)
+	binary.BigEndian.PutUint64(b, uint64(i))
+	return b
+}
+
+// noRetrieval fails to retrieve any chunk from the network, as if no peer
+// had it.
+type noRetrieval struct{}
+
+func (noRetrieval) RetrieveChunk(ctx context.Context, addr swarm.Address) ([]byte, error) {
+	return nil, errors.New("no peer delivered the chunk")
+}
@@ -39,18 +39,24 @@ func (c *ChunkPipe) Read(b []byte) (int, error) {
 }
 
 // Writer implements io.Writer
+//
+// Writes of any length are accepted, the data is passed on in chunk size
+// pieces.
 func (c *ChunkPipe) Write(b []byte) (int, error) {
-	copy(c.data[c.cursor:], b)
-	c.cursor += len(b)
-	if c.cursor >= swarm.ChunkSize {
-		_, err := c.writer.Write(c.data[:swarm.ChunkSize])
-		if err != nil {
-			return len(b), err
+	var nw int
+	for nw < len(b) {
+		n := copy(c.data[c.cursor:swarm.ChunkSize], b[nw:])
+		c.cursor += n
+		nw += n
+		if c.cursor == swarm.ChunkSize {
+			_, err := c.writer.Write(c.data[:swarm.ChunkSize])
+			if err != nil {
+				return nw, err
+			}
+			c.cursor = 0
 		}
-		c.cursor -= swarm.ChunkSize
-		copy(c.data, c.data[swarm.ChunkSize:])
 	}
-	return len(b), nil
+	return nw, nil
 }
 
 // Closer implements io.Closer
@@ -23,9 +23,13 @@ var (
 // The call returns when the chunk for the given Swarm Address is found,
 // returning the length of the data which will be returned.
 // The called can then read the data on the io.Reader that was provided.
+//
+// The missing chunks of the data uploaded with redundancy are reconstructed
+// from the other chunks, and their addresses are reported by Recovered.
 type Joiner interface {
 	Join(ctx context.Context, address swarm.Address) (dataOut io.ReadCloser, dataLength int64, err error)
 	Size(ctx context.Context, address swarm.Address) (dataLength int64, err error)
+	Recovered() []swarm.Address
 }
 
 // Splitter starts a new file splitting job.
@@ -17,6 +17,7 @@ import (
 	"github.com/ethersphere/bee/pkg/file/joiner"
 	"github.com/ethersphere/bee/pkg/file/splitter"
 	test "github.com/ethersphere/bee/pkg/file/testing"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
@@ -35,16 +36,29 @@ var (
 func TestSplitThenJoin(t *testing.T) {
 	for i := start; i < end; i++ {
 		dataLengthStr := strconv.Itoa(i)
-		t.Run(dataLengthStr, testSplitThenJoin)
+		t.Run(dataLengthStr, func(t *testing.T) {
+			testSplitThenJoin(t, splitter.NewSimpleSplitter)
+		})
 	}
 }
 
-func testSplitThenJoin(t *testing.T) {
+// TestEncryptedSplitThenJoin does the same as TestSplitThenJoin with the
+// encrypting splitter, verifying that the joiner decrypts the data.
+func TestEncryptedSplitThenJoin(t *testing.T) {
+	for i := start; i < end; i++ {
+		dataLengthStr := strconv.Itoa(i)
+		t.Run(dataLengthStr, func(t *testing.T) {
+			testSplitThenJoin(t, splitter.NewEncryptingSplitter)
+		})
+	}
+}
+
+func testSplitThenJoin(t *testing.T, newSplitter func(storage.Putter) file.Splitter) {
 	var (
 		paramstring = strings.Split(t.Name(), "/")
 		dataIdx, _  = strconv.ParseInt(paramstring[1], 10, 0)
 		store       = mock.NewStorer()
-		s           = splitter.NewSimpleSplitter(store)
+		s           = newSplitter(store)
 		j           = joiner.NewSimpleJoiner(store)
 		data, _     = test.GetVector(t, int(dataIdx))
 	)
@@ -124,6 +138,10 @@ func (j *mockJoiner) Size(ctx context.Context, address swarm.Address) (dataSize
 	return j.l, nil
 }
 
+func (j *mockJoiner) Recovered() []swarm.Address {
+	return nil
+}
+
 // newMockJoiner creates a new mockJoiner.
 func newMockJoiner(l int64) file.Joiner {
 	return &mockJoiner{
@@ -7,31 +7,34 @@ package joiner
 
 import (
 	"context"
-	"encoding/binary"
 	"fmt"
 	"io"
+	"io/ioutil"
 
+	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/file"
-	"github.com/ethersphere/bee/pkg/file/joiner/internal"
+	"github.com/ethersphere/bee/pkg/file/redundancy"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 // simpleJoiner wraps a non-optimized implementation of file.Joiner.
 type simpleJoiner struct {
-	getter storage.Getter
+	getter   storage.Getter
+	recovery *recovery
 }
 
 // NewSimpleJoiner creates a new simpleJoiner.
 func NewSimpleJoiner(getter storage.Getter) file.Joiner {
 	return &simpleJoiner{
-		getter: getter,
+		getter:   getter,
+		recovery: newRecovery(),
 	}
 }
 
 func (s *simpleJoiner) Size(ctx context.Context, address swarm.Address) (dataSize int64, err error) {
 	// retrieve the root chunk to read the total data length the be retrieved
-	rootChunk, err := s.getter.Get(ctx, storage.ModeGetRequest, address)
+	rootChunk, err := newGetter(s.getter, address).Get(ctx, storage.ModeGetRequest, address)
 	if err != nil {
 		return 0, err
 	}
@@ -41,29 +44,58 @@ func (s *simpleJoiner) Size(ctx context.Context, address swarm.Address) (dataSiz
 		return 0, fmt.Errorf("invalid chunk content of %d bytes", chunkLength)
 	}
 
-	dataLength := binary.LittleEndian.Uint64(rootChunk.Data())
-	return int64(dataLength), nil
+	_, dataLength := redundancy.DecodeSpan(rootChunk.Data())
+	return dataLength, nil
+}
+
+// Recovered implements the file.Joiner interface.
+//
+// It returns the addresses of the chunks reconstructed by all the joins.
+func (s *simpleJoiner) Recovered() []swarm.Address {
+	return s.recovery.recovered()
 }
 
 // Join implements the file.Joiner interface.
 //
-// It uses a non-optimized internal component that only retrieves a data chunk
-// after the previous has been read.
+// The data is read by the Reader, which retrieves the chunks as the data is
+// read and reconstructs the missing chunks of the trees with redundancy.
 func (s *simpleJoiner) Join(ctx context.Context, address swarm.Address) (dataOut io.ReadCloser, dataSize int64, err error) {
-
-	// retrieve the root chunk to read the total data length the be retrieved
-	rootChunk, err := s.getter.Get(ctx, storage.ModeGetRequest, address)
+	r, err := newReader(ctx, s.getter, address, s.recovery)
 	if err != nil {
 		return nil, 0, err
 	}
+	return ioutil.NopCloser(r), r.Size(), nil
+}
 
-	// if this is a single chunk, short circuit to returning just that chunk
-	spanLength := binary.LittleEndian.Uint64(rootChunk.Data())
-	if spanLength <= swarm.ChunkSize {
-		data := rootChunk.Data()[8:]
-		return file.NewSimpleReadCloser(data), int64(spanLength), nil
+// newGetter returns the getter to be used for the chunk tree of the
+// address. References to encrypted data include the decryption key, the
+// chunks of such trees are decrypted transparently.
+func newGetter(getter storage.Getter, address swarm.Address) storage.Getter {
+	if len(address.Bytes()) == encryption.ReferenceSize {
+		return &decryptingGetter{getter: getter}
 	}
+	return getter
+}
 
-	r := internal.NewSimpleJoinerJob(ctx, s.getter, rootChunk)
-	return r, int64(spanLength), nil
+// decryptingGetter retrieves chunks by references that consist of the chunk
+// address and the decryption key, returning chunks with decrypted data.
+type decryptingGetter struct {
+	getter storage.Getter
+}
+
+// Get implements the storage.Getter interface.
+func (g *decryptingGetter) Get(ctx context.Context, mode storage.ModeGet, reference swarm.Address) (swarm.Chunk, error) {
+	ref := reference.Bytes()
+	if len(ref) != encryption.ReferenceSize {
+		return nil, fmt.Errorf("invalid encrypted reference length %d", len(ref))
+	}
+	ch, err := g.getter.Get(ctx, mode, swarm.NewAddress(ref[:swarm.HashSize]))
+	if err != nil {
+		return nil, err
+	}
+	data, err := encryption.DecryptChunkData(ch.Data(), encryption.Key(ref[swarm.HashSize:]))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt chunk %s: %w", ch.Address(), err)
+	}
+	return swarm.NewChunk(reference, data), nil
 }
@@ -8,14 +8,20 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/splitter"
 	filetest "github.com/ethersphere/bee/pkg/file/testing"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
+	mockbytes "gitlab.com/nolash/go-mockbytes"
 )
 
 // TestJoiner verifies that a newly created joiner returns the data stored
@@ -114,3 +120,465 @@ func TestJoinerWithReference(t *testing.T) {
 		t.Fatalf("expected resultbuffer %v, got %v", resultBuffer, firstChunk.Data()[:len(resultBuffer)])
 	}
 }
+
+// TestReader verifies that the data split by the splitter is returned by the
+// reader for arbitrary offsets, both for plain and encrypted data. The chunks
+// are retrieved only by the references in the tree.
+func TestReader(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		newSplitter func(storage.Putter) file.Splitter
+	}{
+		{name: "plain", newSplitter: splitter.NewSimpleSplitter},
+		{name: "encrypted", newSplitter: splitter.NewEncryptingSplitter},
+	} {
+		for _, dataLength := range []int{
+			31,
+			swarm.ChunkSize,
+			swarm.ChunkSize + 31,
+			swarm.ChunkSize * 2,
+			swarm.ChunkSize*128 + 31,
+			swarm.ChunkSize * 129,
+			swarm.ChunkSize * 130,
+			swarm.ChunkSize*128*2 + 31,
+			swarm.ChunkSize*128*2 + 32,         // dangling chunk of the reference size
+			swarm.ChunkSize*(128*128+129) + 31, // intermediate chunk with a single reference
+			swarm.ChunkSize*129 - 1,            // single encrypted reference after a full level
+			swarm.ChunkSize*193 - 1,
+			swarm.ChunkSize * 193,
+			swarm.ChunkSize*257 - 1,
+			swarm.ChunkSize * 257,
+		} {
+			t.Run(fmt.Sprintf("%s/%d", tc.name, dataLength), func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+
+				store := mock.NewStorer()
+				g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
+				data, err := g.SequentialBytes(dataLength)
+				if err != nil {
+					t.Fatal(err)
+				}
+				address, err := file.SplitWriteAll(ctx, tc.newSplitter(store), bytes.NewReader(data), int64(len(data)))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				r, err := joiner.NewReader(ctx, &retrievalGetter{Getter: store}, address)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if r.Size() != int64(len(data)) {
+					t.Fatalf("got size %d, want %d", r.Size(), len(data))
+				}
+
+				// read all the data
+				got, err := ioutil.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, data) {
+					t.Fatal("data mismatch")
+				}
+
+				// read ranges across the chunk boundaries
+				for _, off := range []int64{0, 1, swarm.ChunkSize - 1, swarm.ChunkSize, int64(len(data)) / 2, int64(len(data)) - 1} {
+					if off >= int64(len(data)) || off < 0 {
+						continue
+					}
+					b := make([]byte, swarm.ChunkSize+2)
+					n, err := r.ReadAt(b, off)
+					if err != nil && err != io.EOF {
+						t.Fatal(err)
+					}
+					want := data[off:]
+					if len(want) > len(b) {
+						want = want[:len(b)]
+					} else if err != io.EOF {
+						t.Fatalf("offset %d: expected io.EOF on short read", off)
+					}
+					if !bytes.Equal(b[:n], want) {
+						t.Fatalf("offset %d: data mismatch", off)
+					}
+				}
+
+				// seek and read the tail
+				off, err := r.Seek(-1, io.SeekEnd)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err = ioutil.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, data[off:]) {
+					t.Fatal("data mismatch after seek")
+				}
+			})
+		}
+	}
+}
+
+// TestReaderRetrievesOnlyRange verifies that the reader retrieves only the
+// chunks on the path from the root chunk to the requested data.
+func TestReaderRetrievesOnlyRange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := mock.NewStorer()
+	data, _ := filetest.GetVector(t, 18)
+	address, err := file.SplitWriteAll(ctx, splitter.NewSimpleSplitter(store), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getter := &countingGetter{Getter: store}
+	r, err := joiner.NewReader(ctx, getter, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 10)
+	off := int64(swarm.ChunkSize*5 + 3)
+	if _, err := r.ReadAt(b, off); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, data[off:off+10]) {
+		t.Fatal("data mismatch")
+	}
+
+	// root, intermediate and data chunk
+	if getter.count != 3 {
+		t.Fatalf("got %d chunks retrieved, want %d", getter.count, 3)
+	}
+
+	// only the next data chunk, as the path to the previous one is kept
+	off += swarm.ChunkSize
+	if _, err := r.ReadAt(b, off); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, data[off:off+10]) {
+		t.Fatal("data mismatch")
+	}
+	if getter.count != 4 {
+		t.Fatalf("got %d chunks retrieved, want %d", getter.count, 4)
+	}
+}
+
+// TestReaderBufferSize verifies that the reader accepts the buffers of any
+// size, not only of the chunk size.
+func TestReaderBufferSize(t *testing.T) {
+	store := mock.NewStorer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// create root chunk with 2 references and the referenced data chunks
+	rootChunk := filetest.GenerateTestRandomFileChunk(swarm.ZeroAddress, swarm.ChunkSize*2, swarm.SectionSize*2)
+	_, err := store.Put(ctx, storage.ModePutUpload, rootChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstAddress := swarm.NewAddress(rootChunk.Data()[8 : swarm.SectionSize+8])
+	firstChunk := filetest.GenerateTestRandomFileChunk(firstAddress, swarm.ChunkSize, swarm.ChunkSize)
+	_, err = store.Put(ctx, storage.ModePutUpload, firstChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secondAddress := swarm.NewAddress(rootChunk.Data()[swarm.SectionSize+8:])
+	secondChunk := filetest.GenerateTestRandomFileChunk(secondAddress, swarm.ChunkSize, swarm.ChunkSize)
+	_, err = store.Put(ctx, storage.ModePutUpload, secondChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append(append([]byte{}, firstChunk.Data()[8:]...), secondChunk.Data()[8:]...)
+
+	for _, size := range []int{swarm.SectionSize, swarm.ChunkSize, swarm.ChunkSize + swarm.SectionSize} {
+		r, err := joiner.NewReader(ctx, store, rootChunk.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := make([]byte, size)
+		c, err := r.Read(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c == 0 || !bytes.Equal(b[:c], data[:c]) {
+			t.Fatalf("buffer size %d: data mismatch", size)
+		}
+	}
+}
+
+// TestReaderOneLevel tests the retrieval of two data chunks immediately below
+// the root chunk level.
+func TestReaderOneLevel(t *testing.T) {
+	store := mock.NewStorer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// create root chunk with 2 references and the referenced data chunks
+	rootChunk := filetest.GenerateTestRandomFileChunk(swarm.ZeroAddress, swarm.ChunkSize*2, swarm.SectionSize*2)
+	_, err := store.Put(ctx, storage.ModePutUpload, rootChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstAddress := swarm.NewAddress(rootChunk.Data()[8 : swarm.SectionSize+8])
+	firstChunk := filetest.GenerateTestRandomFileChunk(firstAddress, swarm.ChunkSize, swarm.ChunkSize)
+	_, err = store.Put(ctx, storage.ModePutUpload, firstChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secondAddress := swarm.NewAddress(rootChunk.Data()[swarm.SectionSize+8:])
+	secondChunk := filetest.GenerateTestRandomFileChunk(secondAddress, swarm.ChunkSize, swarm.ChunkSize)
+	_, err = store.Put(ctx, storage.ModePutUpload, secondChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := joiner.NewReader(ctx, store, rootChunk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// verify first chunk content
+	outBuffer := make([]byte, 4096)
+	c, err := r.Read(outBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != 4096 {
+		t.Fatalf("expected firstchunk read count %d, got %d", 4096, c)
+	}
+	if !bytes.Equal(outBuffer, firstChunk.Data()[8:]) {
+		t.Fatalf("firstchunk data mismatch, expected %x, got %x", outBuffer, firstChunk.Data()[8:])
+	}
+
+	// verify second chunk content
+	c, err = r.Read(outBuffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != 4096 {
+		t.Fatalf("expected secondchunk read count %d, got %d", 4096, c)
+	}
+	if !bytes.Equal(outBuffer, secondChunk.Data()[8:]) {
+		t.Fatalf("secondchunk data mismatch, expected %x, got %x", outBuffer, secondChunk.Data()[8:])
+	}
+
+	// verify EOF is returned also after first time it is returned
+	_, err = r.Read(outBuffer)
+	if err != io.EOF {
+		t.Fatal("expected io.EOF")
+	}
+
+	_, err = r.Read(outBuffer)
+	if err != io.EOF {
+		t.Fatal("expected io.EOF")
+	}
+}
+
+// TestReaderTwoLevelsAcrossChunk tests the retrieval of data chunks below
+// first intermediate level and of the dangling data chunk moved up to the
+// root chunk level. Last chunk has sub-chunk length.
+func TestReaderTwoLevelsAcrossChunk(t *testing.T) {
+	store := mock.NewStorer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// create root chunk with 2 references, an intermediate chunk with
+	// references and the dangling data chunk
+	rootChunk := filetest.GenerateTestRandomFileChunk(swarm.ZeroAddress, swarm.ChunkSize*swarm.Branches+42, swarm.SectionSize*2)
+	_, err := store.Put(ctx, storage.ModePutUpload, rootChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	firstAddress := swarm.NewAddress(rootChunk.Data()[8 : swarm.SectionSize+8])
+	firstChunk := filetest.GenerateTestRandomFileChunk(firstAddress, swarm.ChunkSize*swarm.Branches, swarm.ChunkSize)
+	_, err = store.Put(ctx, storage.ModePutUpload, firstChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secondAddress := swarm.NewAddress(rootChunk.Data()[swarm.SectionSize+8:])
+	secondChunk := filetest.GenerateTestRandomFileChunk(secondAddress, 42, 42)
+	_, err = store.Put(ctx, storage.ModePutUpload, secondChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// create 128 chunks for all references in the intermediate chunk
+	cursor := 8
+	for i := 0; i < swarm.Branches; i++ {
+		chunkAddressBytes := firstChunk.Data()[cursor : cursor+swarm.SectionSize]
+		chunkAddress := swarm.NewAddress(chunkAddressBytes)
+		ch := filetest.GenerateTestRandomFileChunk(chunkAddress, swarm.ChunkSize, swarm.ChunkSize)
+		_, err := store.Put(ctx, storage.ModePutUpload, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cursor += swarm.SectionSize
+	}
+
+	r, err := joiner.NewReader(ctx, store, rootChunk.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// read back all the chunks and verify
+	b := make([]byte, swarm.ChunkSize)
+	for i := 0; i < swarm.Branches; i++ {
+		c, err := r.Read(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != swarm.ChunkSize {
+			t.Fatalf("chunk %d expected read %d bytes; got %d", i, swarm.ChunkSize, c)
+		}
+	}
+	c, err := r.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != 42 {
+		t.Fatalf("last chunk expected read %d bytes; got %d", 42, c)
+	}
+	if !bytes.Equal(b[:c], secondChunk.Data()[8:]) {
+		t.Fatal("last chunk data mismatch")
+	}
+}
+
+// TestPrefetchingReader verifies that the prefetching reader returns the data
+// in order, while fetching the chunks concurrently within the window.
+func TestPrefetchingReader(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		dataLength int
+		window     int
+	}{
+		{name: "single chunk", dataLength: 31, window: 4},
+		{name: "two levels", dataLength: swarm.ChunkSize*100 + 31, window: 4},
+		{name: "three levels", dataLength: swarm.ChunkSize*128*3 + 32, window: 16},
+		{name: "default window", dataLength: swarm.ChunkSize * 200},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			store := mock.NewStorer()
+			g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
+			data, err := g.SequentialBytes(tc.dataLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+			address, err := file.SplitWriteAll(ctx, splitter.NewSimpleSplitter(store), bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			getter := &concurrencyGetter{Getter: store}
+			metrics := joiner.NewPrefetchMetrics()
+			r, err := joiner.NewPrefetchingReader(ctx, getter, address, joiner.PrefetchOptions{
+				Window:  tc.window,
+				Metrics: metrics,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatal("data mismatch")
+			}
+
+			window := tc.window
+			if window == 0 {
+				window = joiner.DefaultPrefetchWindow
+			}
+			max := getter.maxConcurrent()
+			if tc.dataLength > swarm.ChunkSize && max < 2 {
+				t.Fatalf("got %d concurrent fetches, want more", max)
+			}
+			// the window of data chunks, an intermediate chunk per level and the read chunk
+			if limit := window + 3; max > limit {
+				t.Fatalf("got %d concurrent fetches, want at most %d", max, limit)
+			}
+
+			// read after seeking back, skipping the prefetched chunks
+			if _, err := r.Seek(int64(tc.dataLength/3), io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			got, err = ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data[tc.dataLength/3:]) {
+				t.Fatal("data mismatch after seek")
+			}
+		})
+	}
+}
+
+// concurrencyGetter records the maximal number of concurrent retrievals from
+// the underlying getter, each delayed to overlap with the others.
+type concurrencyGetter struct {
+	storage.Getter
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (g *concurrencyGetter) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	g.mu.Lock()
+	g.current++
+	if g.current > g.max {
+		g.max = g.current
+	}
+	g.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	g.mu.Lock()
+	g.current--
+	g.mu.Unlock()
+	return g.Getter.Get(ctx, mode, addr)
+}
+
+func (g *concurrencyGetter) maxConcurrent() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.max
+}
+
+// countingGetter counts the chunks retrieved from the underlying getter.
+type countingGetter struct {
+	storage.Getter
+	count int
+}
+
+func (g *countingGetter) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	g.count++
+	return g.Getter.Get(ctx, mode, addr)
+}
+
+// retrievalGetter fails to retrieve the chunks missing from the underlying
+// getter with an error other than storage.ErrNotFound, as the retrieval from
+// the network does.
+type retrievalGetter struct {
+	storage.Getter
+}
+
+func (g *retrievalGetter) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	ch, err := g.Getter.Get(ctx, mode, addr)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve chunk %s: %v", addr, err)
+	}
+	return ch, nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package joiner
+
+import (
+	"context"
+	"sync"
+
+	m "github.com/ethersphere/bee/pkg/metrics"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultPrefetchWindow is the default number of the data chunks fetched
+// ahead of the reads.
+const DefaultPrefetchWindow = 32
+
+// PrefetchOptions configure the fetching of the chunks ahead of the reads.
+type PrefetchOptions struct {
+	// Window is the maximal number of the data chunks fetched ahead of the
+	// reads. It also bounds the number of the fetched chunks held in memory.
+	Window int
+	// Metrics are optional metrics shared by the readers.
+	Metrics *PrefetchMetrics
+}
+
+// NewPrefetchingReader creates a new Reader which, unlike the one created by
+// NewReader, fetches the children of the intermediate chunks concurrently,
+// ahead of the reads. The data is still returned in order, and at most the
+// window of the fetched chunks is held in memory.
+//
+// The data chunks are prefetched with the window, while only the next
+// intermediate chunk is prefetched on the levels above.
+func NewPrefetchingReader(ctx context.Context, getter storage.Getter, address swarm.Address, o PrefetchOptions) (*Reader, error) {
+	r, err := NewReader(ctx, getter, address)
+	if err != nil {
+		return nil, err
+	}
+	window := o.Window
+	if window <= 0 {
+		window = DefaultPrefetchWindow
+	}
+	r.prefetcher = &prefetcher{
+		ctx:     ctx,
+		getter:  r.getter,
+		window:  window,
+		limit:   window + levelBufferLimit,
+		fetches: make(map[string]*fetch),
+		metrics: o.Metrics,
+	}
+	return r, nil
+}
+
+// PrefetchMetrics are the metrics of the chunks fetched ahead of the reads.
+type PrefetchMetrics struct {
+	// all metrics fields must be exported
+	// to be able to return them by Metrics()
+	// using reflection
+	FetchesInFlight  prometheus.Gauge
+	PrefetchedChunks prometheus.Counter
+	PrefetchHits     prometheus.Counter
+	DiscardedChunks  prometheus.Counter
+}
+
+// NewPrefetchMetrics creates the prefetch metrics, to be shared by all the
+// readers.
+func NewPrefetchMetrics() *PrefetchMetrics {
+	subsystem := "joiner"
+
+	return &PrefetchMetrics{
+		FetchesInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "fetches_in_flight",
+			Help:      "Number of chunks being fetched ahead of the reads.",
+		}),
+		PrefetchedChunks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "prefetched_chunks_count",
+			Help:      "Number of chunks fetched ahead of the reads.",
+		}),
+		PrefetchHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "prefetch_hits_count",
+			Help:      "Number of reads served by the prefetched chunks.",
+		}),
+		DiscardedChunks: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "discarded_chunks_count",
+			Help:      "Number of prefetched chunks discarded without being read.",
+		}),
+	}
+}
+
+// Metrics returns the prometheus collectors of the metrics.
+func (pm *PrefetchMetrics) Metrics() []prometheus.Collector {
+	return m.PrometheusCollectorsFromFields(pm)
+}
+
+// fetch is a chunk fetched ahead of the reads.
+type fetch struct {
+	done chan struct{}
+	ch   swarm.Chunk
+	err  error
+}
+
+// prefetcher holds the chunks fetched ahead of the reads, until they are read.
+type prefetcher struct {
+	ctx     context.Context
+	getter  storage.Getter
+	window  int // number of the data chunks to prefetch
+	limit   int // maximal number of the held chunks
+	mu      sync.Mutex
+	fetches map[string]*fetch
+	order   []string // keys of the fetches in the order they were started
+	metrics *PrefetchMetrics
+}
+
+// prefetch starts fetching the chunks with the addresses which are not held
+// already. If there are too many held chunks, the oldest fetched ones, which
+// were skipped by the reads, are discarded, or the remaining addresses are
+// not fetched.
+func (p *prefetcher) prefetch(addrs ...swarm.Address) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, addr := range addrs {
+		key := addr.ByteString()
+		if _, ok := p.fetches[key]; ok {
+			continue
+		}
+		if len(p.fetches) >= p.limit && !p.discard() {
+			return
+		}
+		f := &fetch{done: make(chan struct{})}
+		p.fetches[key] = f
+		p.order = append(p.order, key)
+		if p.metrics != nil {
+			p.metrics.FetchesInFlight.Inc()
+			p.metrics.PrefetchedChunks.Inc()
+		}
+		go func(addr swarm.Address) {
+			f.ch, f.err = p.getter.Get(p.ctx, storage.ModeGetRequest, addr)
+			if p.metrics != nil {
+				p.metrics.FetchesInFlight.Dec()
+			}
+			close(f.done)
+		}(addr)
+	}
+}
+
+// discard removes the oldest completed fetch. It must be called with the lock
+// held.
+func (p *prefetcher) discard() bool {
+	for i, key := range p.order {
+		f, ok := p.fetches[key]
+		if !ok {
+			continue
+		}
+		select {
+		case <-f.done:
+		default:
+			continue
+		}
+		delete(p.fetches, key)
+		p.order = append(p.order[:i:i], p.order[i+1:]...)
+		if p.metrics != nil {
+			p.metrics.DiscardedChunks.Inc()
+		}
+		return true
+	}
+	return false
+}
+
+// get returns the chunk with the address, waiting for it if it is being
+// prefetched, or retrieving it with the getter otherwise. The prefetched
+// chunk is released, as it is read only once.
+func (p *prefetcher) get(ctx context.Context, addr swarm.Address) (swarm.Chunk, error) {
+	key := addr.ByteString()
+	p.mu.Lock()
+	f, ok := p.fetches[key]
+	if ok {
+		delete(p.fetches, key)
+		for i, k := range p.order {
+			if k == key {
+				p.order = append(p.order[:i:i], p.order[i+1:]...)
+				break
+			}
+		}
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		return p.getter.Get(ctx, storage.ModeGetRequest, addr)
+	}
+	if p.metrics != nil {
+		p.metrics.PrefetchHits.Inc()
+	}
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if f.err != nil {
+		// the prefetch may have failed with the context of the reader
+		return p.getter.Get(ctx, storage.ModeGetRequest, addr)
+	}
+	return f.ch, nil
+}
@@ -0,0 +1,332 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package joiner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/redundancy"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// maximum amount of file tree levels the reader can handle
+const levelBufferLimit = 9
+
+var (
+	_ io.ReadSeeker = (*Reader)(nil)
+	_ io.ReaderAt   = (*Reader)(nil)
+)
+
+// Reader provides random access to the data represented by a chunk tree.
+//
+// Reads walk the chunk tree down to the data chunks that hold the requested
+// bytes, so that only the chunks which cover the requested range are
+// retrieved. The chunks on the path of the previous read are kept, and the
+// next read starts from the deepest of them which holds its offset.
+//
+// The chunks missing from the trees with redundancy are reconstructed from
+// their siblings and the parity chunks.
+type Reader struct {
+	ctx      context.Context
+	getter   storage.Getter
+	span     int64            // the total length of data represented by the root chunk
+	level    redundancy.Level // redundancy level of the chunk tree
+	refSize  int              // length of the references in intermediate chunks
+	spans    []int64          // maximum span lengths per level represented by one reference
+	levels   []int            // tree levels of the chunks on the path to the last data chunk
+	off      int64            // offset of the next Read
+	recovery *recovery
+	// prefetcher fetches the chunks ahead of the reads, if it is set
+	prefetcher *prefetcher
+
+	mu   sync.Mutex // protects path
+	path []*node    // chunks from the root chunk to the last read data chunk
+}
+
+// node is a chunk of the tree at its position in the data.
+type node struct {
+	data      []byte           // chunk data without the span
+	span      int64            // length of the data represented by the chunk
+	off       int64            // offset of the data represented by the chunk
+	level     redundancy.Level // redundancy level from the span of the chunk
+	treeLevel int              // level in the trees without redundancy, 0 for data chunks
+	last      bool             // whether the chunk is on the path to the last data chunk
+}
+
+// holds returns true if the data at the offset is represented by the chunk.
+func (n *node) holds(off int64) bool {
+	return off >= n.off && off < n.off+n.span
+}
+
+// NewReader creates a new Reader for the data referenced by the address. The
+// root chunk is retrieved to read the total data length, the other chunks are
+// retrieved when data is read. If the address is an encrypted reference, the
+// chunks are decrypted.
+func NewReader(ctx context.Context, getter storage.Getter, address swarm.Address) (*Reader, error) {
+	return newReader(ctx, getter, address, newRecovery())
+}
+
+func newReader(ctx context.Context, getter storage.Getter, address swarm.Address, rc *recovery) (*Reader, error) {
+	getter = newGetter(getter, address)
+
+	rootChunk, err := getter.Get(ctx, storage.ModeGetRequest, address)
+	if err != nil {
+		return nil, err
+	}
+	chunkData := rootChunk.Data()
+	if len(chunkData) < 8 {
+		return nil, fmt.Errorf("invalid chunk content of %d bytes", len(chunkData))
+	}
+
+	refSize := len(address.Bytes())
+	branches := swarm.ChunkSize / refSize
+	level, span := redundancy.DecodeSpan(chunkData[:8])
+	if level != redundancy.None {
+		if !level.Valid() {
+			return nil, fmt.Errorf("%w: %d", redundancy.ErrInvalidLevel, level)
+		}
+		branches = level.MaxShards()
+	}
+	root := &node{
+		data:  chunkData[8:],
+		span:  span,
+		level: level,
+		last:  true,
+	}
+	var levels []int
+	if level == redundancy.None {
+		levels = file.LastChunkLevels(span, refSize)
+		root.treeLevel = levels[0]
+	}
+	return &Reader{
+		ctx:      ctx,
+		getter:   getter,
+		span:     span,
+		level:    level,
+		refSize:  refSize,
+		spans:    file.GenerateSpanSizes(levelBufferLimit, branches),
+		levels:   levels,
+		recovery: rc,
+		path:     []*node{root},
+	}, nil
+}
+
+// Size returns the total length of the data.
+func (r *Reader) Size() int64 {
+	return r.span
+}
+
+// Recovered returns the addresses of the chunks which were reconstructed
+// from the parity chunks rather than retrieved.
+func (r *Reader) Recovered() []swarm.Address {
+	return r.recovery.recovered()
+}
+
+// Read implements the io.Reader interface. The io.EOF is returned only when
+// there is no more data to read.
+func (r *Reader) Read(b []byte) (int, error) {
+	n, err := r.ReadAt(b, r.off)
+	r.off += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek implements the io.Seeker interface.
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.span
+	default:
+		return 0, errors.New("seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("seek: negative position")
+	}
+	r.off = offset
+	return offset, nil
+}
+
+// ReadAt implements the io.ReaderAt interface.
+func (r *Reader) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("read: negative offset")
+	}
+	if off >= r.span {
+		return 0, io.EOF
+	}
+	want := len(b)
+	if remaining := r.span - off; int64(want) > remaining {
+		want = int(remaining)
+	}
+	var n int
+	for n < want {
+		leaf, err := r.leaf(off + int64(n))
+		if err != nil {
+			return n, err
+		}
+		data := leaf.data
+		if int64(len(data)) > leaf.span {
+			// decrypted short data chunks are not truncated to the span
+			data = data[:leaf.span]
+		}
+		o := off + int64(n) - leaf.off
+		if o > int64(len(data)) {
+			return n, fmt.Errorf("offset %d out of chunk data of %d bytes", o, len(data))
+		}
+		c := copy(b[n:want], data[o:])
+		if c == 0 {
+			return n, io.ErrUnexpectedEOF
+		}
+		n += c
+	}
+	if n < len(b) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// leaf returns the data chunk which holds the data at the offset. The tree
+// is walked down from the deepest chunk on the path of the previous read
+// which holds the offset, and the new path is kept for the next read.
+func (r *Reader) leaf(off int64) (*node, error) {
+	r.mu.Lock()
+	path := r.path
+	for len(path) > 1 && !path[len(path)-1].holds(off) {
+		path = path[:len(path)-1]
+	}
+	r.mu.Unlock()
+	// the kept path is shared with the concurrent reads
+	path = path[:len(path):len(path)]
+
+	for {
+		n := path[len(path)-1]
+		if r.isDataChunk(n) {
+			r.mu.Lock()
+			r.path = path
+			r.mu.Unlock()
+			return n, nil
+		}
+		c, err := r.childNode(n, len(path), off)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, c)
+	}
+}
+
+// childNode returns the child of the intermediate chunk, at the depth in the
+// tree, which holds the data at the offset.
+func (r *Reader) childNode(n *node, depth int, off int64) (*node, error) {
+	childSpan := file.ChildSpan(n.span, r.spans)
+	if r.level == redundancy.None {
+		childSpan = r.spans[n.treeLevel-1] * swarm.ChunkSize
+	}
+	children := (n.span + childSpan - 1) / childSpan
+	if int(children)*r.refSize > len(n.data) {
+		return nil, fmt.Errorf("%d children out of intermediate chunk", children)
+	}
+	i := (off - n.off) / childSpan
+	r.prefetch(n.data, childSpan, int(i)+1, int(children))
+	chunkData, err := r.child(n.level, n.data, n.span, childSpan, int(i))
+	if err != nil {
+		return nil, err
+	}
+	if len(chunkData) < 8 {
+		return nil, fmt.Errorf("invalid chunk content of %d bytes", len(chunkData))
+	}
+	level, span := redundancy.DecodeSpan(chunkData[:8])
+	c := &node{
+		data:  chunkData[8:],
+		span:  span,
+		off:   n.off + i*childSpan,
+		level: level,
+	}
+	if r.level == redundancy.None {
+		c.last = n.last && i == children-1
+		c.treeLevel = n.treeLevel - 1
+		if c.last {
+			if depth >= len(r.levels) {
+				return nil, fmt.Errorf("chunk tree deeper than %d levels", len(r.levels))
+			}
+			c.treeLevel = r.levels[depth]
+		}
+	}
+	return c, nil
+}
+
+// child returns the chunk data of the child with the index of the
+// intermediate chunk with the given redundancy level, data and span. The
+// child is reconstructed if it cannot be retrieved and the intermediate
+// chunk references parity chunks.
+func (r *Reader) child(level redundancy.Level, data []byte, span, childSpan int64, index int) ([]byte, error) {
+	cursor := index * r.refSize
+	address := swarm.NewAddress(data[cursor : cursor+r.refSize])
+	if d, ok := r.recovery.get(address); ok {
+		return d, nil
+	}
+	ch, err := r.get(address)
+	if err == nil {
+		return ch.Data(), nil
+	}
+	if level == redundancy.None || r.ctx.Err() != nil {
+		return nil, fmt.Errorf("get chunk %s: %w", address, err)
+	}
+	d, rerr := r.recover(data, span, childSpan, index)
+	if rerr != nil {
+		return nil, fmt.Errorf("get chunk %s: %w: recover: %v", address, err, rerr)
+	}
+	return d, nil
+}
+
+// get returns the chunk with the address, prefetched or not.
+func (r *Reader) get(address swarm.Address) (swarm.Chunk, error) {
+	if r.prefetcher != nil {
+		return r.prefetcher.get(r.ctx, address)
+	}
+	return r.getter.Get(r.ctx, storage.ModeGetRequest, address)
+}
+
+// prefetch starts fetching the children of the intermediate chunk with the
+// data, from the index up to the number of children. The data chunks are
+// prefetched with the window, the intermediate chunks one at a time.
+func (r *Reader) prefetch(data []byte, childSpan int64, from, children int) {
+	if r.prefetcher == nil || from >= children {
+		return
+	}
+	to := from + 1
+	if childSpan <= swarm.ChunkSize {
+		to = from + r.prefetcher.window
+	}
+	if to > children {
+		to = children
+	}
+	addrs := make([]swarm.Address, 0, to-from)
+	for i := from; i < to; i++ {
+		addrs = append(addrs, swarm.NewAddress(data[i*r.refSize:(i+1)*r.refSize]))
+	}
+	r.prefetcher.prefetch(addrs...)
+}
+
+// isDataChunk returns true if the chunk holds the file data rather than
+// references. In the trees with redundancy, only the intermediate chunks have
+// the redundancy level in their spans, while in the other trees the level of
+// the chunk follows from its position and the total length of the data.
+func (r *Reader) isDataChunk(n *node) bool {
+	if r.level != redundancy.None {
+		return n.level == redundancy.None
+	}
+	return n.treeLevel == 0
+}
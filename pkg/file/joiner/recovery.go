@@ -0,0 +1,166 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package joiner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/redundancy"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// recovery holds the chunks reconstructed from the parity chunks of the
+// trees with redundancy.
+type recovery struct {
+	mu     sync.Mutex
+	chunks map[string][]byte // chunk data by the chunk address
+	addrs  []swarm.Address   // addresses in the order of reconstruction
+}
+
+func newRecovery() *recovery {
+	return &recovery{
+		chunks: make(map[string][]byte),
+	}
+}
+
+func (rc *recovery) get(addr swarm.Address) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	data, ok := rc.chunks[addr.ByteString()]
+	return data, ok
+}
+
+func (rc *recovery) add(addr swarm.Address, data []byte) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if _, ok := rc.chunks[addr.ByteString()]; ok {
+		return
+	}
+	rc.chunks[addr.ByteString()] = data
+	rc.addrs = append(rc.addrs, addr)
+}
+
+// recovered returns the addresses of all the reconstructed chunks.
+func (rc *recovery) recovered() []swarm.Address {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]swarm.Address(nil), rc.addrs...)
+}
+
+// recover reconstructs the child chunk with the index from its siblings and
+// the parity chunks, all referenced by the intermediate chunk with the data
+// and span. All the other missing children are reconstructed as well.
+func (r *Reader) recover(data []byte, span, childSpan int64, index int) ([]byte, error) {
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+
+	dataShards := int((span + childSpan - 1) / childSpan)
+	refs := make([]swarm.Address, len(data)/r.refSize)
+	for i := range refs {
+		refs[i] = swarm.NewAddress(data[i*r.refSize : (i+1)*r.refSize])
+	}
+	if dataShards >= len(refs) {
+		return nil, fmt.Errorf("%w: no parity chunks", redundancy.ErrTooManyMissing)
+	}
+
+	shards := make([][]byte, len(refs))
+	var wg sync.WaitGroup
+	for i := range refs {
+		if i == index {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if d, ok := r.recovery.get(refs[i]); ok {
+				shards[i] = d
+				return
+			}
+			ch, err := r.getter.Get(ctx, storage.ModeGetRequest, refs[i])
+			if err != nil {
+				return
+			}
+			shards[i] = ch.Data()
+		}(i)
+	}
+	wg.Wait()
+	if err := r.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// the parities are computed over the chunk data padded to the same size
+	var size int
+	for _, s := range shards[dataShards:] {
+		if s != nil {
+			size = len(s)
+			break
+		}
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("%w: no parity chunks retrieved", redundancy.ErrTooManyMissing)
+	}
+	for i, s := range shards {
+		if s == nil {
+			continue
+		}
+		if len(s) > size {
+			return nil, fmt.Errorf("chunk %s of %d bytes larger than the parity chunks", refs[i], len(s))
+		}
+		padded := make([]byte, size)
+		copy(padded, s)
+		shards[i] = padded
+	}
+	missing := make([]bool, dataShards)
+	for i := range missing {
+		missing[i] = shards[i] == nil
+	}
+
+	if err := redundancy.Reconstruct(shards, dataShards); err != nil {
+		return nil, err
+	}
+
+	for i := range missing {
+		if !missing[i] {
+			continue
+		}
+		d, err := r.trimShard(shards[i])
+		if err != nil {
+			return nil, fmt.Errorf("reconstructed chunk %s: %w", refs[i], err)
+		}
+		ch, err := content.NewChunkWithSpan(d)
+		if err != nil {
+			return nil, err
+		}
+		if !ch.Address().Equal(refs[i]) {
+			return nil, fmt.Errorf("reconstructed chunk address %s, want %s", ch.Address(), refs[i])
+		}
+		r.recovery.add(refs[i], d)
+	}
+	d, _ := r.recovery.get(refs[index])
+	return d, nil
+}
+
+// trimShard returns the chunk data of the reconstructed shard without the
+// padding. The length of the data follows from the span of the chunk.
+func (r *Reader) trimShard(shard []byte) ([]byte, error) {
+	if len(shard) < 8 {
+		return nil, fmt.Errorf("invalid chunk content of %d bytes", len(shard))
+	}
+	level, length := redundancy.DecodeSpan(shard[:8])
+	if level != redundancy.None {
+		childSpan := file.ChildSpan(length, r.spans)
+		children := int((length + childSpan - 1) / childSpan)
+		length = int64((children + level.Parities(children)) * r.refSize)
+	}
+	if length > swarm.ChunkSize || 8+length > int64(len(shard)) {
+		return nil, fmt.Errorf("invalid chunk length %d", length)
+	}
+	return shard[:8+length], nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redundancy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The Reed-Solomon code works over GF(2^8) with the generator matrix of the
+// identity matrix for the data shards on top of a Cauchy matrix for the
+// parity shards. Every square submatrix of a Cauchy matrix is invertible, so
+// the data can be reconstructed from any shards as many as the data shards.
+
+// gfPolynomial is the irreducible polynomial of the field.
+const gfPolynomial = 0x11d
+
+var (
+	gfExp [512]byte
+	gfLog [256]byte
+	// gfMul is the full multiplication table of the field.
+	gfMul [256][256]byte
+)
+
+func init() {
+	x := 1
+	for i := 0; i < 255; i++ {
+		gfExp[i] = byte(x)
+		gfLog[x] = byte(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPolynomial
+		}
+	}
+	for i := 255; i < len(gfExp); i++ {
+		gfExp[i] = gfExp[i-255]
+	}
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			gfMul[a][b] = gfExp[int(gfLog[a])+int(gfLog[b])]
+		}
+	}
+}
+
+func gfInverse(a byte) byte {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// cauchyRow returns the coefficients of the parity shard with the index over
+// the data shards.
+func cauchyRow(parity, shards int) []byte {
+	row := make([]byte, shards)
+	x := byte(shards + parity)
+	for j := range row {
+		row[j] = gfInverse(x ^ byte(j))
+	}
+	return row
+}
+
+// generatorRow returns the row of the generator matrix for the shard with the
+// index, counting the data shards first.
+func generatorRow(i, shards int) []byte {
+	if i < shards {
+		row := make([]byte, shards)
+		row[i] = 1
+		return row
+	}
+	return cauchyRow(i-shards, shards)
+}
+
+// Encode returns the parity shards computed over the data shards. All the
+// data shards must be of the same length.
+func Encode(data [][]byte, parities int) ([][]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("no data shards")
+	}
+	if len(data)+parities > 256 {
+		return nil, fmt.Errorf("too many shards %d", len(data)+parities)
+	}
+	size := len(data[0])
+	for _, d := range data {
+		if len(d) != size {
+			return nil, errors.New("data shards of different lengths")
+		}
+	}
+
+	out := make([][]byte, parities)
+	for i := range out {
+		out[i] = make([]byte, size)
+		mulAdd(out[i], cauchyRow(i, len(data)), data)
+	}
+	return out, nil
+}
+
+// Reconstruct fills the missing data shards, the nil ones, from the other
+// data shards and the parity shards. The shards are the data shards followed
+// by the parity shards, all of the same length when present.
+func Reconstruct(shards [][]byte, dataShards int) error {
+	var missing []int
+	for i := 0; i < dataShards; i++ {
+		if shards[i] == nil {
+			missing = append(missing, i)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// use the first present shards as many as the data shards
+	var (
+		rows    [][]byte
+		present [][]byte
+	)
+	for i, s := range shards {
+		if s == nil {
+			continue
+		}
+		rows = append(rows, generatorRow(i, dataShards))
+		present = append(present, s)
+		if len(rows) == dataShards {
+			break
+		}
+	}
+	if len(rows) < dataShards {
+		return fmt.Errorf("%w: %d of %d data shards available", ErrTooManyMissing, len(rows), dataShards)
+	}
+
+	decode, err := invert(rows)
+	if err != nil {
+		return err
+	}
+	size := len(present[0])
+	for _, i := range missing {
+		shards[i] = make([]byte, size)
+		mulAdd(shards[i], decode[i], present)
+	}
+	return nil
+}
+
+// mulAdd adds to out the linear combination of the shards with the
+// coefficients.
+func mulAdd(out, coefficients []byte, shards [][]byte) {
+	for j, c := range coefficients {
+		if c == 0 {
+			continue
+		}
+		table := &gfMul[c]
+		for b, v := range shards[j] {
+			out[b] ^= table[v]
+		}
+	}
+}
+
+// invert returns the inverse of the square matrix, using the Gauss-Jordan
+// elimination.
+func invert(m [][]byte) ([][]byte, error) {
+	n := len(m)
+	a := make([][]byte, n)
+	inv := make([][]byte, n)
+	for i := range m {
+		a[i] = append([]byte{}, m[i]...)
+		inv[i] = make([]byte, n)
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for r := col; r < n; r++ {
+			if a[r][col] != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, errors.New("singular matrix")
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		if c := a[col][col]; c != 1 {
+			scale := &gfMul[gfInverse(c)]
+			for k := 0; k < n; k++ {
+				a[col][k] = scale[a[col][k]]
+				inv[col][k] = scale[inv[col][k]]
+			}
+		}
+		for r := 0; r < n; r++ {
+			if r == col || a[r][col] == 0 {
+				continue
+			}
+			f := &gfMul[a[r][col]]
+			for k := 0; k < n; k++ {
+				a[r][k] ^= f[a[col][k]]
+				inv[r][k] ^= f[inv[col][k]]
+			}
+		}
+	}
+	return inv, nil
+}
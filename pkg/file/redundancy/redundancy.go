@@ -0,0 +1,119 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package redundancy provides the erasure coded redundancy of the chunk trees.
+//
+// With redundancy, every intermediate chunk holds the references of its
+// children, the data shards, followed by the references of the Reed-Solomon
+// parity chunks computed over the children. Any missing children can be
+// reconstructed from any subset of the data shards and the parity chunks as
+// large as the number of the data shards.
+//
+// The redundancy level is encoded in the most significant byte of the span of
+// the intermediate chunks, which is never used by the real data lengths. The
+// chunk trees without redundancy are not changed, so their references stay
+// the same.
+package redundancy
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Level is the redundancy level of the chunk tree.
+type Level uint8
+
+const (
+	// None is the level without parity chunks.
+	None Level = iota
+	// Medium level tolerates the loss of about 7% of the chunks.
+	Medium
+	// Strong level tolerates the loss of about 16% of the chunks.
+	Strong
+	// Insane level tolerates the loss of about 24% of the chunks.
+	Insane
+	// Paranoid level tolerates the loss of about 70% of the chunks.
+	Paranoid
+)
+
+// maxParities are the numbers of the parity references of the full
+// intermediate chunks, per level.
+var maxParities = [...]int{
+	None:     0,
+	Medium:   9,
+	Strong:   21,
+	Insane:   31,
+	Paranoid: 90,
+}
+
+var (
+	// ErrInvalidLevel is returned for the unknown redundancy levels.
+	ErrInvalidLevel = errors.New("invalid redundancy level")
+	// ErrTooManyMissing is returned when there are not enough shards to
+	// reconstruct the missing ones.
+	ErrTooManyMissing = errors.New("too many missing shards")
+)
+
+// ParseLevel parses the redundancy level from its decimal representation.
+func ParseLevel(s string) (Level, error) {
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || !Level(v).Valid() {
+		return None, fmt.Errorf("%w: %q", ErrInvalidLevel, s)
+	}
+	return Level(v), nil
+}
+
+// Valid returns true if the level is known.
+func (l Level) Valid() bool {
+	return int(l) < len(maxParities)
+}
+
+// MaxShards returns the maximal number of the data shards, the children, of
+// an intermediate chunk, which is the branching factor of the chunk tree.
+func (l Level) MaxShards() int {
+	return swarm.Branches - maxParities[l]
+}
+
+// Parities returns the number of the parity chunks computed over the number
+// of the data shards.
+func (l Level) Parities(shards int) int {
+	if l == None || shards <= 0 {
+		return 0
+	}
+	maxShards := l.MaxShards()
+	return (shards*maxParities[l] + maxShards - 1) / maxShards
+}
+
+// Shards returns the number of the data shards of the intermediate chunk with
+// the number of references, which includes the parity references.
+func (l Level) Shards(refs int) (int, error) {
+	for shards := refs; shards > 0; shards-- {
+		if shards+l.Parities(shards) == refs {
+			return shards, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid number of references %d for redundancy level %d", refs, l)
+}
+
+// EncodeSpan returns the span of the intermediate chunk with the redundancy
+// level.
+func EncodeSpan(span int64, level Level) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, uint64(span))
+	b[7] = byte(level)
+	return b
+}
+
+// DecodeSpan returns the redundancy level and the length of the data from the
+// span of the chunk.
+func DecodeSpan(b []byte) (Level, int64) {
+	level := Level(b[7])
+	s := make([]byte, 8)
+	copy(s, b[:7])
+	return level, int64(binary.LittleEndian.Uint64(s))
+}
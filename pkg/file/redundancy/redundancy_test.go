@@ -0,0 +1,122 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redundancy_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/file/redundancy"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var levels = []redundancy.Level{redundancy.Medium, redundancy.Strong, redundancy.Insane, redundancy.Paranoid}
+
+func TestParseLevel(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		level redundancy.Level
+		err   error
+	}{
+		{value: "0", level: redundancy.None},
+		{value: "1", level: redundancy.Medium},
+		{value: "4", level: redundancy.Paranoid},
+		{value: "5", err: redundancy.ErrInvalidLevel},
+		{value: "-1", err: redundancy.ErrInvalidLevel},
+		{value: "strong", err: redundancy.ErrInvalidLevel},
+	} {
+		level, err := redundancy.ParseLevel(tc.value)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("%q: got error %v, want %v", tc.value, err, tc.err)
+		}
+		if level != tc.level {
+			t.Fatalf("%q: got level %v, want %v", tc.value, level, tc.level)
+		}
+	}
+}
+
+func TestLevelShards(t *testing.T) {
+	for _, level := range levels {
+		if got := level.MaxShards() + level.Parities(level.MaxShards()); got != swarm.Branches {
+			t.Fatalf("level %v: got %v references of the full chunk, want %v", level, got, swarm.Branches)
+		}
+		for shards := 1; shards <= level.MaxShards(); shards++ {
+			if level.Parities(shards) < 1 {
+				t.Fatalf("level %v: no parities for %v shards", level, shards)
+			}
+			got, err := level.Shards(shards + level.Parities(shards))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != shards {
+				t.Fatalf("level %v: got %v shards, want %v", level, got, shards)
+			}
+		}
+	}
+}
+
+func TestSpan(t *testing.T) {
+	for _, level := range append(levels, redundancy.None) {
+		span := int64(rand.Intn(1 << 40))
+		gotLevel, gotSpan := redundancy.DecodeSpan(redundancy.EncodeSpan(span, level))
+		if gotLevel != level || gotSpan != span {
+			t.Fatalf("got level %v span %v, want level %v span %v", gotLevel, gotSpan, level, span)
+		}
+	}
+}
+
+func TestReconstruct(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		shards   int
+		parities int
+		missing  int
+	}{
+		{name: "no missing", shards: 10, parities: 3},
+		{name: "single shard", shards: 1, parities: 1, missing: 1},
+		{name: "some missing", shards: 10, parities: 3, missing: 2},
+		{name: "all parities used", shards: 119, parities: 9, missing: 9},
+		{name: "paranoid", shards: 38, parities: 90, missing: 90},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := make([][]byte, tc.shards)
+			for i := range data {
+				data[i] = make([]byte, 100)
+				rand.Read(data[i])
+			}
+			parities, err := redundancy.Encode(data, tc.parities)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			shards := append(append([][]byte{}, data...), parities...)
+			for _, i := range rand.Perm(len(shards))[:tc.missing] {
+				shards[i] = nil
+			}
+			if err := redundancy.Reconstruct(shards, tc.shards); err != nil {
+				t.Fatal(err)
+			}
+			for i := range data {
+				if !bytes.Equal(shards[i], data[i]) {
+					t.Fatalf("shard %v: data mismatch", i)
+				}
+			}
+		})
+	}
+
+	t.Run("too many missing", func(t *testing.T) {
+		data := [][]byte{{1, 2}, {3, 4}, {5, 6}}
+		parities, err := redundancy.Encode(data, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shards := [][]byte{nil, nil, data[2], parities[0], nil}
+		if err := redundancy.Reconstruct(shards, 3); !errors.Is(err, redundancy.ErrTooManyMissing) {
+			t.Fatalf("got error %v, want %v", err, redundancy.ErrTooManyMissing)
+		}
+	})
+}
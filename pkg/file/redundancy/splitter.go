@@ -0,0 +1,213 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redundancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// splitter builds the chunk trees with the parity chunks.
+type splitter struct {
+	putter storage.Putter
+	level  Level
+}
+
+// NewSplitter creates a new file.Splitter which adds the parity chunks of the
+// redundancy level to every intermediate chunk. The level must not be None.
+func NewSplitter(putter storage.Putter, level Level) file.Splitter {
+	return &splitter{
+		putter: putter,
+		level:  level,
+	}
+}
+
+// Split implements the file.Splitter interface.
+//
+// The data is read until io.EOF. If the dataLength is positive, the length of
+// the read data must match it, otherwise the length is not known upfront.
+//
+// The tree is built bottom up. An intermediate chunk is created as soon as
+// all of its children are stored, while the last chunk of a level without
+// siblings is moved up the tree, as with the trees without redundancy.
+func (s *splitter) Split(ctx context.Context, r io.ReadCloser, dataLength int64) (swarm.Address, error) {
+	if s.level == None || !s.level.Valid() {
+		return swarm.ZeroAddress, fmt.Errorf("%w: %d", ErrInvalidLevel, s.level)
+	}
+
+	j := &splitterJob{
+		ctx:    ctx,
+		putter: s.putter,
+		level:  s.level,
+	}
+
+	var total int64
+	data := make([]byte, swarm.ChunkSize)
+	for {
+		n, err := io.ReadFull(r, data)
+		total += int64(n)
+		if n > 0 || total == 0 && err != nil {
+			if err := j.addData(data[:n]); err != nil {
+				return swarm.ZeroAddress, err
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return swarm.ZeroAddress, err
+		}
+	}
+	if dataLength > 0 && total != dataLength {
+		return swarm.ZeroAddress, fmt.Errorf("splitter received %d bytes of data, expected %d bytes", total, dataLength)
+	}
+
+	return j.sum()
+}
+
+// child is a stored chunk which is not yet referenced by its parent.
+type child struct {
+	address swarm.Address
+	data    []byte // chunk data with the span
+	span    int64  // length of the data under the chunk
+}
+
+// splitterJob holds the chunks which are not referenced yet, per tree level.
+type splitterJob struct {
+	ctx    context.Context
+	putter storage.Putter
+	level  Level
+	levels [][]child
+}
+
+// addData stores the data chunk.
+func (j *splitterJob) addData(payload []byte) error {
+	ch, err := content.NewChunk(payload)
+	if err != nil {
+		return err
+	}
+	if err := j.put(ch); err != nil {
+		return err
+	}
+	return j.add(0, child{
+		address: ch.Address(),
+		data:    ch.Data(),
+		span:    int64(len(payload)),
+	})
+}
+
+// add adds the chunk to the tree level, creating the parent chunk when the
+// level is full.
+func (j *splitterJob) add(lvl int, c child) error {
+	if lvl == len(j.levels) {
+		j.levels = append(j.levels, nil)
+	}
+	j.levels[lvl] = append(j.levels[lvl], c)
+	if len(j.levels[lvl]) < j.level.MaxShards() {
+		return nil
+	}
+	return j.addParent(lvl)
+}
+
+// addParent creates the intermediate chunk with the references of all the
+// chunks of the tree level and of their parity chunks, and adds it to the
+// level above.
+func (j *splitterJob) addParent(lvl int) error {
+	children := j.levels[lvl]
+	j.levels[lvl] = nil
+
+	var (
+		span int64
+		size int
+	)
+	for _, c := range children {
+		span += c.span
+		if len(c.data) > size {
+			size = len(c.data)
+		}
+	}
+
+	// the parities are computed over the chunk data padded with zeros
+	shards := make([][]byte, len(children))
+	for i, c := range children {
+		shards[i] = make([]byte, size)
+		copy(shards[i], c.data)
+	}
+	parities, err := Encode(shards, j.level.Parities(len(children)))
+	if err != nil {
+		return err
+	}
+
+	data := EncodeSpan(span, j.level)
+	for _, c := range children {
+		data = append(data, c.address.Bytes()...)
+	}
+	for _, p := range parities {
+		ch, err := content.NewChunkWithSpan(p)
+		if err != nil {
+			return err
+		}
+		if err := j.put(ch); err != nil {
+			return err
+		}
+		data = append(data, ch.Address().Bytes()...)
+	}
+
+	ch, err := content.NewChunkWithSpan(data)
+	if err != nil {
+		return err
+	}
+	if err := j.put(ch); err != nil {
+		return err
+	}
+	return j.add(lvl+1, child{
+		address: ch.Address(),
+		data:    ch.Data(),
+		span:    span,
+	})
+}
+
+// sum creates the parents of the remaining chunks of all levels and returns
+// the address of the root chunk.
+func (j *splitterJob) sum() (swarm.Address, error) {
+	for lvl := 0; lvl < len(j.levels); lvl++ {
+		children := j.levels[lvl]
+		top := true
+		for _, l := range j.levels[lvl+1:] {
+			if len(l) > 0 {
+				top = false
+				break
+			}
+		}
+		switch {
+		case len(children) == 0:
+		case len(children) == 1 && top:
+			return children[0].address, nil
+		case len(children) == 1:
+			// the single chunk is moved up the tree
+			j.levels[lvl] = nil
+			j.levels[lvl+1] = append(j.levels[lvl+1], children[0])
+		default:
+			if err := j.addParent(lvl); err != nil {
+				return swarm.ZeroAddress, err
+			}
+		}
+	}
+	return swarm.ZeroAddress, errors.New("no root chunk")
+}
+
+func (j *splitterJob) put(ch swarm.Chunk) error {
+	if _, err := j.putter.Put(j.ctx, storage.ModePutUpload, ch); err != nil {
+		return fmt.Errorf("store chunk %s: %w", ch.Address(), err)
+	}
+	return nil
+}
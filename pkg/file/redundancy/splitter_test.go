@@ -0,0 +1,249 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package redundancy_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/redundancy"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestSplitter verifies that the data split with redundancy is returned by
+// the joiners.
+func TestSplitter(t *testing.T) {
+	for _, level := range levels {
+		for _, dataLength := range []int{
+			0,
+			31,
+			swarm.ChunkSize,
+			swarm.ChunkSize + 31,
+			level.MaxShards() * swarm.ChunkSize,
+			level.MaxShards()*swarm.ChunkSize + 31,
+			(level.MaxShards()+2)*swarm.ChunkSize + 32,
+		} {
+			t.Run(fmt.Sprintf("%d/%d", level, dataLength), func(t *testing.T) {
+				ctx := context.Background()
+				store := mock.NewStorer()
+				data := randomData(t, dataLength)
+				address := split(t, store, level, data)
+
+				j := joiner.NewSimpleJoiner(store)
+				size, err := j.Size(ctx, address)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if size != int64(dataLength) {
+					t.Fatalf("got size %v, want %v", size, dataLength)
+				}
+				buf := bytes.NewBuffer(nil)
+				if _, err := file.JoinReadAll(j, address, buf); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(buf.Bytes(), data) {
+					t.Fatal("data mismatch")
+				}
+				if len(j.Recovered()) != 0 {
+					t.Fatalf("got %v recovered chunks, want none", len(j.Recovered()))
+				}
+			})
+		}
+	}
+}
+
+// TestSplitterThreeLevels verifies the chunk tree of three levels, with the
+// smallest branching factor.
+func TestSplitterThreeLevels(t *testing.T) {
+	level := redundancy.Paranoid
+	store := mock.NewStorer()
+	data := randomData(t, level.MaxShards()*level.MaxShards()*swarm.ChunkSize+1)
+	address := split(t, store, level, data)
+
+	r, err := joiner.NewReader(context.Background(), store, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatal("data mismatch")
+	}
+}
+
+// TestSplitterNoRedundancyForSingleChunk verifies that the data of a single
+// chunk has the same reference with and without redundancy.
+func TestSplitterNoRedundancyForSingleChunk(t *testing.T) {
+	store := mock.NewStorer()
+	data := randomData(t, swarm.ChunkSize)
+	address := split(t, store, redundancy.Strong, data)
+
+	want, err := file.SplitWriteAll(context.Background(), splitter.NewSimpleSplitter(store), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !address.Equal(want) {
+		t.Fatalf("got address %s, want %s", address, want)
+	}
+}
+
+// TestRecovery verifies that the missing chunks are reconstructed by the
+// reader and reported as recovered.
+func TestRecovery(t *testing.T) {
+	ctx := context.Background()
+	level := redundancy.Medium
+	dataShards := level.MaxShards()
+	parities := level.Parities(dataShards)
+	data := randomData(t, (dataShards+10)*swarm.ChunkSize)
+
+	store := mock.NewStorer()
+	address := split(t, store, level, data)
+	root, err := store.Get(ctx, storage.ModeGetRequest, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the root chunk references the full intermediate chunk and its sibling
+	intermediate := swarm.NewAddress(root.Data()[8 : 8+swarm.HashSize])
+	sibling := swarm.NewAddress(root.Data()[8+swarm.HashSize : 8+2*swarm.HashSize])
+
+	for _, tc := range []struct {
+		name    string
+		missing []swarm.Address
+		fail    bool
+	}{
+		{
+			name:    "data chunks",
+			missing: dataAddresses(t, data, 0, 3, 50, dataShards-1),
+		},
+		{
+			name:    "as many as parities",
+			missing: dataAddresses(t, data, rand.Perm(dataShards)[:parities]...),
+		},
+		{
+			name:    "intermediate chunk",
+			missing: []swarm.Address{intermediate},
+		},
+		{
+			name:    "too many missing",
+			missing: dataAddresses(t, data, rand.Perm(dataShards)[:parities+1]...),
+			fail:    true,
+		},
+		{
+			name:    "intermediate and its data chunk",
+			missing: append(dataAddresses(t, data, 1), intermediate),
+		},
+		{
+			name:    "intermediate and its sibling",
+			missing: []swarm.Address{intermediate, sibling},
+			fail:    true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			getter := &missingGetter{
+				Getter:  store,
+				missing: make(map[string]struct{}),
+			}
+			for _, a := range tc.missing {
+				getter.missing[a.String()] = struct{}{}
+			}
+
+			r, err := joiner.NewReader(ctx, getter, address)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if tc.fail {
+				if !errors.Is(err, storage.ErrNotFound) {
+					t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatal("data mismatch")
+			}
+
+			recovered := make(map[string]struct{})
+			for _, a := range r.Recovered() {
+				recovered[a.String()] = struct{}{}
+			}
+			if len(recovered) != len(tc.missing) {
+				t.Fatalf("got %v recovered chunks, want %v", len(recovered), len(tc.missing))
+			}
+			for _, a := range tc.missing {
+				if _, ok := recovered[a.String()]; !ok {
+					t.Fatalf("chunk %s not recovered", a)
+				}
+			}
+		})
+	}
+}
+
+// missingGetter returns storage.ErrNotFound for the missing chunks.
+type missingGetter struct {
+	storage.Getter
+	missing map[string]struct{}
+}
+
+func (g *missingGetter) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	if _, ok := g.missing[addr.String()]; ok {
+		return nil, storage.ErrNotFound
+	}
+	return g.Getter.Get(ctx, mode, addr)
+}
+
+func split(t *testing.T, store storage.Storer, level redundancy.Level, data []byte) swarm.Address {
+	t.Helper()
+
+	address, err := file.SplitWriteAll(context.Background(), redundancy.NewSplitter(store, level), bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+// dataAddresses returns the addresses of the data chunks with the indexes.
+func dataAddresses(t *testing.T, data []byte, indexes ...int) []swarm.Address {
+	t.Helper()
+
+	addrs := make([]swarm.Address, 0, len(indexes))
+	for _, i := range indexes {
+		end := (i + 1) * swarm.ChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		ch, err := content.NewChunk(data[i*swarm.ChunkSize : end])
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, ch.Address())
+	}
+	return addrs
+}
+
+func randomData(t *testing.T, size int) []byte {
+	t.Helper()
+
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
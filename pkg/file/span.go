@@ -21,7 +21,7 @@ func GenerateSpanSizes(levels, branches int) []int64 {
 	spans := make([]int64, levels)
 	branchesSixtyfour := int64(branches)
 	var span int64 = 1
-	for i := 0; i < 9; i++ {
+	for i := 0; i < levels; i++ {
 		spans[i] = span
 		span *= branchesSixtyfour
 	}
@@ -42,3 +42,102 @@ func Levels(length int64, sectionSize, branches int) int {
 
 	return int(math.Log(float64(c))/math.Log(float64(b)) + 1)
 }
+
+// ChildSpan returns the maximum length of data referenced by one reference in
+// the intermediate chunk with the span, for the maximum span lengths per
+// level of the chunk tree. It holds only for the trees in which every
+// intermediate chunk is at the lowest level that can reference its span, as
+// the trees with redundancy, while in the other trees the chunks on the path
+// to the last data chunk may be at higher levels, see LastChunkLevels.
+func ChildSpan(span int64, spans []int64) int64 {
+	chunks := (span + swarm.ChunkSize - 1) / swarm.ChunkSize
+	var s int64 = 1
+	for _, v := range spans {
+		if v >= chunks {
+			break
+		}
+		s = v
+	}
+	return s * swarm.ChunkSize
+}
+
+// LastChunkLevels returns the levels of the chunks on the path from the root
+// chunk to the last data chunk of the chunk tree, which the splitter builds
+// for the data of the length with the references of the size. The data
+// chunks are at the level 0 and the intermediate chunks at the level of the
+// references they hold, so that only the chunks on this path may have a
+// lower level than one below the level of their parent chunks.
+//
+// The splitter does not wrap a single dangling reference at the end of a
+// level in an intermediate chunk in all cases, but moves it up the tree
+// instead, so that neither the level of the last chunks nor the number of
+// data chunks they represent can be told from their spans. With encryption,
+// that is with references longer than swarm.HashSize, a single dangling
+// reference is always moved up. This function replays the same decisions
+// from the length alone.
+func LastChunkLevels(length int64, refSize int) []int {
+	if length <= swarm.ChunkSize {
+		return []int{0}
+	}
+	branches := swarm.ChunkSize / refSize
+	spans := GenerateSpanSizes(9, branches)
+	targetLevel := Levels(length, refSize, branches)
+
+	type node struct {
+		level int
+		span  int64
+		refs  []*node
+		full  bool // the chunk of a full subtree
+	}
+	span := func(level int) int64 {
+		s := spans[level] * swarm.ChunkSize
+		return (length-1)%s + 1
+	}
+
+	// the references pending on the levels at the end of the data, as only
+	// the full chunks are summed before
+	pending := make([][]*node, targetLevel+1)
+	sumCounts := make([]int64, targetLevel+1)
+	sumCounts[0] = (length + swarm.ChunkSize - 1) / swarm.ChunkSize
+	full := length / swarm.ChunkSize
+	for i := 1; i <= targetLevel; i++ {
+		sumCounts[i] = full / int64(branches)
+		for j := sumCounts[i] * int64(branches); j < full; j++ {
+			pending[i] = append(pending[i], &node{level: i - 1, span: spans[i-1] * swarm.ChunkSize, full: true})
+		}
+		full = sumCounts[i]
+	}
+	if sumCounts[0] > length/swarm.ChunkSize {
+		pending[1] = append(pending[1], &node{span: span(0)})
+	}
+
+	// the same decisions as the moveDanglingChunk of the splitter job
+	encrypted := refSize != swarm.HashSize
+	for i := 1; i < targetLevel; i++ {
+		if sumCounts[i] > 0 && (sumCounts[i-1]-spans[targetLevel-1-i] <= 1 || (encrypted && len(pending[i]) == 1)) {
+			pending[i+1] = append(pending[i+1], pending[i]...)
+			pending[i] = nil
+			continue
+		}
+		sumCounts[i]++
+		pending[i+1] = append(pending[i+1], &node{level: i, span: span(i), refs: pending[i]})
+		pending[i] = nil
+	}
+
+	var levels []int
+	for n := pending[targetLevel][0]; ; {
+		levels = append(levels, n.level)
+		if n.level == 0 {
+			return levels
+		}
+		if n.full {
+			// the last chunks of a full subtree are at all the levels below
+			for l := n.level - 1; l >= 0; l-- {
+				levels = append(levels, l)
+			}
+			return levels
+		}
+		childSpan := spans[n.level-1] * swarm.ChunkSize
+		n = n.refs[(n.span+childSpan-1)/childSpan-1]
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package file_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestLastChunkLevels verifies the levels of the chunks on the path to the
+// last data chunk, including the data chunks moved up the tree.
+func TestLastChunkLevels(t *testing.T) {
+	for _, tc := range []struct {
+		chunks  int64
+		refSize int
+		want    []int
+	}{
+		{chunks: 1, refSize: swarm.HashSize, want: []int{0}},
+		{chunks: 2, refSize: swarm.HashSize, want: []int{1, 0}},
+		{chunks: 128, refSize: swarm.HashSize, want: []int{1, 0}},
+		{chunks: 129, refSize: swarm.HashSize, want: []int{2, 0}},
+		{chunks: 130, refSize: swarm.HashSize, want: []int{2, 1, 0}},
+		{chunks: 257, refSize: swarm.HashSize, want: []int{2, 1, 0}},
+		{chunks: 128 * 128, refSize: swarm.HashSize, want: []int{2, 1, 0}},
+		{chunks: 128*128 + 1, refSize: swarm.HashSize, want: []int{3, 0}},
+		{chunks: 128*128 + 129, refSize: swarm.HashSize, want: []int{3, 2, 1, 0}},
+		{chunks: 64, refSize: encryption.ReferenceSize, want: []int{1, 0}},
+		{chunks: 65, refSize: encryption.ReferenceSize, want: []int{2, 0}},
+		{chunks: 128, refSize: encryption.ReferenceSize, want: []int{2, 1, 0}},
+		{chunks: 129, refSize: encryption.ReferenceSize, want: []int{2, 0}},
+		{chunks: 193, refSize: encryption.ReferenceSize, want: []int{2, 0}},
+		{chunks: 257, refSize: encryption.ReferenceSize, want: []int{2, 0}},
+	} {
+		t.Run(fmt.Sprintf("%d/%d", tc.refSize, tc.chunks), func(t *testing.T) {
+			got := file.LastChunkLevels(tc.chunks*swarm.ChunkSize-1, tc.refSize)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got levels %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
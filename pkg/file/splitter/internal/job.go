@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"hash"
 
+	"github.com/ethersphere/bee/pkg/encryption"
 	"github.com/ethersphere/bee/pkg/file"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -19,6 +20,10 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
+// UnknownLength is the span length of a job for the data whose length is not
+// known upfront.
+const UnknownLength = -1
+
 // maximum amount of file tree levels this file hasher component can handle
 // (128 ^ (9 - 1)) * 4096 = 295147905179352825856 bytes
 const levelBufferLimit = 9
@@ -46,12 +51,23 @@ type SimpleSplitterJob struct {
 	cursors    []int    // section write position, indexed per level
 	hasher     bmt.Hash // underlying hasher used for hashing the tree
 	buffer     []byte   // keeps data and hashes, indexed by cursors
+	toEncrypt  bool     // whether the chunks are encrypted
+	refSize    int64    // length of the references, doubled with encryption
+	spans      []int64  // maximum span lengths per level represented by one reference
 }
 
 // NewSimpleSplitterJob creates a new SimpleSplitterJob.
 //
-// The spanLength is the length of the data that will be written.
-func NewSimpleSplitterJob(ctx context.Context, putter storage.Putter, spanLength int64) *SimpleSplitterJob {
+// The spanLength is the length of the data that will be written, or
+// UnknownLength if it is not known before the end of the data, in which case
+// Finish must be called after the last Write. If toEncrypt
+// is true, every chunk is encrypted with a random key and the references in
+// the intermediate chunks are the chunk addresses followed by the keys.
+func NewSimpleSplitterJob(ctx context.Context, putter storage.Putter, spanLength int64, toEncrypt bool) *SimpleSplitterJob {
+	refSize := int64(swarm.HashSize)
+	if toEncrypt {
+		refSize += encryption.KeyLength
+	}
 	p := bmtlegacy.NewTreePool(hashFunc, swarm.Branches, bmtlegacy.PoolSize)
 	return &SimpleSplitterJob{
 		ctx:        ctx,
@@ -61,6 +77,9 @@ func NewSimpleSplitterJob(ctx context.Context, putter storage.Putter, spanLength
 		cursors:    make([]int, levelBufferLimit),
 		hasher:     bmtlegacy.New(p),
 		buffer:     make([]byte, file.ChunkWithLengthSize*levelBufferLimit*2), // double size as temp workaround for weak calculation of needed buffer space
+		toEncrypt:  toEncrypt,
+		refSize:    refSize,
+		spans:      file.GenerateSpanSizes(levelBufferLimit, swarm.ChunkSize/int(refSize)),
 	}
 }
 
@@ -70,7 +89,7 @@ func (j *SimpleSplitterJob) Write(b []byte) (int, error) {
 		return 0, fmt.Errorf("Write must be called with a maximum of %d bytes", swarm.ChunkSize)
 	}
 	j.length += int64(len(b))
-	if j.length > j.spanLength {
+	if j.spanLength != UnknownLength && j.length > j.spanLength {
 		return 0, errors.New("write past span length")
 	}
 
@@ -92,6 +111,59 @@ func (j *SimpleSplitterJob) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
+// WriteReference adds the reference of a data chunk, which was already hashed
+// and stored, to the file splitter. The length is the length of the data of the
+// chunk, which must be the chunk size for all but the last data chunk.
+//
+// The data chunks must be written in order and the references must not be
+// mixed with the data written by Write.
+func (j *SimpleSplitterJob) WriteReference(ref []byte, length int) error {
+	if length > swarm.ChunkSize {
+		return fmt.Errorf("data chunk length %d larger than %d bytes", length, swarm.ChunkSize)
+	}
+	j.length += int64(length)
+	if j.length > j.spanLength {
+		return errors.New("write past span length")
+	}
+	j.sumCounts[0]++
+
+	if length == swarm.ChunkSize {
+		// as sumLevel on a full data level
+		err := j.writeToLevel(1, ref)
+		if err != nil {
+			return err
+		}
+		j.cursors[0] = j.cursors[1]
+	} else {
+		if j.length != j.spanLength {
+			return fmt.Errorf("data chunk length %d smaller than %d bytes before the end of data", length, swarm.ChunkSize)
+		}
+		// as hashUnfinished
+		copy(j.buffer[j.cursors[1]:], ref)
+		j.cursors[1] += len(ref)
+		j.cursors[0] = j.cursors[1]
+	}
+
+	if j.length == j.spanLength {
+		err := j.moveDanglingChunk()
+		if err != nil {
+			return file.NewHashError(err)
+		}
+	}
+	return nil
+}
+
+// Finish hashes the remaining chunks of the data of unknown length, after the
+// last Write. The length of the written data becomes the span length.
+func (j *SimpleSplitterJob) Finish() error {
+	if j.spanLength != UnknownLength {
+		return errors.New("finish with known span length")
+	}
+	j.spanLength = j.length
+	_, err := j.Write(nil)
+	return err
+}
+
 // Sum returns the Swarm hash of the data.
 func (j *SimpleSplitterJob) Sum(b []byte) []byte {
 	return j.digest()
@@ -124,36 +196,44 @@ func (s *SimpleSplitterJob) writeToLevel(lvl int, data []byte) error {
 // TODO: error handling on store write fail
 func (s *SimpleSplitterJob) sumLevel(lvl int) ([]byte, error) {
 	s.sumCounts[lvl]++
-	spanSize := file.Spans[lvl] * swarm.ChunkSize
+	spanSize := s.spans[lvl] * swarm.ChunkSize
 	span := (s.length-1)%spanSize + 1
 
-	sizeToSum := s.cursors[lvl] - s.cursors[lvl+1]
+	head := make([]byte, 8)
+	binary.LittleEndian.PutUint64(head, uint64(span))
+	tail := s.buffer[s.cursors[lvl+1]:s.cursors[lvl]]
+	chunkData := append(head, tail...)
+
+	var key encryption.Key
+	if s.toEncrypt {
+		var err error
+		key, chunkData, err = encryption.EncryptChunk(chunkData)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// perform hashing
 	s.hasher.Reset()
-	err := s.hasher.SetSpan(span)
+	err := s.hasher.SetSpan(int64(binary.LittleEndian.Uint64(chunkData[:8])))
 	if err != nil {
 		return nil, err
 	}
-	_, err = s.hasher.Write(s.buffer[s.cursors[lvl+1] : s.cursors[lvl+1]+sizeToSum])
+	_, err = s.hasher.Write(chunkData[8:])
 	if err != nil {
 		return nil, err
 	}
 	ref := s.hasher.Sum(nil)
 
-	// assemble chunk and put in store
+	// put the chunk in store
 	addr := swarm.NewAddress(ref)
-	head := make([]byte, 8)
-	binary.LittleEndian.PutUint64(head, uint64(span))
-	tail := s.buffer[s.cursors[lvl+1]:s.cursors[lvl]]
-	chunkData := append(head, tail...)
 	ch := swarm.NewChunk(addr, chunkData)
 	_, err = s.putter.Put(s.ctx, storage.ModePutUpload, ch)
 	if err != nil {
 		return nil, err
 	}
 
-	return ref, nil
+	return append(ref, key...), nil
 }
 
 // digest returns the calculated digest after a Sum call.
@@ -164,7 +244,7 @@ func (s *SimpleSplitterJob) sumLevel(lvl int) ([]byte, error) {
 // The method does not check that the final hash actually has been written, so
 // timing is the responsibility of the caller.
 func (s *SimpleSplitterJob) digest() []byte {
-	return s.buffer[:swarm.SectionSize]
+	return s.buffer[:s.refSize]
 }
 
 // hashUnfinished hasher the remaining unhashed chunks at the end of each level if
@@ -203,16 +283,19 @@ func (s *SimpleSplitterJob) hashUnfinished() error {
 // After which the SS will be hashed to obtain the final root hash
 func (s *SimpleSplitterJob) moveDanglingChunk() error {
 	// calculate the total number of levels needed to represent the data (including the data level)
-	targetLevel := file.Levels(s.length, swarm.SectionSize, swarm.Branches)
+	targetLevel := file.Levels(s.length, int(s.refSize), swarm.ChunkSize/int(s.refSize))
 
 	// sum every intermediate level and write to the level above it
 	for i := 1; i < targetLevel; i++ {
 
 		// and if there is a single reference outside a balanced tree on this level
 		// don't hash it again but pass it on to the next level
+		//
+		// with encryption, a single reference is always passed on, so that
+		// intermediate chunks never have a span of a single data chunk
 		if s.sumCounts[i] > 0 {
 			// TODO: simplify if possible
-			if int64(s.sumCounts[i-1])-file.Spans[targetLevel-1-i] <= 1 {
+			if int64(s.sumCounts[i-1])-s.spans[targetLevel-1-i] <= 1 || (s.toEncrypt && int64(s.cursors[i]-s.cursors[i+1]) == s.refSize) {
 				s.cursors[i+1] = s.cursors[i]
 				s.cursors[i] = s.cursors[i-1]
 				continue
@@ -0,0 +1,199 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package splitter
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"runtime"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/splitter/internal"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	bmtlegacy "github.com/ethersphere/bmt/legacy"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// putBatchSize is the maximal number of data chunks stored with a single
+	// Put call.
+	putBatchSize = 64
+	// pipelineDepth is the number of data chunks per worker which may be
+	// read, hashed or waiting for the previous chunks at the same time.
+	pipelineDepth = 4
+)
+
+// pipelinedSplitter hashes the data chunks concurrently.
+type pipelinedSplitter struct {
+	putter  storage.Putter
+	workers int
+	pool    *bmtlegacy.TreePool // shared by the hashers of all the workers
+}
+
+// NewPipelinedSplitter creates a new file.Splitter which hashes the data
+// chunks with a pool of workers, one per CPU, and stores them in batches,
+// while the intermediate chunks are built in order as the data chunks are
+// hashed. It returns the same references as the splitter created by
+// NewSimpleSplitter.
+func NewPipelinedSplitter(putter storage.Putter) file.Splitter {
+	workers := runtime.NumCPU()
+	return &pipelinedSplitter{
+		putter:  putter,
+		workers: workers,
+		pool:    bmtlegacy.NewTreePool(hashFunc, swarm.Branches, workers),
+	}
+}
+
+// leaf is a data chunk in the pipeline.
+type leaf struct {
+	index   int64
+	data    []byte // chunk data with the span
+	address swarm.Address
+}
+
+// Split implements the file.Splitter interface.
+//
+// The data is read into the data chunks, which are hashed by the workers and
+// passed back in order to the intermediate chunks of the job of the simple
+// splitter, so that the chunk tree is exactly the same.
+func (s *pipelinedSplitter) Split(ctx context.Context, r io.ReadCloser, dataLength int64) (addr swarm.Address, err error) {
+	j := internal.NewSimpleSplitterJob(ctx, s.putter, dataLength, false)
+	if dataLength == 0 {
+		if _, err := j.Write(nil); err != nil {
+			return swarm.ZeroAddress, err
+		}
+		return swarm.NewAddress(j.Sum(nil)), nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	depth := s.workers * pipelineDepth
+	tokens := make(chan struct{}, depth) // limits the chunks in the pipeline
+	leaves := make(chan *leaf, depth)
+	hashed := make(chan *leaf, depth)
+	readErrC := make(chan error, 1)
+
+	go func() {
+		defer close(leaves)
+		readErrC <- s.read(ctx, r, dataLength, tokens, leaves)
+	}()
+	for i := 0; i < s.workers; i++ {
+		go s.hash(ctx, leaves, hashed)
+	}
+
+	blocks := (dataLength + swarm.ChunkSize - 1) / swarm.ChunkSize
+	pending := make(map[int64]*leaf)
+	batch := make([]swarm.Chunk, 0, putBatchSize)
+	for next := int64(0); next < blocks; {
+		select {
+		case l := <-hashed:
+			pending[l.index] = l
+		case err := <-readErrC:
+			if err != nil {
+				return swarm.ZeroAddress, err
+			}
+			readErrC = nil
+		case <-ctx.Done():
+			return swarm.ZeroAddress, ctx.Err()
+		}
+
+		for l, ok := pending[next]; ok; l, ok = pending[next] {
+			delete(pending, next)
+			next++
+			<-tokens
+
+			batch = append(batch, swarm.NewChunk(l.address, l.data))
+			if len(batch) == putBatchSize || next == blocks {
+				if _, err := s.putter.Put(ctx, storage.ModePutUpload, batch...); err != nil {
+					return swarm.ZeroAddress, fmt.Errorf("store data chunks: %w", err)
+				}
+				batch = make([]swarm.Chunk, 0, putBatchSize)
+			}
+			if err := j.WriteReference(l.address.Bytes(), len(l.data)-8); err != nil {
+				return swarm.ZeroAddress, err
+			}
+		}
+	}
+
+	// the reader validates that there is no data past the data length
+	if readErrC != nil {
+		select {
+		case err := <-readErrC:
+			if err != nil {
+				return swarm.ZeroAddress, err
+			}
+		case <-ctx.Done():
+			return swarm.ZeroAddress, ctx.Err()
+		}
+	}
+
+	return swarm.NewAddress(j.Sum(nil)), nil
+}
+
+// read reads the data into the data chunks and sends them to the workers.
+func (s *pipelinedSplitter) read(ctx context.Context, r io.Reader, dataLength int64, tokens chan<- struct{}, leaves chan<- *leaf) error {
+	var total int64
+	for index := int64(0); ; index++ {
+		select {
+		case tokens <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		data := make([]byte, 8+swarm.ChunkSize)
+		n, err := io.ReadFull(r, data[8:])
+		if n > 0 {
+			total += int64(n)
+			if total > dataLength {
+				return fmt.Errorf("splitter received more than %d bytes of data", dataLength)
+			}
+			binary.LittleEndian.PutUint64(data, uint64(n))
+			select {
+			case leaves <- &leaf{index: index, data: data[:8+n]}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return err
+		}
+	}
+	if total < dataLength {
+		return fmt.Errorf("splitter only received %d bytes of data, expected %d bytes", total, dataLength)
+	}
+	return nil
+}
+
+// hash hashes the data chunks until there are no more of them.
+func (s *pipelinedSplitter) hash(ctx context.Context, leaves <-chan *leaf, hashed chan<- *leaf) {
+	hasher := bmtlegacy.New(s.pool)
+	for l := range leaves {
+		hasher.Reset()
+		// the errors are never returned by the hasher
+		_ = hasher.SetSpan(int64(len(l.data) - 8))
+		_, _ = hasher.Write(l.data[8:])
+		l.address = swarm.NewAddress(hasher.Sum(nil))
+
+		select {
+		case hashed <- l:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// hashFunc is a hasher factory used by the bmt hasher
+func hashFunc() hash.Hash {
+	return sha3.NewLegacyKeccak256()
+}
@@ -18,7 +18,8 @@ import (
 
 // simpleSplitter wraps a non-optimized implementation of file.Splitter
 type simpleSplitter struct {
-	putter storage.Putter
+	putter    storage.Putter
+	toEncrypt bool
 }
 
 // NewSimpleSplitter creates a new SimpleSplitter
@@ -28,6 +29,16 @@ func NewSimpleSplitter(putter storage.Putter) file.Splitter {
 	}
 }
 
+// NewEncryptingSplitter creates a new SimpleSplitter that encrypts every
+// chunk with a random key. The returned reference is the address of the root
+// chunk followed by its decryption key.
+func NewEncryptingSplitter(putter storage.Putter) file.Splitter {
+	return &simpleSplitter{
+		putter:    putter,
+		toEncrypt: true,
+	}
+}
+
 // Split implements the file.Splitter interface
 //
 // It uses a non-optimized internal component that blocks when performing
@@ -35,7 +46,7 @@ func NewSimpleSplitter(putter storage.Putter) file.Splitter {
 //
 // It returns the Swarmhash of the data.
 func (s *simpleSplitter) Split(ctx context.Context, r io.ReadCloser, dataLength int64) (addr swarm.Address, err error) {
-	j := internal.NewSimpleSplitterJob(ctx, s.putter, dataLength)
+	j := internal.NewSimpleSplitterJob(ctx, s.putter, dataLength, s.toEncrypt)
 
 	var total int64
 	data := make([]byte, swarm.ChunkSize)
@@ -7,10 +7,13 @@ package splitter_test
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io/ioutil"
 	"testing"
 	"time"
 
 	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
 	"github.com/ethersphere/bee/pkg/file/splitter"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
@@ -182,3 +185,189 @@ func TestUnalignedSplit(t *testing.T) {
 	}
 
 }
+
+// TestPipelinedSplitter verifies that the pipelined splitter returns the same
+// references as the simple splitter and stores all the chunks of the tree.
+func TestPipelinedSplitter(t *testing.T) {
+	for _, dataLength := range []int{
+		0,
+		31,
+		swarm.ChunkSize,
+		swarm.ChunkSize + 31,
+		swarm.ChunkSize * swarm.Branches,
+		swarm.ChunkSize*swarm.Branches + 31,
+		swarm.ChunkSize * (swarm.Branches + 1),
+		swarm.ChunkSize*swarm.Branches*2 + 32,
+		swarm.ChunkSize*swarm.Branches*3 + swarm.ChunkSize*5,
+	} {
+		t.Run(fmt.Sprintf("%d", dataLength), func(t *testing.T) {
+			ctx := context.Background()
+			g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
+			data, err := g.SequentialBytes(dataLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := file.SplitWriteAll(ctx, splitter.NewSimpleSplitter(mock.NewStorer()), bytes.NewReader(data), int64(dataLength))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			store := mock.NewStorer()
+			got, err := file.SplitWriteAll(ctx, splitter.NewPipelinedSplitter(store), bytes.NewReader(data), int64(dataLength))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) {
+				t.Fatalf("got address %s, want %s", got, want)
+			}
+			if dataLength == 0 {
+				return
+			}
+
+			buf := bytes.NewBuffer(nil)
+			if _, err := file.JoinReadAll(joiner.NewSimpleJoiner(store), got, buf); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), data) {
+				t.Fatal("data mismatch")
+			}
+		})
+	}
+
+	t.Run("incomplete", func(t *testing.T) {
+		s := splitter.NewPipelinedSplitter(mock.NewStorer())
+		_, err := s.Split(context.Background(), file.NewSimpleReadCloser(make([]byte, 5000)), 5001)
+		if err == nil {
+			t.Fatal("expected error on EOF before full length write")
+		}
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		s := splitter.NewPipelinedSplitter(mock.NewStorer())
+		_, err := s.Split(context.Background(), file.NewSimpleReadCloser(make([]byte, 5000)), 4999)
+		if err == nil {
+			t.Fatal("expected error on data past the full length")
+		}
+	})
+}
+
+func BenchmarkSplitter(b *testing.B) {
+	for _, bc := range []struct {
+		name        string
+		newSplitter func(storage.Putter) file.Splitter
+	}{
+		{name: "simple", newSplitter: splitter.NewSimpleSplitter},
+		{name: "pipelined", newSplitter: splitter.NewPipelinedSplitter},
+	} {
+		for _, dataLength := range []int{
+			swarm.ChunkSize * 10,
+			swarm.ChunkSize * swarm.Branches * 2,
+			swarm.ChunkSize * swarm.Branches * 16,
+		} {
+			b.Run(fmt.Sprintf("%s/%d", bc.name, dataLength), func(b *testing.B) {
+				g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
+				data, err := g.SequentialBytes(dataLength)
+				if err != nil {
+					b.Fatal(err)
+				}
+				ctx := context.Background()
+				b.SetBytes(int64(dataLength))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					s := bc.newSplitter(mock.NewStorer())
+					if _, err := s.Split(ctx, file.NewSimpleReadCloser(data), int64(dataLength)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestStreamingSplitter verifies that the streaming splitter returns the same
+// references as the simple splitter, without the data length.
+func TestStreamingSplitter(t *testing.T) {
+	for _, dataLength := range []int{
+		0,
+		1,
+		31,
+		swarm.ChunkSize,
+		swarm.ChunkSize + 31,
+		swarm.ChunkSize * swarm.Branches,
+		swarm.ChunkSize*swarm.Branches + 31,
+		swarm.ChunkSize * (swarm.Branches + 1),
+		swarm.ChunkSize*swarm.Branches*2 + 32,
+		swarm.ChunkSize * swarm.Branches * 3,
+		swarm.ChunkSize*swarm.Branches*3 + swarm.ChunkSize*5,
+		swarm.ChunkSize*swarm.Branches*swarm.Branches + swarm.ChunkSize,
+	} {
+		t.Run(fmt.Sprintf("%d", dataLength), func(t *testing.T) {
+			ctx := context.Background()
+			g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
+			data, err := g.SequentialBytes(dataLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want, err := file.SplitWriteAll(ctx, splitter.NewSimpleSplitter(mock.NewStorer()), bytes.NewReader(data), int64(dataLength))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := splitter.NewStreamingSplitter(mock.NewStorer()).Split(ctx, ioutil.NopCloser(bytes.NewReader(data)), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(want) {
+				t.Fatalf("got address %s, want %s", got, want)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name        string
+		newSplitter func(storage.Putter) file.Splitter
+		dataLength  int
+	}{
+		{name: "single chunk", newSplitter: splitter.NewStreamingSplitter, dataLength: 100},
+		{name: "encrypted", newSplitter: splitter.NewEncryptingStreamingSplitter, dataLength: swarm.ChunkSize*64*2 + 10},
+		{name: "encrypted dangling chunk", newSplitter: splitter.NewEncryptingStreamingSplitter, dataLength: swarm.ChunkSize*64 + 10},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			g := mockbytes.New(0, mockbytes.MockTypeStandard).WithModulus(255)
+			data, err := g.SequentialBytes(tc.dataLength)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			store := mock.NewStorer()
+			addr, err := tc.newSplitter(store).Split(ctx, ioutil.NopCloser(bytes.NewReader(data)), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := joiner.NewReader(ctx, store, addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatal("data mismatch")
+			}
+		})
+	}
+
+	for _, dataLength := range []int64{4999, 5001} {
+		t.Run(fmt.Sprintf("length mismatch %d", dataLength), func(t *testing.T) {
+			s := splitter.NewStreamingSplitter(mock.NewStorer())
+			_, err := s.Split(context.Background(), file.NewSimpleReadCloser(make([]byte, 5000)), dataLength)
+			if err == nil {
+				t.Fatal("expected error on data length mismatch")
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package splitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/splitter/internal"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// streamingSplitter splits the data of unknown length in a single pass.
+type streamingSplitter struct {
+	putter    storage.Putter
+	toEncrypt bool
+}
+
+// NewStreamingSplitter creates a new file.Splitter which does not need the
+// length of the data upfront. It returns the same references as the splitter
+// created by NewSimpleSplitter.
+func NewStreamingSplitter(putter storage.Putter) file.Splitter {
+	return &streamingSplitter{
+		putter: putter,
+	}
+}
+
+// NewEncryptingStreamingSplitter creates a new file.Splitter which does not
+// need the length of the data upfront and encrypts every chunk with a random
+// key, as the splitter created by NewEncryptingSplitter.
+func NewEncryptingStreamingSplitter(putter storage.Putter) file.Splitter {
+	return &streamingSplitter{
+		putter:    putter,
+		toEncrypt: true,
+	}
+}
+
+// Split implements the file.Splitter interface.
+//
+// The data is read until io.EOF. If the dataLength is positive, the length of
+// the read data must match it, otherwise the length is not known upfront.
+// The chunks of the tree are hashed as soon as they are full, while the spans
+// of the last chunks on every level are computed at the end of the data.
+func (s *streamingSplitter) Split(ctx context.Context, r io.ReadCloser, dataLength int64) (addr swarm.Address, err error) {
+	j := internal.NewSimpleSplitterJob(ctx, s.putter, internal.UnknownLength, s.toEncrypt)
+
+	var total int64
+	data := make([]byte, swarm.ChunkSize)
+	for {
+		n, err := io.ReadFull(r, data)
+		total += int64(n)
+		if dataLength > 0 && total > dataLength {
+			return swarm.ZeroAddress, fmt.Errorf("splitter received more than %d bytes of data", dataLength)
+		}
+		if n > 0 {
+			if _, err := j.Write(data[:n]); err != nil {
+				return swarm.ZeroAddress, err
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return swarm.ZeroAddress, err
+		}
+	}
+	if total < dataLength {
+		return swarm.ZeroAddress, fmt.Errorf("splitter only received %d bytes of data, expected %d bytes", total, dataLength)
+	}
+
+	if err := j.Finish(); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return swarm.NewAddress(j.Sum(nil)), nil
+}
@@ -140,7 +140,13 @@ func ResponseDirectSendHeadersAndReceiveHeaders(t *testing.T, client *http.Clien
 func ResponseUnmarshal(t *testing.T, client *http.Client, method, url string, body io.Reader, responseCode int, response interface{}) {
 	t.Helper()
 
-	resp := request(t, client, method, url, body, responseCode, nil)
+	ResponseUnmarshalSendHeaders(t, client, method, url, body, responseCode, response, nil)
+}
+
+func ResponseUnmarshalSendHeaders(t *testing.T, client *http.Client, method, url string, body io.Reader, responseCode int, response interface{}, headers http.Header) {
+	t.Helper()
+
+	resp := request(t, client, method, url, body, responseCode, headers)
 	defer resp.Body.Close()
 
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
@@ -93,6 +93,19 @@ func decryptKey(data []byte, password string) (*ecdsa.PrivateKey, error) {
 	return crypto.DecodeSecp256k1PrivateKey(d)
 }
 
+// keyAddress returns the ethereum address of the key, which is stored
+// unencrypted.
+func keyAddress(data []byte) ([]byte, error) {
+	var k encryptedKey
+	if err := json.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	if k.Version != keyVersion {
+		return nil, fmt.Errorf("unsupported key version: %v", k.Version)
+	}
+	return hex.DecodeString(k.Address)
+}
+
 func encryptData(data, password []byte) (*keyCripto, error) {
 	salt := make([]byte, 32)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
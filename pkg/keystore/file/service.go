@@ -57,6 +57,20 @@ func (s *Service) Key(name, password string) (pk *ecdsa.PrivateKey, created bool
 	return pk, false, nil
 }
 
+// EthereumAddress returns the ethereum address of the existing key without
+// the password, as it is not encrypted.
+func (s *Service) EthereumAddress(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(s.keyFilename(name))
+	if err != nil {
+		return nil, fmt.Errorf("read private key: %w", err)
+	}
+	addr, err := keyAddress(data)
+	if err != nil {
+		return nil, fmt.Errorf("key address: %w", err)
+	}
+	return addr, nil
+}
+
 func (s *Service) keyFilename(name string) string {
 	return filepath.Join(s.dir, fmt.Sprintf("%s.key", name))
 }
@@ -5,10 +5,13 @@
 package file_test
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/keystore/file"
 	"github.com/ethersphere/bee/pkg/keystore/test"
 )
@@ -22,3 +25,32 @@ func TestService(t *testing.T) {
 
 	test.Service(t, file.New(dir))
 }
+
+func TestServiceEthereumAddress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bzz-keystore-file-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := file.New(dir)
+	if _, err := s.EthereumAddress("swarm"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("got error %v, want %v", err, os.ErrNotExist)
+	}
+
+	k, _, err := s.Key("swarm", "pass123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := crypto.NewEthereumAddress(k.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.EthereumAddress("swarm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got address %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// diskUsageInterval is the time between the measurements
+// of the database size and the free disk space.
+var diskUsageInterval = 10 * time.Second
+
+// errDiskSpaceNotSupported is returned by freeDiskSpace on
+// the platforms where the free disk space can not be measured.
+var errDiskSpaceNotSupported = errors.New("free disk space not supported")
+
+// LowDiskSpace returns true if the free disk space is below the
+// MinFreeDiskSpace option, in which case the chunks put with
+// ModePutSync and ModePutRequest modes are rejected.
+func (db *DB) LowDiskSpace() bool {
+	return atomic.LoadInt32(&db.lowDiskSpace) == 1
+}
+
+// diskUsageWorker is a long running function that periodically
+// measures the size of the database and the free disk space on its
+// file system, if the database is not in memory and any of the
+// limits is set.
+func (db *DB) diskUsageWorker() {
+	defer close(db.diskUsageWorkerDone)
+
+	if db.path == "" || (db.maxSize == 0 && db.minFreeDiskSpace == 0) {
+		return
+	}
+
+	ticker := time.NewTicker(diskUsageInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := db.checkDiskUsage(); err != nil {
+			db.logger.Errorf("localstore: disk usage: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-db.close:
+			return
+		}
+	}
+}
+
+// checkDiskUsage sets the low disk space flag if the free disk space is
+// below the minimum and lowers the capacity to the number of chunks that
+// fit in the maximal database size, triggering garbage collection if the
+// database is larger than that.
+func (db *DB) checkDiskUsage() error {
+	if db.minFreeDiskSpace > 0 {
+		free, err := freeDiskSpace(db.path)
+		switch {
+		case errors.Is(err, errDiskSpaceNotSupported):
+		case err != nil:
+			return err
+		default:
+			db.metrics.DiskFree.Set(float64(free))
+			low := free < db.minFreeDiskSpace
+			if low && atomic.CompareAndSwapInt32(&db.lowDiskSpace, 0, 1) {
+				db.logger.Warningf("localstore: low disk space: %d bytes free, not storing synced and requested chunks below %d bytes", free, db.minFreeDiskSpace)
+			}
+			if !low && atomic.CompareAndSwapInt32(&db.lowDiskSpace, 1, 0) {
+				db.logger.Infof("localstore: disk space recovered: %d bytes free", free)
+			}
+		}
+	}
+
+	if db.maxSize > 0 {
+		size, err := dirSize(db.path)
+		if err != nil {
+			return err
+		}
+		db.metrics.DiskSize.Set(float64(size))
+		if err := db.setSizeCapacity(size); err != nil {
+			return err
+		}
+		if size > db.maxSize {
+			db.triggerGarbageCollection()
+		}
+	}
+	return nil
+}
+
+// setSizeCapacity estimates the number of chunks that fit in the maximal
+// database size from the measured size and the current number of chunks.
+// As the deleted chunks take the disk space until the database files are
+// compacted, the estimate is not updated if the number of chunks decreased
+// since the last measurement, so that the garbage collected chunks do not
+// lower it further.
+func (db *DB) setSizeCapacity(size uint64) error {
+	gcSize, err := db.gcSize.Get()
+	if err != nil {
+		return err
+	}
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil {
+		return err
+	}
+	count := gcSize + reserveSize
+
+	lastCount := atomic.SwapUint64(&db.lastCount, count)
+	if size == 0 || count == 0 {
+		return nil
+	}
+	if count < lastCount && atomic.LoadUint64(&db.sizeCapacity) > 0 {
+		return nil
+	}
+	capacity := uint64(float64(count) * float64(db.maxSize) / float64(size))
+	if capacity == 0 {
+		capacity = 1
+	}
+	atomic.StoreUint64(&db.sizeCapacity, capacity)
+	return nil
+}
+
+// dirSize returns the total size of the files in the directory.
+func dirSize(path string) (size uint64, err error) {
+	err = filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,14 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package localstore
+
+// freeDiskSpace is not supported on this platform, so
+// the chunks are stored regardless of the free disk space.
+var freeDiskSpace = func(path string) (uint64, error) {
+	return 0, errDiskSpaceNotSupported
+}
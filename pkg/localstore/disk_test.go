@@ -0,0 +1,162 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// TestDB_lowDiskSpace tests that the chunks from the network are rejected
+// while the free disk space is below the minimum.
+func TestDB_lowDiskSpace(t *testing.T) {
+	var free uint64 = 500
+	t.Cleanup(setFreeDiskSpace(func(string) (uint64, error) {
+		return atomic.LoadUint64(&free), nil
+	}))
+	db := newTestDiskDB(t, &Options{
+		MinFreeDiskSpace: 1000,
+	})
+
+	if err := db.checkDiskUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if !db.LowDiskSpace() {
+		t.Fatal("got enough disk space, want low")
+	}
+
+	for _, mode := range []storage.ModePut{storage.ModePutSync, storage.ModePutRequest} {
+		_, err := db.Put(context.Background(), mode, generateTestRandomChunk())
+		if !errors.Is(err, storage.ErrLowDiskSpace) {
+			t.Errorf("put %v: got error %v, want %v", mode, err, storage.ErrLowDiskSpace)
+		}
+	}
+	// the uploaded chunks are stored
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, generateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+
+	atomic.StoreUint64(&free, 2000)
+	if err := db.checkDiskUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if db.LowDiskSpace() {
+		t.Fatal("got low disk space, want enough")
+	}
+
+	for _, mode := range []storage.ModePut{storage.ModePutSync, storage.ModePutRequest} {
+		if _, err := db.Put(context.Background(), mode, generateTestRandomChunk()); err != nil {
+			t.Errorf("put %v: %v", mode, err)
+		}
+	}
+}
+
+// TestDB_maxSize tests that the chunks are garbage collected when the
+// database size exceeds the maximal size, even if the capacity in the
+// number of chunks is not reached.
+func TestDB_maxSize(t *testing.T) {
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	}))
+	db := newTestDiskDB(t, &Options{
+		Capacity: 1000,
+		MaxSize:  100 * 1024,
+	})
+	closed = db.close
+
+	chunkCount := 100
+	for _, ch := range generateTestRandomChunks(chunkCount) {
+		_, err := db.Put(context.Background(), storage.ModePutUpload, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := db.checkDiskUsage(); err != nil {
+		t.Fatal(err)
+	}
+	capacity := atomic.LoadUint64(&db.sizeCapacity)
+	if capacity == 0 || capacity >= uint64(chunkCount) {
+		t.Fatalf("got size capacity %v, want between 0 and %v", capacity, chunkCount)
+	}
+
+	gcTarget := db.gcTarget()
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize <= gcTarget {
+			break
+		}
+	}
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	// the size capacity is not lowered further as the
+	// collected chunks may still take the disk space
+	if err := db.checkDiskUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if got := atomic.LoadUint64(&db.sizeCapacity); got != capacity {
+		t.Errorf("got size capacity %v after garbage collection, want %v", got, capacity)
+	}
+}
+
+// newTestDiskDB is a helper function that constructs a temporary database
+// on disk, which is closed and removed when the test finishes.
+func newTestDiskDB(t *testing.T, o *Options) *DB {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "localstore-disk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	db, err := New(dir, make([]byte, 32), o, logging.New(ioutil.Discard, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return db
+}
+
+// setFreeDiskSpace replaces freeDiskSpace function with
+// a provided one and returns a function that will set
+// the current one back.
+func setFreeDiskSpace(f func(path string) (uint64, error)) (reset func()) {
+	current := freeDiskSpace
+	reset = func() { freeDiskSpace = current }
+	freeDiskSpace = f
+	return reset
+}
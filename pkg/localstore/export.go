@@ -20,11 +20,13 @@ import (
 	"archive/tar"
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"sync"
+	"strconv"
 
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -35,13 +37,46 @@ const (
 	// about exported data format version
 	exportVersionFilename = ".swarm-export-version"
 	// current export format version
-	currentExportVersion = "1"
+	currentExportVersion = "2"
+	// export format version without the postage stamps
+	// and the pin counters of the chunks
+	legacyExportVersion = "1"
+
+	// the PAX records of the chunk files in tar archive
+	// with the postage stamp and the pin counter
+	exportStampRecord = "SWARM.stamp"
+	exportPinRecord   = "SWARM.pin"
 )
 
+// ExportOptions filter the exported chunks.
+type ExportOptions struct {
+	// PinnedOnly limits the export to the pinned chunks.
+	PinnedOnly bool
+	// Bins limit the export to the chunks with these proximity orders to the
+	// base key. All the chunks are exported if it is empty.
+	Bins []uint8
+	// Progress is called with the number of the exported chunks after every
+	// exported chunk.
+	Progress func(count int64)
+}
+
 // Export writes a tar structured data to the writer of
-// all chunks in the retrieval data index. It returns the
-// number of chunks exported.
-func (db *DB) Export(w io.Writer) (count int64, err error) {
+// all chunks in the retrieval data index, or only of the
+// ones selected by the options, with their postage stamps
+// and pin counters. It returns the number of chunks
+// exported.
+func (db *DB) Export(w io.Writer, o *ExportOptions) (count int64, err error) {
+	if o == nil {
+		o = new(ExportOptions)
+	}
+	var bins map[uint8]struct{}
+	if len(o.Bins) > 0 {
+		bins = make(map[uint8]struct{}, len(o.Bins))
+		for _, bin := range o.Bins {
+			bins[bin] = struct{}{}
+		}
+	}
+
 	tw := tar.NewWriter(w)
 	defer tw.Close()
 
@@ -56,13 +91,42 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 		return 0, err
 	}
 
-	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+	export := func(item shed.Item) (stop bool, err error) {
+		if bins != nil {
+			if _, ok := bins[db.po(swarm.NewAddress(item.Address))]; !ok {
+				return false, nil
+			}
+		}
+		if o.PinnedOnly {
+			// the pin index holds only the addresses
+			i, err := db.retrievalDataIndex.Get(item)
+			if err != nil {
+				return true, fmt.Errorf("pinned chunk %x: %w", item.Address, err)
+			}
+			item = i
+		}
+		if err := db.fillStamp(&item); err != nil {
+			return true, fmt.Errorf("chunk %x stamp: %w", item.Address, err)
+		}
+		pinCounter, err := db.pinCounter(swarm.NewAddress(item.Address))
+		if err != nil {
+			return true, fmt.Errorf("chunk %x pin counter: %w", item.Address, err)
+		}
 
 		hdr := &tar.Header{
 			Name: hex.EncodeToString(item.Address),
 			Mode: 0644,
 			Size: int64(len(item.Data)),
 		}
+		if item.Stamp != nil || pinCounter > 0 {
+			hdr.PAXRecords = make(map[string]string)
+			if item.Stamp != nil {
+				hdr.PAXRecords[exportStampRecord] = hex.EncodeToString(item.Stamp)
+			}
+			if pinCounter > 0 {
+				hdr.PAXRecords[exportPinRecord] = strconv.FormatUint(pinCounter, 10)
+			}
+		}
 
 		if err := tw.WriteHeader(hdr); err != nil {
 			return false, err
@@ -71,130 +135,193 @@ func (db *DB) Export(w io.Writer) (count int64, err error) {
 			return false, err
 		}
 		count++
+		if o.Progress != nil {
+			o.Progress(count)
+		}
 		return false, nil
-	}, nil)
+	}
 
+	if o.PinnedOnly {
+		err = db.pinIndex.Iterate(export, nil)
+	} else {
+		err = db.retrievalDataIndex.Iterate(export, nil)
+	}
 	return count, err
 }
 
+// pinCounter returns the pin counter of the chunk, which is
+// zero if the chunk is not pinned.
+func (db *DB) pinCounter(addr swarm.Address) (uint64, error) {
+	c, err := db.PinInfo(addr)
+	if errors.Is(err, storage.ErrNotFound) {
+		return 0, nil
+	}
+	return c, err
+}
+
+// importBatchSize is the number of chunks stored together
+// by Import.
+const importBatchSize = 100
+
+// ErrInvalidChunk is returned by Import when an imported
+// chunk is not valid.
+var ErrInvalidChunk = errors.New("invalid chunk")
+
+// ImportOptions validate and report the imported chunks.
+type ImportOptions struct {
+	// Validators validate the imported chunks, which are valid if any of the
+	// validators accepts them. The chunks are not validated if it is empty.
+	Validators []swarm.ChunkValidator
+	// Progress is called with the number of the imported chunks after every
+	// stored batch of chunks.
+	Progress func(count int64)
+}
+
 // Import reads a tar structured data from the reader and
-// stores chunks in the database. It returns the number of
-// chunks imported.
-func (db *DB) Import(r io.Reader, legacy bool) (count int64, err error) {
+// stores chunks in the database as synced chunks, so that
+// they are not pushed to the network again, with their
+// postage stamps and pin counters. It returns the number
+// of chunks imported. If any of the chunks is not valid,
+// the import stops with ErrInvalidChunk. As the synced
+// chunks, the chunks are not stored while the free disk
+// space is low.
+func (db *DB) Import(r io.Reader, o *ImportOptions) (count int64, err error) {
+	if o == nil {
+		o = new(ImportOptions)
+	}
 	tr := tar.NewReader(r)
+	ctx := context.Background()
+
+	var (
+		firstFile = true
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	errC := make(chan error)
-	doneC := make(chan struct{})
-	tokenPool := make(chan struct{}, 100)
-	var wg sync.WaitGroup
-	go func() {
-		var (
-			firstFile = true
-
-			// if exportVersionFilename file is not present
-			// assume current version
-			version = currentExportVersion
-		)
-		for {
-			hdr, err := tr.Next()
+		// if exportVersionFilename file is not present
+		// assume current version
+		version = currentExportVersion
+
+		batch = make([]swarm.Chunk, 0, importBatchSize)
+		// pin counters of the chunks in the batch
+		pins = make(map[string]uint64)
+	)
+	put := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := db.Put(ctx, storage.ModePutSync, batch...); err != nil {
+			return err
+		}
+		addrs := make([]swarm.Address, 0, len(batch))
+		for _, ch := range batch {
+			addrs = append(addrs, ch.Address())
+			want, ok := pins[ch.Address().ByteString()]
+			if !ok {
+				continue
+			}
+			// the chunk may already be pinned in the database
+			have, err := db.pinCounter(ch.Address())
 			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
+				return err
 			}
-			if firstFile {
-				firstFile = false
-				if hdr.Name == exportVersionFilename {
-					data, err := ioutil.ReadAll(tr)
-					if err != nil {
-						select {
-						case errC <- err:
-						case <-ctx.Done():
-						}
-					}
-					version = string(data)
-					continue
+			for ; have < want; have++ {
+				if err := db.Set(ctx, storage.ModeSetPin, ch.Address()); err != nil {
+					return err
 				}
 			}
+		}
+		// the pinned chunks are not added to the gc index
+		if err := db.Set(ctx, storage.ModeSetSyncPull, addrs...); err != nil {
+			return err
+		}
+		count += int64(len(batch))
+		batch = batch[:0]
+		pins = make(map[string]uint64)
+		if o.Progress != nil {
+			o.Progress(count)
+		}
+		return nil
+	}
 
-			if len(hdr.Name) != 64 {
-				db.logger.Warningf("localstore export: ignoring non-chunk file: %s", hdr.Name)
-				continue
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
 			}
-
-			keybytes, err := hex.DecodeString(hdr.Name)
-			if err != nil {
-				db.logger.Warningf("localstore export: ignoring invalid chunk file %s: %v", hdr.Name, err)
+			return count, err
+		}
+		if firstFile {
+			firstFile = false
+			if hdr.Name == exportVersionFilename {
+				data, err := ioutil.ReadAll(tr)
+				if err != nil {
+					return count, err
+				}
+				version = string(data)
 				continue
 			}
+		}
+		if version != currentExportVersion && version != legacyExportVersion {
+			return count, fmt.Errorf("unsupported export data version %q", version)
+		}
+
+		if len(hdr.Name) != 64 {
+			db.logger.Warningf("localstore export: ignoring non-chunk file: %s", hdr.Name)
+			continue
+		}
+
+		keybytes, err := hex.DecodeString(hdr.Name)
+		if err != nil {
+			db.logger.Warningf("localstore export: ignoring invalid chunk file %s: %v", hdr.Name, err)
+			continue
+		}
 
-			data, err := ioutil.ReadAll(tr)
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return count, err
+		}
+		ch := swarm.NewChunk(swarm.NewAddress(keybytes), data)
+		if v, ok := hdr.PAXRecords[exportStampRecord]; ok {
+			b, err := hex.DecodeString(v)
 			if err != nil {
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-				}
+				return count, fmt.Errorf("chunk %s stamp: %w", ch.Address(), err)
 			}
-			key := swarm.NewAddress(keybytes)
-
-			var ch swarm.Chunk
-			switch version {
-			case currentExportVersion:
-				ch = swarm.NewChunk(key, data)
-			default:
-				select {
-				case errC <- fmt.Errorf("unsupported export data version %q", version):
-				case <-ctx.Done():
-				}
+			stamp := new(postage.Stamp)
+			if err := stamp.UnmarshalBinary(b); err != nil {
+				return count, fmt.Errorf("chunk %s stamp: %w", ch.Address(), err)
 			}
-			tokenPool <- struct{}{}
-			wg.Add(1)
-
-			go func() {
-				_, err := db.Put(ctx, storage.ModePutUpload, ch)
-				select {
-				case errC <- err:
-				case <-ctx.Done():
-					wg.Done()
-					<-tokenPool
-				default:
-					_, err := db.Put(ctx, storage.ModePutUpload, ch)
-					if err != nil {
-						errC <- err
-					}
-					wg.Done()
-					<-tokenPool
-				}
-			}()
-
-			count++
+			ch = ch.WithStamp(stamp)
 		}
-		wg.Wait()
-		close(doneC)
-	}()
-
-	// wait for all chunks to be stored
-	for {
-		select {
-		case err := <-errC:
+		if !validChunk(ch, o.Validators) {
+			return count, fmt.Errorf("%w: %s", ErrInvalidChunk, ch.Address())
+		}
+		if v, ok := hdr.PAXRecords[exportPinRecord]; ok {
+			pinCounter, err := strconv.ParseUint(v, 10, 64)
 			if err != nil {
-				return count, err
+				return count, fmt.Errorf("chunk %s pin counter: %w", ch.Address(), err)
 			}
-		case <-ctx.Done():
-			return count, ctx.Err()
-		default:
-			select {
-			case <-doneC:
-				return count, nil
-			default:
+			pins[ch.Address().ByteString()] = pinCounter
+		}
+
+		batch = append(batch, ch)
+		if len(batch) == importBatchSize {
+			if err := put(); err != nil {
+				return count, err
 			}
 		}
 	}
+	return count, put()
+}
+
+// validChunk returns true if there are no validators or if
+// any of them accepts the chunk.
+func validChunk(ch swarm.Chunk, validators []swarm.ChunkValidator) bool {
+	if len(validators) == 0 {
+		return true
+	}
+	for _, v := range validators {
+		if v.Validate(ch) {
+			return true
+		}
+	}
+	return false
 }
@@ -19,10 +19,15 @@ package localstore
 import (
 	"bytes"
 	"context"
+	"errors"
+	"math/rand"
 	"testing"
 
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/validator"
 )
 
 // TestExportImport constructs two databases, one to put and export
@@ -46,7 +51,7 @@ func TestExportImport(t *testing.T) {
 
 	var buf bytes.Buffer
 
-	c, err := db1.Export(&buf)
+	c, err := db1.Export(&buf, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -57,7 +62,7 @@ func TestExportImport(t *testing.T) {
 
 	db2 := newTestDB(t, nil)
 
-	c, err = db2.Import(&buf, false)
+	c, err = db2.Import(&buf, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -77,3 +82,177 @@ func TestExportImport(t *testing.T) {
 		}
 	}
 }
+
+// TestExportImport_stampsAndPins validates that the postage stamps and the
+// pin counters of the chunks are imported, and that the imported chunks are
+// not pushed to the network again.
+func TestExportImport_stampsAndPins(t *testing.T) {
+	db1 := newTestDB(t, nil)
+
+	stamp := postage.NewStamp(bytes.Repeat([]byte{1}, postage.BatchIDSize), make([]byte, postage.IndexSize), make([]byte, postage.SignatureSize))
+	stamped := generateTestRandomChunk().WithStamp(stamp)
+	pinned := generateTestRandomChunk()
+	if _, err := db1.Put(context.Background(), storage.ModePutUpload, stamped, pinned); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := db1.Set(context.Background(), storage.ModeSetPin, pinned.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := db1.Export(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	db2 := newTestDB(t, nil)
+	if _, err := db2.Import(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("stamp", func(t *testing.T) {
+		got, err := db2.Get(context.Background(), storage.ModeGetRequest, stamped.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Stamp() == nil {
+			t.Fatal("missing stamp")
+		}
+		if !bytes.Equal(got.Stamp().BatchID(), stamp.BatchID()) {
+			t.Fatalf("got batch id %x, want %x", got.Stamp().BatchID(), stamp.BatchID())
+		}
+	})
+
+	t.Run("pin counter", func(t *testing.T) {
+		c, err := db2.PinInfo(pinned.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != 2 {
+			t.Fatalf("got pin counter %v, want 2", c)
+		}
+	})
+
+	t.Run("push index", newItemsCountTest(db2.pushIndex, 0))
+
+	t.Run("gc size", newIndexGCSizeTest(db2))
+}
+
+// TestExportFilters validates that only the pinned chunks or the
+// chunks in the selected bins are exported.
+func TestExportFilters(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	chunks := generateTestRandomChunks(100)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, chunks...); err != nil {
+		t.Fatal(err)
+	}
+	pinned := make(map[string]struct{})
+	for _, ch := range chunks[:10] {
+		if err := db.Set(context.Background(), storage.ModeSetPin, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		pinned[ch.Address().String()] = struct{}{}
+	}
+	inBins := make(map[string]struct{})
+	for _, ch := range chunks {
+		if po := db.po(ch.Address()); po == 0 || po == 2 {
+			inBins[ch.Address().String()] = struct{}{}
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		o    *ExportOptions
+		want map[string]struct{}
+	}{
+		{
+			name: "pinned",
+			o:    &ExportOptions{PinnedOnly: true},
+			want: pinned,
+		},
+		{
+			name: "bins",
+			o:    &ExportOptions{Bins: []uint8{0, 2}},
+			want: inBins,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				buf      bytes.Buffer
+				progress int64
+			)
+			tc.o.Progress = func(count int64) {
+				progress = count
+			}
+			c, err := db.Export(&buf, tc.o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c != int64(len(tc.want)) {
+				t.Fatalf("got export count %v, want %v", c, len(tc.want))
+			}
+			if progress != c {
+				t.Fatalf("got progress %v, want %v", progress, c)
+			}
+
+			db2 := newTestDB(t, nil)
+			if _, err := db2.Import(&buf, nil); err != nil {
+				t.Fatal(err)
+			}
+			for a := range tc.want {
+				if _, err := db2.Get(context.Background(), storage.ModeGetRequest, swarm.MustParseHexAddress(a)); err != nil {
+					t.Fatalf("chunk %s: %v", a, err)
+				}
+			}
+		})
+	}
+}
+
+// TestImportValidation validates that the import of an invalid
+// chunk fails when the chunks are validated.
+func TestImportValidation(t *testing.T) {
+	db1 := newTestDB(t, nil)
+
+	for i := 0; i < 10; i++ {
+		data := make([]byte, 100)
+		if _, err := rand.Read(data); err != nil {
+			t.Fatal(err)
+		}
+		ch, err := content.NewChunk(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db1.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := &ImportOptions{
+		Validators: []swarm.ChunkValidator{validator.NewContentAddressValidator()},
+	}
+
+	var buf bytes.Buffer
+	if _, err := db1.Export(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	c, err := newTestDB(t, nil).Import(&buf, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != 10 {
+		t.Fatalf("got import count %v, want 10", c)
+	}
+
+	if _, err := db1.Put(context.Background(), storage.ModePutUpload, generateTestRandomChunk()); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := db1.Export(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTestDB(t, nil).Import(&buf, o); !errors.Is(err, ErrInvalidChunk) {
+		t.Fatalf("got error %v, want %v", err, ErrInvalidChunk)
+	}
+}
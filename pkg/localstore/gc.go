@@ -38,6 +38,10 @@ var (
 	// gcBatchSize limits the number of chunks in a single
 	// badger transaction on garbage collection.
 	gcBatchSize uint64 = 200
+	// gcInterval is the time between the garbage collection
+	// runs which are not triggered by reaching the capacity,
+	// to collect the chunks expired by the gc policy.
+	gcInterval = time.Minute
 )
 
 // collectGarbageWorker is a long running function that waits for
@@ -47,9 +51,21 @@ var (
 func (db *DB) collectGarbageWorker() {
 	defer close(db.collectGarbageWorkerDone)
 
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
+		case <-ticker.C:
+			db.triggerGarbageCollection()
 		case <-db.collectGarbageTrigger:
+			// move the chunks out of the storage radius
+			// from the reserve to the gc index, so that
+			// they are collected if needed
+			_, reserveDone, err := db.evictReserve()
+			if err != nil {
+				db.logger.Errorf("localstore: evict reserve: %v", err)
+			}
 			// run a single collect garbage run and
 			// if done is false, gcBatchSize is reached and
 			// another collect garbage run is needed
@@ -58,7 +74,7 @@ func (db *DB) collectGarbageWorker() {
 				db.logger.Errorf("localstore: collect garbage: %v", err)
 			}
 			// check if another gc run is needed
-			if !done {
+			if !done || !reserveDone {
 				db.triggerGarbageCollection()
 			}
 
@@ -87,7 +103,6 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 	}()
 
 	batch := new(leveldb.Batch)
-	target := db.gcTarget()
 
 	// protect database from changing idexes and gcSize
 	db.batchMu.Lock()
@@ -105,17 +120,20 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		return 0, true, err
 	}
 	db.metrics.GCSize.Inc()
+	target := db.gcTarget()
 
 	done = true
 	err = db.gcIndex.Iterate(func(item shed.Item) (stop bool, err error) {
-		if gcSize-collectedCount <= target {
+		// the expired chunks are collected
+		// even if the target is reached
+		if gcSize-collectedCount <= target && !db.gcPolicy.Expired(item) {
 			return true, nil
 		}
 
 		db.metrics.GCStoreTimeStamps.Set(float64(item.StoreTimestamp))
 		db.metrics.GCStoreAccessTimeStamps.Set(float64(item.AccessTimestamp))
 
-		// delete from retrieve, pull, gc
+		// delete from retrieve, pull, gc, stamp
 		err = db.retrievalDataIndex.DeleteInBatch(batch, item)
 		if err != nil {
 			return true, nil
@@ -132,6 +150,10 @@ func (db *DB) collectGarbage() (collectedCount uint64, done bool, err error) {
 		if err != nil {
 			return true, nil
 		}
+		err = db.stampIndex.DeleteInBatch(batch, item)
+		if err != nil {
+			return true, nil
+		}
 		collectedCount++
 		if collectedCount >= gcBatchSize {
 			// bach size limit reached,
@@ -175,6 +197,7 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 			return false, err
 		}
 		item.AccessTimestamp = retrievalAccessIndexItem.AccessTimestamp
+		item.AccessCount = retrievalAccessIndexItem.AccessCount
 
 		// Get the binId
 		retrievalDataIndexItem, err := db.retrievalDataIndex.Get(item)
@@ -182,6 +205,7 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 			return false, err
 		}
 		item.BinID = retrievalDataIndexItem.BinID
+		item.StoreTimestamp = retrievalDataIndexItem.StoreTimestamp
 
 		// Check if this item is in gcIndex and remove it
 		ok, err := db.gcIndex.Has(item)
@@ -226,9 +250,15 @@ func (db *DB) removeChunksInExcludeIndexFromGC() (err error) {
 }
 
 // gcTrigger retruns the absolute value for garbage collection
-// target value, calculated from db.capacity and gcTargetRatio.
+// target value, calculated from the capacity not used by the
+// reserve and gcTargetRatio.
 func (db *DB) gcTarget() (target uint64) {
-	return uint64(float64(db.capacity) * gcTargetRatio)
+	capacity, err := db.cacheCapacity()
+	if err != nil {
+		db.logger.Errorf("localstore: cache capacity: %v", err)
+		capacity = db.capacity - db.reserveCapacity
+	}
+	return uint64(float64(capacity) * gcTargetRatio)
 }
 
 // triggerGarbageCollection signals collectGarbageWorker
@@ -269,7 +299,11 @@ func (db *DB) incGCSizeInBatch(batch *leveldb.Batch, change int64) (err error) {
 	db.gcSize.PutInBatch(batch, newSize)
 
 	// trigger garbage collection if we reached the capacity
-	if newSize >= db.capacity {
+	capacity, err := db.cacheCapacity()
+	if err != nil {
+		return err
+	}
+	if newSize >= capacity {
 		db.triggerGarbageCollection()
 	}
 	return nil
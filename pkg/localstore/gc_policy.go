@@ -0,0 +1,144 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+)
+
+// GCPolicy decides the order in which the chunks are garbage collected by
+// the composition of the keys of the gc index, which is iterated in the
+// ascending order of the keys.
+type GCPolicy interface {
+	// Name is the name of the gc index, which describes the composition of
+	// its keys. The gc index is rebuilt if the name changes between runs.
+	Name() string
+	// EncodeKey encodes the gc index key of the item, which has Address,
+	// BinID, StoreTimestamp, AccessTimestamp and AccessCount fields set.
+	EncodeKey(fields shed.Item) (key []byte, err error)
+	// DecodeKey decodes the gc index key into the item with Address and all
+	// other fields that are needed to encode the same key.
+	DecodeKey(key []byte) (e shed.Item, err error)
+	// Expired returns true if the chunk of the item decoded from the gc
+	// index key is collected even if the gc size is below the gc target.
+	Expired(item shed.Item) bool
+}
+
+var errInvalidGCKey = errors.New("invalid gc index key")
+
+// lruGCPolicy collects the least recently accessed chunks first.
+type lruGCPolicy struct{}
+
+// NewLRUGCPolicy returns the default GCPolicy, which collects the least
+// recently accessed chunks first.
+func NewLRUGCPolicy() GCPolicy {
+	return lruGCPolicy{}
+}
+
+func (lruGCPolicy) Name() string {
+	return "AccessTimestamp|BinID|Hash->nil"
+}
+
+func (lruGCPolicy) EncodeKey(fields shed.Item) (key []byte, err error) {
+	b := make([]byte, 16, 16+len(fields.Address))
+	binary.BigEndian.PutUint64(b[:8], uint64(fields.AccessTimestamp))
+	binary.BigEndian.PutUint64(b[8:16], fields.BinID)
+	key = append(b, fields.Address...)
+	return key, nil
+}
+
+func (lruGCPolicy) DecodeKey(key []byte) (e shed.Item, err error) {
+	if len(key) < 16 {
+		return e, errInvalidGCKey
+	}
+	e.AccessTimestamp = int64(binary.BigEndian.Uint64(key[:8]))
+	e.BinID = binary.BigEndian.Uint64(key[8:16])
+	e.Address = key[16:]
+	return e, nil
+}
+
+func (lruGCPolicy) Expired(shed.Item) bool {
+	return false
+}
+
+// lfuGCPolicy collects the least frequently accessed chunks first, and the
+// least recently accessed ones of those accessed the same number of times.
+type lfuGCPolicy struct{}
+
+// NewLFUGCPolicy returns the GCPolicy which collects the least frequently
+// accessed chunks first.
+func NewLFUGCPolicy() GCPolicy {
+	return lfuGCPolicy{}
+}
+
+func (lfuGCPolicy) Name() string {
+	return "AccessCount|AccessTimestamp|BinID|Hash->nil"
+}
+
+func (lfuGCPolicy) EncodeKey(fields shed.Item) (key []byte, err error) {
+	b := make([]byte, 24, 24+len(fields.Address))
+	binary.BigEndian.PutUint64(b[:8], fields.AccessCount)
+	binary.BigEndian.PutUint64(b[8:16], uint64(fields.AccessTimestamp))
+	binary.BigEndian.PutUint64(b[16:24], fields.BinID)
+	key = append(b, fields.Address...)
+	return key, nil
+}
+
+func (lfuGCPolicy) DecodeKey(key []byte) (e shed.Item, err error) {
+	if len(key) < 24 {
+		return e, errInvalidGCKey
+	}
+	e.AccessCount = binary.BigEndian.Uint64(key[:8])
+	e.AccessTimestamp = int64(binary.BigEndian.Uint64(key[8:16]))
+	e.BinID = binary.BigEndian.Uint64(key[16:24])
+	e.Address = key[24:]
+	return e, nil
+}
+
+func (lfuGCPolicy) Expired(shed.Item) bool {
+	return false
+}
+
+// ttlGCPolicy collects the chunks in the order they were stored, and the
+// chunks stored longer than the ttl even if the capacity is not reached.
+type ttlGCPolicy struct {
+	ttl time.Duration
+}
+
+// NewTTLGCPolicy returns the GCPolicy which collects the chunks stored first
+// and the chunks which are stored longer than the ttl.
+func NewTTLGCPolicy(ttl time.Duration) GCPolicy {
+	return ttlGCPolicy{ttl: ttl}
+}
+
+func (ttlGCPolicy) Name() string {
+	return "StoreTimestamp|BinID|Hash->nil"
+}
+
+func (ttlGCPolicy) EncodeKey(fields shed.Item) (key []byte, err error) {
+	b := make([]byte, 16, 16+len(fields.Address))
+	binary.BigEndian.PutUint64(b[:8], uint64(fields.StoreTimestamp))
+	binary.BigEndian.PutUint64(b[8:16], fields.BinID)
+	key = append(b, fields.Address...)
+	return key, nil
+}
+
+func (ttlGCPolicy) DecodeKey(key []byte) (e shed.Item, err error) {
+	if len(key) < 16 {
+		return e, errInvalidGCKey
+	}
+	e.StoreTimestamp = int64(binary.BigEndian.Uint64(key[:8]))
+	e.BinID = binary.BigEndian.Uint64(key[8:16])
+	e.Address = key[16:]
+	return e, nil
+}
+
+func (p ttlGCPolicy) Expired(item shed.Item) bool {
+	return now()-item.StoreTimestamp >= int64(p.ttl)
+}
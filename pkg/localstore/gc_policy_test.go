@@ -0,0 +1,217 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestGCPolicy_keys validates that the gc index keys of the policies are
+// decoded to the items which encode the same keys.
+func TestGCPolicy_keys(t *testing.T) {
+	item := shed.Item{
+		Address:         generateTestRandomChunk().Address().Bytes(),
+		BinID:           42,
+		StoreTimestamp:  time.Now().UnixNano(),
+		AccessTimestamp: time.Now().UnixNano() + 1,
+		AccessCount:     7,
+	}
+	for _, p := range []GCPolicy{
+		NewLRUGCPolicy(),
+		NewLFUGCPolicy(),
+		NewTTLGCPolicy(time.Hour),
+	} {
+		t.Run(p.Name(), func(t *testing.T) {
+			key, err := p.EncodeKey(item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := p.DecodeKey(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !swarm.NewAddress(decoded.Address).Equal(swarm.NewAddress(item.Address)) {
+				t.Errorf("got address %x, want %x", decoded.Address, item.Address)
+			}
+			if decoded.BinID != item.BinID {
+				t.Errorf("got bin id %v, want %v", decoded.BinID, item.BinID)
+			}
+			key2, err := p.EncodeKey(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(key) != string(key2) {
+				t.Errorf("got key %x, want %x", key2, key)
+			}
+
+			if _, err := p.DecodeKey(key[:8]); !errors.Is(err, errInvalidGCKey) {
+				t.Errorf("got error %v, want %v", err, errInvalidGCKey)
+			}
+		})
+	}
+}
+
+// TestGCPolicy_LFU tests that the chunks accessed more times are garbage
+// collected after those accessed less times, even if they were accessed
+// before.
+func TestGCPolicy_LFU(t *testing.T) {
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	}))
+	testHookUpdateGCChan := make(chan struct{})
+	t.Cleanup(setTestHookUpdateGC(func() {
+		testHookUpdateGCChan <- struct{}{}
+	}))
+	db := newTestDB(t, &Options{
+		Capacity: 100,
+		GCPolicy: NewLFUGCPolicy(),
+	})
+	closed = db.close
+
+	putSync := func(count int) (addrs []swarm.Address) {
+		for i := 0; i < count; i++ {
+			ch := generateTestRandomChunk()
+			_, err := db.Put(context.Background(), storage.ModePutUpload, ch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			addrs = append(addrs, ch.Address())
+		}
+		return addrs
+	}
+
+	accessed := putSync(10)
+	for _, addr := range accessed {
+		_, err := db.Get(context.Background(), storage.ModeGetRequest, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-testHookUpdateGCChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("update gc timeout")
+		}
+	}
+	addrs := putSync(100)
+
+	gcTarget := db.gcTarget()
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gcSize == gcTarget {
+			break
+		}
+	}
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("accessed chunks not collected", func(t *testing.T) {
+		for _, addr := range accessed {
+			if _, err := db.Get(context.Background(), storage.ModeGetLookup, addr); err != nil {
+				t.Errorf("chunk %s: %v", addr, err)
+			}
+		}
+	})
+
+	t.Run("first not accessed chunks collected", func(t *testing.T) {
+		for _, addr := range addrs[:110-int(gcTarget)] {
+			_, err := db.Get(context.Background(), storage.ModeGetLookup, addr)
+			if !errors.Is(err, storage.ErrNotFound) {
+				t.Errorf("chunk %s: got error %v, want %v", addr, err, storage.ErrNotFound)
+			}
+		}
+	})
+}
+
+// TestGCPolicy_TTL tests that the chunks stored longer than the ttl are
+// garbage collected even if the capacity is not reached.
+func TestGCPolicy_TTL(t *testing.T) {
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	}))
+	db := newTestDB(t, &Options{
+		Capacity: 100,
+		GCPolicy: NewTTLGCPolicy(time.Hour),
+	})
+	closed = db.close
+
+	start := time.Now().UnixNano()
+	var timestamp = start
+	t.Cleanup(setNow(func() int64 {
+		return timestamp
+	}))
+
+	putSync := func(count int) (addrs []swarm.Address) {
+		for i := 0; i < count; i++ {
+			ch := generateTestRandomChunk()
+			_, err := db.Put(context.Background(), storage.ModePutUpload, ch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			addrs = append(addrs, ch.Address())
+		}
+		return addrs
+	}
+
+	expired := putSync(10)
+	timestamp = start + int64(90*time.Minute)
+	fresh := putSync(10)
+
+	db.triggerGarbageCollection()
+	select {
+	case c := <-testHookCollectGarbageChan:
+		if c != uint64(len(expired)) {
+			t.Fatalf("got %v collected chunks, want %v", c, len(expired))
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("collect garbage timeout")
+	}
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	for _, addr := range expired {
+		_, err := db.Get(context.Background(), storage.ModeGetLookup, addr)
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("expired chunk %s: got error %v, want %v", addr, err, storage.ErrNotFound)
+		}
+	}
+	for _, addr := range fresh {
+		if _, err := db.Get(context.Background(), storage.ModeGetLookup, addr); err != nil {
+			t.Errorf("fresh chunk %s: %v", addr, err)
+		}
+	}
+}
@@ -25,10 +25,12 @@ import (
 	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/shed"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/syndtr/goleveldb/leveldb"
 )
@@ -39,6 +41,13 @@ var (
 	// ErrInvalidMode is retuned when an unknown Mode
 	// is provided to the function.
 	ErrInvalidMode = errors.New("invalid mode")
+	// ErrInvalidReserveCapacity is returned when the reserve
+	// capacity is not lower than the capacity.
+	ErrInvalidReserveCapacity = errors.New("reserve capacity must be lower than capacity")
+	// ErrGCPolicyChanged is returned when the database is opened with
+	// a different gc policy than the one it was stored with, while the
+	// gc index must not be rebuilt.
+	ErrGCPolicyChanged = errors.New("gc policy changed")
 )
 
 var (
@@ -52,6 +61,18 @@ var (
 // DB is the local store implementation and holds
 // database related objects.
 type DB struct {
+	// the fields accessed atomically are
+	// first for the 64-bit alignment
+
+	// number of chunks estimated to fit in maxSize,
+	// zero if the size is not measured
+	sizeCapacity uint64
+	// number of chunks at the last size measurement
+	lastCount uint64
+	// set to 1 if the free disk space
+	// is below minFreeDiskSpace
+	lowDiskSpace int32
+
 	shed *shed.DB
 	tags *tags.Tags
 
@@ -80,19 +101,61 @@ type DB struct {
 	// garbage collection index
 	gcIndex shed.Index
 
+	// decides the order of the chunks in gc index
+	gcPolicy GCPolicy
+
+	// field that stores the name of gc index
+	// to detect the changes of the gc policy
+	gcIndexName shed.StringField
+
 	// garbage collection exclude index for pinned contents
 	gcExcludeIndex shed.Index
 
 	// pin files Index
 	pinIndex shed.Index
 
+	// postage stamps of the chunks
+	stampIndex shed.Index
+
 	// field that stores number of intems in gc index
 	gcSize shed.Uint64Field
 
+	// reserve index of the chunks within the storage radius,
+	// which are not garbage collected
+	reserveIndex shed.Index
+
+	// field that stores number of items in reserve index
+	reserveSize shed.Uint64Field
+
+	// field that stores the radius to which the reserve
+	// has grown when it overflowed
+	reserveRadius shed.Uint64Field
+
 	// garbage collection is triggered when gcSize exceeds
-	// the capacity value
+	// the capacity value reduced by the reserve size
 	capacity uint64
 
+	// maximal number of items in reserve index,
+	// zero if the reserve is disabled
+	reserveCapacity uint64
+
+	// path of the database directory,
+	// empty if the database is in memory
+	path string
+
+	// maximal size of the database directory in bytes
+	// which lowers the capacity if exceeded,
+	// zero if the size is not limited
+	maxSize uint64
+
+	// minimal free disk space in bytes to store
+	// synced and requested chunks, zero if
+	// the free disk space is not checked
+	minFreeDiskSpace uint64
+
+	// provides the neighbourhood depth, the minimal storage radius
+	depther topology.NeighborhoodDepther
+
 	// triggers garbage collection event loop
 	collectGarbageTrigger chan struct{}
 
@@ -118,6 +181,10 @@ type DB struct {
 	// are done
 	collectGarbageWorkerDone chan struct{}
 
+	// protect Close method from exiting before
+	// the disk usage worker is done
+	diskUsageWorkerDone chan struct{}
+
 	// wait for all subscriptions to finish before closing
 	// underlaying BadgerDB to prevent possible panics from
 	// iterators
@@ -133,6 +200,30 @@ type Options struct {
 	// Capacity is a limit that triggers garbage collection when
 	// number of items in gcIndex equals or exceeds it.
 	Capacity uint64
+	// ReserveCapacity is the part of the Capacity for the chunks within
+	// the storage radius, which are not garbage collected. The reserve
+	// is disabled if it is zero.
+	ReserveCapacity uint64
+	// MaxSize is the maximal size of the database files in bytes. The
+	// capacity is lowered to the number of chunks that fit in it when it
+	// is exceeded. The size is not limited if it is zero.
+	MaxSize uint64
+	// MinFreeDiskSpace is the free disk space in bytes below which the
+	// chunks put with ModePutSync and ModePutRequest modes are rejected
+	// with storage.ErrLowDiskSpace. The free disk space is not checked
+	// if it is zero.
+	MinFreeDiskSpace uint64
+	// NeighborhoodDepther provides the neighbourhood depth, which is the
+	// minimal storage radius of the reserve.
+	NeighborhoodDepther topology.NeighborhoodDepther
+	// GCPolicy decides the order in which the chunks are garbage
+	// collected. The chunks are collected in the least recently
+	// accessed order if it is not set.
+	GCPolicy GCPolicy
+	// KeepGCPolicy makes New fail with ErrGCPolicyChanged instead of
+	// rebuilding the gc index if the existing database was stored with
+	// a different GCPolicy.
+	KeepGCPolicy bool
 	// MetricsPrefix defines a prefix for metrics names.
 	MetricsPrefix string
 	Tags          *tags.Tags
@@ -150,9 +241,15 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	}
 
 	db = &DB{
-		capacity: o.Capacity,
-		baseKey:  baseKey,
-		tags:     o.Tags,
+		capacity:         o.Capacity,
+		reserveCapacity:  o.ReserveCapacity,
+		path:             path,
+		maxSize:          o.MaxSize,
+		minFreeDiskSpace: o.MinFreeDiskSpace,
+		depther:          o.NeighborhoodDepther,
+		gcPolicy:         o.GCPolicy,
+		baseKey:          baseKey,
+		tags:             o.Tags,
 		// channel collectGarbageTrigger
 		// needs to be buffered with the size of 1
 		// to signal another event if it
@@ -160,12 +257,19 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		collectGarbageTrigger:    make(chan struct{}, 1),
 		close:                    make(chan struct{}),
 		collectGarbageWorkerDone: make(chan struct{}),
+		diskUsageWorkerDone:      make(chan struct{}),
 		metrics:                  newMetrics(),
 		logger:                   logger,
 	}
 	if db.capacity == 0 {
 		db.capacity = defaultCapacity
 	}
+	if db.gcPolicy == nil {
+		db.gcPolicy = NewLRUGCPolicy()
+	}
+	if db.reserveCapacity >= db.capacity {
+		return nil, ErrInvalidReserveCapacity
+	}
 
 	capacityMB := float64(db.capacity*swarm.ChunkSize) * 9.5367431640625e-7
 
@@ -193,6 +297,8 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
 		return nil, err
 	}
+	// the gc index of a new database is empty and never kept
+	keepGCPolicy := o.KeepGCPolicy && schemaName != ""
 	if schemaName == "" {
 		// initial new localstore run
 		err := db.schemaName.Put(DbSchemaCurrent)
@@ -213,6 +319,16 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		return nil, err
 	}
 
+	// Persist reserve size and radius.
+	db.reserveSize, err = db.shed.NewUint64Field("reserve-size")
+	if err != nil {
+		return nil, err
+	}
+	db.reserveRadius, err = db.shed.NewUint64Field("reserve-radius")
+	if err != nil {
+		return nil, err
+	}
+
 	// Index storing actual chunk address, data and bin id.
 	db.retrievalDataIndex, err = db.shed.NewIndex("Address->StoreTimestamp|BinID|Data", shed.IndexFuncs{
 		EncodeKey: func(fields shed.Item) (key []byte, err error) {
@@ -239,7 +355,7 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	if err != nil {
 		return nil, err
 	}
-	// Index storing access timestamp for a particular address.
+	// Index storing access timestamp and count for a particular address.
 	// It is needed in order to update gc index keys for iteration order.
 	db.retrievalAccessIndex, err = db.shed.NewIndex("Address->AccessTimestamp", shed.IndexFuncs{
 		EncodeKey: func(fields shed.Item) (key []byte, err error) {
@@ -250,12 +366,17 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 			return e, nil
 		},
 		EncodeValue: func(fields shed.Item) (value []byte, err error) {
-			b := make([]byte, 8)
-			binary.BigEndian.PutUint64(b, uint64(fields.AccessTimestamp))
+			b := make([]byte, 16)
+			binary.BigEndian.PutUint64(b[:8], uint64(fields.AccessTimestamp))
+			binary.BigEndian.PutUint64(b[8:16], fields.AccessCount)
 			return b, nil
 		},
 		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
-			e.AccessTimestamp = int64(binary.BigEndian.Uint64(value))
+			e.AccessTimestamp = int64(binary.BigEndian.Uint64(value[:8]))
+			// the access count is not stored by the older versions
+			if len(value) >= 16 {
+				e.AccessCount = binary.BigEndian.Uint64(value[8:16])
+			}
 			return e, nil
 		},
 	})
@@ -332,25 +453,41 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 	}
 	// create a push syncing triggers used by SubscribePush function
 	db.pushTriggers = make([]chan struct{}, 0)
-	// gc index for removable chunk ordered by ascending last access time
-	db.gcIndex, err = db.shed.NewIndex("AccessTimestamp|BinID|Hash->nil", shed.IndexFuncs{
+	// gc index for removable chunk ordered by the gc policy
+	db.gcIndex, err = db.shed.NewIndex(db.gcPolicy.Name(), shed.IndexFuncs{
+		EncodeKey: db.gcPolicy.EncodeKey,
+		DecodeKey: db.gcPolicy.DecodeKey,
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return nil, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// reserve index for the chunks within the storage radius
+	// ordered by proximity order bins for eviction when the radius grows
+	db.reserveIndex, err = db.shed.NewIndex("PO|BinID->Hash", shed.IndexFuncs{
 		EncodeKey: func(fields shed.Item) (key []byte, err error) {
-			b := make([]byte, 16, 16+len(fields.Address))
-			binary.BigEndian.PutUint64(b[:8], uint64(fields.AccessTimestamp))
-			binary.BigEndian.PutUint64(b[8:16], fields.BinID)
-			key = append(b, fields.Address...)
+			key = make([]byte, 9)
+			key[0] = db.po(swarm.NewAddress(fields.Address))
+			binary.BigEndian.PutUint64(key[1:9], fields.BinID)
 			return key, nil
 		},
 		DecodeKey: func(key []byte) (e shed.Item, err error) {
-			e.AccessTimestamp = int64(binary.BigEndian.Uint64(key[:8]))
-			e.BinID = binary.BigEndian.Uint64(key[8:16])
-			e.Address = key[16:]
+			e.BinID = binary.BigEndian.Uint64(key[1:9])
 			return e, nil
 		},
 		EncodeValue: func(fields shed.Item) (value []byte, err error) {
-			return nil, nil
+			value = make([]byte, 32)
+			copy(value, fields.Address)
+			return value, nil
 		},
 		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.Address = value[:32]
 			return e, nil
 		},
 	})
@@ -401,8 +538,46 @@ func New(path string, baseKey []byte, o *Options, logger logging.Logger) (db *DB
 		return nil, err
 	}
 
+	// Create a index structure for storing postage stamps of the chunks
+	db.stampIndex, err = db.shed.NewIndex("Address->Stamp", shed.IndexFuncs{
+		EncodeKey: func(fields shed.Item) (key []byte, err error) {
+			return fields.Address, nil
+		},
+		DecodeKey: func(key []byte) (e shed.Item, err error) {
+			e.Address = key
+			return e, nil
+		},
+		EncodeValue: func(fields shed.Item) (value []byte, err error) {
+			return fields.Stamp, nil
+		},
+		DecodeValue: func(keyItem shed.Item, value []byte) (e shed.Item, err error) {
+			e.Stamp = value
+			return e, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// rebuild gc index if gc policy has changed
+	db.gcIndexName, err = db.shed.NewStringField("gc-index-name")
+	if err != nil {
+		return nil, err
+	}
+	err = db.migrateGCPolicy(keepGCPolicy)
+	if err != nil {
+		// the database may be opened again with another gc policy
+		if cerr := db.shed.Close(); cerr != nil {
+			db.logger.Errorf("localstore: close: %v", cerr)
+		}
+		return nil, err
+	}
+
 	// start garbage collection worker
 	go db.collectGarbageWorker()
+
+	// start disk usage worker
+	go db.diskUsageWorker()
 	return db, nil
 }
 
@@ -418,6 +593,7 @@ func (db *DB) Close() (err error) {
 		// wait for gc worker to
 		// return before closing the shed
 		<-db.collectGarbageWorkerDone
+		<-db.diskUsageWorkerDone
 		close(done)
 	}()
 	select {
@@ -453,6 +629,8 @@ func (db *DB) DebugIndices() (indexInfo map[string]int, err error) {
 		"gcIndex":              db.gcIndex,
 		"gcExcludeIndex":       db.gcExcludeIndex,
 		"pinIndex":             db.pinIndex,
+		"stampIndex":           db.stampIndex,
+		"reserveIndex":         db.reserveIndex,
 	} {
 		indexSize, err := v.Count()
 		if err != nil {
@@ -465,17 +643,61 @@ func (db *DB) DebugIndices() (indexInfo map[string]int, err error) {
 		return indexInfo, err
 	}
 	indexInfo["gcSize"] = int(val)
+	val, err = db.reserveSize.Get()
+	if err != nil {
+		return indexInfo, err
+	}
+	indexInfo["reserveSize"] = int(val)
 
 	return indexInfo, err
 }
 
 // chunkToItem creates new Item with data provided by the Chunk.
-func chunkToItem(ch swarm.Chunk) shed.Item {
-	return shed.Item{
+// It returns an error if the postage stamp of the chunk cannot be
+// serialized.
+func chunkToItem(ch swarm.Chunk) (shed.Item, error) {
+	item := shed.Item{
 		Address: ch.Address().Bytes(),
 		Data:    ch.Data(),
 		Tag:     ch.TagID(),
 	}
+	if stamp := ch.Stamp(); stamp != nil {
+		b, err := stamp.MarshalBinary()
+		if err != nil {
+			return shed.Item{}, err
+		}
+		item.Stamp = b
+	}
+	return item, nil
+}
+
+// itemToChunk creates a new Chunk with the data and the postage stamp
+// provided by the Item.
+func itemToChunk(item shed.Item) (swarm.Chunk, error) {
+	ch := swarm.NewChunk(swarm.NewAddress(item.Address), item.Data)
+	if item.Stamp != nil {
+		stamp := new(postage.Stamp)
+		if err := stamp.UnmarshalBinary(item.Stamp); err != nil {
+			return nil, err
+		}
+		ch = ch.WithStamp(stamp)
+	}
+	return ch, nil
+}
+
+// fillStamp sets the Stamp field of the Item from the stamp index, if the
+// chunk has a postage stamp.
+func (db *DB) fillStamp(item *shed.Item) error {
+	i, err := db.stampIndex.Get(*item)
+	switch {
+	case err == nil:
+		item.Stamp = i.Stamp
+	case errors.Is(err, leveldb.ErrNotFound):
+		// the chunk is not stamped
+	default:
+		return err
+	}
+	return nil
 }
 
 // addressToItem creates new Item with a provided address.
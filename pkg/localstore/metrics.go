@@ -16,6 +16,7 @@ type metrics struct {
 
 	TotalTimeCollectGarbage         prometheus.Counter
 	TotalTimeGCExclude              prometheus.Counter
+	TotalTimeReserveEvict           prometheus.Counter
 	TotalTimeGet                    prometheus.Counter
 	TotalTimeUpdateGC               prometheus.Counter
 	TotalTimeGetMulti               prometheus.Counter
@@ -36,6 +37,7 @@ type metrics struct {
 	GCExcludeWriteBatchError prometheus.Counter
 	GCUpdate                 prometheus.Counter
 	GCUpdateError            prometheus.Counter
+	ReserveEvictCounter      prometheus.Counter
 
 	ModeGet                       prometheus.Counter
 	ModeGetFailure                prometheus.Counter
@@ -62,6 +64,8 @@ type metrics struct {
 	GCSize                  prometheus.Gauge
 	GCStoreTimeStamps       prometheus.Gauge
 	GCStoreAccessTimeStamps prometheus.Gauge
+	DiskSize                prometheus.Gauge
+	DiskFree                prometheus.Gauge
 }
 
 func newMetrics() metrics {
@@ -80,6 +84,12 @@ func newMetrics() metrics {
 			Name:      "gc_exclude_index_time",
 			Help:      "Total time taken to exclude gc index.",
 		}),
+		TotalTimeReserveEvict: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reserve_evict_time",
+			Help:      "Total time taken to evict chunks from the reserve.",
+		}),
 		TotalTimeGet: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -194,6 +204,12 @@ func newMetrics() metrics {
 			Name:      "fc_update_error_count",
 			Help:      "Number of times the gc update had error.",
 		}),
+		ReserveEvictCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "reserve_evict_count",
+			Help:      "Number of times the chunks are evicted from the reserve.",
+		}),
 
 		ModeGet: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: m.Namespace,
@@ -340,6 +356,18 @@ func newMetrics() metrics {
 			Name:      "gc_access_time_stamp",
 			Help:      "Access timestamp in Garbage collection iteration.",
 		}),
+		DiskSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "disk_size",
+			Help:      "Size of the database files in bytes.",
+		}),
+		DiskFree: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "disk_free",
+			Help:      "Free disk space in bytes on the database file system.",
+		}),
 	}
 }
 
@@ -19,6 +19,9 @@ package localstore
 import (
 	"errors"
 	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/syndtr/goleveldb/leveldb"
 )
 
 var errMissingCurrentSchema = errors.New("could not find current db schema")
@@ -98,3 +101,94 @@ func getMigrations(currentSchema, targetSchema string, allSchemeMigrations []mig
 	}
 	return migrations, nil
 }
+
+// gcMigrationBatchSize is the maximal number of items
+// written in a single batch by migrateGCPolicy.
+var gcMigrationBatchSize = 1000
+
+// migrateGCPolicy rebuilds the gc index if the gc policy has changed since
+// the last run, as the keys of gc index are encoded by the policy. The items
+// of the previous gc index are deleted and all the accessed chunks that are
+// neither pinned nor in the reserve are added to the current gc index. If
+// keep is true, ErrGCPolicyChanged is returned instead.
+func (db *DB) migrateGCPolicy(keep bool) error {
+	stored, err := db.gcIndexName.Get()
+	if err != nil {
+		return err
+	}
+	name := stored
+	if name == "" {
+		// the gc index of the databases which do
+		// not store the name is the lru index
+		name = NewLRUGCPolicy().Name()
+	}
+	if name == db.gcPolicy.Name() {
+		if stored == "" {
+			return db.gcIndexName.Put(name)
+		}
+		return nil
+	}
+	if keep {
+		return fmt.Errorf("%w: gc index %q, not %q", ErrGCPolicyChanged, name, db.gcPolicy.Name())
+	}
+
+	db.logger.Infof("localstore migration: rebuilding gc index %q from %q", db.gcPolicy.Name(), name)
+
+	if _, err := db.shed.TruncateIndex(name); err != nil {
+		return fmt.Errorf("truncate previous gc index: %w", err)
+	}
+	// the current gc index may contain the items from
+	// a run before the policy was changed the last time
+	if _, err := db.shed.TruncateIndex(db.gcPolicy.Name()); err != nil {
+		return fmt.Errorf("truncate gc index: %w", err)
+	}
+
+	var gcSize uint64
+	batch := new(leveldb.Batch)
+	err = db.retrievalAccessIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		ok, err := db.pinIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		if ok {
+			return false, nil
+		}
+		i, err := db.retrievalDataIndex.Get(item)
+		if err != nil {
+			return true, err
+		}
+		item.StoreTimestamp = i.StoreTimestamp
+		item.BinID = i.BinID
+		ok, err = db.reserveIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		if ok {
+			return false, nil
+		}
+
+		err = db.gcIndex.PutInBatch(batch, item)
+		if err != nil {
+			return true, err
+		}
+		gcSize++
+		if batch.Len() >= gcMigrationBatchSize {
+			if err := db.shed.WriteBatch(batch); err != nil {
+				return true, err
+			}
+			batch.Reset()
+		}
+		return false, nil
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("rebuild gc index: %w", err)
+	}
+	db.gcSize.PutInBatch(batch, gcSize)
+	err = db.shed.WriteBatch(batch)
+	if err != nil {
+		return err
+	}
+
+	db.logger.Infof("localstore migration: rebuilt gc index %q with %d chunks", db.gcPolicy.Name(), gcSize)
+	return db.gcIndexName.Put(db.gcPolicy.Name())
+}
@@ -17,13 +17,17 @@
 package localstore
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"math/rand"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/storage"
 )
 
 func TestOneMigration(t *testing.T) {
@@ -311,3 +315,123 @@ func TestMigrationErrorTo(t *testing.T) {
 		t.Errorf("migration ran but shouldnt have")
 	}
 }
+
+// TestMigrateGCPolicy tests that the gc index is rebuilt when the localstore
+// is opened with a different gc policy.
+func TestMigrateGCPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logging.New(ioutil.Discard, 0)
+
+	db, err := New(dir, baseKey, nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := generateTestRandomChunks(20)
+	for _, ch := range chunks {
+		_, err := db.Put(context.Background(), storage.ModePutUpload, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = db.Set(context.Background(), storage.ModeSetSyncPush, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// pinned chunks are not added to the gc index
+	err = db.Set(context.Background(), storage.ModeSetPin, chunks[0].Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []GCPolicy{
+		NewLFUGCPolicy(),
+		NewTTLGCPolicy(time.Hour),
+		NewLRUGCPolicy(),
+	} {
+		db, err = New(dir, baseKey, &Options{GCPolicy: p}, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(p.Name()+" gc index count", newItemsCountTest(db.gcIndex, len(chunks)-1))
+
+		t.Run(p.Name()+" gc size", newIndexGCSizeTest(db))
+
+		name, err := db.gcIndexName.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != p.Name() {
+			t.Errorf("got gc index name %q, want %q", name, p.Name())
+		}
+
+		err = db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestMigrateGCPolicy_keep tests that the localstore is not opened with
+// a different gc policy if the gc policy must be kept, while a new
+// localstore is created with any gc policy.
+func TestMigrateGCPolicy_keep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := logging.New(ioutil.Discard, 0)
+
+	db, err := New(dir, baseKey, &Options{GCPolicy: NewLFUGCPolicy(), KeepGCPolicy: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Put(context.Background(), storage.ModePutUpload, generateTestRandomChunk())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = New(dir, baseKey, &Options{KeepGCPolicy: true}, logger)
+	if !errors.Is(err, ErrGCPolicyChanged) {
+		t.Fatalf("got error %v, want %v", err, ErrGCPolicyChanged)
+	}
+
+	db, err = New(dir, baseKey, &Options{GCPolicy: NewLFUGCPolicy(), KeepGCPolicy: true}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := db.gcIndexName.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := NewLFUGCPolicy().Name(); name != want {
+		t.Errorf("got gc index name %q, want %q", name, want)
+	}
+	err = db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -50,7 +50,14 @@ func (db *DB) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address)
 		}
 		return nil, err
 	}
-	return swarm.NewChunk(swarm.NewAddress(out.Address), out.Data).WithPinCounter(out.PinCounter), nil
+	if err := db.fillStamp(&out); err != nil {
+		return nil, err
+	}
+	ch, err = itemToChunk(out)
+	if err != nil {
+		return nil, err
+	}
+	return ch.WithPinCounter(out.PinCounter), nil
 }
 
 // get returns Item from the retrieval index
@@ -134,6 +141,7 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
+		item.AccessCount = i.AccessCount
 	case errors.Is(err, leveldb.ErrNotFound):
 		// no chunk accesses
 	default:
@@ -144,31 +152,43 @@ func (db *DB) updateGC(item shed.Item) (err error) {
 		// do not add it to the gc index
 		return nil
 	}
-	// delete current entry from the gc index
-	err = db.gcIndex.DeleteInBatch(batch, item)
+	// delete current entry from the gc or reserve index
+	gcSizeChange, reserveSizeChange, err := db.deleteGCInBatch(batch, item)
 	if err != nil {
 		return err
 	}
 	// update access timestamp
 	item.AccessTimestamp = now()
+	item.AccessCount++
 	// update retrieve access index
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
 		return err
 	}
 
-	// add new entry to gc index ONLY if it is not present in pinIndex
+	// add new entry to gc index ONLY if it is not present in pinIndex,
+	// the chunks within the storage radius are added to reserve index
 	ok, err := db.pinIndex.Has(item)
 	if err != nil {
 		return err
 	}
 	if !ok {
-		err = db.gcIndex.PutInBatch(batch, item)
+		c, r, err := db.putGCInBatch(batch, item)
 		if err != nil {
 			return err
 		}
+		gcSizeChange += c
+		reserveSizeChange += r
 	}
 
+	err = db.incGCSizeInBatch(batch, gcSizeChange)
+	if err != nil {
+		return err
+	}
+	err = db.incReserveSizeInBatch(batch, reserveSizeChange)
+	if err != nil {
+		return err
+	}
 	return db.shed.WriteBatch(batch)
 }
 
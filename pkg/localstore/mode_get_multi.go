@@ -49,8 +49,15 @@ func (db *DB) GetMulti(ctx context.Context, mode storage.ModeGet, addrs ...swarm
 		return nil, err
 	}
 	chunks = make([]swarm.Chunk, len(out))
-	for i, ch := range out {
-		chunks[i] = swarm.NewChunk(swarm.NewAddress(ch.Address), ch.Data).WithPinCounter(ch.PinCounter)
+	for i, item := range out {
+		if err := db.fillStamp(&item); err != nil {
+			return nil, err
+		}
+		ch, err := itemToChunk(item)
+		if err != nil {
+			return nil, err
+		}
+		chunks[i] = ch.WithPinCounter(item.PinCounter)
 	}
 	return chunks, nil
 }
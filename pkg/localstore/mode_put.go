@@ -36,6 +36,13 @@ func (db *DB) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk)
 	db.metrics.ModePut.Inc()
 	defer totalTimeMetric(db.metrics.TotalTimePut, time.Now())
 
+	// the chunks from the network are not stored
+	// when the disk is about to be full
+	if (mode == storage.ModePutSync || mode == storage.ModePutRequest) && db.LowDiskSpace() {
+		db.metrics.ModePutFailure.Inc()
+		return nil, storage.ErrLowDiskSpace
+	}
+
 	exist, err = db.put(mode, chs...)
 	if err != nil {
 		db.metrics.ModePutFailure.Inc()
@@ -61,6 +68,7 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 	// variables that provide information for operations
 	// to be done after write batch function successfully executes
 	var gcSizeChange int64                      // number to add or subtract from gcSize
+	var reserveSizeChange int64                 // number to add or subtract from reserveSize
 	var triggerPushFeed bool                    // signal push feed subscriptions to iterate
 	triggerPullFeed := make(map[uint8]struct{}) // signal pull feed subscriptions to iterate
 
@@ -79,21 +87,32 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putRequest(batch, binIDs, chunkToItem(ch))
+			item, err := chunkToItem(ch)
+			if err != nil {
+				return nil, err
+			}
+			exists, c, r, err := db.putRequest(batch, binIDs, item)
 			if err != nil {
 				return nil, err
 			}
 			exist[i] = exists
 			gcSizeChange += c
+			reserveSizeChange += r
 		}
 
-	case storage.ModePutUpload:
+	case storage.ModePutUpload, storage.ModePutUploadDirect:
+		// the directly uploaded chunks are pushed by the uploader
+		push := mode == storage.ModePutUpload
 		for i, ch := range chs {
 			if containsChunk(ch.Address(), chs[:i]...) {
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putUpload(batch, binIDs, chunkToItem(ch))
+			item, err := chunkToItem(ch)
+			if err != nil {
+				return nil, err
+			}
+			exists, c, err := db.putUpload(batch, binIDs, item, push)
 			if err != nil {
 				return nil, err
 			}
@@ -102,7 +121,9 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				// chunk is new so, trigger subscription feeds
 				// after the batch is successfully written
 				triggerPullFeed[db.po(ch.Address())] = struct{}{}
-				triggerPushFeed = true
+				if push {
+					triggerPushFeed = true
+				}
 			}
 			gcSizeChange += c
 		}
@@ -113,7 +134,11 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 				exist[i] = true
 				continue
 			}
-			exists, c, err := db.putSync(batch, binIDs, chunkToItem(ch))
+			item, err := chunkToItem(ch)
+			if err != nil {
+				return nil, err
+			}
+			exists, c, err := db.putSync(batch, binIDs, item)
 			if err != nil {
 				return nil, err
 			}
@@ -138,6 +163,10 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 	if err != nil {
 		return nil, err
 	}
+	err = db.incReserveSizeInBatch(batch, reserveSizeChange)
+	if err != nil {
+		return nil, err
+	}
 
 	err = db.shed.WriteBatch(batch)
 	if err != nil {
@@ -154,11 +183,11 @@ func (db *DB) put(mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err e
 }
 
 // putRequest adds an Item to the batch by updating required indexes:
-//  - put to indexes: retrieve, gc
+//  - put to indexes: retrieve, gc or reserve
 //  - it does not enter the syncpool
 // The batch can be written to the database.
 // Provided batch and binID map are updated.
-func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
+func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange, reserveSizeChange int64, err error) {
 	i, err := db.retrievalDataIndex.Get(item)
 	switch {
 	case err == nil:
@@ -169,7 +198,7 @@ func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item she
 		// no chunk accesses
 		exists = false
 	default:
-		return false, 0, err
+		return false, 0, 0, err
 	}
 	if item.StoreTimestamp == 0 {
 		item.StoreTimestamp = now()
@@ -177,28 +206,33 @@ func (db *DB) putRequest(batch *leveldb.Batch, binIDs map[uint8]uint64, item she
 	if item.BinID == 0 {
 		item.BinID, err = db.incBinID(binIDs, db.po(swarm.NewAddress(item.Address)))
 		if err != nil {
-			return false, 0, err
+			return false, 0, 0, err
 		}
 	}
 
-	gcSizeChange, err = db.setGC(batch, item)
+	gcSizeChange, reserveSizeChange, err = db.setGC(batch, item)
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
 	}
 
 	err = db.retrievalDataIndex.PutInBatch(batch, item)
 	if err != nil {
-		return false, 0, err
+		return false, 0, 0, err
+	}
+	err = db.putStampInBatch(batch, item)
+	if err != nil {
+		return false, 0, 0, err
 	}
 
-	return exists, gcSizeChange, nil
+	return exists, gcSizeChange, reserveSizeChange, nil
 }
 
 // putUpload adds an Item to the batch by updating required indexes:
 //  - put to indexes: retrieve, push, pull
+//  - the push index is skipped if push is false
 // The batch can be written to the database.
 // Provided batch and binID map are updated.
-func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item) (exists bool, gcSizeChange int64, err error) {
+func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.Item, push bool) (exists bool, gcSizeChange int64, err error) {
 	exists, err = db.retrievalDataIndex.Has(item)
 	if err != nil {
 		return false, 0, err
@@ -224,11 +258,15 @@ func (db *DB) putUpload(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed
 	if err != nil {
 		return false, 0, err
 	}
+	err = db.putStampInBatch(batch, item)
+	if err != nil {
+		return false, 0, err
+	}
 	err = db.pullIndex.PutInBatch(batch, item)
 	if err != nil {
 		return false, 0, err
 	}
-	if !anonymous {
+	if push && !anonymous {
 		err = db.pushIndex.PutInBatch(batch, item)
 		if err != nil {
 			return false, 0, err
@@ -260,6 +298,10 @@ func (db *DB) putSync(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.I
 	if err != nil {
 		return false, 0, err
 	}
+	err = db.putStampInBatch(batch, item)
+	if err != nil {
+		return false, 0, err
+	}
 	err = db.pullIndex.PutInBatch(batch, item)
 	if err != nil {
 		return false, 0, err
@@ -268,17 +310,26 @@ func (db *DB) putSync(batch *leveldb.Batch, binIDs map[uint8]uint64, item shed.I
 	return false, gcSizeChange, nil
 }
 
+// putStampInBatch adds the postage stamp of the Item to the batch, if the
+// Item has one.
+func (db *DB) putStampInBatch(batch *leveldb.Batch, item shed.Item) error {
+	if item.Stamp == nil {
+		return nil
+	}
+	return db.stampIndex.PutInBatch(batch, item)
+}
+
 // setGC is a helper function used to add chunks to the retrieval access
-// index and the gc index in the cases that the putToGCCheck condition
+// index and the gc or reserve index in the cases that the putToGCCheck condition
 // warrants a gc set. this is to mitigate index leakage in edge cases where
 // a chunk is added to a node's localstore and given that the chunk is
 // already within that node's NN (thus, it can be added to the gc index
 // safely)
-func (db *DB) setGC(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64, err error) {
+func (db *DB) setGC(batch *leveldb.Batch, item shed.Item) (gcSizeChange, reserveSizeChange int64, err error) {
 	if item.BinID == 0 {
 		i, err := db.retrievalDataIndex.Get(item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 		item.BinID = i.BinID
 	}
@@ -286,36 +337,38 @@ func (db *DB) setGC(batch *leveldb.Batch, item shed.Item) (gcSizeChange int64, e
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		err = db.gcIndex.DeleteInBatch(batch, item)
+		item.AccessCount = i.AccessCount
+		gcSizeChange, reserveSizeChange, err = db.deleteGCInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange--
 	case errors.Is(err, leveldb.ErrNotFound):
 		// the chunk is not accessed before
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 	item.AccessTimestamp = now()
+	item.AccessCount++
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	// add new entry to gc index ONLY if it is not present in pinIndex
 	ok, err := db.pinIndex.Has(item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	if !ok {
-		err = db.gcIndex.PutInBatch(batch, item)
+		c, r, err := db.putGCInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange++
+		gcSizeChange += c
+		reserveSizeChange += r
 	}
 
-	return gcSizeChange, nil
+	return gcSizeChange, reserveSizeChange, nil
 }
 
 // incBinID is a helper function for db.put* methods that increments bin id
@@ -24,8 +24,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // TestModePutRequest validates ModePutRequest index values on the provided DB.
@@ -145,6 +147,89 @@ func TestModePutUpload(t *testing.T) {
 	}
 }
 
+// TestModePutUploadDirect validates ModePutUploadDirect index values on the
+// provided DB, the chunks are not put in the push index.
+func TestModePutUploadDirect(t *testing.T) {
+	for _, tc := range multiChunkTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newTestDB(t, nil)
+
+			wantTimestamp := time.Now().UTC().UnixNano()
+			defer setNow(func() (t int64) {
+				return wantTimestamp
+			})()
+
+			chunks := generateTestRandomChunks(tc.count)
+
+			_, err := db.Put(context.Background(), storage.ModePutUploadDirect, chunks...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			binIDs := make(map[uint8]uint64)
+
+			for _, ch := range chunks {
+				po := db.po(ch.Address())
+				binIDs[po]++
+
+				newRetrieveIndexesTest(db, ch, wantTimestamp, 0)(t)
+				newPullIndexTest(db, ch, binIDs[po], nil)(t)
+				newPushIndexTest(db, ch, wantTimestamp, leveldb.ErrNotFound)(t)
+			}
+		})
+	}
+}
+
+// TestModePut_stamp validates that the postage stamp of the chunk is stored,
+// returned with the chunk and removed with it.
+func TestModePut_stamp(t *testing.T) {
+	db := newTestDB(t, nil)
+
+	stamp := postage.NewStamp(bytes.Repeat([]byte{1}, postage.BatchIDSize), make([]byte, postage.IndexSize), make([]byte, postage.SignatureSize))
+	ch := generateTestRandomChunk().WithStamp(stamp)
+
+	_, err := db.Put(context.Background(), storage.ModePutUpload, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkStamp := func(t *testing.T, got swarm.Chunk) {
+		t.Helper()
+		if got.Stamp() == nil {
+			t.Fatal("missing stamp")
+		}
+		if !bytes.Equal(got.Stamp().BatchID(), stamp.BatchID()) {
+			t.Fatalf("got batch id %x, want %x", got.Stamp().BatchID(), stamp.BatchID())
+		}
+	}
+
+	t.Run("get", func(t *testing.T) {
+		got, err := db.Get(context.Background(), storage.ModeGetRequest, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkStamp(t, got)
+	})
+
+	t.Run("subscribe push", func(t *testing.T) {
+		chunks, stop := db.SubscribePush(context.Background())
+		defer stop()
+		select {
+		case got := <-chunks:
+			checkStamp(t, got)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		if err := db.Set(context.Background(), storage.ModeSetRemove, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+		newItemsCountTest(db.stampIndex, 0)(t)
+	})
+}
+
 // TestModePutUpload_parallel uploads chunks in parallel
 // and validates if all chunks can be retrieved with correct data.
 func TestModePutUpload_parallel(t *testing.T) {
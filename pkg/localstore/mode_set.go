@@ -56,6 +56,7 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 	// variables that provide information for operations
 	// to be done after write batch function successfully executes
 	var gcSizeChange int64                      // number to add or subtract from gcSize
+	var reserveSizeChange int64                 // number to add or subtract from reserveSize
 	triggerPullFeed := make(map[uint8]struct{}) // signal pull feed subscriptions to iterate
 
 	switch mode {
@@ -66,11 +67,12 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 		binIDs := make(map[uint8]uint64)
 		for _, addr := range addrs {
 			po := db.po(addr)
-			c, err := db.setAccess(batch, binIDs, addr, po)
+			c, r, err := db.setAccess(batch, binIDs, addr, po)
 			if err != nil {
 				return err
 			}
 			gcSizeChange += c
+			reserveSizeChange += r
 			triggerPullFeed[po] = struct{}{}
 		}
 		for po, id := range binIDs {
@@ -79,20 +81,22 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 
 	case storage.ModeSetSyncPush, storage.ModeSetSyncPull:
 		for _, addr := range addrs {
-			c, err := db.setSync(batch, addr, mode)
+			c, r, err := db.setSync(batch, addr, mode)
 			if err != nil {
 				return err
 			}
 			gcSizeChange += c
+			reserveSizeChange += r
 		}
 
 	case storage.ModeSetRemove:
 		for _, addr := range addrs {
-			c, err := db.setRemove(batch, addr)
+			c, r, err := db.setRemove(batch, addr)
 			if err != nil {
 				return err
 			}
 			gcSizeChange += c
+			reserveSizeChange += r
 		}
 
 	case storage.ModeSetPin:
@@ -118,6 +122,10 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 	if err != nil {
 		return err
 	}
+	err = db.incReserveSizeInBatch(batch, reserveSizeChange)
+	if err != nil {
+		return err
+	}
 
 	err = db.shed.WriteBatch(batch)
 	if err != nil {
@@ -130,9 +138,9 @@ func (db *DB) set(mode storage.ModeSet, addrs ...swarm.Address) (err error) {
 }
 
 // setAccess sets the chunk access time by updating required indexes:
-//  - add to pull, insert to gc
+//  - add to pull, insert to gc or reserve
 // Provided batch and binID map are updated.
-func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swarm.Address, po uint8) (gcSizeChange int64, err error) {
+func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swarm.Address, po uint8) (gcSizeChange, reserveSizeChange int64, err error) {
 
 	item := addressToItem(addr)
 
@@ -147,54 +155,56 @@ func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swar
 	case errors.Is(err, leveldb.ErrNotFound):
 		err = db.pushIndex.DeleteInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 		item.StoreTimestamp = now()
 		item.BinID, err = db.incBinID(binIDs, po)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 
 	i, err = db.retrievalAccessIndex.Get(item)
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		err = db.gcIndex.DeleteInBatch(batch, item)
+		item.AccessCount = i.AccessCount
+		gcSizeChange, reserveSizeChange, err = db.deleteGCInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange--
 	case errors.Is(err, leveldb.ErrNotFound):
 		// the chunk is not accessed before
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 	item.AccessTimestamp = now()
+	item.AccessCount++
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	err = db.pullIndex.PutInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	ok, err := db.pinIndex.Has(item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	if !ok {
-		err = db.gcIndex.PutInBatch(batch, item)
+		c, r, err := db.putGCInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange++
+		gcSizeChange += c
+		reserveSizeChange += r
 	}
 
-	return gcSizeChange, nil
+	return gcSizeChange, reserveSizeChange, nil
 }
 
 // setSync adds the chunk to the garbage collection after syncing by updating indexes
@@ -202,9 +212,9 @@ func (db *DB) setAccess(batch *leveldb.Batch, binIDs map[uint8]uint64, addr swar
 //	 is then set to 0 to prevent duplicate increments for the same chunk synced multiple times
 // - ModeSetSyncPush - the corresponding tag is incremented, then item is removed
 //   from push sync index
-// - update to gc index happens given item does not exist in pin index
+// - update to gc or reserve index happens given item does not exist in pin index
 // Provided batch is updated.
-func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.ModeSet) (gcSizeChange int64, err error) {
+func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.ModeSet) (gcSizeChange, reserveSizeChange int64, err error) {
 	item := addressToItem(addr)
 
 	// need to get access timestamp here as it is not
@@ -220,11 +230,11 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 			// if it is there
 			err = db.pushIndex.DeleteInBatch(batch, item)
 			if err != nil {
-				return 0, err
+				return 0, 0, err
 			}
-			return 0, nil
+			return 0, 0, nil
 		}
-		return 0, err
+		return 0, 0, err
 	}
 	item.StoreTimestamp = i.StoreTimestamp
 	item.BinID = i.BinID
@@ -244,7 +254,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 				db.logger.Debugf("localstore: chunk with address %s not found in pull index", addr)
 				break
 			}
-			return 0, err
+			return 0, 0, err
 		}
 
 		if db.tags != nil && i.Tag != 0 {
@@ -264,7 +274,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 
 				err = db.pullIndex.PutInBatch(batch, item)
 				if err != nil {
-					return 0, err
+					return 0, 0, err
 				}
 			}
 		}
@@ -273,12 +283,12 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 		if err != nil {
 			if errors.Is(err, leveldb.ErrNotFound) {
 				// we handle this error internally, since this is an internal inconsistency of the indices
-				// this error can happen if the chunk is put with ModePutRequest or ModePutSync
-				// but this function is called with ModeSetSyncPush
+				// this error can happen if the chunk is put with ModePutRequest, ModePutSync
+				// or ModePutUploadDirect but this function is called with ModeSetSyncPush
 				db.logger.Debugf("localstore: chunk with address %s not found in push index", addr)
 				break
 			}
-			return 0, err
+			return 0, 0, err
 		}
 		if db.tags != nil && i.Tag != 0 {
 			t, err := db.tags.Get(i.Tag)
@@ -289,7 +299,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 			} else {
 				// setting a chunk for push sync assumes the tag is not anonymous
 				if t.Anonymous {
-					return 0, errors.New("got an anonymous chunk in push sync index")
+					return 0, 0, errors.New("got an anonymous chunk in push sync index")
 				}
 
 				t.Inc(tags.StateSynced)
@@ -298,7 +308,7 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 
 		err = db.pushIndex.DeleteInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
 	}
 
@@ -306,42 +316,43 @@ func (db *DB) setSync(batch *leveldb.Batch, addr swarm.Address, mode storage.Mod
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
-		err = db.gcIndex.DeleteInBatch(batch, item)
+		item.AccessCount = i.AccessCount
+		gcSizeChange, reserveSizeChange, err = db.deleteGCInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange--
 	case errors.Is(err, leveldb.ErrNotFound):
 		// the chunk is not accessed before
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 	item.AccessTimestamp = now()
 	err = db.retrievalAccessIndex.PutInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 
 	// Add in gcIndex only if this chunk is not pinned
 	ok, err := db.pinIndex.Has(item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	if !ok {
-		err = db.gcIndex.PutInBatch(batch, item)
+		c, r, err := db.putGCInBatch(batch, item)
 		if err != nil {
-			return 0, err
+			return 0, 0, err
 		}
-		gcSizeChange++
+		gcSizeChange += c
+		reserveSizeChange += r
 	}
 
-	return gcSizeChange, nil
+	return gcSizeChange, reserveSizeChange, nil
 }
 
 // setRemove removes the chunk by updating indexes:
-//  - delete from retrieve, pull, gc
+//  - delete from retrieve, pull, gc or reserve, stamp
 // Provided batch is updated.
-func (db *DB) setRemove(batch *leveldb.Batch, addr swarm.Address) (gcSizeChange int64, err error) {
+func (db *DB) setRemove(batch *leveldb.Batch, addr swarm.Address) (gcSizeChange, reserveSizeChange int64, err error) {
 	item := addressToItem(addr)
 
 	// need to get access timestamp here as it is not
@@ -351,41 +362,37 @@ func (db *DB) setRemove(batch *leveldb.Batch, addr swarm.Address) (gcSizeChange
 	switch {
 	case err == nil:
 		item.AccessTimestamp = i.AccessTimestamp
+		item.AccessCount = i.AccessCount
 	case errors.Is(err, leveldb.ErrNotFound):
 	default:
-		return 0, err
+		return 0, 0, err
 	}
 	i, err = db.retrievalDataIndex.Get(item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	item.StoreTimestamp = i.StoreTimestamp
 	item.BinID = i.BinID
 
 	err = db.retrievalDataIndex.DeleteInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	err = db.retrievalAccessIndex.DeleteInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
 	err = db.pullIndex.DeleteInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-	err = db.gcIndex.DeleteInBatch(batch, item)
+	err = db.stampIndex.DeleteInBatch(batch, item)
 	if err != nil {
-		return 0, err
+		return 0, 0, err
 	}
-	// a check is needed for decrementing gcSize
-	// as delete is not reporting if the key/value pair
-	// is deleted or not
-	if _, err := db.gcIndex.Get(item); err == nil {
-		gcSizeChange = -1
-	}
-
-	return gcSizeChange, nil
+	// the sizes are changed only if the item
+	// is deleted from the gc or reserve index
+	return db.deleteGCInBatch(batch, item)
 }
 
 // setPin increments pin counter for the chunk by updating
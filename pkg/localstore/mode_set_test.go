@@ -18,11 +18,16 @@ package localstore
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/shed"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/tags"
 	tagtesting "github.com/ethersphere/bee/pkg/tags/testing"
@@ -238,6 +243,69 @@ func TestModeSetSyncPullPushAnonymousTag(t *testing.T) {
 	}
 }
 
+// TestModeSetSyncPushPersistedTag makes sure that push sync increments the
+// tag loaded from the state store after the node restart.
+func TestModeSetSyncPushPersistedTag(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstore-persisted-tag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	baseKey := make([]byte, 32)
+	if _, err := rand.Read(baseKey); err != nil {
+		t.Fatal(err)
+	}
+	logger := logging.New(ioutil.Discard, 0)
+	stateStore := statestore.NewStateStore()
+
+	ts, err := tags.New(tags.Options{StateStore: stateStore, Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := New(dir, baseKey, &Options{Tags: ts}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := ts.Create("test", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := generateTestRandomChunk().WithTagID(tag.Uid)
+	if _, err := db.Put(context.Background(), storage.ModePutUpload, ch); err != nil {
+		t.Fatal(err)
+	}
+	tag.Inc(tags.StateStored)
+
+	// restart the node
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ts, err = tags.New(tags.Options{StateStore: stateStore, Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+	db, err = New(dir, baseKey, &Options{Tags: ts}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := db.Set(context.Background(), storage.ModeSetSyncPush, ch.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err = ts.Get(tag.Uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagtesting.CheckTag(t, tag, 0, 1, 0, 0, 1, 1)
+}
+
 // TestModeSetSyncPushNormalTag makes sure that push sync increments tags
 // correctly on a normal tag (that is, a tag that is expected to show progress bars
 // according to push sync progress)
@@ -0,0 +1,281 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// ReserveState is the state of the reserve, which holds the chunks within
+// the storage radius of the node, that are not garbage collected.
+type ReserveState struct {
+	// Radius is the storage radius, the minimal proximity order of the
+	// chunks in the reserve.
+	Radius uint8
+	// Size is the number of chunks in the reserve.
+	Size uint64
+	// Capacity is the maximal number of chunks in the reserve, zero if the
+	// reserve is disabled.
+	Capacity uint64
+}
+
+// ReserveState returns the current state of the reserve.
+func (db *DB) ReserveState() (*ReserveState, error) {
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	radius, err := db.storageRadius()
+	if err != nil {
+		return nil, err
+	}
+	size, err := db.reserveSize.Get()
+	if err != nil {
+		return nil, err
+	}
+	return &ReserveState{
+		Radius:   radius,
+		Size:     size,
+		Capacity: db.reserveCapacity,
+	}, nil
+}
+
+// storageRadius returns the storage radius, which is the neighbourhood depth
+// or the radius to which the reserve has grown, if it is greater.
+func (db *DB) storageRadius() (radius uint8, err error) {
+	r, err := db.reserveRadius.Get()
+	if err != nil {
+		return 0, err
+	}
+	radius = uint8(r)
+	if db.depther != nil {
+		if depth := db.depther.NeighborhoodDepth(); depth > radius {
+			radius = depth
+		}
+	}
+	return radius, nil
+}
+
+// withinRadius returns true if the chunk of the item belongs to the reserve.
+func (db *DB) withinRadius(item shed.Item) (bool, error) {
+	if db.reserveCapacity == 0 {
+		return false, nil
+	}
+	radius, err := db.storageRadius()
+	if err != nil {
+		return false, err
+	}
+	return db.po(swarm.NewAddress(item.Address)) >= radius, nil
+}
+
+// putGCInBatch adds the item to the reserve index if its chunk is within the
+// storage radius, or to the gc index otherwise. The item must have all fields
+// of the gc index key set. This function must be called under batchMu lock.
+func (db *DB) putGCInBatch(batch *leveldb.Batch, item shed.Item) (gcSizeChange, reserveSizeChange int64, err error) {
+	within, err := db.withinRadius(item)
+	if err != nil {
+		return 0, 0, err
+	}
+	if within {
+		err = db.reserveIndex.PutInBatch(batch, item)
+		if err != nil {
+			return 0, 0, err
+		}
+		return 0, 1, nil
+	}
+	err = db.gcIndex.PutInBatch(batch, item)
+	if err != nil {
+		return 0, 0, err
+	}
+	return 1, 0, nil
+}
+
+// deleteGCInBatch removes the item from the reserve index or the gc index,
+// whichever holds it. The item must have all fields of the gc index key set.
+// This function must be called under batchMu lock.
+func (db *DB) deleteGCInBatch(batch *leveldb.Batch, item shed.Item) (gcSizeChange, reserveSizeChange int64, err error) {
+	ok, err := db.reserveIndex.Has(item)
+	if err != nil {
+		return 0, 0, err
+	}
+	if ok {
+		err = db.reserveIndex.DeleteInBatch(batch, item)
+		if err != nil {
+			return 0, 0, err
+		}
+		return 0, -1, nil
+	}
+	ok, err = db.gcIndex.Has(item)
+	if err != nil {
+		return 0, 0, err
+	}
+	if ok {
+		err = db.gcIndex.DeleteInBatch(batch, item)
+		if err != nil {
+			return 0, 0, err
+		}
+		return -1, 0, nil
+	}
+	return 0, 0, nil
+}
+
+// incReserveSizeInBatch changes reserveSize field value
+// by change which can be negative. This function
+// must be called under batchMu lock.
+func (db *DB) incReserveSizeInBatch(batch *leveldb.Batch, change int64) (err error) {
+	if change == 0 {
+		return nil
+	}
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return err
+	}
+
+	var newSize uint64
+	if change > 0 {
+		newSize = reserveSize + uint64(change)
+	} else {
+		c := uint64(-change)
+		if c > reserveSize {
+			// protect uint64 undeflow
+			return nil
+		}
+		newSize = reserveSize - c
+	}
+	db.reserveSize.PutInBatch(batch, newSize)
+
+	// trigger garbage collection to grow the radius
+	// if the reserve overflows
+	if newSize > db.reserveCapacity {
+		db.triggerGarbageCollection()
+	}
+	return nil
+}
+
+// cacheCapacity returns the maximal number of chunks in the gc index, the
+// part of the capacity which is not used by the reserve. The capacity is
+// lowered to the number of chunks that fit in the maximal database size.
+func (db *DB) cacheCapacity() (capacity uint64, err error) {
+	capacity = db.capacity
+	if c := atomic.LoadUint64(&db.sizeCapacity); c > 0 && c < capacity {
+		capacity = c
+	}
+	if db.reserveCapacity == 0 {
+		return capacity, nil
+	}
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil {
+		return 0, err
+	}
+	if reserveSize > db.reserveCapacity {
+		reserveSize = db.reserveCapacity
+	}
+	if reserveSize >= capacity {
+		return 0, nil
+	}
+	return capacity - reserveSize, nil
+}
+
+// evictReserve moves the chunks which are not within the storage radius from
+// the reserve to the gc index, so that they can be garbage collected. While
+// the reserve size exceeds its capacity, the radius is increased and the
+// chunks of the next bin are moved. If done is false, the batch size limit
+// is reached and another call to this function is needed. This function is
+// called in collectGarbageWorker.
+func (db *DB) evictReserve() (evictedCount uint64, done bool, err error) {
+	if db.reserveCapacity == 0 {
+		return 0, true, nil
+	}
+	db.metrics.ReserveEvictCounter.Inc()
+	defer totalTimeMetric(db.metrics.TotalTimeReserveEvict, time.Now())
+
+	// protect database from changing idexes and sizes
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil {
+		return 0, true, err
+	}
+	radius, err := db.storageRadius()
+	if err != nil {
+		return 0, true, err
+	}
+
+	batch := new(leveldb.Batch)
+	var gcSizeChange int64
+	done = true
+	for po := uint8(0); po < swarm.MaxPO && done; po++ {
+		if po >= radius {
+			if reserveSize <= db.reserveCapacity {
+				break
+			}
+			// the reserve overflows, so the chunks
+			// of this bin are not stored in it anymore
+			radius = po + 1
+			db.reserveRadius.PutInBatch(batch, uint64(radius))
+		}
+		err = db.reserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			i, err := db.retrievalAccessIndex.Get(item)
+			if err != nil {
+				return true, err
+			}
+			item.AccessTimestamp = i.AccessTimestamp
+			item.AccessCount = i.AccessCount
+			i, err = db.retrievalDataIndex.Get(item)
+			if err != nil {
+				return true, err
+			}
+			item.StoreTimestamp = i.StoreTimestamp
+
+			err = db.reserveIndex.DeleteInBatch(batch, item)
+			if err != nil {
+				return true, err
+			}
+			reserveSize--
+			// pinned chunks are excluded from the garbage collection
+			ok, err := db.pinIndex.Has(item)
+			if err != nil {
+				return true, err
+			}
+			if !ok {
+				err = db.gcIndex.PutInBatch(batch, item)
+				if err != nil {
+					return true, err
+				}
+				gcSizeChange++
+			}
+			evictedCount++
+			if evictedCount >= gcBatchSize {
+				// batch size limit reached,
+				// another run is needed
+				done = false
+				return true, nil
+			}
+			return false, nil
+		}, &shed.IterateOptions{
+			Prefix: []byte{po},
+		})
+		if err != nil {
+			return 0, false, err
+		}
+	}
+
+	db.reserveSize.PutInBatch(batch, reserveSize)
+	err = db.incGCSizeInBatch(batch, gcSizeChange)
+	if err != nil {
+		return 0, false, err
+	}
+	err = db.shed.WriteBatch(batch)
+	if err != nil {
+		return 0, false, err
+	}
+	return evictedCount, done, nil
+}
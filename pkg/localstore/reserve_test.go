@@ -0,0 +1,223 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestDB_reserve tests that the chunks within the storage radius are kept in
+// the reserve and not garbage collected, and that the radius grows when the
+// reserve overflows.
+func TestDB_reserve(t *testing.T) {
+	var closed chan struct{}
+	testHookCollectGarbageChan := make(chan uint64)
+	t.Cleanup(setTestHookCollectGarbage(func(collectedCount uint64) {
+		select {
+		case testHookCollectGarbageChan <- collectedCount:
+		case <-closed:
+		}
+	}))
+	depther := newTestDepther(1)
+	db := newTestDB(t, &Options{
+		Capacity:            200,
+		ReserveCapacity:     50,
+		NeighborhoodDepther: depther,
+	})
+	closed = db.close
+
+	addrs := make([]swarm.Address, 0)
+	for i := 0; i < 300; i++ {
+		ch := generateTestRandomChunk()
+		_, err := db.Put(context.Background(), storage.ModePutUpload, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs = append(addrs, ch.Address())
+	}
+
+	for {
+		select {
+		case <-testHookCollectGarbageChan:
+		case <-time.After(10 * time.Second):
+			t.Fatal("collect garbage timeout")
+		}
+		state, err := db.ReserveState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		gcSize, err := db.gcSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.Size <= state.Capacity && gcSize <= db.gcTarget() {
+			break
+		}
+	}
+
+	state, err := db.ReserveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Radius <= 1 {
+		t.Errorf("got radius %v, want greater than the depth", state.Radius)
+	}
+	if state.Capacity != 50 {
+		t.Errorf("got reserve capacity %v, want %v", state.Capacity, 50)
+	}
+
+	t.Run("reserve size", newIndexReserveSizeTest(db))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("reserve chunks within radius", func(t *testing.T) {
+		err := db.reserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			if po := db.po(swarm.NewAddress(item.Address)); po < state.Radius {
+				t.Errorf("got chunk with po %v in reserve, want at least %v", po, state.Radius)
+			}
+			return false, nil
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("reserve chunks not collected", func(t *testing.T) {
+		for _, addr := range addrs {
+			if db.po(addr) < state.Radius {
+				continue
+			}
+			_, err := db.Get(context.Background(), storage.ModeGetLookup, addr)
+			if err != nil {
+				t.Errorf("chunk %s within radius: %v", addr, err)
+			}
+		}
+	})
+}
+
+// TestDB_reserveDepth tests that the chunks which are not within the radius
+// anymore after the depth increased are moved to the gc index.
+func TestDB_reserveDepth(t *testing.T) {
+	depther := newTestDepther(0)
+	db := newTestDB(t, &Options{
+		Capacity:            1000,
+		ReserveCapacity:     500,
+		NeighborhoodDepther: depther,
+	})
+
+	chunks := generateTestRandomChunks(100)
+	for _, ch := range chunks {
+		_, err := db.Put(context.Background(), storage.ModePutUpload, ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = db.Set(context.Background(), storage.ModeSetSyncPush, ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("all chunks in reserve", newItemsCountTest(db.reserveIndex, len(chunks)))
+
+	t.Run("no chunks in gc", newItemsCountTest(db.gcIndex, 0))
+
+	depther.set(2)
+
+	_, done, err := db.evictReserve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done {
+		t.Fatal("reserve eviction not done")
+	}
+
+	var withinRadius int
+	for _, ch := range chunks {
+		if db.po(ch.Address()) >= 2 {
+			withinRadius++
+		}
+	}
+
+	t.Run("reserve count", newItemsCountTest(db.reserveIndex, withinRadius))
+
+	t.Run("gc count", newItemsCountTest(db.gcIndex, len(chunks)-withinRadius))
+
+	t.Run("reserve size", newIndexReserveSizeTest(db))
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	state, err := db.ReserveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Radius != 2 {
+		t.Errorf("got radius %v, want %v", state.Radius, 2)
+	}
+}
+
+// TestDB_reserveCapacity tests that the reserve capacity must be lower than
+// the capacity.
+func TestDB_reserveCapacity(t *testing.T) {
+	_, err := New("", make([]byte, 32), &Options{
+		Capacity:        100,
+		ReserveCapacity: 100,
+	}, nil)
+	if !errors.Is(err, ErrInvalidReserveCapacity) {
+		t.Fatalf("got error %v, want %v", err, ErrInvalidReserveCapacity)
+	}
+}
+
+// newIndexReserveSizeTest retruns a test function that validates if
+// db.reserveSize field value is the same as the number of items in
+// db.reserveIndex.
+func newIndexReserveSizeTest(db *DB) func(t *testing.T) {
+	return func(t *testing.T) {
+		var want uint64
+		err := db.reserveIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+			want++
+			return
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := db.reserveSize.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got reserve size %v, want %v", got, want)
+		}
+	}
+}
+
+// testDepther is a topology.NeighborhoodDepther
+// with the depth which can be changed.
+type testDepther struct {
+	depth uint32
+}
+
+func newTestDepther(depth uint8) *testDepther {
+	return &testDepther{depth: uint32(depth)}
+}
+
+func (d *testDepther) NeighborhoodDepth() uint8 {
+	return uint8(atomic.LoadUint32(&d.depth))
+}
+
+func (d *testDepther) set(depth uint8) {
+	atomic.StoreUint32(&d.depth, uint32(depth))
+}
@@ -72,9 +72,16 @@ func (db *DB) SubscribePush(ctx context.Context) (c <-chan swarm.Chunk, stop fun
 					if err != nil {
 						return true, err
 					}
+					if err := db.fillStamp(&dataItem); err != nil {
+						return true, err
+					}
+					ch, err := itemToChunk(dataItem)
+					if err != nil {
+						return true, err
+					}
 
 					select {
-					case chunks <- swarm.NewChunk(swarm.NewAddress(dataItem.Address), dataItem.Data).WithTagID(item.Tag):
+					case chunks <- ch.WithTagID(item.Tag):
 						count++
 						// set next iteration start item
 						// when its chunk is successfully sent to channel
@@ -0,0 +1,424 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// maxReportedInconsistencies limits the number of the inconsistency
+// descriptions in the ValidationReport, while all of them are counted.
+var maxReportedInconsistencies = 1000
+
+// repairBatchSize is the maximal number of items
+// written in a single batch on repair.
+var repairBatchSize = 1000
+
+// ValidateOptions configure the validation of the database.
+type ValidateOptions struct {
+	// Validators validate the stored chunks, which are valid if any of the
+	// validators accepts them. The chunks are not validated if it is empty.
+	Validators []swarm.ChunkValidator
+	// Repair removes the invalid chunks and rebuilds the indexes derived
+	// from the retrieval data index if any problem is found.
+	Repair bool
+	// Progress is called with the number of the validated chunks after
+	// every validated chunk.
+	Progress func(count int64)
+}
+
+// ValidationReport is the result of the database validation.
+type ValidationReport struct {
+	// Chunks is the number of the stored chunks.
+	Chunks int64
+	// InvalidChunks are the addresses of the chunks which are not accepted
+	// by any of the validators.
+	InvalidChunks []swarm.Address
+	// InconsistencyCount is the number of the inconsistencies between the
+	// indexes and the fields of the database.
+	InconsistencyCount int64
+	// Inconsistencies describe the inconsistencies, up to the first
+	// maxReportedInconsistencies of them.
+	Inconsistencies []string
+	// Repaired is true if the database is repaired.
+	Repaired bool
+}
+
+// Valid returns true if no invalid chunks or inconsistencies are found.
+func (r *ValidationReport) Valid() bool {
+	return len(r.InvalidChunks) == 0 && r.InconsistencyCount == 0
+}
+
+func (r *ValidationReport) inconsistent(format string, a ...interface{}) {
+	r.InconsistencyCount++
+	if len(r.Inconsistencies) < maxReportedInconsistencies {
+		r.Inconsistencies = append(r.Inconsistencies, fmt.Sprintf(format, a...))
+	}
+}
+
+// Validate checks the data of every stored chunk with the validators and
+// cross-checks all indexes against the retrieval data index, including the
+// gc and reserve sizes and the last bin ids. If the Repair option is set and
+// any problem is found, the database is repaired. It is meant to be used on
+// the database of a stopped node.
+func (db *DB) Validate(o *ValidateOptions) (report *ValidationReport, err error) {
+	if o == nil {
+		o = new(ValidateOptions)
+	}
+
+	db.batchMu.Lock()
+	defer db.batchMu.Unlock()
+
+	report = new(ValidationReport)
+	maxBinIDs, unindexed, gcCount, reserveCount, err := db.validateChunks(report, o)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.validateIndexes(report); err != nil {
+		return nil, err
+	}
+
+	for po, maxBinID := range maxBinIDs {
+		binID, err := db.binIDs.Get(uint64(po))
+		if err != nil {
+			return nil, err
+		}
+		if maxBinID > binID {
+			report.inconsistent("last bin id %d of bin %d is lower than bin id %d of a stored chunk", binID, po, maxBinID)
+		}
+	}
+
+	gcIndexCount, err := db.gcIndex.Count()
+	if err != nil {
+		return nil, err
+	}
+	if c := gcIndexCount - gcCount; c > 0 {
+		report.inconsistent("%d gc index entries do not match any chunk", c)
+	}
+	gcSize, err := db.gcSize.Get()
+	if err != nil {
+		return nil, err
+	}
+	if gcSize != uint64(gcIndexCount) {
+		report.inconsistent("gc size %d is not the number of gc index entries %d", gcSize, gcIndexCount)
+	}
+
+	reserveIndexCount, err := db.reserveIndex.Count()
+	if err != nil {
+		return nil, err
+	}
+	if c := reserveIndexCount - reserveCount; c > 0 {
+		report.inconsistent("%d reserve index entries do not match any chunk", c)
+	}
+	reserveSize, err := db.reserveSize.Get()
+	if err != nil {
+		return nil, err
+	}
+	if reserveSize != uint64(reserveIndexCount) {
+		report.inconsistent("reserve size %d is not the number of reserve index entries %d", reserveSize, reserveIndexCount)
+	}
+
+	if o.Repair && !report.Valid() {
+		if err := db.repair(report.InvalidChunks, unindexed, maxBinIDs); err != nil {
+			return nil, fmt.Errorf("repair: %w", err)
+		}
+		report.Repaired = true
+	}
+	return report, nil
+}
+
+// validateChunks validates every chunk in the retrieval data index and
+// checks the indexes that are keyed by its fields. It returns the maximal
+// bin id of the chunks in every bin, the chunks without their pull index
+// entries and the number of the chunks in the gc and reserve indexes.
+func (db *DB) validateChunks(report *ValidationReport, o *ValidateOptions) (maxBinIDs []uint64, unindexed []shed.Item, gcCount, reserveCount int, err error) {
+	maxBinIDs = make([]uint64, swarm.MaxPO+1)
+	err = db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		report.Chunks++
+		if o.Progress != nil {
+			defer o.Progress(report.Chunks)
+		}
+		addr := swarm.NewAddress(item.Address)
+
+		if po := db.po(addr); item.BinID > maxBinIDs[po] {
+			maxBinIDs[po] = item.BinID
+		}
+		if !validChunk(swarm.NewChunk(addr, item.Data), o.Validators) {
+			report.InvalidChunks = append(report.InvalidChunks, addr)
+		}
+
+		var inPull bool
+		i, err := db.pullIndex.Get(item)
+		switch {
+		case err == nil:
+			inPull = true
+			if !addr.Equal(swarm.NewAddress(i.Address)) {
+				report.inconsistent("pull index entry of chunk %s with bin id %d is of chunk %x", addr, item.BinID, i.Address)
+				unindexed = append(unindexed, shed.Item{Address: item.Address, BinID: item.BinID})
+			}
+		case errors.Is(err, leveldb.ErrNotFound):
+		default:
+			return true, err
+		}
+
+		pinned, err := db.pinIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		excluded, err := db.gcExcludeIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		i, err = db.retrievalAccessIndex.Get(item)
+		switch {
+		case err == nil:
+			item.AccessTimestamp = i.AccessTimestamp
+			item.AccessCount = i.AccessCount
+		case errors.Is(err, leveldb.ErrNotFound):
+			// the chunks which are not synced yet are not in the
+			// gc or reserve index, but they are all in the pull
+			// index, unlike the chunks from the retrieval requests
+			if !inPull {
+				report.inconsistent("chunk %s with bin id %d is not in pull index", addr, item.BinID)
+				unindexed = append(unindexed, shed.Item{Address: item.Address, BinID: item.BinID})
+			}
+			return false, nil
+		default:
+			return true, err
+		}
+
+		inGC, err := db.gcIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		inReserve, err := db.reserveIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		if inGC {
+			gcCount++
+		}
+		if inReserve {
+			reserveCount++
+		}
+		switch {
+		case inGC && inReserve:
+			report.inconsistent("chunk %s is in both gc and reserve index", addr)
+		case pinned && inGC && !excluded:
+			report.inconsistent("pinned chunk %s is in gc index", addr)
+		case !pinned && !inGC && !inReserve:
+			report.inconsistent("chunk %s is in neither gc nor reserve index", addr)
+		}
+		return false, nil
+	}, nil)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	return maxBinIDs, unindexed, gcCount, reserveCount, nil
+}
+
+// validateIndexes checks that the indexes derived from the retrieval data
+// index have no entries of the chunks which are not stored.
+func (db *DB) validateIndexes(report *ValidationReport) error {
+	for _, d := range db.derivedIndexes() {
+		err := d.index.Iterate(func(item shed.Item) (stop bool, err error) {
+			ok, err := db.matchesChunk(item, d.match)
+			if err != nil {
+				return true, err
+			}
+			if !ok {
+				report.inconsistent("%s entry of chunk %x does not match any chunk", d.name, item.Address)
+			}
+			return false, nil
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %w", d.name, err)
+		}
+	}
+	return nil
+}
+
+// derivedIndex is an index which entries are valid only if the chunks that
+// they reference are in the retrieval data index.
+type derivedIndex struct {
+	name  string
+	index shed.Index
+	// match returns true if the entry matches the stored chunk,
+	// it is not called if it is nil
+	match func(entry, chunk shed.Item) bool
+}
+
+// derivedIndexes returns the indexes which entries are checked against the
+// retrieval data index, except gc and reserve indexes, which are checked by
+// their counts.
+func (db *DB) derivedIndexes() []derivedIndex {
+	return []derivedIndex{
+		{name: "retrievalAccessIndex", index: db.retrievalAccessIndex},
+		{name: "pullIndex", index: db.pullIndex, match: func(entry, chunk shed.Item) bool {
+			return entry.BinID == chunk.BinID
+		}},
+		{name: "pushIndex", index: db.pushIndex, match: func(entry, chunk shed.Item) bool {
+			return entry.StoreTimestamp == chunk.StoreTimestamp
+		}},
+		{name: "pinIndex", index: db.pinIndex},
+		{name: "gcExcludeIndex", index: db.gcExcludeIndex},
+		{name: "stampIndex", index: db.stampIndex},
+	}
+}
+
+// matchesChunk returns true if the chunk of the item is stored and the item
+// matches it.
+func (db *DB) matchesChunk(item shed.Item, match func(entry, chunk shed.Item) bool) (bool, error) {
+	chunk, err := db.retrievalDataIndex.Get(item)
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return match == nil || match(item, chunk), nil
+}
+
+// repair removes the invalid chunks and the entries of the derived indexes
+// which do not match the stored chunks, re-creates the pull index entries of
+// the unindexed chunks from their bin ids, and rebuilds the gc and reserve
+// indexes, their sizes and the last bin ids from the retrieval data index.
+// The chunks which are not pinned or waiting to be push synced are garbage
+// collectable. This function must be called under batchMu lock.
+func (db *DB) repair(invalidChunks []swarm.Address, unindexed []shed.Item, maxBinIDs []uint64) error {
+	batch := new(leveldb.Batch)
+	write := func(force bool) error {
+		if batch.Len() < repairBatchSize && !force {
+			return nil
+		}
+		if err := db.shed.WriteBatch(batch); err != nil {
+			return err
+		}
+		batch.Reset()
+		return nil
+	}
+
+	for _, addr := range invalidChunks {
+		if err := db.retrievalDataIndex.DeleteInBatch(batch, addressToItem(addr)); err != nil {
+			return err
+		}
+		if err := write(false); err != nil {
+			return err
+		}
+	}
+	if err := write(true); err != nil {
+		return err
+	}
+
+	for _, d := range db.derivedIndexes() {
+		err := d.index.Iterate(func(item shed.Item) (stop bool, err error) {
+			ok, err := db.matchesChunk(item, d.match)
+			if err != nil {
+				return true, err
+			}
+			if ok {
+				return false, nil
+			}
+			if err := d.index.DeleteInBatch(batch, item); err != nil {
+				return true, err
+			}
+			return false, write(false)
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("%s: %w", d.name, err)
+		}
+	}
+	if err := write(true); err != nil {
+		return err
+	}
+
+	for _, item := range unindexed {
+		// the invalid chunks are removed
+		ok, err := db.matchesChunk(item, nil)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := db.pullIndex.PutInBatch(batch, item); err != nil {
+			return err
+		}
+		if err := write(false); err != nil {
+			return err
+		}
+	}
+	if err := write(true); err != nil {
+		return err
+	}
+
+	for _, name := range []string{db.gcPolicy.Name(), "PO|BinID->Hash", "Hash->nil"} {
+		if _, err := db.shed.TruncateIndex(name); err != nil {
+			return fmt.Errorf("truncate %s: %w", name, err)
+		}
+	}
+
+	var gcSize, reserveSize int64
+	err := db.retrievalDataIndex.Iterate(func(item shed.Item) (stop bool, err error) {
+		i, err := db.retrievalAccessIndex.Get(item)
+		switch {
+		case err == nil:
+			item.AccessTimestamp = i.AccessTimestamp
+			item.AccessCount = i.AccessCount
+		case errors.Is(err, leveldb.ErrNotFound):
+			// the chunks which are waiting
+			// to be push synced are not collectable
+			ok, err := db.pushIndex.Has(item)
+			if err != nil {
+				return true, err
+			}
+			if ok {
+				return false, nil
+			}
+			item.AccessTimestamp = item.StoreTimestamp
+			if err := db.retrievalAccessIndex.PutInBatch(batch, item); err != nil {
+				return true, err
+			}
+		default:
+			return true, err
+		}
+
+		ok, err := db.pinIndex.Has(item)
+		if err != nil {
+			return true, err
+		}
+		if ok {
+			return false, nil
+		}
+		c, r, err := db.putGCInBatch(batch, item)
+		if err != nil {
+			return true, err
+		}
+		gcSize += c
+		reserveSize += r
+		return false, write(false)
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	db.gcSize.PutInBatch(batch, uint64(gcSize))
+	db.reserveSize.PutInBatch(batch, uint64(reserveSize))
+	for po, maxBinID := range maxBinIDs {
+		binID, err := db.binIDs.Get(uint64(po))
+		if err != nil {
+			return err
+		}
+		if maxBinID > binID {
+			db.binIDs.PutInBatch(batch, uint64(po), maxBinID)
+		}
+	}
+	return write(true)
+}
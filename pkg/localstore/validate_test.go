@@ -0,0 +1,271 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package localstore
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/shed"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/validator"
+)
+
+// TestDB_Validate tests that the database with the chunks stored in all
+// modes is valid.
+func TestDB_Validate(t *testing.T) {
+	db := newTestDB(t, &Options{
+		Capacity:            100,
+		ReserveCapacity:     50,
+		NeighborhoodDepther: newTestDepther(1),
+	})
+
+	chunks := putValidateTestChunks(t, db)
+
+	var progress int64
+	report, err := db.Validate(&ValidateOptions{
+		Validators: []swarm.ChunkValidator{validator.NewContentAddressValidator()},
+		Progress: func(count int64) {
+			progress = count
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid() {
+		t.Fatalf("got invalid chunks %v and inconsistencies %v, want none", report.InvalidChunks, report.Inconsistencies)
+	}
+	if report.Chunks != int64(len(chunks)) {
+		t.Errorf("got %v chunks, want %v", report.Chunks, len(chunks))
+	}
+	if progress != int64(len(chunks)) {
+		t.Errorf("got progress %v, want %v", progress, len(chunks))
+	}
+	if report.Repaired {
+		t.Error("got repaired valid database")
+	}
+}
+
+// TestDB_Validate_repair tests that the invalid chunks and the inconsistent
+// indexes are reported and repaired.
+func TestDB_Validate_repair(t *testing.T) {
+	db := newTestDB(t, &Options{
+		Capacity:            100,
+		ReserveCapacity:     50,
+		NeighborhoodDepther: newTestDepther(1),
+	})
+
+	chunks := putValidateTestChunks(t, db)
+
+	// chunk with the data that does not match its address
+	invalid := chunks[0].Address()
+	item, err := db.retrievalDataIndex.Get(addressToItem(invalid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.Data = append([]byte(nil), item.Data...)
+	item.Data[len(item.Data)-1]++
+	if err := db.retrievalDataIndex.Put(item); err != nil {
+		t.Fatal(err)
+	}
+
+	// chunk missing in the gc and reserve indexes
+	lost := chunks[len(chunks)-1].Address()
+	item, err = db.retrievalDataIndex.Get(addressToItem(lost))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := db.retrievalAccessIndex.Get(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item.AccessTimestamp = i.AccessTimestamp
+	item.AccessCount = i.AccessCount
+	if err := db.gcIndex.Delete(item); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.reserveIndex.Delete(item); err != nil {
+		t.Fatal(err)
+	}
+
+	// uploaded chunk missing in the pull index
+	unindexed := chunks[2].Address()
+	item, err = db.retrievalDataIndex.Get(addressToItem(unindexed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.pullIndex.Delete(item); err != nil {
+		t.Fatal(err)
+	}
+
+	// pin index entry of a chunk which is not stored
+	if err := db.pinIndex.Put(shed.Item{
+		Address:    generateTestRandomChunk().Address().Bytes(),
+		PinCounter: 1,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// wrong gc size and bin ids
+	if err := db.gcSize.Put(1000); err != nil {
+		t.Fatal(err)
+	}
+	for po := uint64(0); po <= uint64(swarm.MaxPO); po++ {
+		if err := db.binIDs.Put(po, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	o := &ValidateOptions{
+		Validators: []swarm.ChunkValidator{validator.NewContentAddressValidator()},
+	}
+	report, err := db.Validate(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Valid() {
+		t.Fatal("got valid database")
+	}
+	if len(report.InvalidChunks) != 1 || !report.InvalidChunks[0].Equal(invalid) {
+		t.Errorf("got invalid chunks %v, want %v", report.InvalidChunks, invalid)
+	}
+	// lost chunk, unindexed chunk, dangling pin, gc size and at least one bin id
+	if report.InconsistencyCount < 5 {
+		t.Errorf("got %v inconsistencies %v, want at least 5", report.InconsistencyCount, report.Inconsistencies)
+	}
+	if !hasInconsistency(report, unindexed.String()) {
+		t.Errorf("got inconsistencies %v, want one of chunk %s", report.Inconsistencies, unindexed)
+	}
+	if report.Repaired {
+		t.Error("got repaired without the repair option")
+	}
+
+	o.Repair = true
+	report, err = db.Validate(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Repaired {
+		t.Fatal("got not repaired database")
+	}
+
+	o.Repair = false
+	report, err = db.Validate(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid() {
+		t.Fatalf("got invalid chunks %v and inconsistencies %v after repair, want none", report.InvalidChunks, report.Inconsistencies)
+	}
+	if report.Chunks != int64(len(chunks)-1) {
+		t.Errorf("got %v chunks, want %v", report.Chunks, len(chunks)-1)
+	}
+
+	t.Run("gc size", newIndexGCSizeTest(db))
+
+	t.Run("reserve size", newIndexReserveSizeTest(db))
+
+	t.Run("invalid chunk removed", func(t *testing.T) {
+		_, err := db.Get(context.Background(), storage.ModeGetLookup, invalid)
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+
+	t.Run("pinned chunk", func(t *testing.T) {
+		ok, err := db.pinIndex.Has(addressToItem(chunks[1].Address()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Error("pinned chunk not in pin index")
+		}
+	})
+
+	t.Run("pull index", func(t *testing.T) {
+		item, err := db.retrievalDataIndex.Get(addressToItem(unindexed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		i, err := db.pullIndex.Get(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !unindexed.Equal(swarm.NewAddress(i.Address)) {
+			t.Errorf("got pull index entry of chunk %x, want %s", i.Address, unindexed)
+		}
+	})
+
+	t.Run("bin ids", func(t *testing.T) {
+		for _, ch := range chunks[1:] {
+			item, err := db.retrievalDataIndex.Get(addressToItem(ch.Address()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			binID, err := db.binIDs.Get(uint64(db.po(ch.Address())))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if binID < item.BinID {
+				t.Errorf("got last bin id %v lower than chunk bin id %v", binID, item.BinID)
+			}
+		}
+	})
+}
+
+// putValidateTestChunks stores valid chunks in the database with all put
+// modes, the second one of them pinned and the last one synced.
+func putValidateTestChunks(t *testing.T, db *DB) (chunks []swarm.Chunk) {
+	t.Helper()
+
+	for _, mode := range []storage.ModePut{
+		storage.ModePutUpload,
+		storage.ModePutSync,
+		storage.ModePutRequest,
+	} {
+		for i := 0; i < 10; i++ {
+			data := make([]byte, 100)
+			if _, err := rand.Read(data); err != nil {
+				t.Fatal(err)
+			}
+			ch, err := content.NewChunk(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.Put(context.Background(), mode, ch); err != nil {
+				t.Fatal(err)
+			}
+			chunks = append(chunks, ch)
+		}
+	}
+	if err := db.Set(context.Background(), storage.ModeSetPin, chunks[1].Address()); err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range chunks[10:20] {
+		if err := db.Set(context.Background(), storage.ModeSetSyncPull, ch.Address()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Set(context.Background(), storage.ModeSetSyncPush, chunks[len(chunks)-1].Address()); err != nil {
+		t.Fatal(err)
+	}
+	return chunks
+}
+
+// hasInconsistency returns true if any of the reported inconsistencies
+// contains the substring.
+func hasInconsistency(report *ValidationReport, substr string) bool {
+	for _, i := range report.Inconsistencies {
+		if strings.Contains(i, substr) {
+			return true
+		}
+	}
+	return false
+}
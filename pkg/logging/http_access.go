@@ -5,6 +5,7 @@
 package logging
 
 import (
+	"bufio"
 	"net"
 	"net/http"
 	"time"
@@ -99,6 +100,11 @@ func (l *responseLogger) Push(target string, opts *http.PushOptions) error {
 	return l.w.(http.Pusher).Push(target, opts)
 }
 
+func (l *responseLogger) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	// Hijacker interface is required by the websocket upgrader
+	return l.w.(http.Hijacker).Hijack()
+}
+
 func (l *responseLogger) Write(b []byte) (int, error) {
 	size, err := l.w.Write(b)
 	l.size += size
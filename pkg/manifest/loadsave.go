@@ -0,0 +1,60 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// storeLoadSaver stores manifest nodes as chunks.
+type storeLoadSaver struct {
+	storer    storage.Storer
+	toEncrypt bool
+}
+
+// NewLoadSaver creates a new LoadSaver that splits serialized nodes into
+// chunks and stores them in the provided storer.
+func NewLoadSaver(storer storage.Storer) LoadSaver {
+	return &storeLoadSaver{
+		storer: storer,
+	}
+}
+
+// NewEncryptingLoadSaver creates a new LoadSaver that stores serialized nodes
+// as encrypted chunks. Node references are then encrypted references.
+func NewEncryptingLoadSaver(storer storage.Storer) LoadSaver {
+	return &storeLoadSaver{
+		storer:    storer,
+		toEncrypt: true,
+	}
+}
+
+// Load implements LoadSaver.
+func (s *storeLoadSaver) Load(ctx context.Context, reference swarm.Address) ([]byte, error) {
+	j := joiner.NewSimpleJoiner(s.storer)
+	buf := bytes.NewBuffer(nil)
+	if _, err := file.JoinReadAll(j, reference, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Save implements LoadSaver.
+func (s *storeLoadSaver) Save(ctx context.Context, data []byte) (swarm.Address, error) {
+	var sp file.Splitter
+	if s.toEncrypt {
+		sp = splitter.NewEncryptingSplitter(s.storer)
+	} else {
+		sp = splitter.NewSimpleSplitter(s.storer)
+	}
+	return file.SplitWriteAll(ctx, sp, bytes.NewReader(data), int64(len(data)))
+}
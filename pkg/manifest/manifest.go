@@ -0,0 +1,146 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package manifest provides a path-addressable collection of file entries.
+//
+// A manifest is a radix trie keyed by the bytes of a path, where every path
+// terminates in a reference to a collection/entry.Entry. Every trie node is
+// stored as a separate blob of data through a LoadSaver, so only the nodes on
+// the path that is being looked up need to be retrieved.
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/collection"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// ContentType is the mime type set in the metadata of an entry which
+// references a manifest.
+const ContentType = "application/bzz-manifest+octet-stream"
+
+var _ collection.Collection = (*Manifest)(nil)
+
+var (
+	// ErrNotFound is returned when there is no entry for the given path.
+	ErrNotFound = errors.New("manifest: not found")
+	// ErrInvalidPath is returned when the path can not be stored in the manifest.
+	ErrInvalidPath = errors.New("manifest: invalid path")
+	// ErrInvalidNode is returned when a stored manifest node can not be decoded.
+	ErrInvalidNode = errors.New("manifest: invalid node")
+)
+
+// LoadSaver loads and saves serialized manifest nodes.
+type LoadSaver interface {
+	Load(ctx context.Context, reference swarm.Address) (data []byte, err error)
+	Save(ctx context.Context, data []byte) (reference swarm.Address, err error)
+}
+
+// WalkFunc is called by Walk for every path that has an entry.
+type WalkFunc func(path string, entry swarm.Address) error
+
+// Manifest maps paths to entry references.
+// Implements collection.Collection.
+type Manifest struct {
+	ls   LoadSaver
+	root *node
+}
+
+// New creates a new empty Manifest.
+func New(ls LoadSaver) *Manifest {
+	return &Manifest{
+		ls:   ls,
+		root: newNode(),
+	}
+}
+
+// NewFromReference creates a Manifest from the reference of its stored root
+// node. Nodes are loaded lazily, when they are needed.
+func NewFromReference(ls LoadSaver, reference swarm.Address) *Manifest {
+	return &Manifest{
+		ls:   ls,
+		root: newNodeRef(reference),
+	}
+}
+
+// Add sets the entry reference for the given path, replacing any existing one.
+func (m *Manifest) Add(ctx context.Context, path string, entry swarm.Address) error {
+	p, err := cleanPath(path)
+	if err != nil {
+		return err
+	}
+	if entry.IsZero() {
+		return errors.New("manifest: empty entry reference")
+	}
+	return m.root.add(ctx, m.ls, p, entry)
+}
+
+// Remove deletes the entry for the given path.
+func (m *Manifest) Remove(ctx context.Context, path string) error {
+	p, err := cleanPath(path)
+	if err != nil {
+		return err
+	}
+	return m.root.remove(ctx, m.ls, p)
+}
+
+// Lookup returns the entry reference for the given path.
+func (m *Manifest) Lookup(ctx context.Context, path string) (swarm.Address, error) {
+	p, err := cleanPath(path)
+	if err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return m.root.lookup(ctx, m.ls, p)
+}
+
+// Walk calls fn for every path in the manifest in lexicographic order,
+// loading all nodes that are not yet in memory.
+func (m *Manifest) Walk(ctx context.Context, fn WalkFunc) error {
+	return m.root.walk(ctx, m.ls, nil, fn)
+}
+
+// WalkNodes calls fn with the references of all stored nodes of the manifest,
+// parents before their children and forks in lexicographic order of their
+// prefixes. Nodes that are modified and not stored yet are skipped.
+func (m *Manifest) WalkNodes(ctx context.Context, fn func(reference swarm.Address) error) error {
+	return m.root.walkNodes(ctx, m.ls, fn)
+}
+
+// Load brings all nodes of the manifest in memory.
+func (m *Manifest) Load(ctx context.Context) error {
+	return m.Walk(ctx, func(string, swarm.Address) error { return nil })
+}
+
+// Store saves all modified nodes and returns the reference of the root node.
+func (m *Manifest) Store(ctx context.Context) (swarm.Address, error) {
+	if err := m.root.save(ctx, m.ls); err != nil {
+		return swarm.ZeroAddress, fmt.Errorf("manifest store: %w", err)
+	}
+	return m.root.ref, nil
+}
+
+// Addresses implements collection.Collection.
+//
+// It returns entry references, in lexicographic order of their paths, of
+// all nodes in memory. Load should be called before on manifests created
+// by NewFromReference.
+func (m *Manifest) Addresses() (addrs []swarm.Address) {
+	m.root.walkLoaded(func(entry swarm.Address) {
+		addrs = append(addrs, entry)
+	})
+	return addrs
+}
+
+// cleanPath validates and normalizes the path to be stored in the trie.
+func cleanPath(path string) ([]byte, error) {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" || len(path) > maxPrefixLength {
+		return nil, ErrInvalidPath
+	}
+	return []byte(path), nil
+}
@@ -0,0 +1,259 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/swarm/test"
+)
+
+var testPaths = []string{
+	"index.html",
+	"img/1.png",
+	"img/2.png",
+	"img/20.png",
+	"img",
+	"robots.txt",
+	"css/style.css",
+	"css/style.css.map",
+}
+
+// TestManifestAddLookup checks that all added paths can be resolved,
+// both from the in-memory and the stored manifest.
+func TestManifestAddLookup(t *testing.T) {
+	ctx := context.Background()
+	ls := manifest.NewLoadSaver(mock.NewStorer())
+	m := manifest.New(ls)
+
+	entries := make(map[string]swarm.Address)
+	for _, p := range testPaths {
+		entries[p] = test.RandomAddress()
+		if err := m.Add(ctx, p, entries[p]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkEntries := func(t *testing.T, m *manifest.Manifest) {
+		t.Helper()
+		for p, want := range entries {
+			got, err := m.Lookup(ctx, p)
+			if err != nil {
+				t.Fatalf("lookup %q: %v", p, err)
+			}
+			if !got.Equal(want) {
+				t.Fatalf("lookup %q: got %s, want %s", p, got, want)
+			}
+		}
+		for _, p := range []string{"img/", "img/3.png", "css/style", "index.htm", "index.html5"} {
+			if _, err := m.Lookup(ctx, p); !errors.Is(err, manifest.ErrNotFound) {
+				t.Fatalf("lookup %q: got error %v, want %v", p, err, manifest.ErrNotFound)
+			}
+		}
+	}
+
+	checkEntries(t, m)
+
+	ref, err := m.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkEntries(t, manifest.NewFromReference(ls, ref))
+
+	// storing the same paths in a different order must result in the same reference
+	m = manifest.New(ls)
+	for i := len(testPaths) - 1; i >= 0; i-- {
+		if err := m.Add(ctx, testPaths[i], entries[testPaths[i]]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ref2, err := m.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ref.Equal(ref2) {
+		t.Fatalf("got reference %s, want %s", ref2, ref)
+	}
+}
+
+// TestManifestRemove checks that removed paths are not resolved and that the
+// manifest is equal to the one that never had them.
+func TestManifestRemove(t *testing.T) {
+	ctx := context.Background()
+	ls := manifest.NewLoadSaver(mock.NewStorer())
+
+	entries := make(map[string]swarm.Address)
+	full := manifest.New(ls)
+	for _, p := range testPaths {
+		entries[p] = test.RandomAddress()
+		if err := full.Add(ctx, p, entries[p]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fullRef, err := full.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed := map[string]bool{"img/2.png": true, "img": true, "css/style.css": true}
+
+	partial := manifest.New(ls)
+	for _, p := range testPaths {
+		if removed[p] {
+			continue
+		}
+		if err := partial.Add(ctx, p, entries[p]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := partial.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := manifest.NewFromReference(ls, fullRef)
+	for p := range removed {
+		if err := m.Remove(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.Lookup(ctx, p); !errors.Is(err, manifest.ErrNotFound) {
+			t.Fatalf("lookup %q: got error %v, want %v", p, err, manifest.ErrNotFound)
+		}
+	}
+	if err := m.Remove(ctx, "img/2.png"); !errors.Is(err, manifest.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, manifest.ErrNotFound)
+	}
+
+	got, err := m.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(want) {
+		t.Fatalf("got reference %s, want %s", got, want)
+	}
+}
+
+// TestManifestWalk checks that paths are walked in lexicographic order and
+// that Addresses returns entries in the same order.
+func TestManifestWalk(t *testing.T) {
+	ctx := context.Background()
+	ls := manifest.NewLoadSaver(mock.NewStorer())
+	m := manifest.New(ls)
+
+	entries := make(map[string]swarm.Address)
+	for _, p := range testPaths {
+		entries[p] = test.RandomAddress()
+		if err := m.Add(ctx, p, entries[p]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ref, err := m.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantPaths := []string{
+		"css/style.css",
+		"css/style.css.map",
+		"img",
+		"img/1.png",
+		"img/2.png",
+		"img/20.png",
+		"index.html",
+		"robots.txt",
+	}
+
+	m = manifest.NewFromReference(ls, ref)
+	if l := len(m.Addresses()); l != 0 {
+		t.Fatalf("got %v addresses before load, want none", l)
+	}
+
+	var gotPaths []string
+	if err := m.Walk(ctx, func(path string, entry swarm.Address) error {
+		if !entry.Equal(entries[path]) {
+			t.Fatalf("walk %q: got entry %s, want %s", path, entry, entries[path])
+		}
+		gotPaths = append(gotPaths, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotPaths) != len(wantPaths) {
+		t.Fatalf("got paths %v, want %v", gotPaths, wantPaths)
+	}
+	for i, p := range wantPaths {
+		if gotPaths[i] != p {
+			t.Fatalf("got paths %v, want %v", gotPaths, wantPaths)
+		}
+	}
+
+	addrs := m.Addresses()
+	if len(addrs) != len(wantPaths) {
+		t.Fatalf("got %v addresses, want %v", len(addrs), len(wantPaths))
+	}
+	for i, p := range wantPaths {
+		if !addrs[i].Equal(entries[p]) {
+			t.Fatalf("address %v: got %s, want %s", i, addrs[i], entries[p])
+		}
+	}
+}
+
+// TestManifestWalkNodes checks that the references of all stored nodes are
+// walked, starting with the root node.
+func TestManifestWalkNodes(t *testing.T) {
+	ctx := context.Background()
+	ls := manifest.NewLoadSaver(mock.NewStorer())
+	m := manifest.New(ls)
+
+	for _, p := range testPaths {
+		if err := m.Add(ctx, p, test.RandomAddress()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ref, err := m.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m = manifest.NewFromReference(ls, ref)
+	var refs []swarm.Address
+	seen := make(map[string]bool)
+	if err := m.WalkNodes(ctx, func(reference swarm.Address) error {
+		if seen[reference.String()] {
+			t.Fatalf("node %s walked twice", reference)
+		}
+		seen[reference.String()] = true
+		if _, err := ls.Load(ctx, reference); err != nil {
+			t.Fatalf("load node %s: %v", reference, err)
+		}
+		refs = append(refs, reference)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(refs) == 0 || !refs[0].Equal(ref) {
+		t.Fatalf("got first node %v, want root %s", refs, ref)
+	}
+	// every path ends at a separate node
+	if len(refs) <= len(testPaths) {
+		t.Fatalf("got %v nodes, want more than %v", len(refs), len(testPaths))
+	}
+}
+
+func TestManifestInvalidPath(t *testing.T) {
+	m := manifest.New(manifest.NewLoadSaver(mock.NewStorer()))
+	for _, p := range []string{"", "/"} {
+		if err := m.Add(context.Background(), p, test.RandomAddress()); !errors.Is(err, manifest.ErrInvalidPath) {
+			t.Fatalf("add %q: got error %v, want %v", p, err, manifest.ErrInvalidPath)
+		}
+	}
+}
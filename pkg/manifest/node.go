@@ -0,0 +1,346 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	// maxPrefixLength is the maximal length of a single fork prefix,
+	// limited by the two bytes that encode it.
+	maxPrefixLength = 1<<16 - 1
+
+	// flagHasEntry is set when a path ends at the node.
+	flagHasEntry byte = 1
+)
+
+// node is a single node of the manifest radix trie.
+//
+// A node that is only known by its reference is not loaded, and its entry
+// and forks are retrieved from the LoadSaver on the first access. A node
+// which is modified has its reference reset, until it is saved again.
+type node struct {
+	ref    swarm.Address  // reference of the stored node, zero if modified
+	loaded bool           // whether entry and forks are available
+	entry  swarm.Address  // entry reference, zero if no path ends here
+	forks  map[byte]*fork // child nodes, keyed by the first byte of the prefix
+}
+
+// fork is an edge of the trie, labeled with a non-empty prefix.
+type fork struct {
+	prefix []byte
+	node   *node
+}
+
+func newNode() *node {
+	return &node{
+		loaded: true,
+		forks:  make(map[byte]*fork),
+	}
+}
+
+func newNodeRef(ref swarm.Address) *node {
+	return &node{
+		ref: ref,
+	}
+}
+
+// load retrieves the node data if it is not loaded already.
+func (n *node) load(ctx context.Context, ls LoadSaver) error {
+	if n.loaded {
+		return nil
+	}
+	data, err := ls.Load(ctx, n.ref)
+	if err != nil {
+		return fmt.Errorf("load node %s: %w", n.ref, err)
+	}
+	if err := n.UnmarshalBinary(data); err != nil {
+		return fmt.Errorf("load node %s: %w", n.ref, err)
+	}
+	return nil
+}
+
+func (n *node) add(ctx context.Context, ls LoadSaver, path []byte, entry swarm.Address) error {
+	if err := n.load(ctx, ls); err != nil {
+		return err
+	}
+	n.ref = swarm.ZeroAddress
+
+	if len(path) == 0 {
+		n.entry = entry
+		return nil
+	}
+
+	f, ok := n.forks[path[0]]
+	if !ok {
+		child := newNode()
+		child.entry = entry
+		n.forks[path[0]] = &fork{prefix: path, node: child}
+		return nil
+	}
+
+	c := commonPrefixLength(f.prefix, path)
+	if c < len(f.prefix) {
+		// split the fork at the end of the common prefix
+		mid := newNode()
+		mid.forks[f.prefix[c]] = &fork{prefix: f.prefix[c:], node: f.node}
+		f.prefix = f.prefix[:c]
+		f.node = mid
+	}
+	return f.node.add(ctx, ls, path[c:], entry)
+}
+
+func (n *node) remove(ctx context.Context, ls LoadSaver, path []byte) error {
+	if err := n.load(ctx, ls); err != nil {
+		return err
+	}
+
+	if len(path) == 0 {
+		if n.entry.IsZero() {
+			return ErrNotFound
+		}
+		n.entry = swarm.ZeroAddress
+		n.ref = swarm.ZeroAddress
+		return nil
+	}
+
+	f, ok := n.forks[path[0]]
+	if !ok || !bytes.HasPrefix(path, f.prefix) {
+		return ErrNotFound
+	}
+	if err := f.node.remove(ctx, ls, path[len(f.prefix):]); err != nil {
+		return err
+	}
+	n.ref = swarm.ZeroAddress
+
+	// keep the trie compact by removing empty nodes
+	// and merging nodes with a single fork into their parent
+	if !f.node.entry.IsZero() {
+		return nil
+	}
+	switch len(f.node.forks) {
+	case 0:
+		delete(n.forks, path[0])
+	case 1:
+		for _, cf := range f.node.forks {
+			if len(f.prefix)+len(cf.prefix) > maxPrefixLength {
+				break
+			}
+			f.prefix = append(append([]byte{}, f.prefix...), cf.prefix...)
+			f.node = cf.node
+		}
+	}
+	return nil
+}
+
+func (n *node) lookup(ctx context.Context, ls LoadSaver, path []byte) (swarm.Address, error) {
+	if err := n.load(ctx, ls); err != nil {
+		return swarm.ZeroAddress, err
+	}
+
+	if len(path) == 0 {
+		if n.entry.IsZero() {
+			return swarm.ZeroAddress, ErrNotFound
+		}
+		return n.entry, nil
+	}
+
+	f, ok := n.forks[path[0]]
+	if !ok || !bytes.HasPrefix(path, f.prefix) {
+		return swarm.ZeroAddress, ErrNotFound
+	}
+	return f.node.lookup(ctx, ls, path[len(f.prefix):])
+}
+
+func (n *node) walk(ctx context.Context, ls LoadSaver, prefix []byte, fn WalkFunc) error {
+	if err := n.load(ctx, ls); err != nil {
+		return err
+	}
+	if !n.entry.IsZero() {
+		if err := fn(string(prefix), n.entry); err != nil {
+			return err
+		}
+	}
+	for _, f := range n.sortedForks() {
+		p := append(append([]byte{}, prefix...), f.prefix...)
+		if err := f.node.walk(ctx, ls, p, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *node) walkNodes(ctx context.Context, ls LoadSaver, fn func(swarm.Address) error) error {
+	if !n.ref.IsZero() {
+		if err := fn(n.ref); err != nil {
+			return err
+		}
+	}
+	if err := n.load(ctx, ls); err != nil {
+		return err
+	}
+	for _, f := range n.sortedForks() {
+		if err := f.node.walkNodes(ctx, ls, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkLoaded calls fn for entries of all loaded nodes, without
+// retrieving the ones that are not.
+func (n *node) walkLoaded(fn func(entry swarm.Address)) {
+	if !n.loaded {
+		return
+	}
+	if !n.entry.IsZero() {
+		fn(n.entry)
+	}
+	for _, f := range n.sortedForks() {
+		f.node.walkLoaded(fn)
+	}
+}
+
+// save stores all modified nodes depth first, as a parent node
+// needs references of all of its children.
+func (n *node) save(ctx context.Context, ls LoadSaver) error {
+	if !n.ref.IsZero() {
+		return nil
+	}
+	for _, f := range n.forks {
+		if err := f.node.save(ctx, ls); err != nil {
+			return err
+		}
+	}
+	data, err := n.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	ref, err := ls.Save(ctx, data)
+	if err != nil {
+		return err
+	}
+	n.ref = ref
+	return nil
+}
+
+func (n *node) sortedForks() []*fork {
+	keys := make([]int, 0, len(n.forks))
+	for k := range n.forks {
+		keys = append(keys, int(k))
+	}
+	sort.Ints(keys)
+	forks := make([]*fork, len(keys))
+	for i, k := range keys {
+		forks[i] = n.forks[byte(k)]
+	}
+	return forks
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The node is serialized as:
+//   - 1 byte size of all references in the node
+//   - 1 byte flags
+//   - the entry reference, if the node has an entry
+//   - for every fork, ordered by prefix: 2 bytes big-endian prefix length,
+//     the prefix and the child node reference
+func (n *node) MarshalBinary() ([]byte, error) {
+	refSize := 0
+	checkSize := func(ref swarm.Address) error {
+		l := len(ref.Bytes())
+		if refSize == 0 {
+			refSize = l
+		}
+		if l == 0 || l > 255 || l != refSize {
+			return fmt.Errorf("invalid reference size %d", l)
+		}
+		return nil
+	}
+
+	var flags byte
+	if !n.entry.IsZero() {
+		if err := checkSize(n.entry); err != nil {
+			return nil, err
+		}
+		flags |= flagHasEntry
+	}
+	forks := n.sortedForks()
+	for _, f := range forks {
+		if err := checkSize(f.node.ref); err != nil {
+			return nil, err
+		}
+	}
+	if refSize == 0 {
+		refSize = swarm.HashSize
+	}
+
+	b := []byte{byte(refSize), flags}
+	b = append(b, n.entry.Bytes()...)
+	for _, f := range forks {
+		l := make([]byte, 2)
+		binary.BigEndian.PutUint16(l, uint16(len(f.prefix)))
+		b = append(b, l...)
+		b = append(b, f.prefix...)
+		b = append(b, f.node.ref.Bytes()...)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (n *node) UnmarshalBinary(b []byte) error {
+	if len(b) < 2 || b[0] == 0 {
+		return ErrInvalidNode
+	}
+	refSize, flags := int(b[0]), b[1]
+	b = b[2:]
+
+	var entry swarm.Address
+	if flags&flagHasEntry != 0 {
+		if len(b) < refSize {
+			return ErrInvalidNode
+		}
+		entry = swarm.NewAddress(append([]byte{}, b[:refSize]...))
+		b = b[refSize:]
+	}
+
+	forks := make(map[byte]*fork)
+	for len(b) > 0 {
+		if len(b) < 2 {
+			return ErrInvalidNode
+		}
+		l := int(binary.BigEndian.Uint16(b))
+		b = b[2:]
+		if l == 0 || len(b) < l+refSize {
+			return ErrInvalidNode
+		}
+		prefix := append([]byte{}, b[:l]...)
+		if _, ok := forks[prefix[0]]; ok {
+			return ErrInvalidNode
+		}
+		ref := swarm.NewAddress(append([]byte{}, b[l:l+refSize]...))
+		forks[prefix[0]] = &fork{prefix: prefix, node: newNodeRef(ref)}
+		b = b[l+refSize:]
+	}
+
+	n.entry = entry
+	n.forks = forks
+	n.loaded = true
+	return nil
+}
+
+func commonPrefixLength(a, b []byte) (i int) {
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/retrieval"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -18,16 +19,26 @@ type store struct {
 	storage.Storer
 
 	retrieval  retrieval.Interface
+	validStamp postage.ValidStampFn
 	validators []swarm.ChunkValidator
 }
 
-// New returns a new NetStore that wraps a given Storer.
-func New(s storage.Storer, r retrieval.Interface, validators ...swarm.ChunkValidator) storage.Storer {
-	return &store{Storer: s, retrieval: r, validators: validators}
+var (
+	// ErrNoStamp is returned by Put when the chunk has no postage stamp
+	// and the stamps are validated.
+	ErrNoStamp = errors.New("chunk has no postage stamp")
+)
+
+// New returns a new NetStore that wraps a given Storer. The postage stamps of
+// the stored chunks are validated with validStamp, unless it is nil.
+func New(s storage.Storer, validStamp postage.ValidStampFn, r retrieval.Interface, validators ...swarm.ChunkValidator) storage.Storer {
+	return &store{Storer: s, retrieval: r, validStamp: validStamp, validators: validators}
 }
 
 // Get retrieves a given chunk address.
 // It will request a chunk from the network whenever it cannot be found locally.
+// If the chunk cannot be retrieved from the network either, the returned
+// error wraps storage.ErrNotFound.
 func (s *store) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (ch swarm.Chunk, err error) {
 	ch, err = s.Storer.Get(ctx, mode, addr)
 	if err != nil {
@@ -35,7 +46,11 @@ func (s *store) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Addres
 			// request from network
 			data, err := s.retrieval.RetrieveChunk(ctx, addr)
 			if err != nil {
-				return nil, fmt.Errorf("netstore retrieve chunk: %w", err)
+				if ctx.Err() != nil {
+					return nil, fmt.Errorf("netstore retrieve chunk: %w", ctx.Err())
+				}
+				// the chunk which no peer delivers is not found
+				return nil, fmt.Errorf("netstore retrieve chunk: %w: %v", storage.ErrNotFound, err)
 			}
 
 			ch = swarm.NewChunk(addr, data)
@@ -44,7 +59,9 @@ func (s *store) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Addres
 			}
 
 			_, err = s.Storer.Put(ctx, storage.ModePutRequest, ch)
-			if err != nil {
+			// the retrieved chunk is returned even if it is
+			// not cached because of the low disk space
+			if err != nil && !errors.Is(err, storage.ErrLowDiskSpace) {
 				return nil, fmt.Errorf("netstore retrieve put: %w", err)
 			}
 			return ch, nil
@@ -62,10 +79,31 @@ func (s *store) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chun
 		if !s.valid(ch) {
 			return nil, storage.ErrInvalidChunk
 		}
+		if err := s.validateStamp(ch); err != nil {
+			return nil, fmt.Errorf("netstore put chunk %s: %w", ch.Address(), err)
+		}
 	}
 	return s.Storer.Put(ctx, mode, chs...)
 }
 
+// validateStamp checks the postage stamp of the chunk if the stamps are
+// validated.
+func (s *store) validateStamp(ch swarm.Chunk) error {
+	if s.validStamp == nil {
+		return nil
+	}
+	stamp := ch.Stamp()
+	if stamp == nil {
+		return ErrNoStamp
+	}
+	b, err := stamp.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = s.validStamp(ch, b)
+	return err
+}
+
 // checks if a particular chunk is valid using the built in validators
 func (s *store) valid(ch swarm.Chunk) (ok bool) {
 	for _, v := range s.validators {
@@ -7,10 +7,12 @@ package netstore_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"sync/atomic"
 	"testing"
 
 	"github.com/ethersphere/bee/pkg/netstore"
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -93,15 +95,115 @@ func TestNetstoreNoRetrieval(t *testing.T) {
 	}
 }
 
+// TestNetstoreRetrievalLowDiskSpace verifies that the retrieved chunk is
+// returned even if it is not stored because of the low disk space.
+func TestNetstoreRetrievalLowDiskSpace(t *testing.T) {
+	retrieve := &retrievalMock{}
+	store := lowDiskSpaceStorer{Storer: mock.NewStorer()}
+	nstore := netstore.New(store, nil, retrieve, mockValidator{})
+
+	addr := swarm.MustParseHexAddress("000001")
+	d, err := nstore.Get(context.Background(), storage.ModeGetRequest, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(d.Data(), chunkData) {
+		t.Fatal("chunk data not equal to expected data")
+	}
+	if retrieve.callCount != 1 {
+		t.Fatalf("call count %d", retrieve.callCount)
+	}
+}
+
+// TestNetstorePutStamp verifies that the postage stamps of the chunks are
+// validated on put.
+func TestNetstorePutStamp(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	validBatchID := make([]byte, 32)
+	validStamp := func(ch swarm.Chunk, stampBytes []byte) (swarm.Chunk, error) {
+		stamp := new(postage.Stamp)
+		if err := stamp.UnmarshalBinary(stampBytes); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(stamp.BatchID(), validBatchID) {
+			return nil, errInvalid
+		}
+		return ch.WithStamp(stamp), nil
+	}
+	store := mock.NewStorer()
+	nstore := netstore.New(store, validStamp, &retrievalMock{}, mockValidator{})
+
+	for _, tc := range []struct {
+		name  string
+		stamp swarm.Stamp
+		err   error
+	}{
+		{"no stamp", nil, netstore.ErrNoStamp},
+		{"invalid stamp", postage.NewStamp([]byte{1}, make([]byte, 8), make([]byte, 65)), errInvalid},
+		{"valid stamp", postage.NewStamp(validBatchID, make([]byte, 8), make([]byte, 65)), nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := swarm.MustParseHexAddress("000001")
+			ch := swarm.NewChunk(addr, chunkData)
+			if tc.stamp != nil {
+				ch = ch.WithStamp(tc.stamp)
+			}
+			_, err := nstore.Put(context.Background(), storage.ModePutUpload, ch)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+			has, err := store.Has(context.Background(), addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if has != (tc.err == nil) {
+				t.Fatalf("got stored %v, want %v", has, tc.err == nil)
+			}
+		})
+	}
+}
+
 // returns a mock retrieval protocol, a mock local storage and a netstore
+// TestNetstoreRetrievalNotFound verifies that a chunk which cannot be
+// retrieved from the network is not found, unless the retrieval is canceled.
+func TestNetstoreRetrievalNotFound(t *testing.T) {
+	nstore := netstore.New(mock.NewStorer(), nil, failingRetrieval{}, mockValidator{})
+	addr := swarm.MustParseHexAddress("000001")
+
+	_, err := nstore.Get(context.Background(), storage.ModeGetRequest, addr)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = nstore.Get(ctx, storage.ModeGetRequest, addr)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
 func newRetrievingNetstore() (ret *retrievalMock, mockStore storage.Storer, ns storage.Storer) {
 	retrieve := &retrievalMock{}
 	store := mock.NewStorer()
-	nstore := netstore.New(store, retrieve, mockValidator{})
+	nstore := netstore.New(store, nil, retrieve, mockValidator{})
 
 	return retrieve, store, nstore
 }
 
+// lowDiskSpaceStorer does not store the chunks put with
+// ModePutRequest mode as if the disk space is low.
+type lowDiskSpaceStorer struct {
+	storage.Storer
+}
+
+func (s lowDiskSpaceStorer) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) (exist []bool, err error) {
+	if mode == storage.ModePutRequest {
+		return nil, storage.ErrLowDiskSpace
+	}
+	return s.Storer.Put(ctx, mode, chs...)
+}
+
 type retrievalMock struct {
 	called    bool
 	callCount int32
@@ -114,3 +216,10 @@ func (r *retrievalMock) RetrieveChunk(ctx context.Context, addr swarm.Address) (
 	r.addr = addr
 	return chunkData, nil
 }
+
+// failingRetrieval fails to retrieve any chunk, as if no peer had it.
+type failingRetrieval struct{}
+
+func (failingRetrieval) RetrieveChunk(ctx context.Context, addr swarm.Address) (data []byte, err error) {
+	return nil, errors.New("no peer delivered the chunk")
+}
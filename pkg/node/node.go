@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math/big"
 	"net"
 	"net/http"
 	"path/filepath"
@@ -17,6 +18,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/accounting"
 	"github.com/ethersphere/bee/pkg/addressbook"
 	"github.com/ethersphere/bee/pkg/api"
 	"github.com/ethersphere/bee/pkg/crypto"
@@ -33,12 +35,23 @@ import (
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/libp2p"
 	"github.com/ethersphere/bee/pkg/pingpong"
+	"github.com/ethersphere/bee/pkg/pinning"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchservice"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	"github.com/ethersphere/bee/pkg/pricer"
+	"github.com/ethersphere/bee/pkg/pss"
 	"github.com/ethersphere/bee/pkg/puller"
 	"github.com/ethersphere/bee/pkg/pullsync"
 	"github.com/ethersphere/bee/pkg/pullsync/pullstorage"
 	"github.com/ethersphere/bee/pkg/pusher"
 	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/retrieval"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/soc"
 	"github.com/ethersphere/bee/pkg/statestore/leveldb"
 	mockinmem "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -52,23 +65,32 @@ import (
 )
 
 type Bee struct {
-	p2pService       io.Closer
-	p2pCancel        context.CancelFunc
-	apiServer        *http.Server
-	debugAPIServer   *http.Server
-	errorLogWriter   *io.PipeWriter
-	tracerCloser     io.Closer
-	stateStoreCloser io.Closer
-	localstoreCloser io.Closer
-	topologyCloser   io.Closer
-	pusherCloser     io.Closer
-	pullerCloser     io.Closer
-	pullSyncCloser   io.Closer
+	p2pService           io.Closer
+	p2pCancel            context.CancelFunc
+	apiServer            *http.Server
+	apiCloser            io.Closer
+	debugAPIServer       *http.Server
+	debugAPICloser       io.Closer
+	errorLogWriter       *io.PipeWriter
+	tracerCloser         io.Closer
+	stateStoreCloser     io.Closer
+	localstoreCloser     io.Closer
+	topologyCloser       io.Closer
+	pusherCloser         io.Closer
+	pushSyncCloser       io.Closer
+	pullerCloser         io.Closer
+	pullSyncCloser       io.Closer
+	batchListenerCloser  io.Closer
+	postageServiceCloser io.Closer
+	tagsCloser           io.Closer
 }
 
 type Options struct {
 	DataDir            string
 	DBCapacity         uint64
+	DBMinFreeDiskSpace uint64
+	DBReserveRatio     float64
+	DBGCPolicy         localstore.GCPolicy
 	Password           string
 	APIAddr            string
 	DebugAPIAddr       string
@@ -84,6 +106,28 @@ type Options struct {
 	TracingEnabled     bool
 	TracingEndpoint    string
 	TracingServiceName string
+	PaymentThreshold   uint64
+	PaymentTolerance   uint64
+	PaymentRefreshRate uint64
+	PricePerPO         uint64
+	// TagsRetention is the time the completed upload tags and the receipts
+	// of the pushed chunks are kept for.
+	TagsRetention time.Duration
+	// DownloadPrefetchWindow is the number of the data chunks fetched ahead
+	// of the reads of the downloads.
+	DownloadPrefetchWindow int
+	// DirectUploadTimeout is the deadline for the chunks of the direct
+	// uploads to be synced.
+	DirectUploadTimeout time.Duration
+	// BatchListener is the source of the postage batch events. When it is
+	// set, chunks without a valid postage stamp are rejected.
+	BatchListener postage.Listener
+	// SwapBackend is the chain with the chequebook contracts. When it is
+	// set, the debts to the peers are settled with swap cheques instead of
+	// pseudosettle payments, from a chequebook deployed with the initial
+	// deposit on the first start.
+	SwapBackend        chequebook.Backend
+	SwapInitialDeposit uint64
 }
 
 func NewBee(o Options) (*Bee, error) {
@@ -153,6 +197,28 @@ func NewBee(o Options) (*Bee, error) {
 	addressbook := addressbook.New(stateStore)
 	signer := crypto.NewDefaultSigner(swarmPrivateKey)
 
+	postageService, err := postage.NewService(stateStore)
+	if err != nil {
+		return nil, fmt.Errorf("postage service: %w", err)
+	}
+	b.postageServiceCloser = postageService
+
+	var validStamp postage.ValidStampFn
+	if o.BatchListener != nil {
+		batchStore := batchstore.New(stateStore)
+		chainState, err := batchStore.GetChainState()
+		if err != nil {
+			return nil, fmt.Errorf("batch store chain state: %w", err)
+		}
+		owner, err := crypto.NewEthereumAddress(swarmPrivateKey.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("postage batch owner: %w", err)
+		}
+		o.BatchListener.Listen(chainState.Block, batchservice.New(batchStore, logger, owner, postageService))
+		b.batchListenerCloser = o.BatchListener
+		validStamp = postage.ValidStamp(batchStore)
+	}
+
 	p2ps, err := libp2p.New(p2pCtx, signer, o.NetworkID, address, o.Addr, libp2p.Options{
 		PrivateKey:     libp2pPrivateKey,
 		NATAddr:        o.NATAddr,
@@ -225,37 +291,122 @@ func NewBee(o Options) (*Bee, error) {
 	if o.DataDir != "" {
 		path = filepath.Join(o.DataDir, "localstore")
 	}
-	lo := &localstore.Options{
-		Capacity: o.DBCapacity,
+	tag, err := tags.New(tags.Options{
+		StateStore: stateStore,
+		Logger:     logger,
+		Retention:  o.TagsRetention,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tags: %w", err)
 	}
-	storer, err = localstore.New(path, address.Bytes(), lo, logger)
+	b.tagsCloser = tag
+
+	lo := &localstore.Options{
+		Capacity:            o.DBCapacity / swarm.ChunkSize,
+		ReserveCapacity:     uint64(float64(o.DBCapacity/swarm.ChunkSize) * o.DBReserveRatio),
+		MaxSize:             o.DBCapacity,
+		MinFreeDiskSpace:    o.DBMinFreeDiskSpace,
+		NeighborhoodDepther: topologyDriver,
+		GCPolicy:            o.DBGCPolicy,
+		Tags:                tag,
+	}
+	localStore, err := localstore.New(path, address.Bytes(), lo, logger)
 	if err != nil {
 		return nil, fmt.Errorf("localstore: %w", err)
 	}
-	b.localstoreCloser = storer
+	b.localstoreCloser = localStore
+	storer = localStore
+
+	var (
+		settlementService settlement.Interface
+		chequebookService chequebook.Service
+		swapService       *swap.Service
+	)
+	if o.SwapBackend != nil {
+		chequebookService, err = chequebook.Init(p2pCtx, o.SwapBackend, stateStore, signer, new(big.Int).SetUint64(o.SwapInitialDeposit))
+		if err != nil {
+			return nil, fmt.Errorf("chequebook: %w", err)
+		}
+		beneficiary, err := signer.EthereumAddress()
+		if err != nil {
+			return nil, fmt.Errorf("beneficiary: %w", err)
+		}
+		swapService = swap.New(swap.Options{
+			Streamer:    p2ps,
+			Logger:      logger,
+			Store:       stateStore,
+			Chequebook:  chequebookService,
+			ChequeStore: chequebook.NewChequeStore(stateStore, o.SwapBackend, beneficiary),
+			Beneficiary: beneficiary,
+		})
+		if err = p2ps.AddProtocol(swapService.Protocol()); err != nil {
+			return nil, fmt.Errorf("swap service: %w", err)
+		}
+		settlementService = swapService
+	} else {
+		pseudosettleService := pseudosettle.New(pseudosettle.Options{
+			Streamer:    p2ps,
+			Logger:      logger,
+			Store:       stateStore,
+			RefreshRate: o.PaymentRefreshRate,
+		})
+		if err = p2ps.AddProtocol(pseudosettleService.Protocol()); err != nil {
+			return nil, fmt.Errorf("pseudosettle service: %w", err)
+		}
+		settlementService = pseudosettleService
+	}
+
+	acc, err := accounting.NewAccounting(accounting.Options{
+		PaymentThreshold: o.PaymentThreshold,
+		PaymentTolerance: o.PaymentTolerance,
+		Logger:           logger,
+		Store:            stateStore,
+		Settlement:       settlementService,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("accounting: %w", err)
+	}
+	settlementService.SetPaymentObserver(acc)
+
+	chunkPricer := pricer.NewFixedPricer(address, o.PricePerPO)
 
 	retrieve := retrieval.New(retrieval.Options{
 		Streamer:    p2ps,
 		ChunkPeerer: topologyDriver,
+		Accounting:  acc,
+		Pricer:      chunkPricer,
 		Logger:      logger,
 	})
-	tag := tags.NewTags()
-
 	if err = p2ps.AddProtocol(retrieve.Protocol()); err != nil {
 		return nil, fmt.Errorf("retrieval service: %w", err)
 	}
 
-	ns := netstore.New(storer, retrieve, validator.NewContentAddressValidator())
+	ns := netstore.New(storer, validStamp, retrieve, validator.NewContentAddressValidator(), soc.NewValidator())
 
 	retrieve.SetStorer(ns)
 
+	pssService := pss.New(swarmPrivateKey, logger)
+
 	pushSyncProtocol := pushsync.New(pushsync.Options{
-		Streamer:      p2ps,
-		Storer:        storer,
-		ClosestPeerer: topologyDriver,
-		Logger:        logger,
+		Address:             address,
+		NetworkID:           o.NetworkID,
+		Signer:              signer,
+		Store:               stateStore,
+		ReceiptRetention:    o.TagsRetention,
+		Streamer:            p2ps,
+		Storer:              storer,
+		ClosestPeerer:       topologyDriver,
+		NeighborhoodDepther: topologyDriver,
+		DeliveryCallback:    pssService.TryUnwrap,
+		ValidStamp:          validStamp,
+		Accounting:          acc,
+		Pricer:              chunkPricer,
+		Logger:              logger,
 	})
 
+	b.pushSyncCloser = pushSyncProtocol
+	pssService.SetPushSyncer(pushSyncProtocol)
+
 	if err = p2ps.AddProtocol(pushSyncProtocol.Protocol()); err != nil {
 		return nil, fmt.Errorf("pushsync service: %w", err)
 	}
@@ -272,9 +423,10 @@ func NewBee(o Options) (*Bee, error) {
 	pullStorage := pullstorage.New(storer)
 
 	pullSync := pullsync.New(pullsync.Options{
-		Streamer: p2ps,
-		Storage:  pullStorage,
-		Logger:   logger,
+		Streamer:   p2ps,
+		Storage:    pullStorage,
+		ValidStamp: validStamp,
+		Logger:     logger,
 	})
 	b.pullSyncCloser = pullSync
 
@@ -294,11 +446,18 @@ func NewBee(o Options) (*Bee, error) {
 	if o.APIAddr != "" {
 		// API server
 		apiService = api.New(api.Options{
-			Tags:               tag,
-			Storer:             ns,
-			CORSAllowedOrigins: o.CORSAllowedOrigins,
-			Logger:             logger,
-			Tracer:             tracer,
+			Tags:                   tag,
+			Storer:                 ns,
+			Signer:                 signer,
+			Pss:                    pssService,
+			Post:                   postageService,
+			CORSAllowedOrigins:     o.CORSAllowedOrigins,
+			Logger:                 logger,
+			Tracer:                 tracer,
+			DownloadPrefetchWindow: o.DownloadPrefetchWindow,
+			PushSyncer:             pushSyncProtocol,
+			DirectUploadTimeout:    o.DirectUploadTimeout,
+			StampsRequired:         o.BatchListener != nil,
 		})
 		apiListener, err := net.Listen("tcp", o.APIAddr)
 		if err != nil {
@@ -320,12 +479,14 @@ func NewBee(o Options) (*Bee, error) {
 		}()
 
 		b.apiServer = apiServer
+		b.apiCloser = apiService
 	}
 
 	if o.DebugAPIAddr != "" {
 		// Debug API server
-		debugAPIService := debugapi.New(debugapi.Options{
+		debugOpts := debugapi.Options{
 			Overlay:        address,
+			PublicKey:      swarmPrivateKey.PublicKey,
 			P2P:            p2ps,
 			Pingpong:       pingPong,
 			Logger:         logger,
@@ -333,7 +494,19 @@ func NewBee(o Options) (*Bee, error) {
 			Addressbook:    addressbook,
 			TopologyDriver: topologyDriver,
 			Storer:         storer,
-		})
+			Pinning:        pinning.New(storer, stateStore),
+			Accounting:     acc,
+			Settlement:     settlementService,
+			Receipts:       pushSyncProtocol,
+			Reserve:        localStore,
+			DiskSpace:      localStore,
+		}
+		if swapService != nil {
+			debugOpts.Chequebook = chequebookService
+			debugOpts.Swap = swapService
+		}
+		debugAPIService := debugapi.New(debugOpts)
+		b.debugAPICloser = debugAPIService
 		// register metrics from components
 		debugAPIService.MustRegisterMetrics(p2ps.Metrics()...)
 		debugAPIService.MustRegisterMetrics(pingPong.Metrics()...)
@@ -454,6 +627,18 @@ func NewBee(o Options) (*Bee, error) {
 func (b *Bee) Shutdown(ctx context.Context) error {
 	errs := new(multiError)
 
+	if b.apiCloser != nil {
+		if err := b.apiCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("api: %w", err))
+		}
+	}
+
+	if b.debugAPICloser != nil {
+		if err := b.debugAPICloser.Close(); err != nil {
+			errs.add(fmt.Errorf("debug api: %w", err))
+		}
+	}
+
 	var eg errgroup.Group
 	if b.apiServer != nil {
 		eg.Go(func() error {
@@ -479,6 +664,10 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 		errs.add(fmt.Errorf("pusher: %w", err))
 	}
 
+	if err := b.pushSyncCloser.Close(); err != nil {
+		errs.add(fmt.Errorf("pushsync: %w", err))
+	}
+
 	if err := b.pullerCloser.Close(); err != nil {
 		return fmt.Errorf("puller: %w", err)
 	}
@@ -492,6 +681,20 @@ func (b *Bee) Shutdown(ctx context.Context) error {
 		errs.add(fmt.Errorf("p2p server: %w", err))
 	}
 
+	if b.batchListenerCloser != nil {
+		if err := b.batchListenerCloser.Close(); err != nil {
+			errs.add(fmt.Errorf("batch listener: %w", err))
+		}
+	}
+
+	if err := b.postageServiceCloser.Close(); err != nil {
+		errs.add(fmt.Errorf("postage service: %w", err))
+	}
+
+	if err := b.tagsCloser.Close(); err != nil {
+		errs.add(fmt.Errorf("tags: %w", err))
+	}
+
 	if err := b.tracerCloser.Close(); err != nil {
 		errs.add(fmt.Errorf("tracer: %w", err))
 	}
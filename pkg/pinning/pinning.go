@@ -0,0 +1,205 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pinning pins and unpins the whole chunk trees of the uploaded data
+// and files by their root references. The chunks are pinned with the pin
+// counters of the local store, while the pinned root references are kept in
+// the state store, so that they can be listed without iterating over all
+// pinned chunks.
+package pinning
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+)
+
+// rootKeyPrefix is the state store key prefix of the pinned root references.
+const rootKeyPrefix = "pinning_root_"
+
+// Type is the type of the content referenced by the pinned root.
+type Type string
+
+const (
+	// TypeBytes is the type of the roots of the raw data chunk trees.
+	TypeBytes Type = "bytes"
+	// TypeFile is the type of the roots of the files: the entries that join
+	// the file data with its metadata. The entries of the manifests are
+	// pinned with all the files of the collection.
+	TypeFile Type = "file"
+)
+
+// ErrNotPinned is returned when the root reference is not pinned with the
+// type.
+var ErrNotPinned = errors.New("root not pinned")
+
+// Pin is the pinned root reference.
+type Pin struct {
+	Address swarm.Address `json:"address"`
+	Type    Type          `json:"type"`
+	// Counter is the number of times the root is pinned.
+	Counter uint64 `json:"counter"`
+}
+
+// Interface is the pinning of the chunk trees.
+type Interface interface {
+	// Pin pins all chunks of the tree of the root reference with the type.
+	Pin(ctx context.Context, root swarm.Address, typ Type) error
+	// Unpin unpins all chunks of the tree of the root reference pinned
+	// with the type.
+	Unpin(ctx context.Context, root swarm.Address, typ Type) error
+	// Pins returns the pinned root references.
+	Pins() ([]Pin, error)
+}
+
+var _ Interface = (*Service)(nil)
+
+// Service pins the chunk trees in the local store.
+type Service struct {
+	mu         sync.Mutex // serializes the updates of the pinned roots
+	storer     storage.Storer
+	stateStore storage.StateStorer
+	traverser  traversal.Traverser
+}
+
+// New creates a new pinning service with the chunks in the storer.
+func New(storer storage.Storer, stateStore storage.StateStorer) *Service {
+	return &Service{
+		storer:     storer,
+		stateStore: stateStore,
+		traverser:  traversal.New(storer),
+	}
+}
+
+// Pin pins all chunks of the tree of the root reference with the type. The
+// chunks must be present in the local store, as no chunks are pinned unless
+// the whole tree is traversed. Pinning the same root again increments the pin
+// counters of its chunks once more.
+func (s *Service) Pin(ctx context.Context, root swarm.Address, typ Type) error {
+	addrs, err := s.chunkAddresses(ctx, root, typ)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pin, err := s.pin(root)
+	if err != nil && !errors.Is(err, ErrNotPinned) {
+		return err
+	}
+	if err == nil && pin.Type != typ {
+		return fmt.Errorf("root pinned as %s", pin.Type)
+	}
+
+	// all chunks are pinned in a single batch
+	if err := s.storer.Set(ctx, storage.ModeSetPin, addrs...); err != nil {
+		return fmt.Errorf("pin chunks: %w", err)
+	}
+
+	return s.stateStore.Put(rootKey(root), Pin{
+		Address: root,
+		Type:    typ,
+		Counter: pin.Counter + 1,
+	})
+}
+
+// Unpin unpins all chunks of the tree of the root reference. The root must be
+// pinned with the same type.
+func (s *Service) Unpin(ctx context.Context, root swarm.Address, typ Type) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pin, err := s.pin(root)
+	if err != nil {
+		return err
+	}
+	if pin.Type != typ {
+		return ErrNotPinned
+	}
+
+	addrs, err := s.chunkAddresses(ctx, root, typ)
+	if err != nil {
+		return err
+	}
+	if err := s.storer.Set(ctx, storage.ModeSetUnpin, addrs...); err != nil {
+		return fmt.Errorf("unpin chunks: %w", err)
+	}
+
+	if pin.Counter <= 1 {
+		return s.stateStore.Delete(rootKey(root))
+	}
+	pin.Counter--
+	return s.stateStore.Put(rootKey(root), pin)
+}
+
+// Pins returns the pinned root references.
+func (s *Service) Pins() (pins []Pin, err error) {
+	err = s.stateStore.Iterate(rootKeyPrefix, func(key, value []byte) (stop bool, err error) {
+		var pin Pin
+		if err := json.Unmarshal(value, &pin); err != nil {
+			return true, fmt.Errorf("unmarshal pin %s: %w", string(key), err)
+		}
+		pins = append(pins, pin)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pins, nil
+}
+
+// pin returns the record of the pinned root. The zero value is returned with
+// the ErrNotPinned error if the root is not pinned.
+func (s *Service) pin(root swarm.Address) (pin Pin, err error) {
+	if err := s.stateStore.Get(rootKey(root), &pin); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return Pin{}, ErrNotPinned
+		}
+		return Pin{}, err
+	}
+	return pin, nil
+}
+
+// chunkAddresses returns the distinct addresses of all chunks of the tree of
+// the root reference.
+func (s *Service) chunkAddresses(ctx context.Context, root swarm.Address, typ Type) ([]swarm.Address, error) {
+	var (
+		addrs []swarm.Address
+		seen  = make(map[string]struct{})
+	)
+	fn := func(addr swarm.Address) error {
+		if _, ok := seen[addr.ByteString()]; ok {
+			return nil
+		}
+		seen[addr.ByteString()] = struct{}{}
+		addrs = append(addrs, addr)
+		return nil
+	}
+
+	var err error
+	switch typ {
+	case TypeBytes:
+		err = s.traverser.TraverseBytesAddresses(ctx, root, fn)
+	case TypeFile:
+		err = s.traverser.TraverseFileAddresses(ctx, root, fn)
+	default:
+		err = fmt.Errorf("unknown pin type %q", typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return addrs, nil
+}
+
+func rootKey(root swarm.Address) string {
+	return rootKeyPrefix + hex.EncodeToString(root.Bytes())
+}
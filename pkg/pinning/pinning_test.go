@@ -0,0 +1,232 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pinning_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/collection/entry"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	"github.com/ethersphere/bee/pkg/pinning"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+func TestPinBytes(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		size    int
+		encrypt bool
+		chunks  int
+	}{
+		{name: "single chunk", size: 100, chunks: 1},
+		{name: "two levels", size: 5*swarm.ChunkSize + 10, chunks: 7},
+		{name: "three levels", size: 130 * swarm.ChunkSize, chunks: 130 + 2 + 1},
+		{name: "encrypted", size: 5*swarm.ChunkSize + 10, encrypt: true, chunks: 7},
+		{name: "encrypted dangling chunk", size: 129*swarm.ChunkSize - 1, encrypt: true, chunks: 129 + 2 + 1},
+		{name: "encrypted dangling full chunk", size: 257 * swarm.ChunkSize, encrypt: true, chunks: 257 + 4 + 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			storer := mock.NewStorer()
+			root := split(t, storer, randomData(t, tc.size), tc.encrypt)
+
+			s := pinning.New(storer, statestore.NewStateStore())
+
+			if err := s.Pin(ctx, root, pinning.TypeBytes); err != nil {
+				t.Fatal(err)
+			}
+			checkPinnedChunks(t, storer, tc.chunks, 1)
+
+			// pinning again increments the counters
+			if err := s.Pin(ctx, root, pinning.TypeBytes); err != nil {
+				t.Fatal(err)
+			}
+			checkPinnedChunks(t, storer, tc.chunks, 2)
+			checkPins(t, s, pinning.Pin{Address: root, Type: pinning.TypeBytes, Counter: 2})
+
+			if err := s.Unpin(ctx, root, pinning.TypeBytes); err != nil {
+				t.Fatal(err)
+			}
+			checkPinnedChunks(t, storer, tc.chunks, 1)
+			checkPins(t, s, pinning.Pin{Address: root, Type: pinning.TypeBytes, Counter: 1})
+
+			if err := s.Unpin(ctx, root, pinning.TypeBytes); err != nil {
+				t.Fatal(err)
+			}
+			checkPinnedChunks(t, storer, 0, 0)
+			checkPins(t, s)
+
+			if err := s.Unpin(ctx, root, pinning.TypeBytes); !errors.Is(err, pinning.ErrNotPinned) {
+				t.Fatalf("got error %v, want %v", err, pinning.ErrNotPinned)
+			}
+		})
+	}
+}
+
+func TestPinFile(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		encrypt bool
+	}{
+		{name: "plain"},
+		{name: "encrypted", encrypt: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			storer := mock.NewStorer()
+			root := storeFile(t, storer, randomData(t, 3*swarm.ChunkSize), tc.encrypt)
+
+			s := pinning.New(storer, statestore.NewStateStore())
+
+			if err := s.Pin(ctx, root, pinning.TypeFile); err != nil {
+				t.Fatal(err)
+			}
+			// the entry, the metadata, three data chunks and their parent
+			checkPinnedChunks(t, storer, 6, 1)
+			checkPins(t, s, pinning.Pin{Address: root, Type: pinning.TypeFile, Counter: 1})
+
+			// the root is not pinned as bytes
+			if err := s.Unpin(ctx, root, pinning.TypeBytes); !errors.Is(err, pinning.ErrNotPinned) {
+				t.Fatalf("got error %v, want %v", err, pinning.ErrNotPinned)
+			}
+
+			if err := s.Unpin(ctx, root, pinning.TypeFile); err != nil {
+				t.Fatal(err)
+			}
+			checkPinnedChunks(t, storer, 0, 0)
+			checkPins(t, s)
+		})
+	}
+}
+
+func TestPinMissingChunk(t *testing.T) {
+	storer := mock.NewStorer()
+	s := pinning.New(storer, statestore.NewStateStore())
+
+	root := swarm.MustParseHexAddress("ca8d2d29466e017cba46d383e7e0794d99a141185ec525086037f25fc2093155")
+	err := s.Pin(context.Background(), root, pinning.TypeBytes)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+	checkPins(t, s)
+}
+
+// TestPinMissingDataChunk verifies that no chunks are pinned if a chunk of
+// the tree is missing.
+func TestPinMissingDataChunk(t *testing.T) {
+	storer := mock.NewStorer()
+	s := pinning.New(storer, statestore.NewStateStore())
+
+	root := split(t, &droppingPutter{Storer: storer}, randomData(t, 129*swarm.ChunkSize-1), true)
+	err := s.Pin(context.Background(), root, pinning.TypeBytes)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+	checkPinnedChunks(t, storer, 0, 0)
+	checkPins(t, s)
+}
+
+// checkPinnedChunks validates the number of the pinned chunks in the storer
+// and that all of them have the pin counter.
+func checkPinnedChunks(t *testing.T, storer *mock.MockStorer, count int, counter uint64) {
+	t.Helper()
+
+	// the mock returns an error when there are no pinned chunks
+	pinned, _ := storer.PinnedChunks(context.Background(), swarm.ZeroAddress)
+	if len(pinned) != count {
+		t.Fatalf("got %v pinned chunks, want %v", len(pinned), count)
+	}
+	for _, p := range pinned {
+		if p.PinCounter != counter {
+			t.Fatalf("chunk %s: got pin counter %v, want %v", p.Address, p.PinCounter, counter)
+		}
+	}
+}
+
+func checkPins(t *testing.T, s *pinning.Service, want ...pinning.Pin) {
+	t.Helper()
+
+	pins, err := s.Pins()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != len(want) {
+		t.Fatalf("got %v pins, want %v", len(pins), len(want))
+	}
+	for i := range want {
+		if !pins[i].Address.Equal(want[i].Address) || pins[i].Type != want[i].Type || pins[i].Counter != want[i].Counter {
+			t.Fatalf("got pin %+v, want %+v", pins[i], want[i])
+		}
+	}
+}
+
+func split(t *testing.T, storer storage.Storer, data []byte, encrypt bool) swarm.Address {
+	t.Helper()
+
+	var s file.Splitter = splitter.NewSimpleSplitter(storer)
+	if encrypt {
+		s = splitter.NewEncryptingSplitter(storer)
+	}
+	addr, err := file.SplitWriteAll(context.Background(), s, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+// storeFile stores the data with its metadata and the entry which joins them,
+// returning the reference of the entry.
+func storeFile(t *testing.T, storer storage.Storer, data []byte, encrypt bool) swarm.Address {
+	t.Helper()
+
+	metadata, err := json.Marshal(entry.NewMetadata("file.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := entry.New(split(t, storer, data, encrypt), split(t, storer, metadata, encrypt))
+	b, err := e.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return split(t, storer, b, encrypt)
+}
+
+// droppingPutter does not store the first chunk put, which is the first data
+// chunk of the split data.
+type droppingPutter struct {
+	storage.Storer
+	dropped bool
+}
+
+func (p *droppingPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	if !p.dropped && len(chs) > 0 {
+		p.dropped = true
+		exist, err := p.Storer.Put(ctx, mode, chs[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		return append([]bool{false}, exist...), nil
+	}
+	return p.Storer.Put(ctx, mode, chs...)
+}
+
+func randomData(t *testing.T, size int) []byte {
+	t.Helper()
+
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+)
+
+// batchSize is the length of the serialized batch without the value.
+const batchSize = 32 + 8 + 20 + 1
+
+// ErrInvalidBatch is returned when the serialized batch is malformed.
+var ErrInvalidBatch = errors.New("invalid batch")
+
+// Batch represents a postage batch, a payment on the blockchain that
+// entitles its owner to stamp 2^Depth chunks.
+type Batch struct {
+	ID    []byte   // batch identifier
+	Value *big.Int // value paid for the batch
+	Start uint64   // block number the batch was created
+	Owner []byte   // ethereum address of the batch owner
+	Depth uint8    // batch depth, the logarithm of the batch capacity
+}
+
+// MarshalBinary serializes the batch as:
+//
+//	id (32 bytes) | start (8 bytes) | owner (20 bytes) | depth (1 byte) | value
+func (b *Batch) MarshalBinary() ([]byte, error) {
+	out := make([]byte, batchSize)
+	copy(out, b.ID)
+	binary.BigEndian.PutUint64(out[32:40], b.Start)
+	copy(out[40:60], b.Owner)
+	out[60] = b.Depth
+	if b.Value != nil {
+		out = append(out, b.Value.Bytes()...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary deserializes the batch from the data created by
+// MarshalBinary.
+func (b *Batch) UnmarshalBinary(data []byte) error {
+	if len(data) < batchSize {
+		return ErrInvalidBatch
+	}
+	b.ID = append([]byte(nil), data[:32]...)
+	b.Start = binary.BigEndian.Uint64(data[32:40])
+	b.Owner = append([]byte(nil), data[40:60]...)
+	b.Depth = data[60]
+	b.Value = new(big.Int).SetBytes(data[batchSize:])
+	return nil
+}
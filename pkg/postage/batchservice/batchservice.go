@@ -0,0 +1,111 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package batchservice applies the postage batch events to the batch store.
+package batchservice
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+var _ postage.EventUpdater = (*batchService)(nil)
+
+type batchService struct {
+	storer  postage.Storer
+	logger  logging.Logger
+	owner   []byte
+	issuers postage.Service
+}
+
+// New creates a new batch service. The batches created with the owner
+// ethereum address are added to the stamp issuers of the postage service, so
+// that they can be used for uploads.
+func New(storer postage.Storer, logger logging.Logger, owner []byte, issuers postage.Service) postage.EventUpdater {
+	return &batchService{
+		storer:  storer,
+		logger:  logger,
+		owner:   owner,
+		issuers: issuers,
+	}
+}
+
+// Create will create a new batch and store it in the batch store.
+func (svc *batchService) Create(id, owner []byte, value *big.Int, depth uint8) error {
+	cs, err := svc.storer.GetChainState()
+	if err != nil {
+		return fmt.Errorf("get chain state: %w", err)
+	}
+	b := &postage.Batch{
+		ID:    id,
+		Owner: owner,
+		Value: value,
+		Start: cs.Block,
+		Depth: depth,
+	}
+	if err := svc.storer.Put(b); err != nil {
+		return fmt.Errorf("put: %w", err)
+	}
+
+	if bytes.Equal(owner, svc.owner) {
+		st := postage.NewStampIssuer(hex.EncodeToString(id), id, depth, postage.BucketDepth)
+		if err := svc.issuers.Add(st); err != nil {
+			return fmt.Errorf("add stamp issuer: %w", err)
+		}
+	}
+
+	svc.logger.Debugf("batch service: created batch id %s", hex.EncodeToString(b.ID))
+	return nil
+}
+
+// TopUp implements the EventUpdater interface. It adds the value to the
+// batch value.
+func (svc *batchService) TopUp(id []byte, value *big.Int) error {
+	b, err := svc.storer.Get(id)
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	b.Value = new(big.Int).Add(b.Value, value)
+	if err := svc.storer.Put(b); err != nil {
+		return fmt.Errorf("put: %w", err)
+	}
+
+	svc.logger.Debugf("batch service: topped up batch id %s with %v", hex.EncodeToString(b.ID), value)
+	return nil
+}
+
+// UpdateDepth implements the EventUpdater interface. It sets the new depth of
+// the batch.
+func (svc *batchService) UpdateDepth(id []byte, depth uint8) error {
+	b, err := svc.storer.Get(id)
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	b.Depth = depth
+	if err := svc.storer.Put(b); err != nil {
+		return fmt.Errorf("put: %w", err)
+	}
+
+	svc.logger.Debugf("batch service: updated depth of batch id %s to %d", hex.EncodeToString(b.ID), depth)
+	return nil
+}
+
+// UpdateBlockNumber implements the EventUpdater interface. It persists the
+// block number of the last processed event.
+func (svc *batchService) UpdateBlockNumber(blockNumber uint64) error {
+	cs, err := svc.storer.GetChainState()
+	if err != nil {
+		return fmt.Errorf("get chain state: %w", err)
+	}
+	cs.Block = blockNumber
+	if err := svc.storer.PutChainState(cs); err != nil {
+		return fmt.Errorf("put chain state: %w", err)
+	}
+	return nil
+}
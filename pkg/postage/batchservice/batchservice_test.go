@@ -0,0 +1,112 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batchservice_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchservice"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	"github.com/ethersphere/bee/pkg/postage/mock"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func TestBatchService(t *testing.T) {
+	var (
+		logger     = logging.New(ioutil.Discard, 0)
+		owner      = bytes.Repeat([]byte{1}, 20)
+		otherOwner = bytes.Repeat([]byte{2}, 20)
+		chain      = mock.NewChain()
+		stateStore = statestore.NewStateStore()
+		store      = batchstore.New(stateStore)
+	)
+	issuers, err := postage.NewService(stateStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.Listen(0, batchservice.New(store, logger, owner, issuers))
+
+	id, err := chain.CreateBatch(owner, big.NewInt(100), 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherID, err := chain.CreateBatch(otherOwner, big.NewInt(100), 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.TopUp(id, big.NewInt(50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.UpdateDepth(id, 21); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := store.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Value.Cmp(big.NewInt(150)) != 0 {
+		t.Fatalf("got value %v, want 150", b.Value)
+	}
+	if b.Depth != 21 {
+		t.Fatalf("got depth %d, want 21", b.Depth)
+	}
+	if b.Start != 1 {
+		t.Fatalf("got start %d, want 1", b.Start)
+	}
+	if !bytes.Equal(b.Owner, owner) {
+		t.Fatalf("got owner %x, want %x", b.Owner, owner)
+	}
+	if _, err := store.Get(otherID); err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := store.GetChainState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.Block != 4 {
+		t.Fatalf("got block %d, want 4", cs.Block)
+	}
+
+	// only the batches of the owner can be used for stamping
+	if _, err := issuers.GetStampIssuer(id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issuers.GetStampIssuer(otherID); !errors.Is(err, postage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, postage.ErrNotFound)
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		// a listener resuming from the last processed block gets only the
+		// new events
+		resumedStore := batchstore.New(statestore.NewStateStore())
+		if err := resumedStore.PutChainState(cs); err != nil {
+			t.Fatal(err)
+		}
+		chain.Listen(cs.Block, batchservice.New(resumedStore, logger, owner, issuers))
+
+		if _, err := resumedStore.Get(id); err == nil {
+			t.Fatal("got batch from the processed blocks")
+		}
+		newID, err := chain.CreateBatch(otherOwner, big.NewInt(100), 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := resumedStore.Get(newID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Start != 5 {
+			t.Fatalf("got start %d, want 5", b.Start)
+		}
+	})
+}
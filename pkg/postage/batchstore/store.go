@@ -0,0 +1,70 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package batchstore persists the postage batches and the chain state in the
+// state store.
+package batchstore
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	batchKeyPrefix = "batchstore_batch_"
+	chainStateKey  = "batchstore_chainstate"
+)
+
+var _ postage.Storer = (*store)(nil)
+
+// store implements postage.Storer on a state store.
+type store struct {
+	store storage.StateStorer
+}
+
+// New constructs a new postage batch store.
+func New(st storage.StateStorer) postage.Storer {
+	return &store{store: st}
+}
+
+// Get returns the batch with the given id. It returns storage.ErrNotFound if
+// the batch is not in the store.
+func (s *store) Get(id []byte) (*postage.Batch, error) {
+	b := new(postage.Batch)
+	if err := s.store.Get(batchKey(id), b); err != nil {
+		return nil, fmt.Errorf("get batch %x: %w", id, err)
+	}
+	return b, nil
+}
+
+// Put stores the batch, replacing the batch with the same id.
+func (s *store) Put(b *postage.Batch) error {
+	return s.store.Put(batchKey(b.ID), b)
+}
+
+// PutChainState stores the chain state.
+func (s *store) PutChainState(cs *postage.ChainState) error {
+	return s.store.Put(chainStateKey, cs)
+}
+
+// GetChainState returns the stored chain state, or the initial chain state if
+// no chain state is stored yet.
+func (s *store) GetChainState() (*postage.ChainState, error) {
+	cs := new(postage.ChainState)
+	if err := s.store.Get(chainStateKey, cs); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return cs, nil
+		}
+		return nil, err
+	}
+	return cs, nil
+}
+
+func batchKey(id []byte) string {
+	return batchKeyPrefix + hex.EncodeToString(id)
+}
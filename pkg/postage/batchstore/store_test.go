@@ -0,0 +1,60 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batchstore_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+func TestBatchStore(t *testing.T) {
+	store := batchstore.New(statestore.NewStateStore())
+
+	b := &postage.Batch{
+		ID:    bytes.Repeat([]byte{1}, 32),
+		Value: big.NewInt(1000),
+		Start: 7,
+		Owner: bytes.Repeat([]byte{2}, 20),
+		Depth: 20,
+	}
+	if _, err := store.Get(b.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+	if err := store.Put(b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.ID, b.ID) || got.Value.Cmp(b.Value) != 0 || got.Start != b.Start || !bytes.Equal(got.Owner, b.Owner) || got.Depth != b.Depth {
+		t.Fatalf("got batch %+v, want %+v", got, b)
+	}
+
+	cs, err := store.GetChainState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.Block != 0 {
+		t.Fatalf("got initial block %d, want 0", cs.Block)
+	}
+	if err := store.PutChainState(&postage.ChainState{Block: 42}); err != nil {
+		t.Fatal(err)
+	}
+	cs, err = store.GetChainState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cs.Block != 42 {
+		t.Fatalf("got block %d, want 42", cs.Block)
+	}
+}
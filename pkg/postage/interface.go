@@ -0,0 +1,42 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"io"
+	"math/big"
+)
+
+// EventUpdater interface definitions reflect the updates triggered by events
+// emitted by the postage contract on the blockchain.
+type EventUpdater interface {
+	Create(id []byte, owner []byte, value *big.Int, depth uint8) error
+	TopUp(id []byte, value *big.Int) error
+	UpdateDepth(id []byte, depth uint8) error
+	UpdateBlockNumber(blockNumber uint64) error
+}
+
+// Storer represents the persistence layer for batches on the current (highest
+// available) block.
+type Storer interface {
+	Get(id []byte) (*Batch, error)
+	Put(*Batch) error
+	PutChainState(*ChainState) error
+	GetChainState() (*ChainState, error)
+}
+
+// Listener provides the batch events to the EventUpdater. It is the source of
+// the batches, the blockchain in production and an in-memory chain in tests.
+type Listener interface {
+	// Listen delivers the events from the blocks after the from block
+	// number to the updater.
+	Listen(from uint64, updater EventUpdater)
+	io.Closer
+}
+
+// ChainState contains data the batch service reads from the chain.
+type ChainState struct {
+	Block uint64 `json:"block"` // the block number of the last processed event
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mock provides an in-memory chain as the source of postage batch
+// events for tests.
+package mock
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/postage"
+)
+
+var _ postage.Listener = (*Chain)(nil)
+
+// Chain is an in-memory chain that delivers the postage batch events to the
+// listening updaters synchronously. Every event is mined in its own block.
+type Chain struct {
+	mtx      sync.Mutex
+	events   []event
+	updaters []postage.EventUpdater
+}
+
+type event struct {
+	block uint64
+	apply func(postage.EventUpdater) error
+}
+
+// NewChain constructs a new in-memory chain.
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Listen delivers the events from the blocks after the from block number to
+// the updater, and all the subsequent events.
+func (c *Chain) Listen(from uint64, updater postage.EventUpdater) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, e := range c.events {
+		if e.block <= from {
+			continue
+		}
+		// errors of the replayed events were already returned to the
+		// event emitter
+		_ = deliver(updater, e)
+	}
+	c.updaters = append(c.updaters, updater)
+}
+
+// Close stops the delivery of the events.
+func (c *Chain) Close() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.updaters = nil
+	return nil
+}
+
+// CreateBatch creates a new batch with a random id, returning the id.
+func (c *Chain) CreateBatch(owner []byte, value *big.Int, depth uint8) ([]byte, error) {
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return id, c.emit(func(u postage.EventUpdater) error {
+		return u.Create(id, owner, value, depth)
+	})
+}
+
+// TopUp adds the value to the batch.
+func (c *Chain) TopUp(id []byte, value *big.Int) error {
+	return c.emit(func(u postage.EventUpdater) error {
+		return u.TopUp(id, value)
+	})
+}
+
+// UpdateDepth sets the depth of the batch.
+func (c *Chain) UpdateDepth(id []byte, depth uint8) error {
+	return c.emit(func(u postage.EventUpdater) error {
+		return u.UpdateDepth(id, depth)
+	})
+}
+
+// emit mines the event in a new block and delivers it to the updaters. It
+// returns the first error of the updaters.
+func (c *Chain) emit(apply func(postage.EventUpdater) error) (err error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e := event{
+		block: uint64(len(c.events)) + 1,
+		apply: apply,
+	}
+	c.events = append(c.events, e)
+	for _, u := range c.updaters {
+		if uerr := deliver(u, e); uerr != nil && err == nil {
+			err = uerr
+		}
+	}
+	return err
+}
+
+// deliver advances the block number of the updater to the block of the event
+// and applies the event.
+func deliver(u postage.EventUpdater, e event) error {
+	if err := u.UpdateBlockNumber(e.block); err != nil {
+		return err
+	}
+	return e.apply(u)
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package postage implements postage stamps, the proof of payment for the
+// storage of chunks in the network.
+//
+// Chunks are stamped with a batch, a payment on the blockchain by the batch
+// owner. The stamp assigns the chunk an index in the batch and is signed by
+// the batch owner, so that the nodes storing the chunk can validate that the
+// chunk is paid for and that the batch capacity is not exceeded.
+package postage
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+// stampIssuerKeyPrefix is the state store key prefix of the stamp issuers.
+const stampIssuerKeyPrefix = "postage_stampissuer_"
+
+// ErrNotFound is returned when the stamp issuer for the batch is not found.
+var ErrNotFound = errors.New("stamp issuer not found")
+
+// Service is the postage service interface, holding the stamp issuers of the
+// batches owned by the node.
+type Service interface {
+	Add(*StampIssuer) error
+	StampIssuers() []*StampIssuer
+	GetStampIssuer([]byte) (*StampIssuer, error)
+	io.Closer
+}
+
+// service handles postage batches.
+// It persists the stamp issuers in the state store.
+type service struct {
+	lock    sync.Mutex
+	store   storage.StateStorer
+	issuers []*StampIssuer
+}
+
+// NewService constructs a new Service, loading the stamp issuers from the
+// state store.
+func NewService(store storage.StateStorer) (Service, error) {
+	s := &service{
+		store: store,
+	}
+	if err := store.Iterate(stampIssuerKeyPrefix, func(key, value []byte) (bool, error) {
+		st := new(StampIssuer)
+		if err := st.UnmarshalBinary(value); err != nil {
+			return true, fmt.Errorf("stamp issuer %s: %w", key, err)
+		}
+		st.store = store
+		s.issuers = append(s.issuers, st)
+		return false, nil
+	}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Add adds a stamp issuer to the active issuers and persists it.
+func (s *service) Add(st *StampIssuer) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, v := range s.issuers {
+		if bytes.Equal(v.batchID, st.batchID) {
+			return nil
+		}
+	}
+	st.store = s.store
+	if err := st.save(); err != nil {
+		return err
+	}
+	s.issuers = append(s.issuers, st)
+	return nil
+}
+
+// StampIssuers returns the currently active stamp issuers.
+func (s *service) StampIssuers() []*StampIssuer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]*StampIssuer(nil), s.issuers...)
+}
+
+// GetStampIssuer finds a stamp issuer by batch ID.
+func (s *service) GetStampIssuer(batchID []byte) (*StampIssuer, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, st := range s.issuers {
+		if bytes.Equal(batchID, st.batchID) {
+			return st, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// Close saves the stamp issuers with their bucket counters to the state store.
+// The counters are also saved whenever a stamp is issued, so they are not lost
+// if the node is not shut down cleanly.
+func (s *service) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, st := range s.issuers {
+		if err := st.save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func stampIssuerKey(batchID []byte) string {
+	return stampIssuerKeyPrefix + hex.EncodeToString(batchID)
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/postage"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestServicePersistence tests that the stamp issuers with their bucket
+// counters are restored from the state store.
+func TestServicePersistence(t *testing.T) {
+	store := statestore.NewStateStore()
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+
+	s, err := postage.NewService(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batchID := randBytes(t, 32)
+	if err := s.Add(postage.NewStampIssuer("label", batchID, postage.BucketDepth+1, postage.BucketDepth)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetStampIssuer(randBytes(t, 32)); !errors.Is(err, postage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, postage.ErrNotFound)
+	}
+	st, err := s.GetStampIssuer(batchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := swarm.NewAddress(randBytes(t, 32))
+	if _, err := postage.NewStamper(st, signer).Stamp(addr); err != nil {
+		t.Fatal(err)
+	}
+
+	// the bucket counters are persisted when the stamp is issued,
+	// without closing the service
+	s, err = postage.NewService(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuers := s.StampIssuers()
+	if len(issuers) != 1 {
+		t.Fatalf("got %d stamp issuers, want 1", len(issuers))
+	}
+	st = issuers[0]
+	if !bytes.Equal(st.ID(), batchID) || st.Label() != "label" || st.Depth() != postage.BucketDepth+1 || st.BucketDepth() != postage.BucketDepth {
+		t.Fatal("stamp issuer not restored")
+	}
+	if u := st.Utilization(); u != 1 {
+		t.Fatalf("got utilization %d, want 1", u)
+	}
+
+	// the restored counter prevents exceeding the bucket capacity
+	stamper := postage.NewStamper(st, signer)
+	if _, err := stamper.Stamp(addr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stamper.Stamp(addr); !errors.Is(err, postage.ErrBucketFull) {
+		t.Fatalf("got error %v, want %v", err, postage.ErrBucketFull)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// BatchIDSize is the length of the batch identifier.
+	BatchIDSize = 32
+	// IndexSize is the length of the stamp index.
+	IndexSize = 8
+	// SignatureSize is the length of the stamp signature.
+	SignatureSize = 65
+	// StampSize is the length of the serialized stamp.
+	StampSize = BatchIDSize + IndexSize + SignatureSize
+	// BucketDepth is the depth of the buckets the batch capacity is evenly
+	// distributed over, by the first bits of the chunk address.
+	BucketDepth = 16
+)
+
+var (
+	// ErrInvalidStamp is returned when the serialized stamp is malformed.
+	ErrInvalidStamp = errors.New("invalid stamp")
+	// ErrBatchNotFound is returned when the batch of the stamp is unknown.
+	ErrBatchNotFound = errors.New("batch not found")
+	// ErrOwnerMismatch is returned when the stamp is not signed by the
+	// owner of the batch.
+	ErrOwnerMismatch = errors.New("owner mismatch")
+	// ErrBucketMismatch is returned when the stamp index is not in the
+	// bucket of the chunk address.
+	ErrBucketMismatch = errors.New("bucket mismatch")
+	// ErrInvalidIndex is returned when the stamp index exceeds the batch
+	// capacity.
+	ErrInvalidIndex = errors.New("index out of range")
+)
+
+var _ swarm.Stamp = (*Stamp)(nil)
+
+// Stamp represents a postage stamp as attached to a chunk.
+type Stamp struct {
+	batchID []byte // postage batch ID
+	index   []byte // index of the chunk in the batch
+	sig     []byte // common r[32]s[32]v[1]-style 65 byte ECDSA signature
+}
+
+// NewStamp constructs a new stamp from a given batch ID, index and
+// signature.
+func NewStamp(batchID, index, sig []byte) *Stamp {
+	return &Stamp{batchID, index, sig}
+}
+
+// BatchID returns the batch ID of the stamp.
+func (s *Stamp) BatchID() []byte {
+	return s.batchID
+}
+
+// Index returns the index of the chunk in the batch.
+func (s *Stamp) Index() []byte {
+	return s.index
+}
+
+// Sig returns the signature of the stamp by the batch owner.
+func (s *Stamp) Sig() []byte {
+	return s.sig
+}
+
+// MarshalBinary serializes the stamp as:
+//
+//	batch id (32 bytes) | index (8 bytes) | signature (65 bytes)
+func (s *Stamp) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, StampSize)
+	copy(buf, s.batchID)
+	copy(buf[BatchIDSize:], s.index)
+	copy(buf[BatchIDSize+IndexSize:], s.sig)
+	return buf, nil
+}
+
+// UnmarshalBinary deserializes the stamp from the data created by
+// MarshalBinary.
+func (s *Stamp) UnmarshalBinary(buf []byte) error {
+	if len(buf) != StampSize {
+		return ErrInvalidStamp
+	}
+	s.batchID = append([]byte(nil), buf[:BatchIDSize]...)
+	s.index = append([]byte(nil), buf[BatchIDSize:BatchIDSize+IndexSize]...)
+	s.sig = append([]byte(nil), buf[BatchIDSize+IndexSize:]...)
+	return nil
+}
+
+// Valid checks the validity of the stamp on the chunk address against the
+// owner and the depth of its batch. The stamp is valid if its index is in the
+// bucket of the chunk address, it is within the capacity of the bucket and it
+// is signed by the batch owner.
+func (s *Stamp) Valid(chunkAddr swarm.Address, owner []byte, depth, bucketDepth uint8) error {
+	if len(s.index) != IndexSize {
+		return ErrInvalidStamp
+	}
+	bucket, index := bucketAndIndex(s.index)
+	if toBucket(bucketDepth, chunkAddr) != bucket {
+		return ErrBucketMismatch
+	}
+	if depth < bucketDepth || index >= 1<<(depth-bucketDepth) {
+		return ErrInvalidIndex
+	}
+	digest, err := toSignDigest(chunkAddr.Bytes(), s.batchID, s.index)
+	if err != nil {
+		return err
+	}
+	pub, err := crypto.Recover(s.sig, digest)
+	if err != nil {
+		return err
+	}
+	signer, err := crypto.NewEthereumAddress(*pub)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signer, owner) {
+		return ErrOwnerMismatch
+	}
+	return nil
+}
+
+// ValidStampFn is the function that validates the serialized stamp of the
+// chunk and returns the chunk with the stamp attached.
+type ValidStampFn func(ch swarm.Chunk, stampBytes []byte) (swarm.Chunk, error)
+
+// ValidStamp returns a stamp validating function that checks the stamp
+// against the batches from the batch store.
+func ValidStamp(batchStore Storer) ValidStampFn {
+	return func(ch swarm.Chunk, stampBytes []byte) (swarm.Chunk, error) {
+		stamp := new(Stamp)
+		if err := stamp.UnmarshalBinary(stampBytes); err != nil {
+			return nil, err
+		}
+		b, err := batchStore.Get(stamp.BatchID())
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return nil, fmt.Errorf("batch %x: %w", stamp.BatchID(), ErrBatchNotFound)
+			}
+			return nil, err
+		}
+		if err := stamp.Valid(ch.Address(), b.Owner, b.Depth, BucketDepth); err != nil {
+			return nil, err
+		}
+		return ch.WithStamp(stamp), nil
+	}
+}
+
+// toSignDigest creates a digest that represents the stamp which is to be
+// signed by the owner of the batch.
+func toSignDigest(addr, batchID, index []byte) ([]byte, error) {
+	h := sha3.NewLegacyKeccak256()
+	if _, err := h.Write(addr); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(batchID); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(index); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// toBucket returns the bucket of the chunk address, the first depth bits of
+// the address.
+func toBucket(depth uint8, addr swarm.Address) uint32 {
+	return binary.BigEndian.Uint32(addr.Bytes()[:4]) >> (32 - depth)
+}
+
+// indexToBytes serializes the bucket and the index of the chunk within the
+// bucket.
+func indexToBytes(bucket, index uint32) []byte {
+	buf := make([]byte, IndexSize)
+	binary.BigEndian.PutUint32(buf, bucket)
+	binary.BigEndian.PutUint32(buf[4:], index)
+	return buf
+}
+
+// bucketAndIndex deserializes the bucket and the index of the chunk within
+// the bucket.
+func bucketAndIndex(buf []byte) (bucket, index uint32) {
+	return binary.BigEndian.Uint32(buf[:4]), binary.BigEndian.Uint32(buf[4:])
+}
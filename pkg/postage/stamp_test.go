@@ -0,0 +1,167 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/postage/batchstore"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestStampMarshalling tests the idempotence of binary marshal/unmarshal.
+func TestStampMarshalling(t *testing.T) {
+	sExp := postage.NewStamp(randBytes(t, 32), randBytes(t, 8), randBytes(t, 65))
+	buf, err := sExp.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) != postage.StampSize {
+		t.Fatalf("invalid length for serialised stamp. expected %d, got  %d", postage.StampSize, len(buf))
+	}
+	s := new(postage.Stamp)
+	if err := s.UnmarshalBinary(buf); err != nil {
+		t.Fatalf("unexpected error unmarshalling stamp: %v", err)
+	}
+	if !bytes.Equal(sExp.BatchID(), s.BatchID()) {
+		t.Fatalf("id mismatch, expected %x, got %x", sExp.BatchID(), s.BatchID())
+	}
+	if !bytes.Equal(sExp.Index(), s.Index()) {
+		t.Fatalf("index mismatch, expected %x, got %x", sExp.Index(), s.Index())
+	}
+	if !bytes.Equal(sExp.Sig(), s.Sig()) {
+		t.Fatalf("sig mismatch, expected %x, got %x", sExp.Sig(), s.Sig())
+	}
+
+	if err := s.UnmarshalBinary(buf[1:]); !errors.Is(err, postage.ErrInvalidStamp) {
+		t.Fatalf("got error %v, want %v", err, postage.ErrInvalidStamp)
+	}
+}
+
+// TestValidStamp tests that the stamps issued by the stamper are validated
+// against the batches in the batch store.
+func TestValidStamp(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, err := crypto.NewEthereumAddress(privKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+
+	var (
+		depth   = uint8(postage.BucketDepth + 1)
+		batchID = randBytes(t, 32)
+		store   = batchstore.New(statestore.NewStateStore())
+	)
+	if err := store.Put(&postage.Batch{ID: batchID, Owner: owner, Depth: depth, Value: big.NewInt(1)}); err != nil {
+		t.Fatal(err)
+	}
+	validStamp := postage.ValidStamp(store)
+	stamper := postage.NewStamper(postage.NewStampIssuer("label", batchID, depth, postage.BucketDepth), signer)
+	ch := swarm.NewChunk(swarm.NewAddress(randBytes(t, 32)), []byte("data"))
+
+	// the bucket of the chunk takes two chunks
+	for i := 0; i < 2; i++ {
+		stamp, err := stamper.Stamp(ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		stampBytes, err := stamp.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := validStamp(ch, stampBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Stamp() == nil || !bytes.Equal(got.Stamp().BatchID(), batchID) {
+			t.Fatal("stamp not attached to the chunk")
+		}
+
+		t.Run("other chunk", func(t *testing.T) {
+			other := swarm.NewChunk(swarm.NewAddress(append([]byte{^ch.Address().Bytes()[0]}, ch.Address().Bytes()[1:]...)), []byte("data"))
+			if _, err := validStamp(other, stampBytes); !errors.Is(err, postage.ErrBucketMismatch) {
+				t.Fatalf("got error %v, want %v", err, postage.ErrBucketMismatch)
+			}
+		})
+	}
+
+	t.Run("bucket full", func(t *testing.T) {
+		if _, err := stamper.Stamp(ch.Address()); !errors.Is(err, postage.ErrBucketFull) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrBucketFull)
+		}
+	})
+
+	t.Run("not owner", func(t *testing.T) {
+		otherKey, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stamper := postage.NewStamper(postage.NewStampIssuer("label", batchID, depth, postage.BucketDepth), crypto.NewDefaultSigner(otherKey))
+		stamp, err := stamper.Stamp(ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		stampBytes, err := stamp.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := validStamp(ch, stampBytes); !errors.Is(err, postage.ErrOwnerMismatch) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrOwnerMismatch)
+		}
+	})
+
+	t.Run("index out of range", func(t *testing.T) {
+		stamper := postage.NewStamper(postage.NewStampIssuer("label", batchID, depth+1, postage.BucketDepth), signer)
+		var stampBytes []byte
+		for i := 0; i < 3; i++ {
+			stamp, err := stamper.Stamp(ch.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			stampBytes, err = stamp.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := validStamp(ch, stampBytes); !errors.Is(err, postage.ErrInvalidIndex) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrInvalidIndex)
+		}
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		stamper := postage.NewStamper(postage.NewStampIssuer("label", randBytes(t, 32), depth, postage.BucketDepth), signer)
+		stamp, err := stamper.Stamp(ch.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		stampBytes, err := stamp.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := validStamp(ch, stampBytes); !errors.Is(err, postage.ErrBatchNotFound) {
+			t.Fatalf("got error %v, want %v", err, postage.ErrBatchNotFound)
+		}
+	})
+}
+
+func randBytes(t *testing.T, n int) []byte {
+	t.Helper()
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Stamper can issue stamps from the given address.
+type Stamper interface {
+	Stamp(swarm.Address) (*Stamp, error)
+}
+
+// stamper connects a stamp issuer with a signer.
+type stamper struct {
+	issuer *StampIssuer
+	signer crypto.Signer
+}
+
+// NewStamper constructs a Stamper.
+func NewStamper(st *StampIssuer, signer crypto.Signer) Stamper {
+	return &stamper{st, signer}
+}
+
+// Stamp takes a chunk address, assigns it an index in the batch and creates
+// a stamp signed by the batch owner. The incremented bucket counter is
+// persisted before the stamp is returned, so that the index is not issued
+// again after a restart.
+func (st *stamper) Stamp(addr swarm.Address) (*Stamp, error) {
+	index, err := st.issuer.inc(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := st.issuer.save(); err != nil {
+		return nil, fmt.Errorf("save stamp issuer: %w", err)
+	}
+	digest, err := toSignDigest(addr.Bytes(), st.issuer.batchID, index)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := st.signer.Sign(digest)
+	if err != nil {
+		return nil, err
+	}
+	return NewStamp(st.issuer.batchID, index, sig), nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postage
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// stampIssuerHeaderSize is the length of the serialized stamp issuer without
+// the label and the bucket counters.
+const stampIssuerHeaderSize = BatchIDSize + 1 + 1 + 2
+
+// ErrBucketFull is returned when the bucket of the chunk address has no more
+// capacity in the batch.
+var ErrBucketFull = errors.New("bucket full")
+
+// StampIssuer is a local extension of a batch issuing stamps for the chunks
+// uploaded with the batch. It keeps track of the number of chunks stamped in
+// every bucket, so that the batch capacity is not exceeded.
+type StampIssuer struct {
+	mtx         sync.Mutex
+	label       string   // label to distinguish batches
+	batchID     []byte   // the batch stamps are issued for
+	batchDepth  uint8    // batch depth, the batch capacity is 2^batchDepth
+	bucketDepth uint8    // bucket depth, the number of buckets is 2^bucketDepth
+	buckets     []uint32 // number of chunks stamped in each bucket
+
+	saveMtx sync.Mutex          // orders the writes of the bucket counters
+	store   storage.StateStorer // set by the service the issuer is added to
+}
+
+// NewStampIssuer constructs a StampIssuer as an extension of a batch for local
+// upload.
+//
+// bucketDepth must not be larger than batchDepth, otherwise no stamps can be
+// issued.
+func NewStampIssuer(label string, batchID []byte, batchDepth, bucketDepth uint8) *StampIssuer {
+	return &StampIssuer{
+		label:       label,
+		batchID:     batchID,
+		batchDepth:  batchDepth,
+		bucketDepth: bucketDepth,
+		buckets:     make([]uint32, 1<<bucketDepth),
+	}
+}
+
+// inc increments the count in the bucket of the chunk address and returns
+// the serialized index of the chunk in the batch.
+func (st *StampIssuer) inc(addr swarm.Address) ([]byte, error) {
+	st.mtx.Lock()
+	defer st.mtx.Unlock()
+
+	b := toBucket(st.bucketDepth, addr)
+	index := st.buckets[b]
+	if st.batchDepth < st.bucketDepth || index == 1<<(st.batchDepth-st.bucketDepth) {
+		return nil, ErrBucketFull
+	}
+	st.buckets[b]++
+	return indexToBytes(b, index), nil
+}
+
+// save persists the stamp issuer with its bucket counters in the state store
+// of the service, if it was added to one. The counters are serialized under
+// saveMtx, so that a concurrent save can not overwrite them with older values.
+func (st *StampIssuer) save() error {
+	st.saveMtx.Lock()
+	defer st.saveMtx.Unlock()
+
+	if st.store == nil {
+		return nil
+	}
+	return st.store.Put(stampIssuerKey(st.batchID), st)
+}
+
+// Label returns the label of the issuer.
+func (st *StampIssuer) Label() string {
+	return st.label
+}
+
+// ID returns the batch ID of the issuer.
+func (st *StampIssuer) ID() []byte {
+	return st.batchID
+}
+
+// Depth returns the batch depth of the issuer.
+func (st *StampIssuer) Depth() uint8 {
+	return st.batchDepth
+}
+
+// BucketDepth returns the bucket depth of the issuer.
+func (st *StampIssuer) BucketDepth() uint8 {
+	return st.bucketDepth
+}
+
+// Utilization returns the number of chunks stamped in the fullest bucket.
+// The batch is exhausted when it reaches 2^(depth-bucketDepth).
+func (st *StampIssuer) Utilization() uint32 {
+	st.mtx.Lock()
+	defer st.mtx.Unlock()
+
+	var max uint32
+	for _, v := range st.buckets {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
+
+// MarshalBinary serializes the stamp issuer as:
+//
+//	batch id (32 bytes) | batch depth (1 byte) | bucket depth (1 byte) |
+//	label length (2 bytes) | label | bucket counters (4 bytes each)
+func (st *StampIssuer) MarshalBinary() ([]byte, error) {
+	st.mtx.Lock()
+	defer st.mtx.Unlock()
+
+	buf := make([]byte, stampIssuerHeaderSize, stampIssuerHeaderSize+len(st.label)+4*len(st.buckets))
+	copy(buf, st.batchID)
+	buf[BatchIDSize] = st.batchDepth
+	buf[BatchIDSize+1] = st.bucketDepth
+	binary.BigEndian.PutUint16(buf[BatchIDSize+2:], uint16(len(st.label)))
+	buf = append(buf, st.label...)
+	for _, v := range st.buckets {
+		buf = append(buf, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(buf[len(buf)-4:], v)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary deserializes the stamp issuer from the data created by
+// MarshalBinary.
+func (st *StampIssuer) UnmarshalBinary(buf []byte) error {
+	if len(buf) < stampIssuerHeaderSize {
+		return ErrInvalidBatch
+	}
+	batchDepth := buf[BatchIDSize]
+	bucketDepth := buf[BatchIDSize+1]
+	labelLen := int(binary.BigEndian.Uint16(buf[BatchIDSize+2:]))
+	if len(buf) != stampIssuerHeaderSize+labelLen+4<<bucketDepth {
+		return ErrInvalidBatch
+	}
+
+	st.mtx.Lock()
+	defer st.mtx.Unlock()
+
+	st.batchID = append([]byte(nil), buf[:BatchIDSize]...)
+	st.batchDepth = batchDepth
+	st.bucketDepth = bucketDepth
+	st.label = string(buf[stampIssuerHeaderSize : stampIssuerHeaderSize+labelLen])
+	counters := buf[stampIssuerHeaderSize+labelLen:]
+	st.buckets = make([]uint32, 1<<bucketDepth)
+	for i := range st.buckets {
+		st.buckets[i] = binary.BigEndian.Uint32(counters[4*i:])
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pricer provides the prices of the chunks served to and by the peers.
+package pricer
+
+import (
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Interface is the main interface of the pricer.
+type Interface interface {
+	// PeerPrice is the price the peer charges for the chunk.
+	PeerPrice(peer, chunk swarm.Address) uint64
+	// Price is the price we charge for the chunk.
+	Price(chunk swarm.Address) uint64
+}
+
+// FixedPricer is a pricer with a fixed price per proximity order.
+type FixedPricer struct {
+	overlay swarm.Address
+	poPrice uint64
+}
+
+// NewFixedPricer returns a new FixedPricer with the given overlay address
+// and the price per proximity order.
+func NewFixedPricer(overlay swarm.Address, poPrice uint64) *FixedPricer {
+	return &FixedPricer{
+		overlay: overlay,
+		poPrice: poPrice,
+	}
+}
+
+// PeerPrice implements Interface.
+func (pf *FixedPricer) PeerPrice(peer, chunk swarm.Address) uint64 {
+	return uint64(swarm.MaxPO-swarm.Proximity(peer.Bytes(), chunk.Bytes())+1) * pf.poPrice
+}
+
+// Price implements Interface.
+func (pf *FixedPricer) Price(chunk swarm.Address) uint64 {
+	return pf.PeerPrice(pf.overlay, chunk)
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pss provides end-to-end messaging between nodes with trojan
+// chunks.
+//
+// A message is encrypted for the recipient and wrapped in a trojan chunk,
+// a content addressed chunk whose address is mined to fall into the
+// neighbourhood of the recipient. The chunk is sent with push sync, and every
+// node that receives a chunk with push sync tries to decrypt it with its own
+// key, passing the messages that it can decrypt to the handlers registered
+// on the message topic.
+package pss
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// Interface sends and receives pss messages.
+type Interface interface {
+	// Send sends the message on the topic to the recipient, with the trojan
+	// chunk address mined to match one of the targets. The trojan chunk is
+	// stamped with the stamper, unless it is nil.
+	Send(ctx context.Context, topic Topic, msg []byte, stamper postage.Stamper, recipient *ecdsa.PublicKey, targets Targets) error
+	// Register registers the handler of the messages on the topic. The
+	// returned function deregisters the handler.
+	Register(topic Topic, handler Handler) (cleanup func())
+	// TryUnwrap tries to decrypt the chunk as a message for this node and
+	// passes it to the handlers of its topic.
+	TryUnwrap(ctx context.Context, ch swarm.Chunk) error
+}
+
+// Handler handles a received message.
+type Handler func(ctx context.Context, msg []byte)
+
+var _ Interface = (*Pss)(nil)
+
+// Pss is the pss messaging service.
+type Pss struct {
+	key        *ecdsa.PrivateKey
+	pusher     pushsync.PushSyncer
+	handlers   map[Topic][]*Handler
+	handlersMu sync.Mutex
+	logger     logging.Logger
+}
+
+// New creates a new Pss service receiving the messages encrypted for the
+// public key of the key.
+func New(key *ecdsa.PrivateKey, logger logging.Logger) *Pss {
+	return &Pss{
+		key:      key,
+		handlers: make(map[Topic][]*Handler),
+		logger:   logger,
+	}
+}
+
+// SetPushSyncer sets the push syncer used to send the messages.
+func (p *Pss) SetPushSyncer(pusher pushsync.PushSyncer) {
+	p.pusher = pusher
+}
+
+// Send implements the Interface interface.
+func (p *Pss) Send(ctx context.Context, topic Topic, msg []byte, stamper postage.Stamper, recipient *ecdsa.PublicKey, targets Targets) error {
+	if p.pusher == nil {
+		return errors.New("pss: no push syncer")
+	}
+	ch, err := Wrap(ctx, topic, msg, recipient, targets)
+	if err != nil {
+		return err
+	}
+	if stamper != nil {
+		stamp, err := stamper.Stamp(ch.Address())
+		if err != nil {
+			return fmt.Errorf("pss: stamp: %w", err)
+		}
+		ch = ch.WithStamp(stamp)
+	}
+	_, err = p.pusher.PushChunkToClosest(ctx, ch)
+	return err
+}
+
+// Register implements the Interface interface.
+func (p *Pss) Register(topic Topic, handler Handler) (cleanup func()) {
+	p.handlersMu.Lock()
+	defer p.handlersMu.Unlock()
+
+	h := &handler
+	p.handlers[topic] = append(p.handlers[topic], h)
+
+	return func() {
+		p.handlersMu.Lock()
+		defer p.handlersMu.Unlock()
+
+		hs := p.handlers[topic]
+		for i, v := range hs {
+			if v == h {
+				p.handlers[topic] = append(hs[:i:i], hs[i+1:]...)
+				break
+			}
+		}
+		if len(p.handlers[topic]) == 0 {
+			delete(p.handlers, topic)
+		}
+	}
+}
+
+// TryUnwrap implements the Interface interface. Chunks that are not messages
+// for this node are ignored, and the handlers are called in their own
+// goroutines, so that the delivery of the chunk is not delayed.
+func (p *Pss) TryUnwrap(ctx context.Context, ch swarm.Chunk) error {
+	if !p.hasHandlers() {
+		return nil
+	}
+	topic, msg, err := Unwrap(p.key, ch)
+	if err != nil {
+		if errors.Is(err, ErrNotTrojan) {
+			return nil
+		}
+		return err
+	}
+
+	handlers := p.getHandlers(topic)
+	if len(handlers) == 0 {
+		p.logger.Debugf("pss: no handler for topic %x", topic)
+		return nil
+	}
+	for _, h := range handlers {
+		go (*h)(context.Background(), msg)
+	}
+	return nil
+}
+
+func (p *Pss) hasHandlers() bool {
+	p.handlersMu.Lock()
+	defer p.handlersMu.Unlock()
+
+	return len(p.handlers) > 0
+}
+
+func (p *Pss) getHandlers(topic Topic) []*Handler {
+	p.handlersMu.Lock()
+	defer p.handlersMu.Unlock()
+
+	return append([]*Handler(nil), p.handlers[topic]...)
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/pushsync"
+	pushsyncmock "github.com/ethersphere/bee/pkg/pushsync/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestSendReceive sends a message from one node to another, passing the
+// pushed trojan chunk to the recipient as if it was delivered by push sync.
+func TestSendReceive(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+
+	senderKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipient := pss.New(recipientKey, logger)
+	sender := pss.New(senderKey, logger)
+	sender.SetPushSyncer(pushsyncmock.New(func(ctx context.Context, ch swarm.Chunk) (*pushsync.Receipt, error) {
+		// the sender does not receive its own messages
+		if err := sender.TryUnwrap(ctx, ch); err != nil {
+			return nil, err
+		}
+		if err := recipient.TryUnwrap(ctx, ch); err != nil {
+			return nil, err
+		}
+		return &pushsync.Receipt{Address: ch.Address()}, nil
+	}))
+
+	topic := pss.NewTopic("topic")
+	received := make(chan []byte, 1)
+	cleanup := recipient.Register(topic, func(_ context.Context, msg []byte) {
+		received <- msg
+	})
+	senderReceived := make(chan []byte, 1)
+	defer sender.Register(topic, func(_ context.Context, msg []byte) {
+		senderReceived <- msg
+	})()
+	otherReceived := make(chan []byte, 1)
+	defer recipient.Register(pss.NewTopic("other topic"), func(_ context.Context, msg []byte) {
+		otherReceived <- msg
+	})()
+
+	msg := []byte("hello")
+	if err := sender.Send(context.Background(), topic, msg, nil, &recipientKey.PublicKey, pss.Targets{{0}}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-received:
+		if string(got) != string(msg) {
+			t.Fatalf("got message %q, want %q", got, msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not received")
+	}
+	select {
+	case <-senderReceived:
+		t.Fatal("sender received the message")
+	case <-otherReceived:
+		t.Fatal("message received on other topic")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// no messages are received after the handler is deregistered
+	cleanup()
+	if err := sender.Send(context.Background(), topic, msg, nil, &recipientKey.PublicKey, pss.Targets{{0}}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-received:
+		t.Fatal("message received by deregistered handler")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
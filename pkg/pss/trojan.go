@@ -0,0 +1,227 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/swarm"
+	bmtlegacy "github.com/ethersphere/bmt/legacy"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	nonceSize     = 32
+	publicKeySize = 33
+	macSize       = 32
+	topicSize     = 32
+	lengthSize    = 2
+	// ciphertextSize is the length of the encrypted part of the trojan
+	// chunk payload.
+	ciphertextSize = swarm.ChunkSize - nonceSize - publicKeySize
+	// MaxPayloadSize is the maximal length of a message.
+	MaxPayloadSize = ciphertextSize - macSize - topicSize - lengthSize
+)
+
+var (
+	// ErrPayloadTooBig is returned when the message is longer than
+	// MaxPayloadSize.
+	ErrPayloadTooBig = fmt.Errorf("message payload size cannot be greater than %d bytes", MaxPayloadSize)
+	// ErrEmptyTargets is returned when no targets are given.
+	ErrEmptyTargets = errors.New("target list cannot be empty")
+	// ErrInvalidTargets is returned when a target is empty or longer than
+	// the address.
+	ErrInvalidTargets = errors.New("target length must be between 1 and 32 bytes")
+	// ErrNotTrojan is returned when the chunk is not a trojan chunk for the
+	// key it is unwrapped with.
+	ErrNotTrojan = errors.New("not a trojan chunk for the key")
+)
+
+// Topic is the identifier of the messages of the same kind.
+type Topic [topicSize]byte
+
+// NewTopic creates a new Topic from the name.
+func NewTopic(name string) Topic {
+	var t Topic
+	copy(t[:], legacyKeccak256([]byte(name)))
+	return t
+}
+
+// Target is the prefix of the address of the trojan chunk, which needs to
+// fall into the neighbourhood of the recipient.
+type Target []byte
+
+// Targets is a list of alternative targets.
+type Targets []Target
+
+// Wrap creates a trojan chunk with the message on the topic encrypted for the
+// recipient. The nonce of the chunk is mined so that the chunk address
+// matches one of the targets.
+//
+// The chunk payload is serialized as:
+//
+//	nonce (32 bytes) | ephemeral public key (33 bytes) | ciphertext
+//
+// where the plaintext of the ciphertext is:
+//
+//	mac (32 bytes) | topic (32 bytes) | length (2 bytes) | message | padding
+func Wrap(ctx context.Context, topic Topic, msg []byte, recipient *ecdsa.PublicKey, targets Targets) (swarm.Chunk, error) {
+	if len(msg) > MaxPayloadSize {
+		return nil, ErrPayloadTooBig
+	}
+	if err := checkTargets(targets); err != nil {
+		return nil, err
+	}
+
+	ephemeral, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		return nil, err
+	}
+	key := sharedKey(ephemeral, recipient)
+
+	plaintext := make([]byte, macSize+topicSize+lengthSize+len(msg))
+	copy(plaintext[macSize:], topic[:])
+	binary.BigEndian.PutUint16(plaintext[macSize+topicSize:], uint16(len(msg)))
+	copy(plaintext[macSize+topicSize+lengthSize:], msg)
+	copy(plaintext, mac(key, plaintext[macSize:]))
+
+	ciphertext, err := encryption.New(key, ciphertextSize, 0, hashFunc).Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, swarm.ChunkSize)
+	if _, err := rand.Read(payload[:nonceSize]); err != nil {
+		return nil, err
+	}
+	copy(payload[nonceSize:], crypto.EncodeSecp256k1PublicKey(&ephemeral.PublicKey))
+	copy(payload[nonceSize+publicKeySize:], ciphertext)
+
+	return mine(ctx, payload, targets)
+}
+
+// Unwrap decrypts the trojan chunk with the key, returning the topic and the
+// message. ErrNotTrojan is returned if the chunk is not a trojan chunk
+// encrypted for the key.
+func Unwrap(key *ecdsa.PrivateKey, ch swarm.Chunk) (Topic, []byte, error) {
+	var topic Topic
+	data := ch.Data()
+	if len(data) != 8+swarm.ChunkSize {
+		return topic, nil, ErrNotTrojan
+	}
+	payload := data[8:]
+
+	ephemeral, err := crypto.DecodeSecp256k1PublicKey(payload[nonceSize : nonceSize+publicKeySize])
+	if err != nil {
+		return topic, nil, ErrNotTrojan
+	}
+	k := sharedKey(key, ephemeral)
+
+	plaintext, err := encryption.New(k, ciphertextSize, 0, hashFunc).Decrypt(payload[nonceSize+publicKeySize:])
+	if err != nil {
+		return topic, nil, err
+	}
+	length := int(binary.BigEndian.Uint16(plaintext[macSize+topicSize:]))
+	if length > MaxPayloadSize {
+		return topic, nil, ErrNotTrojan
+	}
+	end := macSize + topicSize + lengthSize + length
+	if string(mac(k, plaintext[macSize:end])) != string(plaintext[:macSize]) {
+		return topic, nil, ErrNotTrojan
+	}
+
+	copy(topic[:], plaintext[macSize:])
+	return topic, plaintext[macSize+topicSize+lengthSize : end], nil
+}
+
+// mine increments the nonce at the start of the payload until the address
+// of the chunk matches one of the targets.
+func mine(ctx context.Context, payload []byte, targets Targets) (swarm.Chunk, error) {
+	span := make([]byte, 8)
+	binary.LittleEndian.PutUint64(span, uint64(len(payload)))
+
+	p := bmtlegacy.NewTreePool(hashFunc, swarm.Branches, bmtlegacy.PoolSize)
+	hasher := bmtlegacy.New(p)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		hasher.Reset()
+		if err := hasher.SetSpan(int64(len(payload))); err != nil {
+			return nil, err
+		}
+		if _, err := hasher.Write(payload); err != nil {
+			return nil, err
+		}
+		address := hasher.Sum(nil)
+		for _, t := range targets {
+			if string(address[:len(t)]) == string(t) {
+				return swarm.NewChunk(swarm.NewAddress(address), append(span, payload...)), nil
+			}
+		}
+
+		incrementNonce(payload[:nonceSize])
+	}
+}
+
+// incrementNonce increments the big endian number in place.
+func incrementNonce(nonce []byte) {
+	for i := len(nonce) - 1; i >= 0; i-- {
+		nonce[i]++
+		if nonce[i] != 0 {
+			return
+		}
+	}
+}
+
+func checkTargets(targets Targets) error {
+	if len(targets) == 0 {
+		return ErrEmptyTargets
+	}
+	for _, t := range targets {
+		if len(t) == 0 || len(t) > swarm.HashSize {
+			return ErrInvalidTargets
+		}
+	}
+	return nil
+}
+
+// sharedKey returns the symmetric key derived from the elliptic curve
+// Diffie-Hellman shared secret of the private and the public key.
+func sharedKey(private *ecdsa.PrivateKey, public *ecdsa.PublicKey) encryption.Key {
+	x, _ := btcec.S256().ScalarMult(public.X, public.Y, private.D.Bytes())
+	secret := make([]byte, 32)
+	xb := x.Bytes()
+	copy(secret[32-len(xb):], xb)
+	return legacyKeccak256(secret)
+}
+
+// mac returns the message authentication code of the data.
+func mac(key encryption.Key, data []byte) []byte {
+	return legacyKeccak256(append(append([]byte(nil), key...), data...))
+}
+
+func legacyKeccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	_, _ = h.Write(data)
+	return h.Sum(nil)
+}
+
+// hashFunc is a hasher factory used by the bmt hasher and the encryption
+func hashFunc() hash.Hash {
+	return sha3.NewLegacyKeccak256()
+}
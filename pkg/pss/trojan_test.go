@@ -0,0 +1,113 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pss_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/pss"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/validator"
+)
+
+func TestWrapUnwrap(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic := pss.NewTopic("topic")
+	msg := []byte("some payload")
+	targets := pss.Targets{{0x0f}, {0xa0}}
+
+	ch, err := pss.Wrap(context.Background(), topic, msg, &key.PublicKey, targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !validator.NewContentAddressValidator().Validate(ch) {
+		t.Fatal("trojan chunk is not a valid content addressed chunk")
+	}
+	if b := ch.Address().Bytes()[0]; b != 0x0f && b != 0xa0 {
+		t.Fatalf("chunk address %s does not match targets", ch.Address())
+	}
+	if bytes.Contains(ch.Data(), msg) {
+		t.Fatal("message is not encrypted")
+	}
+
+	gotTopic, gotMsg, err := pss.Unwrap(key, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotTopic != topic {
+		t.Fatalf("got topic %x, want %x", gotTopic, topic)
+	}
+	if !bytes.Equal(gotMsg, msg) {
+		t.Fatalf("got message %q, want %q", gotMsg, msg)
+	}
+
+	t.Run("other key", func(t *testing.T) {
+		other, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := pss.Unwrap(other, ch); !errors.Is(err, pss.ErrNotTrojan) {
+			t.Fatalf("got error %v, want %v", err, pss.ErrNotTrojan)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		data := append([]byte(nil), ch.Data()...)
+		// change a byte of the encrypted message after the span, nonce,
+		// public key, mac, topic and length
+		data[8+32+33+32+32+2]++
+		if _, _, err := pss.Unwrap(key, swarm.NewChunk(ch.Address(), data)); !errors.Is(err, pss.ErrNotTrojan) {
+			t.Fatalf("got error %v, want %v", err, pss.ErrNotTrojan)
+		}
+	})
+
+	t.Run("not trojan", func(t *testing.T) {
+		if _, _, err := pss.Unwrap(key, swarm.NewChunk(ch.Address(), []byte("foo"))); !errors.Is(err, pss.ErrNotTrojan) {
+			t.Fatalf("got error %v, want %v", err, pss.ErrNotTrojan)
+		}
+	})
+}
+
+func TestWrapErrors(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic := pss.NewTopic("topic")
+
+	for _, tc := range []struct {
+		name    string
+		msg     []byte
+		targets pss.Targets
+		err     error
+	}{
+		{"payload too big", make([]byte, pss.MaxPayloadSize+1), pss.Targets{{1}}, pss.ErrPayloadTooBig},
+		{"no targets", []byte("foo"), nil, pss.ErrEmptyTargets},
+		{"empty target", []byte("foo"), pss.Targets{{}}, pss.ErrInvalidTargets},
+		{"long target", []byte("foo"), pss.Targets{make([]byte, 33)}, pss.ErrInvalidTargets},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := pss.Wrap(context.Background(), topic, tc.msg, &key.PublicKey, tc.targets); !errors.Is(err, tc.err) {
+				t.Fatalf("got error %v, want %v", err, tc.err)
+			}
+		})
+	}
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := pss.Wrap(ctx, topic, []byte("foo"), &key.PublicKey, pss.Targets{make([]byte, 32)}); !errors.Is(err, context.Canceled) {
+			t.Fatalf("got error %v, want %v", err, context.Canceled)
+		}
+	})
+}
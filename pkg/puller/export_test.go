@@ -5,4 +5,6 @@ var (
 	Bins            = &bins
 	ShallowBinPeers = &shallowBinPeers
 	IsSyncing       = isSyncing
+
+	LowDiskSpaceBackoff = &lowDiskSpaceBackoff
 )
@@ -6,6 +6,7 @@ package puller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
@@ -25,6 +26,10 @@ var (
 	// how many peers per bin do we want to sync with outside of depth
 	shallowBinPeers = 2
 	logMore         = false // enable this to get more logging
+
+	// lowDiskSpaceBackoff is the time to wait before syncing the interval
+	// again when the synced chunks are not stored due to the low disk space
+	lowDiskSpaceBackoff = time.Minute
 )
 
 type Options struct {
@@ -381,6 +386,13 @@ func (p *Puller) histSyncWorker(ctx context.Context, peer swarm.Address, bin uin
 			return
 		}
 		top, ruid, err := p.syncer.SyncInterval(ctx, peer, bin, s, cur)
+		if errors.Is(err, storage.ErrLowDiskSpace) {
+			p.logger.Debugf("histSyncWorker low disk space, backing off. peer %s bin %d cursor %d", peer, bin, cur)
+			if !p.backoffLowDiskSpace(ctx, peer, ruid) {
+				return
+			}
+			continue
+		}
 		if err != nil {
 			if logMore {
 				p.logger.Debugf("histSyncWorker error syncing interval. peer %s, bin %d, cursor %d, err %v", peer.String(), bin, cur, err)
@@ -422,6 +434,13 @@ func (p *Puller) liveSyncWorker(ctx context.Context, peer swarm.Address, bin uin
 		default:
 		}
 		top, ruid, err := p.syncer.SyncInterval(ctx, peer, bin, from, math.MaxUint64)
+		if errors.Is(err, storage.ErrLowDiskSpace) {
+			p.logger.Debugf("liveSyncWorker low disk space, backing off. peer %s bin %d from %d", peer, bin, from)
+			if !p.backoffLowDiskSpace(ctx, peer, ruid) {
+				return
+			}
+			continue
+		}
 		if err != nil {
 			if logMore {
 				p.logger.Debugf("liveSyncWorker exit on sync error. peer %s bin %d from %d err %v", peer, bin, from, err)
@@ -446,6 +465,25 @@ func (p *Puller) liveSyncWorker(ctx context.Context, peer swarm.Address, bin uin
 	}
 }
 
+// backoffLowDiskSpace cancels the interval which was not stored due to the low
+// disk space and waits before it is synced again. It returns false if the
+// sync worker needs to quit.
+func (p *Puller) backoffLowDiskSpace(ctx context.Context, peer swarm.Address, ruid uint32) bool {
+	if ruid != 0 {
+		if err := p.syncer.CancelRuid(peer, ruid); err != nil && logMore {
+			p.logger.Debugf("backoffLowDiskSpace cancel ruid: %v", err)
+		}
+	}
+	select {
+	case <-time.After(lowDiskSpaceBackoff):
+		return true
+	case <-p.quit:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (p *Puller) Close() error {
 	p.logger.Info("puller shutting down")
 	close(p.quit)
@@ -213,6 +213,45 @@ func TestSyncFlow_PeerOutsideDepth_Historical(t *testing.T) {
 	}
 }
 
+// TestSyncFlow_LowDiskSpace tests that the interval which is not stored due to
+// the low disk space is synced again after the backoff.
+func TestSyncFlow_LowDiskSpace(t *testing.T) {
+	defer func(b uint8, d time.Duration) {
+		*puller.Bins = b
+		*puller.LowDiskSpaceBackoff = d
+	}(*puller.Bins, *puller.LowDiskSpaceBackoff)
+	*puller.Bins = 5
+	*puller.LowDiskSpaceBackoff = 10 * time.Millisecond
+
+	addr := test.RandomAddress()
+
+	puller, st, kad, pullsync := newPuller(opts{
+		kad: []mockk.Option{
+			mockk.WithEachPeerRevCalls(
+				mockk.AddrTuple{Addr: addr, PO: 1},
+			), mockk.WithDepth(2),
+		},
+		pullSync: []mockps.Option{
+			mockps.WithCursors([]uint64{0, 10}),
+			mockps.WithAutoReply(),
+			mockps.WithLiveSyncBlock(),
+			mockps.WithSyncErrors(storage.ErrLowDiskSpace),
+		},
+	})
+	defer puller.Close()
+	defer pullsync.Close()
+	runtime.Gosched()
+	time.Sleep(10 * time.Millisecond)
+
+	kad.Trigger()
+
+	waitCursorsCalled(t, pullsync, addr, false)
+	waitSyncCalledTimes(t, pullsync, addr, 2)
+
+	checkCalls(t, []c{call(1, 1, 10), call(1, 1, 10)}, pullsync.SyncCalls(addr))
+	checkIntervals(t, st, addr, "[[1 10]]", 1)
+}
+
 func TestSyncFlow_PeerWithinDepth_Live(t *testing.T) {
 	defer func(b uint8) {
 		*puller.Bins = b
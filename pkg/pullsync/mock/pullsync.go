@@ -45,6 +45,14 @@ func WithLiveSyncReplies(r ...uint64) Option {
 	})
 }
 
+// WithSyncErrors makes the historical sync requests return the errors, one
+// for each request, before they are replied.
+func WithSyncErrors(errs ...error) Option {
+	return optionFunc(func(p *PullSyncMock) {
+		p.syncErrors = errs
+	})
+}
+
 func WithLateSyncReply(r ...SyncReply) Option {
 	return optionFunc(func(p *PullSyncMock) {
 		p.lateReply = true
@@ -86,6 +94,7 @@ type PullSyncMock struct {
 	blockLiveSync   bool
 	liveSyncReplies []uint64
 	liveSyncCalls   int
+	syncErrors      []error
 
 	lateReply       bool
 	lateCond        *sync.Cond
@@ -178,6 +187,17 @@ func (p *PullSyncMock) SyncInterval(ctx context.Context, peer swarm.Address, bin
 		return v, 1, nil
 	}
 
+	if !isLive {
+		p.mtx.Lock()
+		if len(p.syncErrors) > 0 {
+			err := p.syncErrors[0]
+			p.syncErrors = p.syncErrors[1:]
+			p.mtx.Unlock()
+			return 0, 1, err
+		}
+		p.mtx.Unlock()
+	}
+
 	if p.autoReply {
 		t := from + limit - 1
 		// floor to the cursor
@@ -18,7 +18,7 @@ import (
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
-
+	"github.com/ethersphere/bee/pkg/postage"
 	"github.com/ethersphere/bee/pkg/pullsync/pb"
 	"github.com/ethersphere/bee/pkg/pullsync/pullstorage"
 	"github.com/ethersphere/bee/pkg/storage"
@@ -47,11 +47,12 @@ type Interface interface {
 }
 
 type Syncer struct {
-	streamer p2p.Streamer
-	logger   logging.Logger
-	storage  pullstorage.Storer
-	quit     chan struct{}
-	wg       sync.WaitGroup
+	streamer   p2p.Streamer
+	logger     logging.Logger
+	storage    pullstorage.Storer
+	validStamp postage.ValidStampFn
+	quit       chan struct{}
+	wg         sync.WaitGroup
 
 	ruidMtx sync.Mutex
 	ruidCtx map[uint32]func()
@@ -63,18 +64,22 @@ type Syncer struct {
 type Options struct {
 	Streamer p2p.Streamer
 	Storage  pullstorage.Storer
+	// ValidStamp validates the postage stamps of the delivered chunks.
+	// Chunks are accepted without validation of their stamps if it is nil.
+	ValidStamp postage.ValidStampFn
 
 	Logger logging.Logger
 }
 
 func New(o Options) *Syncer {
 	return &Syncer{
-		streamer: o.Streamer,
-		storage:  o.Storage,
-		logger:   o.Logger,
-		ruidCtx:  make(map[uint32]func()),
-		wg:       sync.WaitGroup{},
-		quit:     make(chan struct{}),
+		streamer:   o.Streamer,
+		storage:    o.Storage,
+		validStamp: o.ValidStamp,
+		logger:     o.Logger,
+		ruidCtx:    make(map[uint32]func()),
+		wg:         sync.WaitGroup{},
+		quit:       make(chan struct{}),
 	}
 }
 
@@ -197,13 +202,40 @@ func (s *Syncer) SyncInterval(ctx context.Context, peer swarm.Address, bin uint8
 
 		delete(wantChunks, addr.String())
 
-		if err = s.storage.Put(ctx, storage.ModePutSync, swarm.NewChunk(addr, delivery.Data)); err != nil {
+		chunk, err := s.deliveredChunk(addr, &delivery)
+		if err != nil {
+			return 0, ru.Ruid, fmt.Errorf("delivery: %w", err)
+		}
+
+		if err = s.storage.Put(ctx, storage.ModePutSync, chunk); err != nil {
+			if errors.Is(err, storage.ErrLowDiskSpace) {
+				// the rest of the interval is not stored either,
+				// it is synced again when the puller backs off
+				return 0, ru.Ruid, err
+			}
 			return 0, ru.Ruid, fmt.Errorf("delivery put: %w", err)
 		}
 	}
 	return offer.Topmost, ru.Ruid, nil
 }
 
+// deliveredChunk creates the chunk from the delivery with its postage stamp
+// attached, validating the stamp if the stamp validation is set.
+func (s *Syncer) deliveredChunk(addr swarm.Address, delivery *pb.Delivery) (swarm.Chunk, error) {
+	chunk := swarm.NewChunk(addr, delivery.Data)
+	if s.validStamp != nil {
+		return s.validStamp(chunk, delivery.Stamp)
+	}
+	if len(delivery.Stamp) > 0 {
+		stamp := new(postage.Stamp)
+		if err := stamp.UnmarshalBinary(delivery.Stamp); err != nil {
+			return nil, err
+		}
+		chunk = chunk.WithStamp(stamp)
+	}
+	return chunk, nil
+}
+
 // handler handles an incoming request to sync an interval
 func (s *Syncer) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) error {
 	w, r := protobuf.NewWriterAndReader(stream)
@@ -280,6 +312,12 @@ func (s *Syncer) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) err
 
 	for _, v := range chs {
 		deliver := pb.Delivery{Address: v.Address().Bytes(), Data: v.Data()}
+		if stamp := v.Stamp(); stamp != nil {
+			deliver.Stamp, err = stamp.MarshalBinary()
+			if err != nil {
+				return fmt.Errorf("marshal stamp: %w", err)
+			}
+		}
 		if err := w.WriteMsgWithContext(ctx, &deliver); err != nil {
 			return fmt.Errorf("write delivery: %w", err)
 		}
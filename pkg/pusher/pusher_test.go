@@ -65,7 +65,7 @@ func TestSendChunkToPushSync(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	tag.Address = chunk.Address()
+	tag.SetAddress(chunk.Address())
 	p, storer := createPusher(t, triggerPeer, pushSyncService, mtag, mock.WithClosestPeer(closestPeer))
 	defer storer.Close()
 
@@ -153,7 +153,7 @@ func TestSendChunkAndReceiveInvalidReceipt(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	tag.Address = chunk.Address()
+	tag.SetAddress(chunk.Address())
 	p, storer := createPusher(t, triggerPeer, pushSyncService, mtag, mock.WithClosestPeer(closestPeer))
 	defer storer.Close()
 
@@ -200,7 +200,7 @@ func TestSendChunkAndTimeoutinReceivingReceipt(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	tag.Address = chunk.Address()
+	tag.SetAddress(chunk.Address())
 	p, storer := createPusher(t, triggerPeer, pushSyncService, mtag, mock.WithClosestPeer(closestPeer))
 	defer storer.Close()
 	defer p.Close()
@@ -4,8 +4,14 @@
 
 package pushsync
 
+import "time"
+
 var (
 	ProtocolName    = protocolName
 	ProtocolVersion = protocolVersion
 	StreamName      = streamName
 )
+
+func (ps *PushSync) PruneReceipts(now time.Time) error {
+	return ps.pruneReceipts(now)
+}
@@ -25,6 +25,7 @@ type metrics struct {
 	ReceiveReceiptErrorCounter prometheus.Counter
 	RetriesExhaustedCounter    prometheus.Counter
 	InvalidReceiptReceived     prometheus.Counter
+	InvalidStampErrors         prometheus.Counter
 	SendChunkTimer             prometheus.Histogram
 	ReceiptRTT                 prometheus.Histogram
 }
@@ -99,6 +100,12 @@ func newMetrics() metrics {
 			Name:      "invalid_receipt_receipt",
 			Help:      "Invalid receipt received from peer.",
 		}),
+		InvalidStampErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: m.Namespace,
+			Subsystem: subsystem,
+			Name:      "invalid_stamp_errors",
+			Help:      "Chunks with invalid postage stamps received from peer.",
+		}),
 		SendChunkTimer: prometheus.NewHistogram(prometheus.HistogramOpts{
 			Namespace: m.Namespace,
 			Subsystem: subsystem,
@@ -6,6 +6,7 @@ package mock
 
 import (
 	"context"
+	"sync"
 
 	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -13,12 +14,36 @@ import (
 
 type PushSync struct {
 	sendChunk func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error)
+	mtx       sync.Mutex
+	receipts  map[string]*pushsync.Receipt
 }
 
 func New(sendChunk func(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error)) *PushSync {
-	return &PushSync{sendChunk: sendChunk}
+	return &PushSync{
+		sendChunk: sendChunk,
+		receipts:  make(map[string]*pushsync.Receipt),
+	}
 }
 
 func (s *PushSync) PushChunkToClosest(ctx context.Context, chunk swarm.Chunk) (*pushsync.Receipt, error) {
-	return s.sendChunk(ctx, chunk)
+	receipt, err := s.sendChunk(ctx, chunk)
+	if err != nil {
+		return receipt, err
+	}
+	s.mtx.Lock()
+	s.receipts[chunk.Address().String()] = receipt
+	s.mtx.Unlock()
+	return receipt, nil
+}
+
+// LastReceipt returns the last receipt returned for the chunk.
+func (s *PushSync) LastReceipt(addr swarm.Address) (*pushsync.Receipt, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	receipt, ok := s.receipts[addr.String()]
+	if !ok {
+		return nil, pushsync.ErrNoReceipt
+	}
+	return receipt, nil
 }
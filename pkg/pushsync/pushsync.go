@@ -8,11 +8,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/accounting"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/pricer"
 	"github.com/ethersphere/bee/pkg/pushsync/pb"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -29,38 +34,93 @@ type PushSyncer interface {
 	PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Receipt, error)
 }
 
-type Receipt struct {
-	Address swarm.Address
-}
-
 type PushSync struct {
-	streamer      p2p.Streamer
-	storer        storage.Putter
-	peerSuggester topology.ClosestPeerer
-	logger        logging.Logger
-	metrics       metrics
+	address          swarm.Address
+	networkID        uint64
+	signer           crypto.Signer
+	store            storage.StateStorer
+	depther          topology.NeighborhoodDepther
+	streamer         p2p.Streamer
+	storer           storage.Putter
+	peerSuggester    topology.ClosestPeerer
+	deliveryCallback func(context.Context, swarm.Chunk) error
+	validStamp       postage.ValidStampFn
+	accounting       accounting.Interface
+	pricer           pricer.Interface
+	logger           logging.Logger
+	metrics          metrics
+	receiptRetention time.Duration
+	quit             chan struct{}
+	wg               sync.WaitGroup
 }
 
 type Options struct {
+	// Address is the overlay address of the node, derived from the public
+	// key of the Signer, which signs the receipts of the chunks stored by
+	// the node.
+	Address   swarm.Address
+	NetworkID uint64
+	Signer    crypto.Signer
+	// Store persists the last receipts of the pushed chunks.
+	Store storage.StateStorer
+	// ReceiptRetention is the time the receipts are kept for in the Store.
+	// Zero keeps the receipts forever.
+	ReceiptRetention time.Duration
 	Streamer      p2p.Streamer
 	Storer        storage.Putter
 	ClosestPeerer topology.ClosestPeerer
-	Logger        logging.Logger
+	// NeighborhoodDepther provides the depth of the neighborhood of the
+	// chunks which the storers of the receipts must be within.
+	NeighborhoodDepther topology.NeighborhoodDepther
+	// DeliveryCallback is called with every chunk delivered by other nodes.
+	DeliveryCallback func(context.Context, swarm.Chunk) error
+	// ValidStamp validates the postage stamps of the delivered chunks.
+	// Chunks are accepted without validation of their stamps if it is nil.
+	ValidStamp postage.ValidStampFn
+	Accounting accounting.Interface
+	Pricer     pricer.Interface
+	Logger     logging.Logger
 }
 
-var timeToWaitForReceipt = 3 * time.Second // time to wait to get a receipt for a chunk
+var (
+	timeToWaitForReceipt  = 3 * time.Second  // time to wait to get a receipt for a chunk
+	receiptsPruneInterval = 10 * time.Minute // time between the removals of the expired receipts
+)
 
 func New(o Options) *PushSync {
 	ps := &PushSync{
-		streamer:      o.Streamer,
-		storer:        o.Storer,
-		peerSuggester: o.ClosestPeerer,
-		logger:        o.Logger,
-		metrics:       newMetrics(),
+		address:          o.Address,
+		networkID:        o.NetworkID,
+		signer:           o.Signer,
+		store:            o.Store,
+		depther:          o.NeighborhoodDepther,
+		streamer:         o.Streamer,
+		storer:           o.Storer,
+		peerSuggester:    o.ClosestPeerer,
+		deliveryCallback: o.DeliveryCallback,
+		validStamp:       o.ValidStamp,
+		accounting:       o.Accounting,
+		pricer:           o.Pricer,
+		logger:           o.Logger,
+		metrics:          newMetrics(),
+		receiptRetention: o.ReceiptRetention,
+		quit:             make(chan struct{}),
+	}
+
+	if ps.receiptRetention > 0 {
+		ps.wg.Add(1)
+		go ps.pruneReceiptsLoop()
 	}
 	return ps
 }
 
+// Close stops the removal of the expired receipts.
+func (ps *PushSync) Close() error {
+	close(ps.quit)
+	ps.wg.Wait()
+	return nil
+}
+
 func (s *PushSync) Protocol() p2p.ProtocolSpec {
 	return p2p.ProtocolSpec{
 		Name:    protocolName,
@@ -86,6 +146,12 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 		return fmt.Errorf("chunk delivery from peer %s: %w", p.Address.String(), err)
 	}
 
+	if ps.deliveryCallback != nil {
+		if err := ps.deliveryCallback(ctx, chunk); err != nil {
+			ps.logger.Debugf("pushsync: delivery callback for chunk %s: %v", chunk.Address(), err)
+		}
+	}
+
 	// Select the closest peer to forward the chunk
 	peer, err := ps.peerSuggester.ClosestPeer(chunk.Address())
 	if err != nil {
@@ -100,12 +166,15 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 			ps.metrics.TotalChunksStoredInDB.Inc()
 
 			// Send a receipt immediately once the storage of the chunk is successfully
-			receipt := &pb.Receipt{Address: chunk.Address().Bytes()}
+			receipt, err := ps.signReceipt(chunk.Address())
+			if err != nil {
+				return err
+			}
 			err = ps.sendReceipt(w, receipt)
 			if err != nil {
 				return fmt.Errorf("send receipt to peer %s: %w", p.Address.String(), err)
 			}
-			return nil
+			return ps.accounting.Debit(p.Address, ps.pricer.Price(chunk.Address()))
 		}
 		return err
 	}
@@ -122,10 +191,23 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 		ps.metrics.TotalChunksStoredInDB.Inc()
 
 		// Send a receipt immediately once the storage of the chunk is successfully
-		receipt := &pb.Receipt{Address: chunk.Address().Bytes()}
-		return ps.sendReceipt(w, receipt)
+		receipt, err := ps.signReceipt(chunk.Address())
+		if err != nil {
+			return err
+		}
+		if err := ps.sendReceipt(w, receipt); err != nil {
+			return fmt.Errorf("send receipt to peer %s: %w", p.Address.String(), err)
+		}
+		return ps.accounting.Debit(p.Address, ps.pricer.Price(chunk.Address()))
 	}
 
+	// compute the price we pay for this receipt and reserve it for the rest of this function
+	receiptPrice := ps.pricer.PeerPrice(peer, chunk.Address())
+	if err := ps.accounting.Reserve(ctx, peer, receiptPrice); err != nil {
+		return fmt.Errorf("reserve balance for peer %s: %w", peer.String(), err)
+	}
+	defer ps.accounting.Release(peer, receiptPrice)
+
 	// Forward chunk to closest peer
 	streamer, err := ps.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
 	if err != nil {
@@ -146,10 +228,14 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	}
 	ps.metrics.ReceiptRTT.Observe(time.Since(receiptRTTTimer).Seconds())
 
-	// Check if the receipt is valid
-	if !chunk.Address().Equal(swarm.NewAddress(receipt.Address)) {
+	// Check if the receipt is valid before paying for it
+	if _, err := ps.verifyReceipt(chunk.Address(), &receipt); err != nil {
 		ps.metrics.InvalidReceiptReceived.Inc()
-		return fmt.Errorf("invalid receipt from peer %s", peer.String())
+		return fmt.Errorf("receipt from peer %s: %w", peer.String(), err)
+	}
+
+	if err := ps.accounting.Credit(peer, receiptPrice); err != nil {
+		return err
 	}
 
 	// pass back the received receipt in the previously received stream
@@ -159,7 +245,7 @@ func (ps *PushSync) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream)
 	}
 	ps.metrics.ReceiptsSentCounter.Inc()
 
-	return nil
+	return ps.accounting.Debit(p.Address, ps.pricer.Price(chunk.Address()))
 }
 
 func (ps *PushSync) getChunkDelivery(r protobuf.Reader) (chunk swarm.Chunk, err error) {
@@ -173,14 +259,37 @@ func (ps *PushSync) getChunkDelivery(r protobuf.Reader) (chunk swarm.Chunk, err
 	// create chunk
 	addr := swarm.NewAddress(ch.Address)
 	chunk = swarm.NewChunk(addr, ch.Data)
+
+	if ps.validStamp != nil {
+		chunk, err = ps.validStamp(chunk, ch.Stamp)
+		if err != nil {
+			ps.metrics.InvalidStampErrors.Inc()
+			return nil, fmt.Errorf("invalid stamp: %w", err)
+		}
+	} else if len(ch.Stamp) > 0 {
+		stamp := new(postage.Stamp)
+		if err := stamp.UnmarshalBinary(ch.Stamp); err != nil {
+			ps.metrics.InvalidStampErrors.Inc()
+			return nil, fmt.Errorf("invalid stamp: %w", err)
+		}
+		chunk = chunk.WithStamp(stamp)
+	}
 	return chunk, nil
 }
 
 func (ps *PushSync) sendChunkDelivery(w protobuf.Writer, chunk swarm.Chunk) (err error) {
+	var stamp []byte
+	if s := chunk.Stamp(); s != nil {
+		stamp, err = s.MarshalBinary()
+		if err != nil {
+			return err
+		}
+	}
 	startTimer := time.Now()
 	if err = w.WriteMsgWithTimeout(timeToWaitForReceipt, &pb.Delivery{
 		Address: chunk.Address().Bytes(),
 		Data:    chunk.Data(),
+		Stamp:   stamp,
 	}); err != nil {
 		ps.metrics.SendChunkErrorCounter.Inc()
 		return err
@@ -216,13 +325,27 @@ func (ps *PushSync) PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Re
 	if err != nil {
 		if errors.Is(err, topology.ErrWantSelf) {
 			// if you are the closest node return a receipt immediately
-			return &Receipt{
-				Address: ch.Address(),
-			}, nil
+			receipt, err := ps.signReceipt(ch.Address())
+			if err != nil {
+				return nil, err
+			}
+			return ps.storeReceipt(&Receipt{
+				Address:   ch.Address(),
+				Storer:    ps.address,
+				Timestamp: receipt.Timestamp,
+				Signature: receipt.Signature,
+			})
 		}
 		return nil, fmt.Errorf("closest peer: %w", err)
 	}
 
+	// compute the price we pay for this receipt and reserve it for the rest of this function
+	receiptPrice := ps.pricer.PeerPrice(peer, ch.Address())
+	if err := ps.accounting.Reserve(ctx, peer, receiptPrice); err != nil {
+		return nil, fmt.Errorf("reserve balance for peer %s: %w", peer.String(), err)
+	}
+	defer ps.accounting.Release(peer, receiptPrice)
+
 	streamer, err := ps.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
 	if err != nil {
 		return nil, fmt.Errorf("new stream for peer %s: %w", peer.String(), err)
@@ -242,14 +365,23 @@ func (ps *PushSync) PushChunkToClosest(ctx context.Context, ch swarm.Chunk) (*Re
 	ps.metrics.ReceiptRTT.Observe(time.Since(receiptRTTTimer).Seconds())
 
 	// Check if the receipt is valid
-	if !ch.Address().Equal(swarm.NewAddress(receipt.Address)) {
+	rec, err := ps.verifyReceipt(ch.Address(), &receipt)
+	if err != nil {
 		ps.metrics.InvalidReceiptReceived.Inc()
-		return nil, fmt.Errorf("invalid receipt. peer %s", peer.String())
+		return nil, fmt.Errorf("receipt from peer %s: %w", peer.String(), err)
 	}
 
-	rec := &Receipt{
-		Address: swarm.NewAddress(receipt.Address),
+	if err := ps.accounting.Credit(peer, receiptPrice); err != nil {
+		return nil, err
 	}
 
-	return rec, nil
+	return ps.storeReceipt(rec)
+}
+
+// storeReceipt persists the receipt as the last receipt of its chunk.
+func (ps *PushSync) storeReceipt(receipt *Receipt) (*Receipt, error) {
+	if err := ps.store.Put(receiptKey(receipt.Address), receipt); err != nil {
+		return nil, fmt.Errorf("store receipt: %w", err)
+	}
+	return receipt, nil
 }
@@ -7,20 +7,35 @@ package pushsync_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io/ioutil"
 	"testing"
+	"time"
 
+	"github.com/ethersphere/bee/pkg/accounting"
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
+	"github.com/ethersphere/bee/pkg/crypto"
 	"github.com/ethersphere/bee/pkg/localstore"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/postage"
+	"github.com/ethersphere/bee/pkg/pricer"
 	"github.com/ethersphere/bee/pkg/pushsync"
 	"github.com/ethersphere/bee/pkg/pushsync/pb"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 	"github.com/ethersphere/bee/pkg/topology"
 	"github.com/ethersphere/bee/pkg/topology/mock"
 )
 
+const (
+	// fixedPrice is the price per proximity order of the test nodes.
+	fixedPrice    = 10
+	testNetworkID = 1
+)
+
 // TestSendChunkAndGetReceipt inserts a chunk as uploaded chunk in db. This triggers sending a chunk to the closest node
 // and expects a receipt. The message are intercepted in the outgoing stream to check for correctness.
 func TestSendChunkAndReceiveReceipt(t *testing.T) {
@@ -30,19 +45,19 @@ func TestSendChunkAndReceiveReceipt(t *testing.T) {
 	chunk := swarm.NewChunk(chunkAddress, chunkData)
 
 	// create a pivot node and a mocked closest node
-	pivotNode := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")   // base is 0000
-	closestPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000") // binary 0110 -> po 1
+	pivotSigner, pivotNode := newTestSigner(t)
+	closestSigner, closestPeer := newTestSigner(t)
 
 	// peer is the node responding to the chunk receipt message
 	// mock should return ErrWantSelf since there's no one to forward to
-	psPeer, storerPeer := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	psPeer, storerPeer, peerAccounting := createPushSyncNode(t, closestSigner, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
 	defer storerPeer.Close()
 
 	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
 
 	// pivot node needs the streamer since the chunk is intercepted by
 	// the chunk worker, then gets sent by opening a new stream
-	psPivot, storerPivot := createPushSyncNode(t, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	psPivot, storerPivot, pivotAccounting := createPushSyncNode(t, pivotSigner, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
 	defer storerPivot.Close()
 
 	// Trigger the sending of chunk to the closest node
@@ -54,6 +69,18 @@ func TestSendChunkAndReceiveReceipt(t *testing.T) {
 	if !chunk.Address().Equal(receipt.Address) {
 		t.Fatal("invalid receipt")
 	}
+	if !closestPeer.Equal(receipt.Storer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+	}
+
+	// the receipt is stored as the last receipt of the chunk
+	lastReceipt, err := psPivot.LastReceipt(chunkAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastReceipt.Storer.Equal(closestPeer) || lastReceipt.Timestamp != receipt.Timestamp || !bytes.Equal(lastReceipt.Signature, receipt.Signature) {
+		t.Fatalf("got last receipt %+v, want %+v", lastReceipt, receipt)
+	}
 
 	// this intercepts the outgoing delivery message
 	waitOnRecordAndTest(t, closestPeer, recorder, chunkAddress, chunkData)
@@ -61,6 +88,73 @@ func TestSendChunkAndReceiveReceipt(t *testing.T) {
 	// this intercepts the incoming receipt message
 	waitOnRecordAndTest(t, closestPeer, recorder, chunkAddress, nil)
 
+	// the pivot pays the closest peer for the receipt, the streamtest
+	// recorder presents the pivot to the peer with the peer address
+	price := int64(pricer.NewFixedPricer(closestPeer, fixedPrice).Price(chunkAddress))
+	testBalance(t, pivotAccounting, closestPeer, -price)
+	testBalance(t, peerAccounting, closestPeer, price)
+}
+
+// TestInvalidReceipt checks that the receipt signed by a node other than the
+// storer is rejected and that the storer is not paid.
+func TestInvalidReceipt(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotSigner, pivotNode := newTestSigner(t)
+	// the closest peer signs with the key that does not match its overlay
+	closestSigner, _ := newTestSigner(t)
+	_, closestPeer := newTestSigner(t)
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestSigner, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+	psPivot, storerPivot, pivotAccounting := createPushSyncNode(t, pivotSigner, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+	if !errors.Is(err, pushsync.ErrInvalidReceipt) {
+		t.Fatalf("got error %v, want %v", err, pushsync.ErrInvalidReceipt)
+	}
+
+	if _, err := psPivot.LastReceipt(chunkAddress); !errors.Is(err, pushsync.ErrNoReceipt) {
+		t.Fatalf("got error %v, want %v", err, pushsync.ErrNoReceipt)
+	}
+	// the storer is not paid for the rejected receipt
+	if _, err := pivotAccounting.Balance(closestPeer); !errors.Is(err, accounting.ErrPeerNoBalance) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrPeerNoBalance)
+	}
+}
+
+// TestReceiptOutOfNeighborhood checks that the receipt from the storer that is
+// not within the neighborhood depth of the chunk is rejected.
+func TestReceiptOutOfNeighborhood(t *testing.T) {
+	pivotSigner, pivotNode := newTestSigner(t)
+	closestSigner, closestPeer := newTestSigner(t)
+
+	// the chunk address differs from the storer in the first bit
+	chunkAddress := swarm.NewAddress(append([]byte{}, closestPeer.Bytes()...))
+	chunkAddress.Bytes()[0] ^= 0x80
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	psPeer, storerPeer, _ := createPushSyncNode(t, closestSigner, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer storerPeer.Close()
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+
+	psPivot, storerPivot, pivotAccounting := createPushSyncNode(t, pivotSigner, pivotNode, recorder, mock.WithClosestPeer(closestPeer), mock.WithNeighborhoodDepth(1))
+	defer storerPivot.Close()
+
+	_, err := psPivot.PushChunkToClosest(context.Background(), chunk)
+	if !errors.Is(err, pushsync.ErrOutOfNeighborhood) {
+		t.Fatalf("got error %v, want %v", err, pushsync.ErrOutOfNeighborhood)
+	}
+	// the storer is not paid for the rejected receipt
+	if _, err := pivotAccounting.Balance(closestPeer); !errors.Is(err, accounting.ErrPeerNoBalance) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrPeerNoBalance)
+	}
 }
 
 // TestHandler expect a chunk from a node on a stream. It then stores the chunk in the local store and
@@ -76,24 +170,24 @@ func TestHandler(t *testing.T) {
 	chunk := swarm.NewChunk(chunkAddress, chunkData)
 
 	// create a pivot node and a mocked closest node
-	pivotPeer := swarm.MustParseHexAddress("0000000000000000000000000000000000000000000000000000000000000000")
-	triggerPeer := swarm.MustParseHexAddress("6000000000000000000000000000000000000000000000000000000000000000")
-	closestPeer := swarm.MustParseHexAddress("f000000000000000000000000000000000000000000000000000000000000000")
+	pivotSigner, pivotPeer := newTestSigner(t)
+	triggerSigner, triggerPeer := newTestSigner(t)
+	closestSigner, closestPeer := newTestSigner(t)
 
 	// Create the closest peer
-	psClosestPeer, closestStorerPeerDB := createPushSyncNode(t, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	psClosestPeer, closestStorerPeerDB, _ := createPushSyncNode(t, closestSigner, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
 	defer closestStorerPeerDB.Close()
 
 	closestRecorder := streamtest.New(streamtest.WithProtocols(psClosestPeer.Protocol()))
 
 	// creating the pivot peer
-	psPivot, storerPivotDB := createPushSyncNode(t, pivotPeer, closestRecorder, mock.WithClosestPeer(closestPeer))
+	psPivot, storerPivotDB, pivotAccounting := createPushSyncNode(t, pivotSigner, pivotPeer, closestRecorder, mock.WithClosestPeer(closestPeer))
 	defer storerPivotDB.Close()
 
 	pivotRecorder := streamtest.New(streamtest.WithProtocols(psPivot.Protocol()))
 
 	// Creating the trigger peer
-	psTriggerPeer, triggerStorerDB := createPushSyncNode(t, triggerPeer, pivotRecorder, mock.WithClosestPeer(pivotPeer))
+	psTriggerPeer, triggerStorerDB, _ := createPushSyncNode(t, triggerSigner, triggerPeer, pivotRecorder, mock.WithClosestPeer(pivotPeer))
 	defer triggerStorerDB.Close()
 
 	receipt, err := psTriggerPeer.PushChunkToClosest(context.Background(), chunk)
@@ -104,6 +198,10 @@ func TestHandler(t *testing.T) {
 	if !chunk.Address().Equal(receipt.Address) {
 		t.Fatal("invalid receipt")
 	}
+	// the receipt signed by the closest peer is forwarded by the pivot
+	if !closestPeer.Equal(receipt.Storer) {
+		t.Fatalf("got receipt storer %s, want %s", receipt.Storer, closestPeer)
+	}
 
 	// In pivot peer,  intercept the incoming delivery chunk from the trigger peer and check for correctness
 	waitOnRecordAndTest(t, pivotPeer, pivotRecorder, chunkAddress, chunkData)
@@ -117,9 +215,241 @@ func TestHandler(t *testing.T) {
 
 	// In the received stream, check if a receipt is sent from pivot peer and check for its correctness.
 	waitOnRecordAndTest(t, pivotPeer, pivotRecorder, chunkAddress, nil)
+
+	// The pivot peer pays the closest peer for the forwarded chunk and is
+	// paid for it by the trigger peer, which the streamtest recorder presents
+	// with the pivot peer address.
+	closestPrice := int64(pricer.NewFixedPricer(closestPeer, fixedPrice).Price(chunkAddress))
+	pivotPrice := int64(pricer.NewFixedPricer(pivotPeer, fixedPrice).Price(chunkAddress))
+	testBalance(t, pivotAccounting, closestPeer, -closestPrice)
+	testBalance(t, pivotAccounting, pivotPeer, pivotPrice)
+}
+
+// TestHandlerInvalidReceipt checks that the forwarding node rejects the
+// receipt signed by a node other than the storer and does not pay for it.
+//
+// Chunk moves from   TriggerPeer -> PivotPeer -> ClosestPeer
+func TestHandlerInvalidReceipt(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	pivotSigner, pivotPeer := newTestSigner(t)
+	triggerSigner, triggerPeer := newTestSigner(t)
+	// the closest peer signs with the key that does not match its overlay
+	closestSigner, _ := newTestSigner(t)
+	_, closestPeer := newTestSigner(t)
+
+	psClosestPeer, closestStorerPeerDB, _ := createPushSyncNode(t, closestSigner, closestPeer, nil, mock.WithClosestPeerErr(topology.ErrWantSelf))
+	defer closestStorerPeerDB.Close()
+
+	closestRecorder := streamtest.New(streamtest.WithProtocols(psClosestPeer.Protocol()))
+
+	psPivot, storerPivotDB, pivotAccounting := createPushSyncNode(t, pivotSigner, pivotPeer, closestRecorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivotDB.Close()
+
+	pivotRecorder := streamtest.New(streamtest.WithProtocols(psPivot.Protocol()))
+
+	psTriggerPeer, triggerStorerDB, _ := createPushSyncNode(t, triggerSigner, triggerPeer, pivotRecorder, mock.WithClosestPeer(pivotPeer))
+	defer triggerStorerDB.Close()
+
+	if _, err := psTriggerPeer.PushChunkToClosest(context.Background(), chunk); err == nil {
+		t.Fatal("got no error, want the receipt not forwarded")
+	}
+
+	records := pivotRecorder.WaitRecords(t, pivotPeer, pushsync.ProtocolName, pushsync.ProtocolVersion, pushsync.StreamName, 1, 5)
+	if err := records[0].Err(); !errors.Is(err, pushsync.ErrInvalidReceipt) {
+		t.Fatalf("got pivot error %v, want %v", err, pushsync.ErrInvalidReceipt)
+	}
+
+	// the pivot neither pays the closest peer nor is paid by the trigger
+	if _, err := pivotAccounting.Balance(closestPeer); !errors.Is(err, accounting.ErrPeerNoBalance) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrPeerNoBalance)
+	}
+	if _, err := pivotAccounting.Balance(pivotPeer); !errors.Is(err, accounting.ErrPeerNoBalance) {
+		t.Fatalf("got error %v, want %v", err, accounting.ErrPeerNoBalance)
+	}
+}
+
+// TestPruneReceipts checks that the receipts older than the retention are
+// removed.
+func TestPruneReceipts(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunk := swarm.NewChunk(chunkAddress, []byte("1234"))
+
+	signer, addr := newTestSigner(t)
+	mockTopology := mock.NewTopologyDriver(mock.WithClosestPeerErr(topology.ErrWantSelf))
+	ps := pushsync.New(pushsync.Options{
+		Address:             addr,
+		NetworkID:           testNetworkID,
+		Signer:              signer,
+		Store:               statestore.NewStateStore(),
+		ReceiptRetention:    time.Hour,
+		ClosestPeerer:       mockTopology,
+		NeighborhoodDepther: mockTopology,
+		Accounting:          accountingmock.NewAccounting(),
+		Pricer:              pricer.NewFixedPricer(addr, fixedPrice),
+		Logger:              logging.New(ioutil.Discard, 0),
+	})
+	defer ps.Close()
+
+	if _, err := ps.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ps.PruneReceipts(time.Now().Add(30 * time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ps.LastReceipt(chunkAddress); err != nil {
+		t.Fatalf("got error %v for the receipt within the retention", err)
+	}
+
+	if err := ps.PruneReceipts(time.Now().Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ps.LastReceipt(chunkAddress); !errors.Is(err, pushsync.ErrNoReceipt) {
+		t.Fatalf("got error %v, want %v", err, pushsync.ErrNoReceipt)
+	}
+}
+
+// TestHandlerDeliveryCallback checks that the delivery callback is called
+// with the chunks delivered by other nodes.
+func TestHandlerDeliveryCallback(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunkData := []byte("1234")
+	chunk := swarm.NewChunk(chunkAddress, chunkData)
+
+	pivotSigner, pivotNode := newTestSigner(t)
+	closestSigner, closestPeer := newTestSigner(t)
+
+	delivered := make(chan swarm.Chunk, 1)
+	logger := logging.New(ioutil.Discard, 0)
+	storerPeer, err := localstore.New("", closestPeer.Bytes(), nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storerPeer.Close()
+	psPeer := pushsync.New(pushsync.Options{
+		Address:       closestPeer,
+		NetworkID:     testNetworkID,
+		Signer:        closestSigner,
+		Store:         statestore.NewStateStore(),
+		Storer:        storerPeer,
+		ClosestPeerer: mock.NewTopologyDriver(mock.WithClosestPeerErr(topology.ErrWantSelf)),
+		Accounting:    accountingmock.NewAccounting(),
+		Pricer:        pricer.NewFixedPricer(closestPeer, fixedPrice),
+		DeliveryCallback: func(_ context.Context, ch swarm.Chunk) error {
+			delivered <- ch
+			return nil
+		},
+		Logger: logger,
+	})
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotSigner, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ch := <-delivered:
+		if !ch.Equal(chunk) {
+			t.Fatalf("got chunk %s, want %s", ch, chunk)
+		}
+	default:
+		t.Fatal("delivery callback not called")
+	}
+}
+
+// TestPushChunkStamp tests that the postage stamp is delivered with the chunk
+// and that the chunks with invalid stamps are rejected by the receiving node.
+func TestPushChunkStamp(t *testing.T) {
+	chunkAddress := swarm.MustParseHexAddress("7000000000000000000000000000000000000000000000000000000000000000")
+	chunkData := []byte("1234")
+	validBatch := bytes.Repeat([]byte{1}, postage.BatchIDSize)
+
+	pivotSigner, pivotNode := newTestSigner(t)
+	closestSigner, closestPeer := newTestSigner(t)
+
+	errInvalidBatch := errors.New("invalid batch")
+	validStamp := func(ch swarm.Chunk, stampBytes []byte) (swarm.Chunk, error) {
+		stamp := new(postage.Stamp)
+		if err := stamp.UnmarshalBinary(stampBytes); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(stamp.BatchID(), validBatch) {
+			return nil, errInvalidBatch
+		}
+		return ch.WithStamp(stamp), nil
+	}
+
+	logger := logging.New(ioutil.Discard, 0)
+	storerPeer, err := localstore.New("", closestPeer.Bytes(), nil, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer storerPeer.Close()
+	psPeer := pushsync.New(pushsync.Options{
+		Address:       closestPeer,
+		NetworkID:     testNetworkID,
+		Signer:        closestSigner,
+		Store:         statestore.NewStateStore(),
+		Storer:        storerPeer,
+		ClosestPeerer: mock.NewTopologyDriver(mock.WithClosestPeerErr(topology.ErrWantSelf)),
+		ValidStamp:    validStamp,
+		Accounting:    accountingmock.NewAccounting(),
+		Pricer:        pricer.NewFixedPricer(closestPeer, fixedPrice),
+		Logger:        logger,
+	})
+
+	recorder := streamtest.New(streamtest.WithProtocols(psPeer.Protocol()))
+	psPivot, storerPivot, _ := createPushSyncNode(t, pivotSigner, pivotNode, recorder, mock.WithClosestPeer(closestPeer))
+	defer storerPivot.Close()
+
+	t.Run("invalid", func(t *testing.T) {
+		stamp := postage.NewStamp(bytes.Repeat([]byte{2}, postage.BatchIDSize), make([]byte, postage.IndexSize), make([]byte, postage.SignatureSize))
+		chunk := swarm.NewChunk(chunkAddress, chunkData).WithStamp(stamp)
+		if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err == nil {
+			t.Fatal("expected error")
+		}
+		if _, err := storerPeer.Get(context.Background(), storage.ModeGetRequest, chunkAddress); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		stamp := postage.NewStamp(validBatch, make([]byte, postage.IndexSize), make([]byte, postage.SignatureSize))
+		chunk := swarm.NewChunk(chunkAddress, chunkData).WithStamp(stamp)
+		if _, err := psPivot.PushChunkToClosest(context.Background(), chunk); err != nil {
+			t.Fatal(err)
+		}
+		got, err := storerPeer.Get(context.Background(), storage.ModeGetRequest, chunkAddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Stamp() == nil || !bytes.Equal(got.Stamp().BatchID(), validBatch) {
+			t.Fatalf("got stamp %v, want batch %x", got.Stamp(), validBatch)
+		}
+	})
+}
+
+// newTestSigner returns a new signer and the overlay address derived from its
+// key.
+func newTestSigner(t *testing.T) (crypto.Signer, swarm.Address) {
+	t.Helper()
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := crypto.NewOverlayAddress(key.PublicKey, testNetworkID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.NewDefaultSigner(key), addr
 }
 
-func createPushSyncNode(t *testing.T, addr swarm.Address, recorder *streamtest.Recorder, mockOpts ...mock.Option) (*pushsync.PushSync, *localstore.DB) {
+func createPushSyncNode(t *testing.T, signer crypto.Signer, addr swarm.Address, recorder *streamtest.Recorder, mockOpts ...mock.Option) (*pushsync.PushSync, *localstore.DB, accounting.Interface) {
 	logger := logging.New(ioutil.Discard, 0)
 
 	storer, err := localstore.New("", addr.Bytes(), nil, logger)
@@ -128,15 +458,34 @@ func createPushSyncNode(t *testing.T, addr swarm.Address, recorder *streamtest.R
 	}
 
 	mockTopology := mock.NewTopologyDriver(mockOpts...)
+	mockAccounting := accountingmock.NewAccounting()
 
 	ps := pushsync.New(pushsync.Options{
-		Streamer:      recorder,
-		Storer:        storer,
-		ClosestPeerer: mockTopology,
-		Logger:        logger,
+		Address:             addr,
+		NetworkID:           testNetworkID,
+		Signer:              signer,
+		Store:               statestore.NewStateStore(),
+		Streamer:            recorder,
+		Storer:              storer,
+		ClosestPeerer:       mockTopology,
+		NeighborhoodDepther: mockTopology,
+		Accounting:          mockAccounting,
+		Pricer:              pricer.NewFixedPricer(addr, fixedPrice),
+		Logger:              logger,
 	})
 
-	return ps, storer
+	return ps, storer, mockAccounting
+}
+
+func testBalance(t *testing.T, acc accounting.Interface, peer swarm.Address, want int64) {
+	t.Helper()
+	balance, err := acc.Balance(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance != want {
+		t.Fatalf("got balance %d for peer %s, want %d", balance, peer, want)
+	}
 }
 
 func waitOnRecordAndTest(t *testing.T, peer swarm.Address, recorder *streamtest.Recorder, add swarm.Address, data []byte) {
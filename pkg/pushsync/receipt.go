@@ -0,0 +1,189 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pushsync
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/pushsync/pb"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+// receiptKeyPrefix is the state store key prefix of the last receipts of the
+// pushed chunks.
+const receiptKeyPrefix = "pushsync_receipt_"
+
+var (
+	// ErrInvalidReceipt is returned when the receipt is not for the pushed
+	// chunk or it is not signed by its storer.
+	ErrInvalidReceipt = errors.New("invalid receipt")
+	// ErrOutOfNeighborhood is returned when the storer of the receipt is not
+	// within the neighborhood of the chunk.
+	ErrOutOfNeighborhood = errors.New("receipt storer out of neighborhood")
+	// ErrNoReceipt is returned when there is no receipt stored for the chunk.
+	ErrNoReceipt = errors.New("no receipt")
+)
+
+// Receipt is the proof of custody of the chunk, signed by the storer node.
+type Receipt struct {
+	Address   swarm.Address `json:"address"`
+	Storer    swarm.Address `json:"storer"`
+	Timestamp int64         `json:"timestamp"`
+	Signature []byte        `json:"signature"`
+}
+
+// ReceiptGetter provides the last receipts of the pushed chunks.
+type ReceiptGetter interface {
+	// LastReceipt returns the last receipt of the chunk with the address.
+	LastReceipt(addr swarm.Address) (*Receipt, error)
+}
+
+// signReceipt creates the receipt for the chunk stored by the node, signed
+// with the node's signer.
+func (ps *PushSync) signReceipt(addr swarm.Address) (*pb.Receipt, error) {
+	timestamp := time.Now().UnixNano()
+	digest, err := receiptDigest(addr, ps.address, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := ps.signer.Sign(digest)
+	if err != nil {
+		return nil, fmt.Errorf("sign receipt: %w", err)
+	}
+	return &pb.Receipt{
+		Address:   addr.Bytes(),
+		Storer:    ps.address.Bytes(),
+		Timestamp: timestamp,
+		Signature: signature,
+	}, nil
+}
+
+// verifyReceipt checks that the receipt is for the chunk, that it is signed
+// by the node with the storer overlay and that the storer is within the
+// neighborhood of the chunk.
+func (ps *PushSync) verifyReceipt(addr swarm.Address, receipt *pb.Receipt) (*Receipt, error) {
+	if !addr.Equal(swarm.NewAddress(receipt.Address)) {
+		return nil, ErrInvalidReceipt
+	}
+
+	storer := swarm.NewAddress(receipt.Storer)
+	digest, err := receiptDigest(addr, storer, receipt.Timestamp)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := crypto.Recover(receipt.Signature, digest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReceipt, err)
+	}
+	overlay, err := crypto.NewOverlayAddress(*pubKey, ps.networkID)
+	if err != nil {
+		return nil, err
+	}
+	if !overlay.Equal(storer) {
+		return nil, ErrInvalidReceipt
+	}
+
+	if swarm.Proximity(addr.Bytes(), storer.Bytes()) < ps.depther.NeighborhoodDepth() {
+		return nil, ErrOutOfNeighborhood
+	}
+
+	return &Receipt{
+		Address:   addr,
+		Storer:    storer,
+		Timestamp: receipt.Timestamp,
+		Signature: receipt.Signature,
+	}, nil
+}
+
+// LastReceipt returns the last receipt of the chunk pushed by the node.
+func (ps *PushSync) LastReceipt(addr swarm.Address) (*Receipt, error) {
+	receipt := new(Receipt)
+	if err := ps.store.Get(receiptKey(addr), receipt); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNoReceipt
+		}
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// pruneReceiptsLoop periodically removes the receipts that are older than the
+// retention.
+func (ps *PushSync) pruneReceiptsLoop() {
+	defer ps.wg.Done()
+
+	ticker := time.NewTicker(receiptsPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := ps.pruneReceipts(time.Now()); err != nil {
+			ps.logger.Errorf("pushsync: prune receipts: %v", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ps.quit:
+			return
+		}
+	}
+}
+
+// pruneReceipts removes the receipts signed before the retention period
+// preceding now.
+func (ps *PushSync) pruneReceipts(now time.Time) error {
+	expiry := now.Add(-ps.receiptRetention).UnixNano()
+
+	var expired []string
+	if err := ps.store.Iterate(receiptKeyPrefix, func(key, value []byte) (stop bool, err error) {
+		receipt := new(Receipt)
+		if err := json.Unmarshal(value, receipt); err != nil {
+			return true, fmt.Errorf("unmarshal receipt %s: %w", string(key), err)
+		}
+		if receipt.Timestamp < expiry {
+			expired = append(expired, string(key))
+		}
+		return false, nil
+	}); err != nil {
+		return err
+	}
+
+	for _, key := range expired {
+		if err := ps.store.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receiptDigest returns the digest that the storer signs:
+//
+//	keccak256(chunk address | storer overlay | timestamp)
+func receiptDigest(addr, storer swarm.Address, timestamp int64) ([]byte, error) {
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(timestamp))
+
+	h := sha3.NewLegacyKeccak256()
+	if _, err := h.Write(addr.Bytes()); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(storer.Bytes()); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(ts); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func receiptKey(addr swarm.Address) string {
+	return receiptKeyPrefix + addr.String()
+}
@@ -9,9 +9,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/accounting"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/pricer"
 	pb "github.com/ethersphere/bee/pkg/retrieval/pb"
 	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -38,6 +40,8 @@ type Service struct {
 	peerSuggester topology.EachPeerer
 	storer        storage.Storer
 	singleflight  singleflight.Group
+	accounting    accounting.Interface
+	pricer        pricer.Interface
 	logger        logging.Logger
 }
 
@@ -45,6 +49,8 @@ type Options struct {
 	Streamer    p2p.Streamer
 	ChunkPeerer topology.EachPeerer
 	Storer      storage.Storer
+	Accounting  accounting.Interface
+	Pricer      pricer.Interface
 	Logger      logging.Logger
 }
 
@@ -53,6 +59,8 @@ func New(o Options) *Service {
 		streamer:      o.Streamer,
 		peerSuggester: o.ChunkPeerer,
 		storer:        o.Storer,
+		accounting:    o.Accounting,
+		pricer:        o.Pricer,
 		logger:        o.Logger,
 	}
 }
@@ -118,6 +126,14 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, skipPee
 	if err != nil {
 		return nil, peer, fmt.Errorf("get closest: %w", err)
 	}
+
+	// compute the price we pay for this chunk and reserve it for the rest of this function
+	chunkPrice := s.pricer.PeerPrice(peer, addr)
+	if err := s.accounting.Reserve(ctx, peer, chunkPrice); err != nil {
+		return nil, peer, err
+	}
+	defer s.accounting.Release(peer, chunkPrice)
+
 	s.logger.Tracef("retrieval: requesting chunk %s from peer %s", addr, peer)
 	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
 	if err != nil {
@@ -138,6 +154,11 @@ func (s *Service) retrieveChunk(ctx context.Context, addr swarm.Address, skipPee
 		return nil, peer, fmt.Errorf("read delivery: %w peer %s", err, peer.String())
 	}
 
+	// credit the peer after successful delivery
+	if err := s.accounting.Credit(peer, chunkPrice); err != nil {
+		return nil, peer, err
+	}
+
 	return d.Data, peer, nil
 }
 
@@ -200,6 +221,12 @@ func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) er
 		return fmt.Errorf("write delivery: %w peer %s", err, p.Address.String())
 	}
 
+	// compute the price we charge for this chunk and debit it from p's balance
+	chunkPrice := s.pricer.Price(chunk.Address())
+	if err := s.accounting.Debit(p.Address, chunkPrice); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -13,11 +13,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/accounting"
+	accountingmock "github.com/ethersphere/bee/pkg/accounting/mock"
 	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/p2p/protobuf"
 	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/pricer"
 	"github.com/ethersphere/bee/pkg/retrieval"
 	pb "github.com/ethersphere/bee/pkg/retrieval/pb"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
 	"github.com/ethersphere/bee/pkg/storage"
 	storemock "github.com/ethersphere/bee/pkg/storage/mock"
 	"github.com/ethersphere/bee/pkg/swarm"
@@ -43,10 +47,16 @@ func TestDelivery(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	serverAccounting := newTestAccounting(t)
+	pricer := pricer.NewFixedPricer(peerID, 10)
+
 	// create the server that will handle the request and will serve the response
 	server := retrieval.New(retrieval.Options{
-		Storer: mockStorer,
-		Logger: logger,
+		Storer:     mockStorer,
+		Accounting: serverAccounting,
+		Pricer:     pricer,
+		Logger:     logger,
 	})
 	recorder := streamtest.New(
 		streamtest.WithProtocols(server.Protocol()),
@@ -57,8 +67,8 @@ func TestDelivery(t *testing.T) {
 	// presence of the reqAddr key and value to ensure delivery
 	// was successful
 	clientMockStorer := storemock.NewStorer()
+	clientAccounting := newTestAccounting(t)
 
-	peerID := swarm.MustParseHexAddress("9ee7add7")
 	ps := mockPeerSuggester{eachPeerRevFunc: func(f topology.EachPeerFunc) error {
 		_, _, _ = f(peerID, 0)
 		return nil
@@ -67,13 +77,15 @@ func TestDelivery(t *testing.T) {
 		Streamer:    recorder,
 		ChunkPeerer: ps,
 		Storer:      clientMockStorer,
+		Accounting:  clientAccounting,
+		Pricer:      pricer,
 		Logger:      logger,
 	})
 	ctx, cancel := context.WithTimeout(context.Background(), testTimeout)
 	defer cancel()
 	v, err := client.RetrieveChunk(ctx, reqAddr)
 	if err != nil {
-		return
+		t.Fatal(err)
 	}
 	if !bytes.Equal(v, reqData) {
 		t.Fatalf("request and response data not equal. got %s want %s", v, reqData)
@@ -120,15 +132,76 @@ func TestDelivery(t *testing.T) {
 		t.Fatalf("got too many deliveries. want 1 got %d", len(gotDeliveries))
 	}
 
+	price := int64(pricer.PeerPrice(peerID, reqAddr))
+	clientBalance, err := clientAccounting.Balance(peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clientBalance != -price {
+		t.Fatalf("got client balance %d, want %d", clientBalance, -price)
+	}
+	// the streamtest recorder presents the client to the server handler
+	// with the server peer address
+	serverBalance, err := serverAccounting.Balance(peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serverBalance != price {
+		t.Fatalf("got server balance %d, want %d", serverBalance, price)
+	}
+}
+
+// TestRetrieveChunkOverdraft tests that the chunk is not requested from the
+// peer if the price of the chunk would make our debt exceed the payment
+// threshold.
+func TestRetrieveChunkOverdraft(t *testing.T) {
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+	ps := mockPeerSuggester{eachPeerRevFunc: func(f topology.EachPeerFunc) error {
+		_, _, _ = f(peerID, 0)
+		return nil
+	}}
+	recorder := streamtest.New()
+	client := retrieval.New(retrieval.Options{
+		Streamer:    recorder,
+		ChunkPeerer: ps,
+		Storer:      storemock.NewStorer(),
+		Accounting: accountingmock.NewAccounting(accountingmock.WithReserveFunc(func(context.Context, swarm.Address, uint64) error {
+			return accounting.ErrOverdraft
+		})),
+		Pricer: pricer.NewFixedPricer(peerID, 10),
+		Logger: logging.New(ioutil.Discard, 0),
+	})
+
+	// the peer is skipped and there are no other peers to request from
+	if _, err := client.RetrieveChunk(context.Background(), swarm.MustParseHexAddress("00112233")); !errors.Is(err, topology.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, topology.ErrNotFound)
+	}
+	if _, err := recorder.Records(peerID, "retrieval", "1.0.0", "retrieval"); !errors.Is(err, streamtest.ErrRecordsNotFound) {
+		t.Fatalf("got error %v, want %v", err, streamtest.ErrRecordsNotFound)
+	}
+}
+
+func newTestAccounting(t *testing.T) *accounting.Accounting {
+	t.Helper()
+	acc, err := accounting.NewAccounting(accounting.Options{
+		PaymentThreshold: 1000,
+		PaymentTolerance: 100,
+		Logger:           logging.New(ioutil.Discard, 0),
+		Store:            statestore.NewStateStore(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acc
 }
 
 type mockPeerSuggester struct {
 	eachPeerRevFunc func(f topology.EachPeerFunc) error
 }
 
-func (s mockPeerSuggester) EachPeer(f topology.EachPeerFunc) error {
-	return s.eachPeerRevFunc(f)
-}
-func (s mockPeerSuggester) EachPeerRev(topology.EachPeerFunc) error {
+func (s mockPeerSuggester) EachPeer(topology.EachPeerFunc) error {
 	return errors.New("not implemented")
 }
+func (s mockPeerSuggester) EachPeerRev(f topology.EachPeerFunc) error {
+	return s.eachPeerRevFunc(f)
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package settlement defines the interface of the settlement of the debts
+// between the peers, recorded by the accounting.
+package settlement
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var (
+	// ErrPeerNoSettlements is returned when no settlements are recorded
+	// with the peer.
+	ErrPeerNoSettlements = errors.New("no settlements for peer")
+)
+
+// Interface is the interface used by Accounting to trigger settlement.
+type Interface interface {
+	// Pay initiates a payment of the amount to the given peer. It returns
+	// the amount accepted by the peer, which may be less than the amount.
+	Pay(ctx context.Context, peer swarm.Address, amount uint64) (accepted uint64, err error)
+	// TotalSent returns the total amount sent to a peer.
+	TotalSent(peer swarm.Address) (totalSent uint64, err error)
+	// TotalReceived returns the total amount received from a peer.
+	TotalReceived(peer swarm.Address) (totalReceived uint64, err error)
+	// SettlementsSent returns the sent settlements for each known peer.
+	SettlementsSent() (map[string]uint64, error)
+	// SettlementsReceived returns the received settlements for each known
+	// peer.
+	SettlementsReceived() (map[string]uint64, error)
+	// SetPaymentObserver sets the PaymentObserver to notify.
+	SetPaymentObserver(observer PaymentObserver)
+}
+
+// PaymentObserver is the interface Settlement uses to notify other
+// components of an incoming payment.
+type PaymentObserver interface {
+	// NotifyPayment is called when a payment from peer was successfully
+	// received.
+	NotifyPayment(peer swarm.Address, amount uint64) error
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mock provides a mock implementation for the
+// settlement interface.
+package mock
+
+import (
+	"context"
+
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ settlement.Interface = (*Service)(nil)
+
+// Service is the mock settlement service. Without the options, it accepts
+// every payment in full and has no settlements recorded.
+type Service struct {
+	payFunc                 func(context.Context, swarm.Address, uint64) (uint64, error)
+	totalSentFunc           func(swarm.Address) (uint64, error)
+	totalReceivedFunc       func(swarm.Address) (uint64, error)
+	settlementsSentFunc     func() (map[string]uint64, error)
+	settlementsReceivedFunc func() (map[string]uint64, error)
+}
+
+// WithPayFunc sets the mock Pay function.
+func WithPayFunc(f func(context.Context, swarm.Address, uint64) (uint64, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.payFunc = f
+	})
+}
+
+// WithTotalSentFunc sets the mock TotalSent function.
+func WithTotalSentFunc(f func(swarm.Address) (uint64, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.totalSentFunc = f
+	})
+}
+
+// WithTotalReceivedFunc sets the mock TotalReceived function.
+func WithTotalReceivedFunc(f func(swarm.Address) (uint64, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.totalReceivedFunc = f
+	})
+}
+
+// WithSettlementsSentFunc sets the mock SettlementsSent function.
+func WithSettlementsSentFunc(f func() (map[string]uint64, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.settlementsSentFunc = f
+	})
+}
+
+// WithSettlementsReceivedFunc sets the mock SettlementsReceived function.
+func WithSettlementsReceivedFunc(f func() (map[string]uint64, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.settlementsReceivedFunc = f
+	})
+}
+
+// New creates the mock settlement implementation.
+func New(opts ...Option) *Service {
+	mock := new(Service)
+	for _, o := range opts {
+		o.apply(mock)
+	}
+	return mock
+}
+
+// Pay is the mock function wrapper that calls the set implementation.
+func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount uint64) (uint64, error) {
+	if s.payFunc != nil {
+		return s.payFunc(ctx, peer, amount)
+	}
+	return amount, nil
+}
+
+// TotalSent is the mock function wrapper that calls the set implementation.
+func (s *Service) TotalSent(peer swarm.Address) (uint64, error) {
+	if s.totalSentFunc != nil {
+		return s.totalSentFunc(peer)
+	}
+	return 0, settlement.ErrPeerNoSettlements
+}
+
+// TotalReceived is the mock function wrapper that calls the set implementation.
+func (s *Service) TotalReceived(peer swarm.Address) (uint64, error) {
+	if s.totalReceivedFunc != nil {
+		return s.totalReceivedFunc(peer)
+	}
+	return 0, settlement.ErrPeerNoSettlements
+}
+
+// SettlementsSent is the mock function wrapper that calls the set implementation.
+func (s *Service) SettlementsSent() (map[string]uint64, error) {
+	if s.settlementsSentFunc != nil {
+		return s.settlementsSentFunc()
+	}
+	return map[string]uint64{}, nil
+}
+
+// SettlementsReceived is the mock function wrapper that calls the set implementation.
+func (s *Service) SettlementsReceived() (map[string]uint64, error) {
+	if s.settlementsReceivedFunc != nil {
+		return s.settlementsReceivedFunc()
+	}
+	return map[string]uint64{}, nil
+}
+
+// SetPaymentObserver does nothing.
+func (s *Service) SetPaymentObserver(settlement.PaymentObserver) {}
+
+// Option is the option passed to the mock settlement service.
+type Option interface {
+	apply(*Service)
+}
+
+type optionFunc func(*Service)
+
+func (f optionFunc) apply(r *Service) { f(r) }
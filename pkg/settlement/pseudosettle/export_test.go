@@ -0,0 +1,15 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pseudosettle
+
+import "time"
+
+// SetTimeNow replaces the function that returns the current time, returning
+// the function that restores it.
+func SetTimeNow(f func() time.Time) (reset func()) {
+	current := timeNow
+	timeNow = f
+	return func() { timeNow = current }
+}
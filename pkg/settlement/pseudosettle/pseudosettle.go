@@ -0,0 +1,276 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pseudosettle implements a settlement of the debts between the peers
+// without a blockchain. The debtor sends payment messages which the creditor
+// accepts up to an allowance that refreshes with the elapsed time.
+package pseudosettle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle/pb"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	protocolName    = "pseudosettle"
+	protocolVersion = "1.0.0"
+	streamName      = "pseudosettle"
+)
+
+const (
+	totalSentKeyPrefix     = "pseudosettle_total_sent_"
+	totalReceivedKeyPrefix = "pseudosettle_total_received_"
+	lastPaymentKeyPrefix   = "pseudosettle_last_payment_"
+)
+
+var _ settlement.Interface = (*Service)(nil)
+
+var (
+	// ErrInvalidAck is returned when the peer acknowledges more than the
+	// amount of the payment.
+	ErrInvalidAck = errors.New("invalid payment acknowledgement")
+)
+
+// timeNow is the function that returns the current time. It is replaced in
+// tests.
+var timeNow = time.Now
+
+// Service is the pseudosettle service.
+type Service struct {
+	streamer    p2p.Streamer
+	logger      logging.Logger
+	store       storage.StateStorer
+	refreshRate uint64
+	observer    settlement.PaymentObserver
+	receiveMu   sync.Mutex // serializes the accepting of the incoming payments
+}
+
+// Options for the pseudosettle service.
+type Options struct {
+	Streamer p2p.Streamer
+	Logger   logging.Logger
+	Store    storage.StateStorer
+	// RefreshRate is the amount per second of the elapsed time since the
+	// last accepted payment that an incoming payment is accepted up to.
+	RefreshRate uint64
+}
+
+// New creates a new pseudosettle service.
+func New(o Options) *Service {
+	return &Service{
+		streamer:    o.Streamer,
+		logger:      o.Logger,
+		store:       o.Store,
+		refreshRate: o.RefreshRate,
+	}
+}
+
+func (s *Service) Protocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: protocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    streamName,
+				Handler: s.handler,
+			},
+		},
+	}
+}
+
+func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) error {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer stream.Close()
+
+	var req pb.Payment
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read request from peer %v: %w", p.Address, err)
+	}
+
+	accepted, timestamp, err := s.acceptPayment(p.Address, req.Amount)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Tracef("pseudosettle: accepted payment of %d out of %d from peer %v", accepted, req.Amount, p.Address)
+
+	if err := w.WriteMsgWithContext(ctx, &pb.PaymentAck{
+		Amount:    accepted,
+		Timestamp: timestamp,
+	}); err != nil {
+		return fmt.Errorf("write acknowledgement to peer %v: %w", p.Address, err)
+	}
+
+	return nil
+}
+
+// acceptPayment accepts the payment from the peer up to the allowance
+// refreshed since the last accepted payment and records it. It returns the
+// accepted amount and the time of the acceptance.
+func (s *Service) acceptPayment(peer swarm.Address, amount uint64) (accepted uint64, timestamp int64, err error) {
+	s.receiveMu.Lock()
+	defer s.receiveMu.Unlock()
+
+	now := timeNow().Unix()
+
+	var lastPayment int64
+	err = s.store.Get(lastPaymentKey(peer), &lastPayment)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, 0, err
+	}
+
+	accepted = amount
+	// the first payment of the peer is accepted in full
+	if err == nil {
+		elapsed := now - lastPayment
+		if elapsed < 0 {
+			elapsed = 0
+		}
+		if allowance := uint64(elapsed) * s.refreshRate; allowance < accepted {
+			accepted = allowance
+		}
+	}
+
+	if accepted == 0 {
+		return 0, now, nil
+	}
+
+	if err := s.observer.NotifyPayment(peer, accepted); err != nil {
+		return 0, 0, fmt.Errorf("notify payment from peer %v: %w", peer, err)
+	}
+
+	if err := s.store.Put(lastPaymentKey(peer), now); err != nil {
+		return 0, 0, err
+	}
+
+	if err := s.addTotal(totalReceivedKey(peer), accepted); err != nil {
+		return 0, 0, err
+	}
+
+	return accepted, now, nil
+}
+
+// Pay initiates a payment to the given peer.
+func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount uint64) (accepted uint64, err error) {
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	if err != nil {
+		return 0, fmt.Errorf("new stream: %w", err)
+	}
+	defer stream.Close()
+
+	s.logger.Tracef("pseudosettle: sending payment message to peer %v of %d", peer, amount)
+	w, r := protobuf.NewWriterAndReader(stream)
+	if err := w.WriteMsgWithContext(ctx, &pb.Payment{
+		Amount: amount,
+	}); err != nil {
+		return 0, fmt.Errorf("write payment to peer %v: %w", peer, err)
+	}
+
+	var ack pb.PaymentAck
+	if err := r.ReadMsgWithContext(ctx, &ack); err != nil {
+		return 0, fmt.Errorf("read acknowledgement from peer %v: %w", peer, err)
+	}
+
+	if ack.Amount > amount {
+		return 0, fmt.Errorf("peer %v acknowledged %d out of %d: %w", peer, ack.Amount, amount, ErrInvalidAck)
+	}
+
+	if err := s.addTotal(totalSentKey(peer), ack.Amount); err != nil {
+		return 0, err
+	}
+
+	return ack.Amount, nil
+}
+
+// SetPaymentObserver sets the payment observer which will be notified of
+// incoming payments.
+func (s *Service) SetPaymentObserver(observer settlement.PaymentObserver) {
+	s.observer = observer
+}
+
+// TotalSent returns the total amount sent to a peer.
+func (s *Service) TotalSent(peer swarm.Address) (totalSent uint64, err error) {
+	return s.total(totalSentKey(peer))
+}
+
+// TotalReceived returns the total amount received from a peer.
+func (s *Service) TotalReceived(peer swarm.Address) (totalReceived uint64, err error) {
+	return s.total(totalReceivedKey(peer))
+}
+
+// SettlementsSent returns the total amounts sent to each known peer.
+func (s *Service) SettlementsSent() (map[string]uint64, error) {
+	return s.settlements(totalSentKeyPrefix)
+}
+
+// SettlementsReceived returns the total amounts received from each known
+// peer.
+func (s *Service) SettlementsReceived() (map[string]uint64, error) {
+	return s.settlements(totalReceivedKeyPrefix)
+}
+
+func (s *Service) total(key string) (total uint64, err error) {
+	if err := s.store.Get(key, &total); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return 0, settlement.ErrPeerNoSettlements
+		}
+		return 0, err
+	}
+	return total, nil
+}
+
+// addTotal adds the amount to the total stored under the key. The writes of
+// the totals are serialized by the callers.
+func (s *Service) addTotal(key string, amount uint64) error {
+	var total uint64
+	if err := s.store.Get(key, &total); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return s.store.Put(key, total+amount)
+}
+
+func (s *Service) settlements(prefix string) (map[string]uint64, error) {
+	settlements := make(map[string]uint64)
+	err := s.store.Iterate(prefix, func(key, val []byte) (stop bool, err error) {
+		addr, err := swarm.ParseHexAddress(strings.TrimPrefix(string(key), prefix))
+		if err != nil {
+			return false, fmt.Errorf("parse address from key: %s: %w", string(key), err)
+		}
+		var total uint64
+		if err := json.Unmarshal(val, &total); err != nil {
+			return false, fmt.Errorf("unmarshal total: %s: %w", string(key), err)
+		}
+		settlements[addr.String()] = total
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settlements, nil
+}
+
+func totalSentKey(peer swarm.Address) string {
+	return totalSentKeyPrefix + peer.String()
+}
+
+func totalReceivedKey(peer swarm.Address) string {
+	return totalReceivedKeyPrefix + peer.String()
+}
+
+func lastPaymentKey(peer swarm.Address) string {
+	return lastPaymentKeyPrefix + peer.String()
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pseudosettle_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/pseudosettle"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const testRefreshRate = 30
+
+type testObserver struct {
+	peer   swarm.Address
+	amount uint64
+}
+
+func (t *testObserver) NotifyPayment(peer swarm.Address, amount uint64) error {
+	t.peer = peer
+	t.amount += amount
+	return nil
+}
+
+func TestPayment(t *testing.T) {
+	logger := logging.New(ioutil.Discard, 0)
+	now := time.Unix(1000, 0)
+	defer pseudosettle.SetTimeNow(func() time.Time { return now })()
+
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	observer := &testObserver{}
+	recipient := pseudosettle.New(pseudosettle.Options{
+		Logger:      logger,
+		Store:       mock.NewStateStore(),
+		RefreshRate: testRefreshRate,
+	})
+	recipient.SetPaymentObserver(observer)
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(recipient.Protocol()),
+	)
+
+	payer := pseudosettle.New(pseudosettle.Options{
+		Streamer:    recorder,
+		Logger:      logger,
+		Store:       mock.NewStateStore(),
+		RefreshRate: testRefreshRate,
+	})
+
+	for _, tc := range []struct {
+		name     string
+		elapsed  time.Duration
+		amount   uint64
+		accepted uint64
+		total    uint64
+	}{
+		{"first payment", 0, 100, 100, 100},
+		{"no allowance", 0, 100, 0, 100},
+		{"partial allowance", 2 * time.Second, 100, 2 * testRefreshRate, 100 + 2*testRefreshRate},
+		{"full allowance", 10 * time.Second, 100, 100, 200 + 2*testRefreshRate},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now = now.Add(tc.elapsed)
+
+			accepted, err := payer.Pay(context.Background(), peerID, tc.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if accepted != tc.accepted {
+				t.Fatalf("got accepted %d, want %d", accepted, tc.accepted)
+			}
+
+			// the streamtest recorder presents the payer to the recipient
+			// with the recipient peer address
+			if observer.amount != tc.total {
+				t.Fatalf("got notified amount %d, want %d", observer.amount, tc.total)
+			}
+			if !observer.peer.Equal(peerID) {
+				t.Fatalf("got notified peer %s, want %s", observer.peer, peerID)
+			}
+
+			totalSent, err := payer.TotalSent(peerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if totalSent != tc.total {
+				t.Fatalf("got total sent %d, want %d", totalSent, tc.total)
+			}
+			totalReceived, err := recipient.TotalReceived(peerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if totalReceived != tc.total {
+				t.Fatalf("got total received %d, want %d", totalReceived, tc.total)
+			}
+		})
+	}
+
+	sent, err := payer.SettlementsSent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[peerID.String()] != observer.amount {
+		t.Fatalf("got settlements sent %v", sent)
+	}
+	received, err := recipient.SettlementsReceived()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[peerID.String()] != observer.amount {
+		t.Fatalf("got settlements received %v", received)
+	}
+}
+
+func TestNoSettlements(t *testing.T) {
+	s := pseudosettle.New(pseudosettle.Options{
+		Logger: logging.New(ioutil.Discard, 0),
+		Store:  mock.NewStateStore(),
+	})
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	if _, err := s.TotalSent(peerID); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+	if _, err := s.TotalReceived(peerID); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/crypto/eip712"
+)
+
+// Cheque represents a cheque for a SimpleSwap chequebook. The cumulative
+// payout is the total amount the chequebook has committed to the
+// beneficiary, so only the last cheque is needed to cash out.
+type Cheque struct {
+	Chequebook       []byte
+	Beneficiary      []byte
+	CumulativePayout *big.Int
+}
+
+// SignedCheque represents a cheque together with the signature of the issuer.
+type SignedCheque struct {
+	Cheque
+	Signature []byte
+}
+
+// chequeTypes are the EIP-712 type definitions of the cheque.
+var chequeTypes = eip712.Types{
+	eip712.DomainType: {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+	},
+	"Cheque": {
+		{Name: "chequebook", Type: "address"},
+		{Name: "beneficiary", Type: "address"},
+		{Name: "cumulativePayout", Type: "uint256"},
+	},
+}
+
+// chequebookDomain returns the EIP-712 domain of the cheques on the chain.
+func chequebookDomain(chainID int64) eip712.Domain {
+	return eip712.Domain{
+		Name:    "Chequebook",
+		Version: "1.0",
+		ChainID: big.NewInt(chainID),
+	}
+}
+
+// chequeTypedData returns the EIP-712 typed data of the cheque.
+func chequeTypedData(cheque *Cheque, chainID int64) *eip712.TypedData {
+	return &eip712.TypedData{
+		Types:       chequeTypes,
+		PrimaryType: "Cheque",
+		Domain:      chequebookDomain(chainID),
+		Message: map[string]interface{}{
+			"chequebook":       cheque.Chequebook,
+			"beneficiary":      cheque.Beneficiary,
+			"cumulativePayout": cheque.CumulativePayout,
+		},
+	}
+}
+
+// ChequeSigner signs cheques.
+type ChequeSigner interface {
+	// Sign signs the cheque, returning the signature.
+	Sign(cheque *Cheque) ([]byte, error)
+}
+
+type chequeSigner struct {
+	signer  crypto.Signer
+	chainID int64
+}
+
+// NewChequeSigner creates a new cheque signer for the chain.
+func NewChequeSigner(signer crypto.Signer, chainID int64) ChequeSigner {
+	return &chequeSigner{
+		signer:  signer,
+		chainID: chainID,
+	}
+}
+
+// Sign signs the cheque as EIP-712 typed data.
+func (s *chequeSigner) Sign(cheque *Cheque) ([]byte, error) {
+	return s.signer.SignTypedData(chequeTypedData(cheque, s.chainID))
+}
+
+// RecoverCheque returns the ethereum address of the signer of the cheque.
+func RecoverCheque(cheque *SignedCheque, chainID int64) ([]byte, error) {
+	pubKey, err := crypto.RecoverEIP712(cheque.Signature, chequeTypedData(&cheque.Cheque, chainID))
+	if err != nil {
+		return nil, fmt.Errorf("recover cheque signer: %w", err)
+	}
+	return crypto.NewEthereumAddress(*pubKey)
+}
+
+// Equal returns whether the cheques have the same content.
+func (cheque *Cheque) Equal(other *Cheque) bool {
+	return bytes.Equal(cheque.Chequebook, other.Chequebook) &&
+		bytes.Equal(cheque.Beneficiary, other.Beneficiary) &&
+		cheque.CumulativePayout.Cmp(other.CumulativePayout) == 0
+}
+
+// Equal returns whether the signed cheques have the same content and
+// signature.
+func (cheque *SignedCheque) Equal(other *SignedCheque) bool {
+	return cheque.Cheque.Equal(&other.Cheque) && bytes.Equal(cheque.Signature, other.Signature)
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+func TestSignCheque(t *testing.T) {
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	issuer, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cheque := &chequebook.Cheque{
+		Chequebook:       bytes.Repeat([]byte{1}, 20),
+		Beneficiary:      bytes.Repeat([]byte{2}, 20),
+		CumulativePayout: big.NewInt(500),
+	}
+	chainID := int64(1)
+
+	signature, err := chequebook.NewChequeSigner(signer, chainID).Sign(cheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := &chequebook.SignedCheque{
+		Cheque:    *cheque,
+		Signature: signature,
+	}
+
+	recovered, err := chequebook.RecoverCheque(signed, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(recovered, issuer) {
+		t.Fatalf("got signer %x, want %x", recovered, issuer)
+	}
+
+	t.Run("other chain", func(t *testing.T) {
+		recovered, err := chequebook.RecoverCheque(signed, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(recovered, issuer) {
+			t.Fatal("recovered the issuer with the other chain id")
+		}
+	})
+
+	t.Run("modified payout", func(t *testing.T) {
+		modified := *signed
+		modified.CumulativePayout = big.NewInt(600)
+		recovered, err := chequebook.RecoverCheque(&modified, chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(recovered, issuer) {
+			t.Fatal("recovered the issuer of the modified cheque")
+		}
+	})
+}
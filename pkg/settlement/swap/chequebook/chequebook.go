@@ -0,0 +1,292 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chequebook implements the SimpleSwap chequebook: the issuing of the
+// cheques from the chequebook of the node and the validation of the cheques
+// received from the chequebooks of the peers. The chequebook contracts are
+// accessed through the Backend interface.
+package chequebook
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+const (
+	chequebookKey               = "chequebook_address"
+	totalIssuedKey              = "chequebook_total_issued"
+	lastIssuedChequeKeyPrefix   = "chequebook_last_issued_cheque_"
+	lastReceivedChequeKeyPrefix = "chequebook_last_received_cheque_"
+)
+
+var (
+	// ErrOutOfFunds is returned when the available balance of the chequebook
+	// does not cover the cheque.
+	ErrOutOfFunds = errors.New("chequebook out of funds")
+	// ErrNoCheque is returned when no cheque is recorded for the beneficiary
+	// or the chequebook.
+	ErrNoCheque = errors.New("no cheque")
+)
+
+// SendChequeFunc sends the issued cheque to the beneficiary.
+type SendChequeFunc func(cheque *SignedCheque) error
+
+// Service is the interface of the chequebook of the node.
+type Service interface {
+	// Deposit transfers the amount of tokens to the chequebook.
+	Deposit(ctx context.Context, amount *big.Int) error
+	// Balance returns the amount of tokens held by the chequebook.
+	Balance(ctx context.Context) (*big.Int, error)
+	// AvailableBalance returns the balance of the chequebook less the
+	// amount of the issued cheques that are not cashed out yet.
+	AvailableBalance(ctx context.Context) (*big.Int, error)
+	// Address returns the address of the chequebook contract.
+	Address() []byte
+	// Issue issues a cheque increasing the cumulative payout to the
+	// beneficiary by the amount and sends it with the send function.
+	Issue(ctx context.Context, beneficiary []byte, amount *big.Int, send SendChequeFunc) (*SignedCheque, error)
+	// LastCheque returns the last cheque issued to the beneficiary.
+	LastCheque(beneficiary []byte) (*SignedCheque, error)
+	// LastCheques returns the last cheques issued to all beneficiaries by
+	// their hex encoded addresses.
+	LastCheques() (map[string]*SignedCheque, error)
+}
+
+// Options for the chequebook service.
+type Options struct {
+	Backend Backend
+	Store   storage.StateStorer
+	Signer  crypto.Signer
+	// Address is the address of the chequebook contract of the node.
+	Address []byte
+}
+
+type service struct {
+	lock         sync.Mutex // serializes the issuing of the cheques
+	address      []byte
+	contract     Contract
+	store        storage.StateStorer
+	chequeSigner ChequeSigner
+}
+
+// New creates a new chequebook service for the deployed chequebook at the
+// address.
+func New(ctx context.Context, o Options) (Service, error) {
+	chainID, err := o.Backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chain id: %w", err)
+	}
+	contract, err := o.Backend.Contract(ctx, o.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &service{
+		address:      o.Address,
+		contract:     contract,
+		store:        o.Store,
+		chequeSigner: NewChequeSigner(o.Signer, chainID),
+	}, nil
+}
+
+// Init returns the chequebook service of the node. On the first start, the
+// chequebook is deployed with the initial deposit and its address is stored
+// in the state store.
+func Init(ctx context.Context, backend Backend, store storage.StateStorer, signer crypto.Signer, initialDeposit *big.Int) (Service, error) {
+	var address []byte
+	err := store.Get(chequebookKey, &address)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
+	if errors.Is(err, storage.ErrNotFound) {
+		issuer, err := signer.EthereumAddress()
+		if err != nil {
+			return nil, err
+		}
+		address, err = backend.Deploy(ctx, issuer)
+		if err != nil {
+			return nil, fmt.Errorf("deploy chequebook: %w", err)
+		}
+		if initialDeposit != nil && initialDeposit.Sign() > 0 {
+			contract, err := backend.Contract(ctx, address)
+			if err != nil {
+				return nil, err
+			}
+			if err := contract.Deposit(ctx, initialDeposit); err != nil {
+				return nil, fmt.Errorf("initial deposit: %w", err)
+			}
+		}
+		if err := store.Put(chequebookKey, address); err != nil {
+			return nil, err
+		}
+	}
+
+	return New(ctx, Options{
+		Backend: backend,
+		Store:   store,
+		Signer:  signer,
+		Address: address,
+	})
+}
+
+// Deposit transfers the amount of tokens to the chequebook.
+func (s *service) Deposit(ctx context.Context, amount *big.Int) error {
+	return s.contract.Deposit(ctx, amount)
+}
+
+// Balance returns the amount of tokens held by the chequebook.
+func (s *service) Balance(ctx context.Context) (*big.Int, error) {
+	return s.contract.Balance(ctx)
+}
+
+// AvailableBalance returns the balance of the chequebook less the amount of
+// the issued cheques that are not cashed out yet.
+func (s *service) AvailableBalance(ctx context.Context) (*big.Int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.availableBalance(ctx)
+}
+
+func (s *service) availableBalance(ctx context.Context) (*big.Int, error) {
+	balance, err := s.contract.Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totalPaidOut, err := s.contract.TotalPaidOut(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totalIssued, err := s.totalIssued()
+	if err != nil {
+		return nil, err
+	}
+	// the issued cheques not yet cashed out are still in the balance
+	outstanding := new(big.Int).Sub(totalIssued, totalPaidOut)
+	return new(big.Int).Sub(balance, outstanding), nil
+}
+
+// Address returns the address of the chequebook contract.
+func (s *service) Address() []byte {
+	return s.address
+}
+
+// Issue issues a cheque increasing the cumulative payout to the beneficiary
+// by the amount and sends it with the send function. The cheque is persisted
+// only if it is sent, so that the next cheque does not include the amount of
+// the cheque that the beneficiary did not get.
+func (s *service) Issue(ctx context.Context, beneficiary []byte, amount *big.Int, send SendChequeFunc) (*SignedCheque, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	available, err := s.availableBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Cmp(available) > 0 {
+		return nil, ErrOutOfFunds
+	}
+
+	cumulativePayout := new(big.Int).Set(amount)
+	last, err := s.LastCheque(beneficiary)
+	if err != nil && !errors.Is(err, ErrNoCheque) {
+		return nil, err
+	}
+	if err == nil {
+		cumulativePayout.Add(cumulativePayout, last.CumulativePayout)
+	}
+
+	cheque := Cheque{
+		Chequebook:       s.address,
+		Beneficiary:      beneficiary,
+		CumulativePayout: cumulativePayout,
+	}
+	signature, err := s.chequeSigner.Sign(&cheque)
+	if err != nil {
+		return nil, fmt.Errorf("sign cheque: %w", err)
+	}
+	signed := &SignedCheque{
+		Cheque:    cheque,
+		Signature: signature,
+	}
+
+	totalIssued, err := s.totalIssued()
+	if err != nil {
+		return nil, err
+	}
+	if err := send(signed); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(lastIssuedChequeKey(beneficiary), signed); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(totalIssuedKey, totalIssued.Add(totalIssued, amount)); err != nil {
+		return nil, err
+	}
+
+	return signed, nil
+}
+
+// LastCheque returns the last cheque issued to the beneficiary.
+func (s *service) LastCheque(beneficiary []byte) (*SignedCheque, error) {
+	return lastCheque(s.store, lastIssuedChequeKey(beneficiary))
+}
+
+// LastCheques returns the last cheques issued to all beneficiaries.
+func (s *service) LastCheques() (map[string]*SignedCheque, error) {
+	return lastCheques(s.store, lastIssuedChequeKeyPrefix)
+}
+
+func (s *service) totalIssued() (*big.Int, error) {
+	totalIssued := new(big.Int)
+	if err := s.store.Get(totalIssuedKey, totalIssued); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	return totalIssued, nil
+}
+
+func lastCheque(store storage.StateStorer, key string) (*SignedCheque, error) {
+	cheque := new(SignedCheque)
+	if err := store.Get(key, cheque); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrNoCheque
+		}
+		return nil, err
+	}
+	return cheque, nil
+}
+
+func lastCheques(store storage.StateStorer, prefix string) (map[string]*SignedCheque, error) {
+	cheques := make(map[string]*SignedCheque)
+	err := store.Iterate(prefix, func(key, val []byte) (stop bool, err error) {
+		address := strings.TrimPrefix(string(key), prefix)
+		cheque := new(SignedCheque)
+		if err := json.Unmarshal(val, cheque); err != nil {
+			return false, fmt.Errorf("unmarshal cheque: %s: %w", string(key), err)
+		}
+		cheques[address] = cheque
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cheques, nil
+}
+
+func lastIssuedChequeKey(beneficiary []byte) string {
+	return lastIssuedChequeKeyPrefix + hex.EncodeToString(beneficiary)
+}
+
+func lastReceivedChequeKey(chequebook []byte) string {
+	return lastReceivedChequeKeyPrefix + hex.EncodeToString(chequebook)
+}
@@ -0,0 +1,197 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/simulated"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+const testChainID = 1
+
+// sendCheque is the send function of the cheques that are sent successfully.
+func sendCheque(*chequebook.SignedCheque) error { return nil }
+
+// acceptCheque is the accept function of the cheques that are accepted.
+func acceptCheque(*big.Int) error { return nil }
+
+// newTestChequebook deploys a chequebook on the backend with the deposit
+// minted to the issuer.
+func newTestChequebook(t *testing.T, backend *simulated.Backend, deposit int64) (chequebook.Service, crypto.Signer) {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	issuer, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Mint(issuer, big.NewInt(deposit))
+
+	cb, err := chequebook.Init(context.Background(), backend, statestore.NewStateStore(), signer, big.NewInt(deposit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cb, signer
+}
+
+func TestChequebookIssue(t *testing.T) {
+	ctx := context.Background()
+	backend := simulated.NewBackend(testChainID)
+	cb, signer := newTestChequebook(t, backend, 1000)
+	issuer, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	beneficiary := bytes.Repeat([]byte{1}, 20)
+
+	if _, err := cb.LastCheque(beneficiary); !errors.Is(err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCheque)
+	}
+
+	var last *chequebook.SignedCheque
+	for _, amount := range []int64{100, 200} {
+		cheque, err := cb.Issue(ctx, beneficiary, big.NewInt(amount), sendCheque)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = cheque
+	}
+
+	if last.CumulativePayout.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("got cumulative payout %v, want 300", last.CumulativePayout)
+	}
+	if !bytes.Equal(last.Chequebook, cb.Address()) {
+		t.Fatalf("got chequebook %x, want %x", last.Chequebook, cb.Address())
+	}
+	signerAddress, err := chequebook.RecoverCheque(last, testChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signerAddress, issuer) {
+		t.Fatalf("got signer %x, want %x", signerAddress, issuer)
+	}
+
+	stored, err := cb.LastCheque(beneficiary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Equal(last) {
+		t.Fatalf("got last cheque %+v, want %+v", stored, last)
+	}
+
+	cheques, err := cb.LastCheques()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cheques) != 1 {
+		t.Fatalf("got %d cheques, want 1", len(cheques))
+	}
+
+	balance, err := cb.Balance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("got balance %v, want 1000", balance)
+	}
+	available, err := cb.AvailableBalance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available.Cmp(big.NewInt(700)) != 0 {
+		t.Fatalf("got available balance %v, want 700", available)
+	}
+
+	if _, err := cb.Issue(ctx, beneficiary, big.NewInt(701), sendCheque); !errors.Is(err, chequebook.ErrOutOfFunds) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrOutOfFunds)
+	}
+
+	// the cheque that is not sent is not recorded
+	errSend := errors.New("send")
+	if _, err := cb.Issue(ctx, beneficiary, big.NewInt(100), func(*chequebook.SignedCheque) error {
+		return errSend
+	}); !errors.Is(err, errSend) {
+		t.Fatalf("got error %v, want %v", err, errSend)
+	}
+	stored, err = cb.LastCheque(beneficiary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Equal(last) {
+		t.Fatalf("got last cheque %+v after failed send, want %+v", stored, last)
+	}
+	available, err = cb.AvailableBalance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available.Cmp(big.NewInt(700)) != 0 {
+		t.Fatalf("got available balance %v after failed send, want 700", available)
+	}
+
+	// cashing out the cheque keeps the available balance
+	contract, err := backend.Contract(ctx, cb.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := contract.CashCheque(ctx, last); err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.BalanceOf(beneficiary); got.Cmp(big.NewInt(300)) != 0 {
+		t.Fatalf("got beneficiary balance %v, want 300", got)
+	}
+	if err := contract.CashCheque(ctx, last); !errors.Is(err, simulated.ErrNothingToPayOut) {
+		t.Fatalf("got error %v, want %v", err, simulated.ErrNothingToPayOut)
+	}
+	available, err = cb.AvailableBalance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if available.Cmp(big.NewInt(700)) != 0 {
+		t.Fatalf("got available balance %v, want 700", available)
+	}
+}
+
+func TestChequebookInit(t *testing.T) {
+	ctx := context.Background()
+	backend := simulated.NewBackend(testChainID)
+	store := statestore.NewStateStore()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+
+	if _, err := chequebook.Init(ctx, backend, store, signer, big.NewInt(10)); !errors.Is(err, simulated.ErrInsufficientBalance) {
+		t.Fatalf("got error %v, want %v", err, simulated.ErrInsufficientBalance)
+	}
+
+	cb, err := chequebook.Init(ctx, backend, store, signer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the chequebook is deployed only once
+	again, err := chequebook.Init(ctx, backend, store, signer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Address(), cb.Address()) {
+		t.Fatalf("got chequebook %x, want %x", again.Address(), cb.Address())
+	}
+}
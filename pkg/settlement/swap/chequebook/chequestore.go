@@ -0,0 +1,150 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/storage"
+)
+
+var (
+	// ErrWrongBeneficiary is returned when the beneficiary of the cheque is
+	// not the node.
+	ErrWrongBeneficiary = errors.New("wrong beneficiary")
+	// ErrChequeNotIncreasing is returned when the cumulative payout of the
+	// cheque is not larger than the one of the last received cheque.
+	ErrChequeNotIncreasing = errors.New("cheque cumulative payout not increasing")
+	// ErrChequeInvalid is returned when the cheque is not signed by the
+	// issuer of the chequebook.
+	ErrChequeInvalid = errors.New("invalid cheque")
+	// ErrBouncingCheque is returned when the chequebook cannot cover the
+	// cheque.
+	ErrBouncingCheque = errors.New("bouncing cheque")
+)
+
+// AcceptChequeFunc accepts the payment of the amount that the received cheque
+// adds to the last cheque from the chequebook.
+type AcceptChequeFunc func(amount *big.Int) error
+
+// ChequeStore validates and stores the cheques received from the
+// chequebooks of the peers.
+type ChequeStore interface {
+	// ReceiveCheque verifies the cheque and stores it if the amount it adds
+	// to the last received cheque from the chequebook is accepted, returning
+	// the amount.
+	ReceiveCheque(ctx context.Context, cheque *SignedCheque, accept AcceptChequeFunc) (*big.Int, error)
+	// LastCheque returns the last cheque received from the chequebook.
+	LastCheque(chequebook []byte) (*SignedCheque, error)
+	// LastCheques returns the last cheques received from all chequebooks by
+	// their hex encoded addresses.
+	LastCheques() (map[string]*SignedCheque, error)
+}
+
+type chequeStore struct {
+	lock        sync.Mutex // serializes the receiving of the cheques
+	store       storage.StateStorer
+	backend     Backend
+	beneficiary []byte
+}
+
+// NewChequeStore creates a new cheque store for the cheques with the
+// beneficiary.
+func NewChequeStore(store storage.StateStorer, backend Backend, beneficiary []byte) ChequeStore {
+	return &chequeStore{
+		store:       store,
+		backend:     backend,
+		beneficiary: beneficiary,
+	}
+}
+
+// ReceiveCheque verifies and stores the cheque. The cheque must be for the
+// beneficiary of the store, increase the cumulative payout of the last cheque
+// from the chequebook, be signed by the chequebook issuer and be covered by
+// the chequebook. The verified cheque is stored only if the accept function
+// accepts its amount, otherwise the error of the accept function is returned.
+func (s *chequeStore) ReceiveCheque(ctx context.Context, cheque *SignedCheque, accept AcceptChequeFunc) (*big.Int, error) {
+	if !bytes.Equal(cheque.Beneficiary, s.beneficiary) {
+		return nil, ErrWrongBeneficiary
+	}
+	if cheque.CumulativePayout == nil {
+		return nil, ErrChequeInvalid
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	lastReceivedPayout := new(big.Int)
+	last, err := s.LastCheque(cheque.Chequebook)
+	if err != nil && !errors.Is(err, ErrNoCheque) {
+		return nil, err
+	}
+	if err == nil {
+		lastReceivedPayout = last.CumulativePayout
+	}
+	if cheque.CumulativePayout.Cmp(lastReceivedPayout) <= 0 {
+		return nil, ErrChequeNotIncreasing
+	}
+
+	contract, err := s.backend.Contract(ctx, cheque.Chequebook)
+	if err != nil {
+		return nil, err
+	}
+
+	chainID, err := s.backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("chain id: %w", err)
+	}
+	signer, err := RecoverCheque(cheque, chainID)
+	if err != nil {
+		return nil, err
+	}
+	issuer, err := contract.Issuer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(signer, issuer) {
+		return nil, ErrChequeInvalid
+	}
+
+	// the chequebook must hold the amount of the cheque that is not paid out
+	balance, err := contract.Balance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paidOut, err := contract.PaidOut(ctx, s.beneficiary)
+	if err != nil {
+		return nil, err
+	}
+	if new(big.Int).Add(balance, paidOut).Cmp(cheque.CumulativePayout) < 0 {
+		return nil, ErrBouncingCheque
+	}
+
+	amount := new(big.Int).Sub(cheque.CumulativePayout, lastReceivedPayout)
+	if err := accept(amount); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Put(lastReceivedChequeKey(cheque.Chequebook), cheque); err != nil {
+		return nil, err
+	}
+
+	return amount, nil
+}
+
+// LastCheque returns the last cheque received from the chequebook.
+func (s *chequeStore) LastCheque(chequebook []byte) (*SignedCheque, error) {
+	return lastCheque(s.store, lastReceivedChequeKey(chequebook))
+}
+
+// LastCheques returns the last cheques received from all chequebooks.
+func (s *chequeStore) LastCheques() (map[string]*SignedCheque, error) {
+	return lastCheques(s.store, lastReceivedChequeKeyPrefix)
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/simulated"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+)
+
+func TestReceiveCheque(t *testing.T) {
+	ctx := context.Background()
+	backend := simulated.NewBackend(testChainID)
+	cb, _ := newTestChequebook(t, backend, 1000)
+
+	beneficiary := bytes.Repeat([]byte{1}, 20)
+	store := chequebook.NewChequeStore(statestore.NewStateStore(), backend, beneficiary)
+
+	if _, err := store.LastCheque(cb.Address()); !errors.Is(err, chequebook.ErrNoCheque) {
+		t.Fatalf("got error %v, want %v", err, chequebook.ErrNoCheque)
+	}
+
+	first, err := cb.Issue(ctx, beneficiary, big.NewInt(100), sendCheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount, err := store.ReceiveCheque(ctx, first, acceptCheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("got amount %v, want 100", amount)
+	}
+
+	second, err := cb.Issue(ctx, beneficiary, big.NewInt(50), sendCheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount, err = store.ReceiveCheque(ctx, second, acceptCheque)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amount.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("got amount %v, want 50", amount)
+	}
+
+	last, err := store.LastCheque(cb.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Equal(second) {
+		t.Fatalf("got last cheque %+v, want %+v", last, second)
+	}
+	cheques, err := store.LastCheques()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cheques) != 1 {
+		t.Fatalf("got %d cheques, want 1", len(cheques))
+	}
+
+	t.Run("not accepted", func(t *testing.T) {
+		cheque, err := cb.Issue(ctx, beneficiary, big.NewInt(10), sendCheque)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errNotAccepted := errors.New("not accepted")
+		if _, err := store.ReceiveCheque(ctx, cheque, func(amount *big.Int) error {
+			if amount.Cmp(big.NewInt(10)) != 0 {
+				t.Errorf("got amount %v, want 10", amount)
+			}
+			return errNotAccepted
+		}); !errors.Is(err, errNotAccepted) {
+			t.Fatalf("got error %v, want %v", err, errNotAccepted)
+		}
+		last, err := store.LastCheque(cb.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !last.Equal(second) {
+			t.Fatalf("got last cheque %+v, want %+v", last, second)
+		}
+	})
+
+	t.Run("not increasing", func(t *testing.T) {
+		if _, err := store.ReceiveCheque(ctx, first, acceptCheque); !errors.Is(err, chequebook.ErrChequeNotIncreasing) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeNotIncreasing)
+		}
+	})
+
+	t.Run("wrong beneficiary", func(t *testing.T) {
+		cheque, err := cb.Issue(ctx, bytes.Repeat([]byte{2}, 20), big.NewInt(10), sendCheque)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.ReceiveCheque(ctx, cheque, acceptCheque); !errors.Is(err, chequebook.ErrWrongBeneficiary) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrWrongBeneficiary)
+		}
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		key, err := crypto.GenerateSecp256k1Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cheque := chequebook.Cheque{
+			Chequebook:       cb.Address(),
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(500),
+		}
+		signature, err := chequebook.NewChequeSigner(crypto.NewDefaultSigner(key), testChainID).Sign(&cheque)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.ReceiveCheque(ctx, &chequebook.SignedCheque{
+			Cheque:    cheque,
+			Signature: signature,
+		}, acceptCheque); !errors.Is(err, chequebook.ErrChequeInvalid) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrChequeInvalid)
+		}
+	})
+
+	t.Run("bouncing", func(t *testing.T) {
+		// the chequebook holds less than the cumulative payout
+		otherCb, signer := newTestChequebook(t, backend, 100)
+		cheque := chequebook.Cheque{
+			Chequebook:       otherCb.Address(),
+			Beneficiary:      beneficiary,
+			CumulativePayout: big.NewInt(200),
+		}
+		signature, err := chequebook.NewChequeSigner(signer, testChainID).Sign(&cheque)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.ReceiveCheque(ctx, &chequebook.SignedCheque{
+			Cheque:    cheque,
+			Signature: signature,
+		}, acceptCheque); !errors.Is(err, chequebook.ErrBouncingCheque) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrBouncingCheque)
+		}
+	})
+
+	t.Run("not deployed", func(t *testing.T) {
+		cheque := *second
+		cheque.Chequebook = bytes.Repeat([]byte{3}, 20)
+		cheque.CumulativePayout = big.NewInt(1000)
+		if _, err := store.ReceiveCheque(ctx, &cheque, acceptCheque); !errors.Is(err, chequebook.ErrNotDeployed) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrNotDeployed)
+		}
+	})
+}
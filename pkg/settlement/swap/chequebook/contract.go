@@ -0,0 +1,48 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chequebook
+
+import (
+	"context"
+	"errors"
+	"math/big"
+)
+
+// ErrNotDeployed is returned when there is no chequebook contract deployed
+// at the address.
+var ErrNotDeployed = errors.New("chequebook not deployed")
+
+// Contract is the interface to the calls of the chequebook contract on the
+// chain.
+type Contract interface {
+	// Issuer returns the ethereum address of the issuer of the chequebook.
+	Issuer(ctx context.Context) ([]byte, error)
+	// Balance returns the amount of tokens held by the chequebook.
+	Balance(ctx context.Context) (*big.Int, error)
+	// PaidOut returns the total amount cashed out by the beneficiary.
+	PaidOut(ctx context.Context, beneficiary []byte) (*big.Int, error)
+	// TotalPaidOut returns the total amount cashed out by all beneficiaries.
+	TotalPaidOut(ctx context.Context) (*big.Int, error)
+	// Deposit transfers the amount of tokens from the issuer to the
+	// chequebook.
+	Deposit(ctx context.Context, amount *big.Int) error
+	// CashCheque pays out the difference of the cumulative payout of the
+	// cheque and the amount already paid out to its beneficiary.
+	CashCheque(ctx context.Context, cheque *SignedCheque) error
+}
+
+// Backend is the interface to the chain on which the chequebook contracts are
+// deployed.
+type Backend interface {
+	// ChainID returns the id of the chain, which is part of the cheque
+	// signatures.
+	ChainID(ctx context.Context) (int64, error)
+	// Deploy deploys a new chequebook contract for the issuer, returning its
+	// address.
+	Deploy(ctx context.Context, issuer []byte) (address []byte, err error)
+	// Contract returns the chequebook contract at the address. It returns
+	// ErrNotDeployed if there is no chequebook at the address.
+	Contract(ctx context.Context, address []byte) (Contract, error)
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mock provides a mock implementation for the chequebook service.
+package mock
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+var _ chequebook.Service = (*Service)(nil)
+
+// Service is the mock chequebook service. Without the options, it has an
+// empty balance and no cheques.
+type Service struct {
+	depositFunc          func(context.Context, *big.Int) error
+	balanceFunc          func(context.Context) (*big.Int, error)
+	availableBalanceFunc func(context.Context) (*big.Int, error)
+	addressFunc          func() []byte
+	issueFunc            func(context.Context, []byte, *big.Int, chequebook.SendChequeFunc) (*chequebook.SignedCheque, error)
+	lastChequeFunc       func([]byte) (*chequebook.SignedCheque, error)
+	lastChequesFunc      func() (map[string]*chequebook.SignedCheque, error)
+}
+
+// WithDepositFunc sets the mock Deposit function.
+func WithDepositFunc(f func(context.Context, *big.Int) error) Option {
+	return optionFunc(func(s *Service) {
+		s.depositFunc = f
+	})
+}
+
+// WithBalanceFunc sets the mock Balance function.
+func WithBalanceFunc(f func(context.Context) (*big.Int, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.balanceFunc = f
+	})
+}
+
+// WithAvailableBalanceFunc sets the mock AvailableBalance function.
+func WithAvailableBalanceFunc(f func(context.Context) (*big.Int, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.availableBalanceFunc = f
+	})
+}
+
+// WithAddressFunc sets the mock Address function.
+func WithAddressFunc(f func() []byte) Option {
+	return optionFunc(func(s *Service) {
+		s.addressFunc = f
+	})
+}
+
+// WithIssueFunc sets the mock Issue function.
+func WithIssueFunc(f func(context.Context, []byte, *big.Int, chequebook.SendChequeFunc) (*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.issueFunc = f
+	})
+}
+
+// WithLastChequeFunc sets the mock LastCheque function.
+func WithLastChequeFunc(f func([]byte) (*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastChequeFunc = f
+	})
+}
+
+// WithLastChequesFunc sets the mock LastCheques function.
+func WithLastChequesFunc(f func() (map[string]*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastChequesFunc = f
+	})
+}
+
+// NewChequebook creates the mock chequebook implementation.
+func NewChequebook(opts ...Option) *Service {
+	mock := new(Service)
+	for _, o := range opts {
+		o.apply(mock)
+	}
+	return mock
+}
+
+// Deposit is the mock function wrapper that calls the set implementation.
+func (s *Service) Deposit(ctx context.Context, amount *big.Int) error {
+	if s.depositFunc != nil {
+		return s.depositFunc(ctx, amount)
+	}
+	return nil
+}
+
+// Balance is the mock function wrapper that calls the set implementation.
+func (s *Service) Balance(ctx context.Context) (*big.Int, error) {
+	if s.balanceFunc != nil {
+		return s.balanceFunc(ctx)
+	}
+	return new(big.Int), nil
+}
+
+// AvailableBalance is the mock function wrapper that calls the set implementation.
+func (s *Service) AvailableBalance(ctx context.Context) (*big.Int, error) {
+	if s.availableBalanceFunc != nil {
+		return s.availableBalanceFunc(ctx)
+	}
+	return new(big.Int), nil
+}
+
+// Address is the mock function wrapper that calls the set implementation.
+func (s *Service) Address() []byte {
+	if s.addressFunc != nil {
+		return s.addressFunc()
+	}
+	return make([]byte, 20)
+}
+
+// Issue is the mock function wrapper that calls the set implementation.
+func (s *Service) Issue(ctx context.Context, beneficiary []byte, amount *big.Int, send chequebook.SendChequeFunc) (*chequebook.SignedCheque, error) {
+	if s.issueFunc != nil {
+		return s.issueFunc(ctx, beneficiary, amount, send)
+	}
+	return nil, chequebook.ErrOutOfFunds
+}
+
+// LastCheque is the mock function wrapper that calls the set implementation.
+func (s *Service) LastCheque(beneficiary []byte) (*chequebook.SignedCheque, error) {
+	if s.lastChequeFunc != nil {
+		return s.lastChequeFunc(beneficiary)
+	}
+	return nil, chequebook.ErrNoCheque
+}
+
+// LastCheques is the mock function wrapper that calls the set implementation.
+func (s *Service) LastCheques() (map[string]*chequebook.SignedCheque, error) {
+	if s.lastChequesFunc != nil {
+		return s.lastChequesFunc()
+	}
+	return map[string]*chequebook.SignedCheque{}, nil
+}
+
+// Option is the option passed to the mock chequebook service.
+type Option interface {
+	apply(*Service)
+}
+
+type optionFunc func(*Service)
+
+func (f optionFunc) apply(r *Service) { f(r) }
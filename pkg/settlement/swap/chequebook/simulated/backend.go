@@ -0,0 +1,200 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package simulated provides an in-memory chain backend with the chequebook
+// contracts and the token balances for tests.
+package simulated
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+)
+
+var _ chequebook.Backend = (*Backend)(nil)
+
+var (
+	// ErrInsufficientBalance is returned when the token balance of the
+	// sender does not cover the transfer.
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrInvalidSignature is returned when the cheque is not signed by the
+	// issuer of the chequebook.
+	ErrInvalidSignature = errors.New("invalid issuer signature")
+	// ErrNothingToPayOut is returned when the cheque does not increase the
+	// amount paid out to the beneficiary.
+	ErrNothingToPayOut = errors.New("nothing to pay out")
+)
+
+// Backend is an in-memory chain that holds the token balances of the
+// accounts and the chequebook contracts. The calls are applied immediately.
+type Backend struct {
+	mtx         sync.Mutex
+	chainID     int64
+	balances    map[string]*big.Int // token balances by hex encoded address
+	chequebooks map[string]*contract
+}
+
+// NewBackend creates a new in-memory chain with the chain id.
+func NewBackend(chainID int64) *Backend {
+	return &Backend{
+		chainID:     chainID,
+		balances:    make(map[string]*big.Int),
+		chequebooks: make(map[string]*contract),
+	}
+}
+
+// ChainID returns the id of the chain.
+func (b *Backend) ChainID(_ context.Context) (int64, error) {
+	return b.chainID, nil
+}
+
+// Deploy creates a new chequebook of the issuer at a random address.
+func (b *Backend) Deploy(_ context.Context, issuer []byte) ([]byte, error) {
+	address := make([]byte, 20)
+	if _, err := rand.Read(address); err != nil {
+		return nil, err
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.chequebooks[hex.EncodeToString(address)] = &contract{
+		backend: b,
+		address: address,
+		issuer:  issuer,
+		paidOut: make(map[string]*big.Int),
+	}
+	return address, nil
+}
+
+// Contract returns the chequebook at the address.
+func (b *Backend) Contract(_ context.Context, address []byte) (chequebook.Contract, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	c, ok := b.chequebooks[hex.EncodeToString(address)]
+	if !ok {
+		return nil, chequebook.ErrNotDeployed
+	}
+	return c, nil
+}
+
+// Mint adds the amount of tokens to the balance of the address.
+func (b *Backend) Mint(address []byte, amount *big.Int) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.balance(address).Add(b.balance(address), amount)
+}
+
+// BalanceOf returns the token balance of the address.
+func (b *Backend) BalanceOf(address []byte) *big.Int {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return new(big.Int).Set(b.balance(address))
+}
+
+// balance returns the token balance of the address. It must be called with
+// the lock held.
+func (b *Backend) balance(address []byte) *big.Int {
+	key := hex.EncodeToString(address)
+	balance, ok := b.balances[key]
+	if !ok {
+		balance = new(big.Int)
+		b.balances[key] = balance
+	}
+	return balance
+}
+
+// transfer moves the amount of tokens between the addresses. It must be
+// called with the lock held.
+func (b *Backend) transfer(from, to []byte, amount *big.Int) error {
+	if b.balance(from).Cmp(amount) < 0 {
+		return ErrInsufficientBalance
+	}
+	b.balance(from).Sub(b.balance(from), amount)
+	b.balance(to).Add(b.balance(to), amount)
+	return nil
+}
+
+// contract is the simulated chequebook contract.
+type contract struct {
+	backend      *Backend
+	address      []byte
+	issuer       []byte
+	paidOut      map[string]*big.Int
+	totalPaidOut big.Int
+}
+
+func (c *contract) Issuer(_ context.Context) ([]byte, error) {
+	return c.issuer, nil
+}
+
+func (c *contract) Balance(_ context.Context) (*big.Int, error) {
+	return c.backend.BalanceOf(c.address), nil
+}
+
+func (c *contract) PaidOut(_ context.Context, beneficiary []byte) (*big.Int, error) {
+	c.backend.mtx.Lock()
+	defer c.backend.mtx.Unlock()
+
+	paidOut, ok := c.paidOut[hex.EncodeToString(beneficiary)]
+	if !ok {
+		return new(big.Int), nil
+	}
+	return new(big.Int).Set(paidOut), nil
+}
+
+func (c *contract) TotalPaidOut(_ context.Context) (*big.Int, error) {
+	c.backend.mtx.Lock()
+	defer c.backend.mtx.Unlock()
+
+	return new(big.Int).Set(&c.totalPaidOut), nil
+}
+
+func (c *contract) Deposit(_ context.Context, amount *big.Int) error {
+	c.backend.mtx.Lock()
+	defer c.backend.mtx.Unlock()
+
+	return c.backend.transfer(c.issuer, c.address, amount)
+}
+
+func (c *contract) CashCheque(_ context.Context, cheque *chequebook.SignedCheque) error {
+	if !bytes.Equal(cheque.Chequebook, c.address) {
+		return ErrInvalidSignature
+	}
+	signer, err := chequebook.RecoverCheque(cheque, c.backend.chainID)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(signer, c.issuer) {
+		return ErrInvalidSignature
+	}
+
+	c.backend.mtx.Lock()
+	defer c.backend.mtx.Unlock()
+
+	key := hex.EncodeToString(cheque.Beneficiary)
+	paidOut, ok := c.paidOut[key]
+	if !ok {
+		paidOut = new(big.Int)
+	}
+	amount := new(big.Int).Sub(cheque.CumulativePayout, paidOut)
+	if amount.Sign() <= 0 {
+		return ErrNothingToPayOut
+	}
+	if err := c.backend.transfer(c.address, cheque.Beneficiary, amount); err != nil {
+		return err
+	}
+	c.paidOut[key] = new(big.Int).Set(cheque.CumulativePayout)
+	c.totalPaidOut.Add(&c.totalPaidOut, amount)
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mock provides a mock implementation for the cheques exposed by the
+// swap service.
+package mock
+
+import (
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ swap.ApiInterface = (*Service)(nil)
+
+// Service is the mock swap service. Without the options, it has no cheques
+// recorded.
+type Service struct {
+	lastSentChequeFunc      func(swarm.Address) (*chequebook.SignedCheque, error)
+	lastReceivedChequeFunc  func(swarm.Address) (*chequebook.SignedCheque, error)
+	lastSentChequesFunc     func() (map[string]*chequebook.SignedCheque, error)
+	lastReceivedChequesFunc func() (map[string]*chequebook.SignedCheque, error)
+}
+
+// WithLastSentChequeFunc sets the mock LastSentCheque function.
+func WithLastSentChequeFunc(f func(swarm.Address) (*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastSentChequeFunc = f
+	})
+}
+
+// WithLastReceivedChequeFunc sets the mock LastReceivedCheque function.
+func WithLastReceivedChequeFunc(f func(swarm.Address) (*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastReceivedChequeFunc = f
+	})
+}
+
+// WithLastSentChequesFunc sets the mock LastSentCheques function.
+func WithLastSentChequesFunc(f func() (map[string]*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastSentChequesFunc = f
+	})
+}
+
+// WithLastReceivedChequesFunc sets the mock LastReceivedCheques function.
+func WithLastReceivedChequesFunc(f func() (map[string]*chequebook.SignedCheque, error)) Option {
+	return optionFunc(func(s *Service) {
+		s.lastReceivedChequesFunc = f
+	})
+}
+
+// New creates the mock swap implementation.
+func New(opts ...Option) *Service {
+	mock := new(Service)
+	for _, o := range opts {
+		o.apply(mock)
+	}
+	return mock
+}
+
+// LastSentCheque is the mock function wrapper that calls the set implementation.
+func (s *Service) LastSentCheque(peer swarm.Address) (*chequebook.SignedCheque, error) {
+	if s.lastSentChequeFunc != nil {
+		return s.lastSentChequeFunc(peer)
+	}
+	return nil, settlement.ErrPeerNoSettlements
+}
+
+// LastReceivedCheque is the mock function wrapper that calls the set implementation.
+func (s *Service) LastReceivedCheque(peer swarm.Address) (*chequebook.SignedCheque, error) {
+	if s.lastReceivedChequeFunc != nil {
+		return s.lastReceivedChequeFunc(peer)
+	}
+	return nil, settlement.ErrPeerNoSettlements
+}
+
+// LastSentCheques is the mock function wrapper that calls the set implementation.
+func (s *Service) LastSentCheques() (map[string]*chequebook.SignedCheque, error) {
+	if s.lastSentChequesFunc != nil {
+		return s.lastSentChequesFunc()
+	}
+	return map[string]*chequebook.SignedCheque{}, nil
+}
+
+// LastReceivedCheques is the mock function wrapper that calls the set implementation.
+func (s *Service) LastReceivedCheques() (map[string]*chequebook.SignedCheque, error) {
+	if s.lastReceivedChequesFunc != nil {
+		return s.lastReceivedChequesFunc()
+	}
+	return map[string]*chequebook.SignedCheque{}, nil
+}
+
+// Option is the option passed to the mock swap service.
+type Option interface {
+	apply(*Service)
+}
+
+type optionFunc func(*Service)
+
+func (f optionFunc) apply(r *Service) { f(r) }
@@ -0,0 +1,367 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package swap implements the settlement of the debts between the peers with
+// the cheques of the SimpleSwap chequebooks. The debtor issues a cheque from
+// its chequebook to the beneficiary address of the creditor, increasing the
+// cumulative payout by the amount of the payment.
+package swap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p"
+	"github.com/ethersphere/bee/pkg/p2p/protobuf"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/pb"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+const (
+	protocolName    = "swap"
+	protocolVersion = "1.0.0"
+	streamName      = "swap"
+)
+
+const (
+	peerBeneficiaryKeyPrefix = "swap_peer_beneficiary_"
+	peerChequebookKeyPrefix  = "swap_peer_chequebook_"
+)
+
+var (
+	_ settlement.Interface = (*Service)(nil)
+	_ ApiInterface         = (*Service)(nil)
+)
+
+var (
+	// ErrWrongBeneficiary is returned when the peer presents a beneficiary
+	// other than the one it presented before.
+	ErrWrongBeneficiary = errors.New("wrong beneficiary")
+	// ErrWrongChequebook is returned when the peer sends a cheque from a
+	// chequebook other than the one it used before.
+	ErrWrongChequebook = errors.New("wrong chequebook")
+	// ErrInvalidAmount is returned when the amount of the received cheque
+	// does not fit the settlement amounts.
+	ErrInvalidAmount = errors.New("invalid cheque amount")
+)
+
+// ApiInterface exposes the cheques exchanged with the peers.
+type ApiInterface interface {
+	// LastSentCheque returns the last cheque sent to the peer.
+	LastSentCheque(peer swarm.Address) (*chequebook.SignedCheque, error)
+	// LastReceivedCheque returns the last cheque received from the peer.
+	LastReceivedCheque(peer swarm.Address) (*chequebook.SignedCheque, error)
+	// LastSentCheques returns the last cheques sent to all known peers.
+	LastSentCheques() (map[string]*chequebook.SignedCheque, error)
+	// LastReceivedCheques returns the last cheques received from all known
+	// peers.
+	LastReceivedCheques() (map[string]*chequebook.SignedCheque, error)
+}
+
+// Service is the swap service.
+type Service struct {
+	streamer    p2p.Streamer
+	logger      logging.Logger
+	store       storage.StateStorer
+	chequebook  chequebook.Service
+	chequeStore chequebook.ChequeStore
+	beneficiary []byte
+	observer    settlement.PaymentObserver
+}
+
+// Options for the swap service.
+type Options struct {
+	Streamer    p2p.Streamer
+	Logger      logging.Logger
+	Store       storage.StateStorer
+	Chequebook  chequebook.Service
+	ChequeStore chequebook.ChequeStore
+	// Beneficiary is the ethereum address the cheques to the node are
+	// issued to.
+	Beneficiary []byte
+}
+
+// New creates a new swap service.
+func New(o Options) *Service {
+	return &Service{
+		streamer:    o.Streamer,
+		logger:      o.Logger,
+		store:       o.Store,
+		chequebook:  o.Chequebook,
+		chequeStore: o.ChequeStore,
+		beneficiary: o.Beneficiary,
+	}
+}
+
+func (s *Service) Protocol() p2p.ProtocolSpec {
+	return p2p.ProtocolSpec{
+		Name:    protocolName,
+		Version: protocolVersion,
+		StreamSpecs: []p2p.StreamSpec{
+			{
+				Name:    streamName,
+				Handler: s.handler,
+			},
+		},
+	}
+}
+
+// handler presents the beneficiary of the node to the paying peer and
+// receives its cheque.
+func (s *Service) handler(ctx context.Context, p p2p.Peer, stream p2p.Stream) error {
+	w, r := protobuf.NewWriterAndReader(stream)
+	defer stream.Close()
+
+	if err := w.WriteMsgWithContext(ctx, &pb.Handshake{
+		Beneficiary: s.beneficiary,
+	}); err != nil {
+		return fmt.Errorf("write handshake to peer %v: %w", p.Address, err)
+	}
+
+	var req pb.EmitCheque
+	if err := r.ReadMsgWithContext(ctx, &req); err != nil {
+		return fmt.Errorf("read cheque from peer %v: %w", p.Address, err)
+	}
+
+	var cheque chequebook.SignedCheque
+	if err := json.Unmarshal(req.Cheque, &cheque); err != nil {
+		return fmt.Errorf("unmarshal cheque from peer %v: %w", p.Address, err)
+	}
+
+	if err := s.receiveCheque(ctx, p.Address, &cheque); err != nil {
+		return fmt.Errorf("receive cheque from peer %v: %w", p.Address, err)
+	}
+
+	return nil
+}
+
+// receiveCheque validates the cheque of the peer and notifies the observer of
+// the paid amount. The cheque is stored only if the observer accepts the
+// payment.
+func (s *Service) receiveCheque(ctx context.Context, peer swarm.Address, cheque *chequebook.SignedCheque) error {
+	known, err := s.peerChequebook(peer)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	if err == nil && !bytes.Equal(known, cheque.Chequebook) {
+		return ErrWrongChequebook
+	}
+
+	amount, err := s.chequeStore.ReceiveCheque(ctx, cheque, func(amount *big.Int) error {
+		if !amount.IsUint64() {
+			return ErrInvalidAmount
+		}
+		return s.observer.NotifyPayment(peer, amount.Uint64())
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Tracef("swap: received cheque of %d from peer %v", amount.Uint64(), peer)
+
+	return s.store.Put(peerChequebookKey(peer), cheque.Chequebook)
+}
+
+// Pay issues a cheque of the amount to the beneficiary of the peer and sends
+// it to the peer. The cheque is recorded as sent only if it is written to the
+// peer.
+func (s *Service) Pay(ctx context.Context, peer swarm.Address, amount uint64) (accepted uint64, err error) {
+	stream, err := s.streamer.NewStream(ctx, peer, nil, protocolName, protocolVersion, streamName)
+	if err != nil {
+		return 0, fmt.Errorf("new stream: %w", err)
+	}
+	defer stream.Close()
+
+	w, r := protobuf.NewWriterAndReader(stream)
+
+	var handshake pb.Handshake
+	if err := r.ReadMsgWithContext(ctx, &handshake); err != nil {
+		return 0, fmt.Errorf("read handshake from peer %v: %w", peer, err)
+	}
+
+	known, err := s.peerBeneficiary(peer)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, err
+	}
+	if err == nil && !bytes.Equal(known, handshake.Beneficiary) {
+		return 0, ErrWrongBeneficiary
+	}
+	if err := s.store.Put(peerBeneficiaryKey(peer), handshake.Beneficiary); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.chequebook.Issue(ctx, handshake.Beneficiary, new(big.Int).SetUint64(amount), func(cheque *chequebook.SignedCheque) error {
+		encoded, err := json.Marshal(cheque)
+		if err != nil {
+			return err
+		}
+
+		s.logger.Tracef("swap: sending cheque of %d to peer %v", amount, peer)
+		if err := w.WriteMsgWithContext(ctx, &pb.EmitCheque{
+			Cheque: encoded,
+		}); err != nil {
+			return fmt.Errorf("write cheque to peer %v: %w", peer, err)
+		}
+		return nil
+	}); err != nil {
+		return 0, fmt.Errorf("issue cheque: %w", err)
+	}
+
+	// wait for the peer to process the cheque, which is issued regardless
+	if err := stream.FullClose(); err != nil {
+		s.logger.Debugf("swap: close stream to peer %v: %v", peer, err)
+	}
+
+	return amount, nil
+}
+
+// SetPaymentObserver sets the payment observer which will be notified of
+// incoming payments.
+func (s *Service) SetPaymentObserver(observer settlement.PaymentObserver) {
+	s.observer = observer
+}
+
+// TotalSent returns the cumulative payout of the last cheque sent to a peer.
+func (s *Service) TotalSent(peer swarm.Address) (totalSent uint64, err error) {
+	cheque, err := s.LastSentCheque(peer)
+	if err != nil {
+		return 0, err
+	}
+	return cheque.CumulativePayout.Uint64(), nil
+}
+
+// TotalReceived returns the cumulative payout of the last cheque received
+// from a peer.
+func (s *Service) TotalReceived(peer swarm.Address) (totalReceived uint64, err error) {
+	cheque, err := s.LastReceivedCheque(peer)
+	if err != nil {
+		return 0, err
+	}
+	return cheque.CumulativePayout.Uint64(), nil
+}
+
+// SettlementsSent returns the total amounts sent to each known peer.
+func (s *Service) SettlementsSent() (map[string]uint64, error) {
+	return settlements(s.LastSentCheques())
+}
+
+// SettlementsReceived returns the total amounts received from each known
+// peer.
+func (s *Service) SettlementsReceived() (map[string]uint64, error) {
+	return settlements(s.LastReceivedCheques())
+}
+
+// LastSentCheque returns the last cheque sent to the peer.
+func (s *Service) LastSentCheque(peer swarm.Address) (*chequebook.SignedCheque, error) {
+	beneficiary, err := s.peerBeneficiary(peer)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, settlement.ErrPeerNoSettlements
+		}
+		return nil, err
+	}
+	return noSettlements(s.chequebook.LastCheque(beneficiary))
+}
+
+// LastReceivedCheque returns the last cheque received from the peer.
+func (s *Service) LastReceivedCheque(peer swarm.Address) (*chequebook.SignedCheque, error) {
+	chequebookAddress, err := s.peerChequebook(peer)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, settlement.ErrPeerNoSettlements
+		}
+		return nil, err
+	}
+	return noSettlements(s.chequeStore.LastCheque(chequebookAddress))
+}
+
+// LastSentCheques returns the last cheques sent to all known peers.
+func (s *Service) LastSentCheques() (map[string]*chequebook.SignedCheque, error) {
+	return s.lastCheques(peerBeneficiaryKeyPrefix, s.chequebook.LastCheque)
+}
+
+// LastReceivedCheques returns the last cheques received from all known peers.
+func (s *Service) LastReceivedCheques() (map[string]*chequebook.SignedCheque, error) {
+	return s.lastCheques(peerChequebookKeyPrefix, s.chequeStore.LastCheque)
+}
+
+// lastCheques returns the last cheques of the peers stored under the key
+// prefix, looking up the cheques by the stored addresses.
+func (s *Service) lastCheques(prefix string, lastCheque func([]byte) (*chequebook.SignedCheque, error)) (map[string]*chequebook.SignedCheque, error) {
+	addresses := make(map[string][]byte)
+	err := s.store.Iterate(prefix, func(key, val []byte) (stop bool, err error) {
+		peer, err := swarm.ParseHexAddress(strings.TrimPrefix(string(key), prefix))
+		if err != nil {
+			return false, fmt.Errorf("parse address from key: %s: %w", string(key), err)
+		}
+		var address []byte
+		if err := json.Unmarshal(val, &address); err != nil {
+			return false, fmt.Errorf("unmarshal address: %s: %w", string(key), err)
+		}
+		addresses[peer.String()] = address
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cheques := make(map[string]*chequebook.SignedCheque)
+	for peer, address := range addresses {
+		cheque, err := lastCheque(address)
+		if err != nil {
+			if errors.Is(err, chequebook.ErrNoCheque) {
+				continue
+			}
+			return nil, err
+		}
+		cheques[peer] = cheque
+	}
+	return cheques, nil
+}
+
+func (s *Service) peerBeneficiary(peer swarm.Address) (beneficiary []byte, err error) {
+	err = s.store.Get(peerBeneficiaryKey(peer), &beneficiary)
+	return beneficiary, err
+}
+
+func (s *Service) peerChequebook(peer swarm.Address) (chequebookAddress []byte, err error) {
+	err = s.store.Get(peerChequebookKey(peer), &chequebookAddress)
+	return chequebookAddress, err
+}
+
+// noSettlements maps the missing cheque to the settlement error.
+func noSettlements(cheque *chequebook.SignedCheque, err error) (*chequebook.SignedCheque, error) {
+	if errors.Is(err, chequebook.ErrNoCheque) {
+		return nil, settlement.ErrPeerNoSettlements
+	}
+	return cheque, err
+}
+
+func settlements(cheques map[string]*chequebook.SignedCheque, err error) (map[string]uint64, error) {
+	if err != nil {
+		return nil, err
+	}
+	s := make(map[string]uint64, len(cheques))
+	for peer, cheque := range cheques {
+		s[peer] = cheque.CumulativePayout.Uint64()
+	}
+	return s, nil
+}
+
+func peerBeneficiaryKey(peer swarm.Address) string {
+	return peerBeneficiaryKeyPrefix + peer.String()
+}
+
+func peerChequebookKey(peer swarm.Address) string {
+	return peerChequebookKeyPrefix + peer.String()
+}
@@ -0,0 +1,219 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package swap_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/p2p/streamtest"
+	"github.com/ethersphere/bee/pkg/settlement"
+	"github.com/ethersphere/bee/pkg/settlement/swap"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook"
+	"github.com/ethersphere/bee/pkg/settlement/swap/chequebook/simulated"
+	"github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+type testObserver struct {
+	peer   swarm.Address
+	amount uint64
+	err    error
+}
+
+func (t *testObserver) NotifyPayment(peer swarm.Address, amount uint64) error {
+	if t.err != nil {
+		return t.err
+	}
+	t.peer = peer
+	t.amount += amount
+	return nil
+}
+
+// newTestSwap creates a swap service of a node with a chequebook funded with
+// the deposit on the backend.
+func newTestSwap(t *testing.T, backend *simulated.Backend, streamer *streamtest.Recorder, deposit int64) *swap.Service {
+	t.Helper()
+
+	key, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(key)
+	address, err := signer.EthereumAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Mint(address, big.NewInt(deposit))
+
+	store := mock.NewStateStore()
+	cb, err := chequebook.Init(context.Background(), backend, store, signer, big.NewInt(deposit))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := swap.New(swap.Options{
+		Streamer:    streamer,
+		Logger:      logging.New(ioutil.Discard, 0),
+		Store:       store,
+		Chequebook:  cb,
+		ChequeStore: chequebook.NewChequeStore(store, backend, address),
+		Beneficiary: address,
+	})
+	return s
+}
+
+func TestPay(t *testing.T) {
+	backend := simulated.NewBackend(1)
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	observer := &testObserver{}
+	recipient := newTestSwap(t, backend, nil, 0)
+	recipient.SetPaymentObserver(observer)
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(recipient.Protocol()),
+	)
+	payer := newTestSwap(t, backend, recorder, 1000)
+
+	for _, amount := range []uint64{100, 200} {
+		accepted, err := payer.Pay(context.Background(), peerID, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accepted != amount {
+			t.Fatalf("got accepted %d, want %d", accepted, amount)
+		}
+	}
+
+	records, err := recorder.Records(peerID, "swap", "1.0.0", "swap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if err := record.Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the streamtest recorder presents the payer to the recipient with the
+	// recipient peer address
+	if observer.amount != 300 {
+		t.Fatalf("got notified amount %d, want 300", observer.amount)
+	}
+	if !observer.peer.Equal(peerID) {
+		t.Fatalf("got notified peer %s, want %s", observer.peer, peerID)
+	}
+
+	totalSent, err := payer.TotalSent(peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totalSent != 300 {
+		t.Fatalf("got total sent %d, want 300", totalSent)
+	}
+	totalReceived, err := recipient.TotalReceived(peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if totalReceived != 300 {
+		t.Fatalf("got total received %d, want 300", totalReceived)
+	}
+
+	sentCheque, err := payer.LastSentCheque(peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receivedCheque, err := recipient.LastReceivedCheque(peerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sentCheque.Equal(receivedCheque) {
+		t.Fatalf("got received cheque %+v, want %+v", receivedCheque, sentCheque)
+	}
+
+	sent, err := payer.SettlementsSent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[peerID.String()] != 300 {
+		t.Fatalf("got settlements sent %v", sent)
+	}
+	received, err := recipient.SettlementsReceived()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[peerID.String()] != 300 {
+		t.Fatalf("got settlements received %v", received)
+	}
+
+	t.Run("out of funds", func(t *testing.T) {
+		if _, err := payer.Pay(context.Background(), peerID, 701); !errors.Is(err, chequebook.ErrOutOfFunds) {
+			t.Fatalf("got error %v, want %v", err, chequebook.ErrOutOfFunds)
+		}
+	})
+}
+
+// TestPayNotAccepted tests that the cheque is not stored by the recipient if
+// the payment is not accepted.
+func TestPayNotAccepted(t *testing.T) {
+	backend := simulated.NewBackend(1)
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	errOverpayment := errors.New("overpayment")
+	recipient := newTestSwap(t, backend, nil, 0)
+	recipient.SetPaymentObserver(&testObserver{err: errOverpayment})
+
+	recorder := streamtest.New(
+		streamtest.WithProtocols(recipient.Protocol()),
+	)
+	payer := newTestSwap(t, backend, recorder, 1000)
+
+	if _, err := payer.Pay(context.Background(), peerID, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := recorder.Records(peerID, "swap", "1.0.0", "swap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if err := records[0].Err(); !errors.Is(err, errOverpayment) {
+		t.Fatalf("got error %v, want %v", err, errOverpayment)
+	}
+
+	if _, err := recipient.LastReceivedCheque(peerID); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+}
+
+func TestNoSettlements(t *testing.T) {
+	s := newTestSwap(t, simulated.NewBackend(1), nil, 0)
+	peerID := swarm.MustParseHexAddress("9ee7add7")
+
+	if _, err := s.TotalSent(peerID); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+	if _, err := s.TotalReceived(peerID); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+	if _, err := s.LastSentCheque(peerID); !errors.Is(err, settlement.ErrPeerNoSettlements) {
+		t.Fatalf("got error %v, want %v", err, settlement.ErrPeerNoSettlements)
+	}
+	cheques, err := s.LastReceivedCheques()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cheques) != 0 {
+		t.Fatalf("got %d cheques, want none", len(cheques))
+	}
+}
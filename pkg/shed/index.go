@@ -41,10 +41,12 @@ type Item struct {
 	Address         []byte
 	Data            []byte
 	AccessTimestamp int64
+	AccessCount     uint64 // maintains the no of times a chunk is accessed
 	StoreTimestamp  int64
 	BinID           uint64
 	PinCounter      uint64 // maintains the no of time a chunk is pinned
 	Tag             uint32
+	Stamp           []byte // serialized postage stamp
 }
 
 // Merge is a helper method to construct a new
@@ -60,6 +62,9 @@ func (i Item) Merge(i2 Item) Item {
 	if i.AccessTimestamp == 0 {
 		i.AccessTimestamp = i2.AccessTimestamp
 	}
+	if i.AccessCount == 0 {
+		i.AccessCount = i2.AccessCount
+	}
 	if i.StoreTimestamp == 0 {
 		i.StoreTimestamp = i2.StoreTimestamp
 	}
@@ -72,6 +77,9 @@ func (i Item) Merge(i2 Item) Item {
 	if i.Tag == 0 {
 		i.Tag = i2.Tag
 	}
+	if i.Stamp == nil {
+		i.Stamp = i2.Stamp
+	}
 	return i
 }
 
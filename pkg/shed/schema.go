@@ -17,9 +17,12 @@
 package shed
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
 )
 
 var (
@@ -31,6 +34,8 @@ var (
 	// LevelDB key prefix from which indexing keys start.
 	// Every index has its own key prefix and this value defines the first one.
 	keyPrefixIndexStart byte = 2 // Q: or maybe a higher number like 7, to have more space for potential specific perfixes
+	// Maximal number of keys deleted in a single batch by TruncateIndex.
+	truncateBatchSize = 1000
 )
 
 // schema is used to serialize known database structure information.
@@ -118,6 +123,52 @@ func (db *DB) RenameIndex(name, newName string) (renamed bool, err error) {
 	return false, nil
 }
 
+// TruncateIndex deletes all items of the index with the name, without
+// decoding their keys. It is useful when the encoding functions of the index
+// are not known anymore, for example after they were changed by a
+// configuration option. It returns false if the index is not in the schema.
+func (db *DB) TruncateIndex(name string) (truncated bool, err error) {
+	if name == "" {
+		return false, errors.New("index name cannot be blank")
+	}
+	s, err := db.getSchema()
+	if err != nil {
+		return false, fmt.Errorf("get schema: %w", err)
+	}
+	for id, f := range s.Indexes {
+		if f.Name == name {
+			return true, db.deletePrefix([]byte{id})
+		}
+	}
+	return false, nil
+}
+
+// deletePrefix deletes all keys with the prefix in batches of
+// truncateBatchSize keys.
+func (db *DB) deletePrefix(prefix []byte) (err error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	batch := new(leveldb.Batch)
+	for ok := it.Seek(prefix); ok; ok = it.Next() {
+		key := it.Key()
+		if !bytes.HasPrefix(key, prefix) {
+			break
+		}
+		batch.Delete(append([]byte(nil), key...))
+		if batch.Len() >= truncateBatchSize {
+			if err := db.WriteBatch(batch); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return db.WriteBatch(batch)
+}
+
 // schemaIndexID retrieves the complete LevelDB prefix for
 // a particular index.
 func (db *DB) schemaIndexPrefix(name string) (id byte, err error) {
@@ -18,6 +18,7 @@ package shed
 
 import (
 	"bytes"
+	"fmt"
 	"testing"
 )
 
@@ -234,3 +235,55 @@ func TestDB_RenameIndex(t *testing.T) {
 		}
 	})
 }
+
+// TestDB_TruncateIndex validates that TruncateIndex deletes all items of
+// only the index with the provided name.
+func TestDB_TruncateIndex(t *testing.T) {
+	db := newTestDB(t)
+
+	index1, err := db.NewIndex("index1", retrievalIndexFuncs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index2, err := db.NewIndex("index2", retrievalIndexFuncs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// more items than in a single batch
+	count := truncateBatchSize + 10
+	for i := 0; i < count; i++ {
+		item := Item{
+			Address: []byte(fmt.Sprintf("address-%05d", i)),
+			Data:    []byte("data"),
+		}
+		if err := index1.Put(item); err != nil {
+			t.Fatal(err)
+		}
+		if err := index2.Put(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	truncated, err := db.TruncateIndex("index1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated {
+		t.Fatal("index not truncated")
+	}
+
+	if c, err := index1.Count(); err != nil || c != 0 {
+		t.Fatalf("got truncated index count %v (error %v), want 0", c, err)
+	}
+	if c, err := index2.Count(); err != nil || c != count {
+		t.Fatalf("got other index count %v (error %v), want %v", c, err, count)
+	}
+
+	truncated, err = db.TruncateIndex("unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truncated {
+		t.Fatal("unknown index truncated")
+	}
+}
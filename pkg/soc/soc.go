@@ -0,0 +1,239 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package soc provides the single-owner chunk, a chunk whose address is
+// derived from an identifier and the owner instead of from its content.
+//
+// The single-owner chunk wraps a content addressed chunk and is signed by the
+// owner. The signature proves that the owner assigned the content addressed
+// chunk to the identifier, so the owner can update the content of the address
+// by uploading a new chunk under the same identifier.
+//
+// The data of the single-owner chunk is serialized as:
+//
+//	identifier (32 bytes) | signature (65 bytes) | span (8 bytes) | payload
+package soc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// IdSize is the length of the identifier.
+	IdSize = 32
+	// SignatureSize is the length of the signature.
+	SignatureSize = 65
+	// AddressSize is the length of the owner address.
+	AddressSize = 20
+	// spanSize is the length of the span of the wrapped chunk.
+	spanSize = 8
+	// minChunkSize is the length of the chunk data without the payload.
+	minChunkSize = IdSize + SignatureSize + spanSize
+)
+
+var (
+	// ErrInvalidChunk is returned when the chunk data is not a valid
+	// single-owner chunk.
+	ErrInvalidChunk = errors.New("invalid single-owner chunk")
+	// ErrInvalidAddress is returned when the owner address is invalid.
+	ErrInvalidAddress = errors.New("invalid owner address")
+)
+
+// Id is a single-owner chunk identifier.
+type Id []byte
+
+// Owner is the ethereum address of the owner of the single-owner chunk.
+type Owner struct {
+	address []byte
+}
+
+// NewOwner creates a new Owner from the ethereum address.
+func NewOwner(address []byte) (*Owner, error) {
+	if len(address) != AddressSize {
+		return nil, fmt.Errorf("%w: length %d", ErrInvalidAddress, len(address))
+	}
+	return &Owner{
+		address: address,
+	}, nil
+}
+
+// Bytes returns the ethereum address of the owner.
+func (o *Owner) Bytes() []byte {
+	return o.address
+}
+
+// Soc is a single-owner chunk wrapping a content addressed chunk.
+type Soc struct {
+	id        Id
+	signature []byte
+	owner     *Owner
+	chunk     swarm.Chunk
+}
+
+// New creates a new single-owner chunk of the content addressed chunk signed
+// with the signer.
+func New(id Id, ch swarm.Chunk, signer crypto.Signer) (*Soc, error) {
+	if len(id) != IdSize {
+		return nil, fmt.Errorf("invalid identifier length %d", len(id))
+	}
+	publicKey, err := signer.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	ownerAddress, err := crypto.NewEthereumAddress(*publicKey)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := NewOwner(ownerAddress)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := toSignDigest(id, ch.Address())
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(digest)
+	if err != nil {
+		return nil, err
+	}
+	return &Soc{
+		id:        id,
+		signature: signature,
+		owner:     owner,
+		chunk:     ch,
+	}, nil
+}
+
+// NewSigned creates a new single-owner chunk from the identifier, the
+// signature and the content addressed chunk data. The owner is recovered from
+// the signature.
+func NewSigned(id Id, signature, chunkData []byte) (*Soc, error) {
+	if len(id) != IdSize {
+		return nil, fmt.Errorf("%w: identifier length %d", ErrInvalidChunk, len(id))
+	}
+	if len(signature) != SignatureSize {
+		return nil, fmt.Errorf("%w: signature length %d", ErrInvalidChunk, len(signature))
+	}
+	ch, err := content.NewChunkWithSpan(chunkData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChunk, err)
+	}
+	digest, err := toSignDigest(id, ch.Address())
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.Recover(signature, digest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: recover signature: %v", ErrInvalidChunk, err)
+	}
+	ownerAddress, err := crypto.NewEthereumAddress(*publicKey)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := NewOwner(ownerAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &Soc{
+		id:        id,
+		signature: signature,
+		owner:     owner,
+		chunk:     ch,
+	}, nil
+}
+
+// FromChunk parses the single-owner chunk from the chunk data, recovering the
+// owner from the signature. The address of the chunk is not validated.
+func FromChunk(ch swarm.Chunk) (*Soc, error) {
+	data := ch.Data()
+	if len(data) < minChunkSize {
+		return nil, fmt.Errorf("%w: data length %d", ErrInvalidChunk, len(data))
+	}
+	return NewSigned(data[:IdSize], data[IdSize:IdSize+SignatureSize], data[IdSize+SignatureSize:])
+}
+
+// Id returns the identifier of the single-owner chunk.
+func (s *Soc) Id() Id {
+	return s.id
+}
+
+// Signature returns the signature of the owner.
+func (s *Soc) Signature() []byte {
+	return s.signature
+}
+
+// Owner returns the owner of the single-owner chunk.
+func (s *Soc) Owner() *Owner {
+	return s.owner
+}
+
+// WrappedChunk returns the content addressed chunk.
+func (s *Soc) WrappedChunk() swarm.Chunk {
+	return s.chunk
+}
+
+// Address returns the address of the single-owner chunk.
+func (s *Soc) Address() (swarm.Address, error) {
+	return CreateAddress(s.id, s.owner)
+}
+
+// ToChunk returns the single-owner chunk with its serialized data.
+func (s *Soc) ToChunk() (swarm.Chunk, error) {
+	address, err := s.Address()
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.Write(s.id)
+	b.Write(s.signature)
+	b.Write(s.chunk.Data())
+	return swarm.NewChunk(address, b.Bytes()), nil
+}
+
+// CreateAddress creates the address of the single-owner chunk from the
+// identifier and the owner.
+func CreateAddress(id Id, owner *Owner) (swarm.Address, error) {
+	h := sha3.NewLegacyKeccak256()
+	if _, err := h.Write(id); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	if _, err := h.Write(owner.address); err != nil {
+		return swarm.ZeroAddress, err
+	}
+	return swarm.NewAddress(h.Sum(nil)), nil
+}
+
+// Valid returns true if the chunk is a single-owner chunk with a valid
+// signature and the address derived from its identifier and owner.
+func Valid(ch swarm.Chunk) bool {
+	s, err := FromChunk(ch)
+	if err != nil {
+		return false
+	}
+	address, err := s.Address()
+	if err != nil {
+		return false
+	}
+	return ch.Address().Equal(address)
+}
+
+// toSignDigest returns the digest signed by the owner, binding the content
+// addressed chunk to the identifier.
+func toSignDigest(id Id, address swarm.Address) ([]byte, error) {
+	h := sha3.NewLegacyKeccak256()
+	if _, err := h.Write(id); err != nil {
+		return nil, err
+	}
+	if _, err := h.Write(address.Bytes()); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
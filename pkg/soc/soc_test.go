@@ -0,0 +1,140 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soc_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/content"
+	"github.com/ethersphere/bee/pkg/crypto"
+	"github.com/ethersphere/bee/pkg/soc"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// TestToChunk verifies that the chunk created from a single-owner chunk is
+// valid and that it can be parsed back with the owner recovered.
+func TestToChunk(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+	ownerAddress, err := crypto.NewEthereumAddress(privKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := make([]byte, soc.IdSize)
+	id[0] = 1
+	cac, err := content.NewChunk([]byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := soc.New(id, cac, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := s.ToChunk()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner, err := soc.NewOwner(ownerAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := soc.CreateAddress(id, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ch.Address().Equal(address) {
+		t.Fatalf("got address %s, want %s", ch.Address(), address)
+	}
+	if !soc.Valid(ch) {
+		t.Fatal("chunk not valid")
+	}
+
+	parsed, err := soc.FromChunk(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(parsed.Id(), id) {
+		t.Fatalf("got id %x, want %x", parsed.Id(), id)
+	}
+	if !bytes.Equal(parsed.Owner().Bytes(), ownerAddress) {
+		t.Fatalf("got owner %x, want %x", parsed.Owner().Bytes(), ownerAddress)
+	}
+	if !parsed.WrappedChunk().Address().Equal(cac.Address()) {
+		t.Fatalf("got wrapped chunk address %s, want %s", parsed.WrappedChunk().Address(), cac.Address())
+	}
+	if !bytes.Equal(parsed.WrappedChunk().Data(), cac.Data()) {
+		t.Fatalf("got wrapped chunk data %x, want %x", parsed.WrappedChunk().Data(), cac.Data())
+	}
+}
+
+// TestValid verifies that chunks with tampered data or address are not valid
+// single-owner chunks.
+func TestValid(t *testing.T) {
+	privKey, err := crypto.GenerateSecp256k1Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.NewDefaultSigner(privKey)
+
+	id := make([]byte, soc.IdSize)
+	cac, err := content.NewChunk([]byte("foo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := soc.New(id, cac, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := s.ToChunk()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator := soc.NewValidator()
+	if !validator.Validate(ch) {
+		t.Fatal("chunk not valid")
+	}
+
+	t.Run("tampered payload", func(t *testing.T) {
+		data := append([]byte(nil), ch.Data()...)
+		data[len(data)-1]++
+		if validator.Validate(swarm.NewChunk(ch.Address(), data)) {
+			t.Fatal("chunk with tampered payload is valid")
+		}
+	})
+
+	t.Run("tampered id", func(t *testing.T) {
+		data := append([]byte(nil), ch.Data()...)
+		data[0]++
+		if validator.Validate(swarm.NewChunk(ch.Address(), data)) {
+			t.Fatal("chunk with tampered id is valid")
+		}
+	})
+
+	t.Run("wrong address", func(t *testing.T) {
+		if validator.Validate(swarm.NewChunk(cac.Address(), ch.Data())) {
+			t.Fatal("chunk with wrong address is valid")
+		}
+	})
+
+	t.Run("short data", func(t *testing.T) {
+		if validator.Validate(swarm.NewChunk(ch.Address(), ch.Data()[:soc.IdSize+soc.SignatureSize])) {
+			t.Fatal("chunk with short data is valid")
+		}
+	})
+
+	t.Run("content addressed chunk", func(t *testing.T) {
+		if validator.Validate(cac) {
+			t.Fatal("content addressed chunk is a valid single-owner chunk")
+		}
+	})
+}
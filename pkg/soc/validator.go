@@ -0,0 +1,26 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package soc
+
+import (
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+var _ swarm.ChunkValidator = (*Validator)(nil)
+
+// Validator validates that the address of a given chunk is the single-owner
+// chunk address of its identifier and the owner that signed it.
+type Validator struct {
+}
+
+// NewValidator constructs a new Validator.
+func NewValidator() swarm.ChunkValidator {
+	return &Validator{}
+}
+
+// Validate performs the validation check.
+func (v *Validator) Validate(ch swarm.Chunk) (valid bool) {
+	return Valid(ch)
+}
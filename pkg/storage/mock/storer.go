@@ -18,6 +18,7 @@ var _ storage.Storer = (*MockStorer)(nil)
 
 type MockStorer struct {
 	store           map[string][]byte
+	modePut         map[string]storage.ModePut
 	modeSet         map[string]storage.ModeSet
 	modeSetMu       sync.Mutex
 	pinnedAddress   []swarm.Address // Stores the pinned address
@@ -50,6 +51,7 @@ func WithPartialInterval(v bool) Option {
 func NewStorer(opts ...Option) *MockStorer {
 	s := &MockStorer{
 		store:     make(map[string][]byte),
+		modePut:   make(map[string]storage.ModePut),
 		modeSet:   make(map[string]storage.ModeSet),
 		modeSetMu: sync.Mutex{},
 		morePull:  make(chan struct{}),
@@ -66,6 +68,7 @@ func NewStorer(opts ...Option) *MockStorer {
 func NewValidatingStorer(v swarm.ChunkValidator, tags *tags.Tags) *MockStorer {
 	return &MockStorer{
 		store:     make(map[string][]byte),
+		modePut:   make(map[string]storage.ModePut),
 		modeSet:   make(map[string]storage.ModeSet),
 		modeSetMu: sync.Mutex{},
 		pinSetMu:  sync.Mutex{},
@@ -96,6 +99,7 @@ func (m *MockStorer) Put(ctx context.Context, mode storage.ModePut, chs ...swarm
 			}
 		}
 		m.store[ch.Address().String()] = ch.Data()
+		m.modePut[ch.Address().String()] = mode
 		yes, err := m.has(ctx, ch.Address())
 		if err != nil {
 			exist = append(exist, false)
@@ -175,6 +179,15 @@ func (m *MockStorer) Set(ctx context.Context, mode storage.ModeSet, addrs ...swa
 	return nil
 }
 
+func (m *MockStorer) GetModePut(addr swarm.Address) (mode storage.ModePut) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if mode, ok := m.modePut[addr.String()]; ok {
+		return mode
+	}
+	return mode
+}
+
 func (m *MockStorer) GetModeSet(addr swarm.Address) (mode storage.ModeSet) {
 	m.modeSetMu.Lock()
 	defer m.modeSetMu.Unlock()
@@ -16,6 +16,9 @@ import (
 var (
 	ErrNotFound     = errors.New("storage: not found")
 	ErrInvalidChunk = errors.New("storage: invalid chunk")
+	// ErrLowDiskSpace is returned when the chunks are not stored
+	// as the free disk space is below the configured minimum.
+	ErrLowDiskSpace = errors.New("storage: low disk space")
 )
 
 // ModeGet enumerates different Getter modes.
@@ -59,6 +62,8 @@ func (m ModePut) String() string {
 		return "Sync"
 	case ModePutUpload:
 		return "Upload"
+	case ModePutUploadDirect:
+		return "UploadDirect"
 	default:
 		return "Unknown"
 	}
@@ -72,6 +77,9 @@ const (
 	ModePutSync
 	// ModePutUpload: when a chunk is created by local upload
 	ModePutUpload
+	// ModePutUploadDirect: when a chunk is created by local upload and
+	// pushed to the network by the uploader, so it is not synced by the pusher
+	ModePutUploadDirect
 )
 
 // ModeSet enumerates different Setter modes.
@@ -7,6 +7,7 @@ package swarm
 
 import (
 	"bytes"
+	"encoding"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -100,14 +101,28 @@ type Chunk interface {
 	WithPinCounter(p uint64) Chunk
 	TagID() uint32
 	WithTagID(t uint32) Chunk
+	Stamp() Stamp
+	WithStamp(Stamp) Chunk
 	Equal(Chunk) bool
 }
 
+// Stamp is the interface of the postage stamp attached to a chunk. It is
+// defined here to avoid the dependency of this package on the postage
+// package.
+type Stamp interface {
+	BatchID() []byte
+	Index() []byte
+	Sig() []byte
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
 type chunk struct {
 	addr       Address
 	sdata      []byte
 	pinCounter uint64
 	tagID      uint32
+	stamp      Stamp
 }
 
 func NewChunk(addr Address, data []byte) Chunk {
@@ -127,6 +142,11 @@ func (c *chunk) WithTagID(t uint32) Chunk {
 	return c
 }
 
+func (c *chunk) WithStamp(stamp Stamp) Chunk {
+	c.stamp = stamp
+	return c
+}
+
 func (c *chunk) Address() Address {
 	return c.addr
 }
@@ -143,6 +163,10 @@ func (c *chunk) TagID() uint32 {
 	return c.tagID
 }
 
+func (c *chunk) Stamp() Stamp {
+	return c.stamp
+}
+
 func (c *chunk) String() string {
 	return fmt.Sprintf("Address: %v Chunksize: %v", c.addr.String(), len(c.sdata))
 }
@@ -59,9 +59,17 @@ type Tag struct {
 	Uid       uint32        // a unique identifier for this tag
 	Anonymous bool          // indicates if the tag is anonymous (i.e. if only pull sync should be used)
 	Name      string        // a name tag for this tag
-	Address   swarm.Address // the associated swarm hash for this tag
+	Address   swarm.Address // the associated swarm hash for this tag, guarded by addressMu
 	StartedAt time.Time     // tag started to calculate ETA
 
+	addressMu sync.RWMutex // guards Address
+
+	completedAt time.Time // time the tag was found completed, used for its expiry
+
+	subsMu    sync.Mutex                 // guards subs
+	subs      map[chan struct{}]struct{} // signalled on the counter changes
+	subsCount int32                      // number of subs, read without the lock
+
 	// end-to-end tag tracing
 	ctx      context.Context  // tracing context
 	span     opentracing.Span // tracing root span
@@ -78,10 +86,15 @@ func NewTag(ctx context.Context, uid uint32, s string, total int64, anon bool, t
 		Total:     total,
 	}
 
+	t.startSpan(ctx, tracer)
+	return t
+}
+
+// startSpan starts the root tracing span of the tag
+func (t *Tag) startSpan(ctx context.Context, tracer *tracing.Tracer) {
 	// context here is used only to store the root span `new.upload.tag` within Tag,
 	// we don't need any type of ctx Deadline or cancellation for this particular ctx
 	t.span, _, t.ctx = tracer.StartSpanFromContext(ctx, "new.upload.tag", nil)
-	return t
 }
 
 // Context accessor
@@ -114,6 +127,52 @@ func (t *Tag) IncN(state State, n int) {
 		v = &t.Synced
 	}
 	atomic.AddInt64(v, int64(n))
+	t.notify()
+}
+
+// Subscribe returns a channel that is signalled when any of the counters of
+// the tag changes, and a function to stop the subscription. The signals are
+// not queued, a receiver that falls behind gets a single signal for all the
+// changes it missed.
+func (t *Tag) Subscribe() (c <-chan struct{}, unsubscribe func()) {
+	ch := make(chan struct{}, 1)
+
+	t.subsMu.Lock()
+	if t.subs == nil {
+		t.subs = make(map[chan struct{}]struct{})
+	}
+	t.subs[ch] = struct{}{}
+	atomic.StoreInt32(&t.subsCount, int32(len(t.subs)))
+	t.subsMu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			t.subsMu.Lock()
+			delete(t.subs, ch)
+			atomic.StoreInt32(&t.subsCount, int32(len(t.subs)))
+			t.subsMu.Unlock()
+		})
+	}
+}
+
+// notify signals the subscriptions without blocking. The lock is not taken
+// when there are no subscriptions, as the counters of the tags are changed
+// for every chunk of the uploads.
+func (t *Tag) notify() {
+	if atomic.LoadInt32(&t.subsCount) == 0 {
+		return
+	}
+
+	t.subsMu.Lock()
+	defer t.subsMu.Unlock()
+
+	for ch := range t.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
 }
 
 // Inc increments the count for a state
@@ -172,15 +231,41 @@ func (t *Tag) Done(s State) bool {
 	return err == nil && n == total
 }
 
+// completed returns true if the chunks of the tag reached the network: synced
+// with proof or, for the anonymous tags, sent
+func (t *Tag) completed() bool {
+	if t.Anonymous {
+		return t.Done(StateSent)
+	}
+	return t.Done(StateSynced)
+}
+
 // DoneSplit sets total count to SPLIT count and sets the associated swarm hash for this tag
 // is meant to be called when splitter finishes for input streams of unknown size
 func (t *Tag) DoneSplit(address swarm.Address) int64 {
 	total := atomic.LoadInt64(&t.Split)
 	atomic.StoreInt64(&t.Total, total)
-	t.Address = address
+	t.SetAddress(address)
+	t.notify()
 	return total
 }
 
+// SetAddress sets the associated swarm hash for this tag
+func (t *Tag) SetAddress(address swarm.Address) {
+	t.addressMu.Lock()
+	defer t.addressMu.Unlock()
+
+	t.Address = address
+}
+
+// GetAddress returns the associated swarm hash for this tag
+func (t *Tag) GetAddress() swarm.Address {
+	t.addressMu.RLock()
+	defer t.addressMu.RUnlock()
+
+	return t.Address
+}
+
 // Status returns the value of state and the total count
 func (t *Tag) Status(state State) (int64, int64, error) {
 	count, seen, total := t.Get(state), atomic.LoadInt64(&t.Seen), atomic.LoadInt64(&t.Total)
@@ -218,21 +303,34 @@ func (t *Tag) ETA(state State) (time.Time, error) {
 func (tag *Tag) MarshalBinary() (data []byte, err error) {
 	buffer := make([]byte, 4)
 	binary.BigEndian.PutUint32(buffer, tag.Uid)
-	encodeInt64Append(&buffer, tag.Total)
-	encodeInt64Append(&buffer, tag.Split)
-	encodeInt64Append(&buffer, tag.Seen)
-	encodeInt64Append(&buffer, tag.Stored)
-	encodeInt64Append(&buffer, tag.Sent)
-	encodeInt64Append(&buffer, tag.Synced)
+	encodeInt64Append(&buffer, tag.Get(TotalChunks))
+	encodeInt64Append(&buffer, tag.Get(StateSplit))
+	encodeInt64Append(&buffer, tag.Get(StateSeen))
+	encodeInt64Append(&buffer, tag.Get(StateStored))
+	encodeInt64Append(&buffer, tag.Get(StateSent))
+	encodeInt64Append(&buffer, tag.Get(StateSynced))
 
-	intBuffer := make([]byte, 8)
+	intBuffer := make([]byte, binary.MaxVarintLen64)
 
 	n := binary.PutVarint(intBuffer, tag.StartedAt.Unix())
 	buffer = append(buffer, intBuffer[:n]...)
 
-	n = binary.PutVarint(intBuffer, int64(len(tag.Address.Bytes())))
+	var completedAt int64
+	if !tag.completedAt.IsZero() {
+		completedAt = tag.completedAt.Unix()
+	}
+	encodeInt64Append(&buffer, completedAt)
+
+	anonymous := byte(0)
+	if tag.Anonymous {
+		anonymous = 1
+	}
+	buffer = append(buffer, anonymous)
+
+	address := tag.GetAddress()
+	n = binary.PutVarint(intBuffer, int64(len(address.Bytes())))
 	buffer = append(buffer, intBuffer[:n]...)
-	buffer = append(buffer, tag.Address.Bytes()...)
+	buffer = append(buffer, address.Bytes()...)
 	buffer = append(buffer, []byte(tag.Name)...)
 
 	return buffer, nil
@@ -240,7 +338,7 @@ func (tag *Tag) MarshalBinary() (data []byte, err error) {
 
 // UnmarshalBinary unmarshals a byte slice into a tag
 func (tag *Tag) UnmarshalBinary(buffer []byte) error {
-	if len(buffer) < 13 {
+	if len(buffer) < 14 {
 		return errors.New("buffer too short")
 	}
 	tag.Uid = binary.BigEndian.Uint32(buffer)
@@ -257,6 +355,13 @@ func (tag *Tag) UnmarshalBinary(buffer []byte) error {
 	tag.StartedAt = time.Unix(t, 0)
 	buffer = buffer[n:]
 
+	if completedAt := decodeInt64Splice(&buffer); completedAt != 0 {
+		tag.completedAt = time.Unix(completedAt, 0)
+	}
+
+	tag.Anonymous = buffer[0] == 1
+	buffer = buffer[1:]
+
 	t, n = binary.Varint(buffer)
 	buffer = buffer[n:]
 	if t > 0 {
@@ -268,7 +373,7 @@ func (tag *Tag) UnmarshalBinary(buffer []byte) error {
 }
 
 func encodeInt64Append(buffer *[]byte, val int64) {
-	intBuffer := make([]byte, 8)
+	intBuffer := make([]byte, binary.MaxVarintLen64)
 	n := binary.PutVarint(intBuffer, val)
 	*buffer = append(*buffer, intBuffer[:n]...)
 }
@@ -275,3 +275,34 @@ func TestMarshallingNoAddr(t *testing.T) {
 		t.Fatalf("expected tag addresses to be equal length")
 	}
 }
+
+// TestTagSubscribe tests that the subscriptions are signalled on the counter
+// changes until they are stopped.
+func TestTagSubscribe(t *testing.T) {
+	tg := &Tag{Total: 10}
+
+	c, unsubscribe := tg.Subscribe()
+
+	// the signals are not queued
+	tg.Inc(StateStored)
+	tg.Inc(StateSynced)
+
+	select {
+	case <-c:
+	case <-time.After(time.Second):
+		t.Fatal("no signal")
+	}
+	select {
+	case <-c:
+		t.Fatal("unexpected signal")
+	default:
+	}
+
+	unsubscribe()
+	tg.Inc(StateStored)
+	select {
+	case <-c:
+		t.Fatal("signal after unsubscribe")
+	default:
+	}
+}
@@ -22,14 +22,24 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/ethersphere/bee/pkg/logging"
 	"github.com/ethersphere/bee/pkg/sctx"
+	"github.com/ethersphere/bee/pkg/storage"
 	"github.com/ethersphere/bee/pkg/swarm"
 )
 
+const (
+	// tagKeyPrefix is the state store key prefix of the persisted tags.
+	tagKeyPrefix = "tags_"
+	// defaultFlushInterval is used when the flush interval is not set.
+	defaultFlushInterval = 10 * time.Second
+)
+
 var (
 	TagUidFunc  = rand.Uint32
 	ErrNotFound = errors.New("tag not found")
@@ -37,16 +47,60 @@ var (
 
 // Tags hold tag information indexed by a unique random uint32
 type Tags struct {
-	tags *sync.Map
+	tags       *sync.Map
+	stateStore storage.StateStorer
+	logger     logging.Logger
+	retention  time.Duration
+	quit       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// Options for the tags persisted in the state store.
+type Options struct {
+	StateStore storage.StateStorer
+	Logger     logging.Logger
+	// FlushInterval is the period of writing the tag counters to the state
+	// store, ten seconds if not set.
+	FlushInterval time.Duration
+	// Retention is the time the completed tags are kept for. Zero keeps the
+	// completed tags forever.
+	Retention time.Duration
 }
 
-// NewTags creates a tags object
+// NewTags creates a tags object that keeps the tags only in memory
 func NewTags() *Tags {
 	return &Tags{
 		tags: &sync.Map{},
 	}
 }
 
+// New creates a tags object with the tags loaded from the state store. The
+// tags are written to the state store periodically and on Close, so that the
+// upload progress is kept across node restarts.
+func New(o Options) (*Tags, error) {
+	ts := &Tags{
+		tags:       &sync.Map{},
+		stateStore: o.StateStore,
+		logger:     o.Logger,
+		retention:  o.Retention,
+		quit:       make(chan struct{}),
+	}
+
+	if err := ts.load(); err != nil {
+		return nil, fmt.Errorf("load tags: %w", err)
+	}
+
+	flushInterval := o.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+
+	ts.wg.Add(1)
+	go ts.flushLoop(flushInterval)
+
+	return ts, nil
+}
+
 // Create creates a new tag, stores it by the name and returns it
 // it returns an error if the tag with this name already exists
 func (ts *Tags) Create(s string, total int64, anon bool) (*Tag, error) {
@@ -56,6 +110,15 @@ func (ts *Tags) Create(s string, total int64, anon bool) (*Tag, error) {
 		return nil, errExists
 	}
 
+	// persist the tag right away so that the chunks referencing it in the
+	// push index are not left without the tag after a crash
+	if ts.stateStore != nil {
+		if err := ts.stateStore.Put(tagKey(t.Uid), t); err != nil {
+			ts.tags.Delete(t.Uid)
+			return nil, fmt.Errorf("persist tag: %w", err)
+		}
+	}
+
 	return t, nil
 }
 
@@ -71,6 +134,24 @@ func (ts *Tags) All() (t []*Tag) {
 	return t
 }
 
+// ListAll returns the tags ordered by their uids, skipping the offset number
+// of tags and returning at most the limit number of tags
+func (ts *Tags) ListAll(offset, limit int) []*Tag {
+	all := ts.All()
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Uid < all[j].Uid
+	})
+
+	if offset >= len(all) {
+		return nil
+	}
+	all = all[offset:]
+	if limit < len(all) {
+		all = all[:limit]
+	}
+	return all
+}
+
 // Get returns the underlying tag for the uid or an error if not found
 func (ts *Tags) Get(uid uint32) (*Tag, error) {
 	t, ok := ts.tags.Load(uid)
@@ -86,7 +167,7 @@ func (ts *Tags) GetByAddress(address swarm.Address) (*Tag, error) {
 	var lastTime time.Time
 	ts.tags.Range(func(key interface{}, value interface{}) bool {
 		rcvdTag := value.(*Tag)
-		if rcvdTag.Address.Equal(address) && rcvdTag.StartedAt.After(lastTime) {
+		if rcvdTag.GetAddress().Equal(address) && rcvdTag.StartedAt.After(lastTime) {
 			t = rcvdTag
 			lastTime = rcvdTag.StartedAt
 		}
@@ -114,8 +195,93 @@ func (ts *Tags) Range(fn func(k, v interface{}) bool) {
 	ts.tags.Range(fn)
 }
 
+// Delete removes the tag with the uid, also from the state store
 func (ts *Tags) Delete(k interface{}) {
 	ts.tags.Delete(k)
+
+	uid, ok := k.(uint32)
+	if !ok || ts.stateStore == nil {
+		return
+	}
+	if err := ts.stateStore.Delete(tagKey(uid)); err != nil {
+		ts.logger.Errorf("tags: delete tag %d: %v", uid, err)
+	}
+}
+
+// Close stops the periodic flushing and writes the tags to the state store.
+func (ts *Tags) Close() error {
+	if ts.quit == nil {
+		return nil
+	}
+	close(ts.quit)
+	ts.wg.Wait()
+
+	return ts.flush()
+}
+
+// load reads the persisted tags from the state store.
+func (ts *Tags) load() error {
+	return ts.stateStore.Iterate(tagKeyPrefix, func(key, value []byte) (stop bool, err error) {
+		t := new(Tag)
+		if err := t.UnmarshalBinary(value); err != nil {
+			return true, fmt.Errorf("unmarshal tag %s: %w", string(key), err)
+		}
+		t.startSpan(context.Background(), nil)
+
+		ts.tags.Store(t.Uid, t)
+		return false, nil
+	})
+}
+
+func (ts *Tags) flushLoop(interval time.Duration) {
+	defer ts.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := ts.flush(); err != nil {
+				ts.logger.Errorf("tags: flush: %v", err)
+			}
+		case <-ts.quit:
+			return
+		}
+	}
+}
+
+// flush writes the tags to the state store and removes the completed tags
+// that are older than the retention. The completed tags are written only
+// once, as their counters do not change anymore.
+func (ts *Tags) flush() (err error) {
+	now := time.Now()
+	ts.tags.Range(func(k, v interface{}) bool {
+		t := v.(*Tag)
+
+		if !t.completedAt.IsZero() {
+			if ts.retention > 0 && now.Sub(t.completedAt) > ts.retention {
+				ts.tags.Delete(k)
+				if err = ts.stateStore.Delete(tagKey(t.Uid)); err != nil {
+					return false
+				}
+			}
+			return true
+		}
+
+		if t.completed() {
+			t.completedAt = now
+		}
+		if err = ts.stateStore.Put(tagKey(t.Uid), t); err != nil {
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+func tagKey(uid uint32) string {
+	return tagKeyPrefix + strconv.FormatUint(uint64(uid), 10)
 }
 
 func (ts *Tags) MarshalJSON() (out []byte, err error) {
@@ -144,11 +310,6 @@ func (ts *Tags) UnmarshalJSON(value []byte) error {
 		if err != nil {
 			return err
 		}
-
-		// prevent a condition where a chunk was sent before shutdown
-		// and the node was turned off before the receipt was received
-		v.Sent = v.Synced
-
 		ts.tags.Store(key, v)
 	}
 
@@ -17,7 +17,16 @@
 package tags
 
 import (
+	"errors"
+	"io/ioutil"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/logging"
+	statestore "github.com/ethersphere/bee/pkg/statestore/mock"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
 )
 
 func TestAll(t *testing.T) {
@@ -52,3 +61,138 @@ func TestAll(t *testing.T) {
 		t.Fatalf("expected length to be 3 got %d", len(all))
 	}
 }
+
+func TestPersistence(t *testing.T) {
+	store := statestore.NewStateStore()
+	logger := logging.New(ioutil.Discard, 0)
+
+	ts, err := New(Options{StateStore: store, Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := ts.Create("persisted", 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag.SetAddress(swarm.MustParseHexAddress("0001"))
+	tag.IncN(StateStored, 3)
+	tag.IncN(StateSent, 2)
+	tag.Inc(StateSynced)
+	if err := ts.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ts, err = New(Options{StateStore: store, Logger: logger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	got, err := ts.Get(tag.Uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != tag.Name {
+		t.Fatalf("got name %q, want %q", got.Name, tag.Name)
+	}
+	if !got.Address.Equal(tag.Address) {
+		t.Fatalf("got address %s, want %s", got.Address, tag.Address)
+	}
+	if got.StartedAt.Unix() != tag.StartedAt.Unix() {
+		t.Fatalf("got started at %v, want %v", got.StartedAt, tag.StartedAt)
+	}
+	for state, want := range map[State]int64{
+		TotalChunks: 3,
+		StateStored: 3,
+		StateSent:   2,
+		StateSynced: 1,
+	} {
+		if n := got.Get(state); n != want {
+			t.Fatalf("got count %d for state %d, want %d", n, state, want)
+		}
+	}
+
+	// the progress continues on the loaded tag
+	got.IncN(StateSynced, 2)
+	if !got.Done(StateSynced) {
+		t.Fatal("tag not synced")
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	store := statestore.NewStateStore()
+	logger := logging.New(ioutil.Discard, 0)
+
+	ts, err := New(Options{StateStore: store, Logger: logger, Retention: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ts.Close()
+
+	done, err := ts.Create("done", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done.Inc(StateStored)
+	done.Inc(StateSynced)
+	pending, err := ts.Create("pending", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ts.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if done.completedAt.IsZero() {
+		t.Fatal("completed tag not marked")
+	}
+	if !pending.completedAt.IsZero() {
+		t.Fatal("pending tag marked completed")
+	}
+
+	// the completed tag is kept until the retention passes
+	done.completedAt = time.Now().Add(-2 * time.Hour)
+	if err := ts.flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ts.Get(done.Uid); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Get(tagKey(done.Uid), new(Tag)); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+	}
+	if _, err := ts.Get(pending.Uid); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListAll(t *testing.T) {
+	ts := NewTags()
+	for i := 0; i < 5; i++ {
+		if _, err := ts.Create(strconv.Itoa(i), 1, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all := ts.ListAll(0, 10)
+	if len(all) != 5 {
+		t.Fatalf("got %d tags, want 5", len(all))
+	}
+	for i := 1; i < len(all); i++ {
+		if all[i-1].Uid >= all[i].Uid {
+			t.Fatal("tags not ordered by uid")
+		}
+	}
+
+	page := ts.ListAll(3, 10)
+	if len(page) != 2 || page[0].Uid != all[3].Uid {
+		t.Fatalf("got page %v, want the last two tags", page)
+	}
+	if page := ts.ListAll(1, 2); len(page) != 2 || page[1].Uid != all[2].Uid {
+		t.Fatalf("got page %v, want the second and third tag", page)
+	}
+	if page := ts.ListAll(5, 10); len(page) != 0 {
+		t.Fatalf("got %d tags past the end, want none", len(page))
+	}
+}
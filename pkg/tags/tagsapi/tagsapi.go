@@ -0,0 +1,337 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tagsapi provides the HTTP handlers of the upload tags, which are
+// mounted by both the API and the debug API routers under the /tags path,
+// with the {uid} path variable for the single tag endpoints.
+package tagsapi
+
+import (
+	crand "crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethersphere/bee/pkg/jsonhttp"
+	"github.com/ethersphere/bee/pkg/logging"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/tags"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultTagsLimit is the number of the listed tags when the limit is
+	// not given.
+	defaultTagsLimit = 100
+	// maxTagsLimit is the maximal number of the listed tags.
+	maxTagsLimit = 1000
+	// streamInterval is the minimal time between the tag progress events.
+	streamInterval = 100 * time.Millisecond
+)
+
+// Response is the tag in the responses of the handlers.
+type Response struct {
+	Total     int64         `json:"total"`
+	Split     int64         `json:"split"`
+	Seen      int64         `json:"seen"`
+	Stored    int64         `json:"stored"`
+	Sent      int64         `json:"sent"`
+	Synced    int64         `json:"synced"`
+	Uid       uint32        `json:"uid"`
+	Anonymous bool          `json:"anonymous"`
+	Name      string        `json:"name"`
+	Address   swarm.Address `json:"address"`
+	StartedAt time.Time     `json:"startedAt"`
+}
+
+// ListResponse is the response of the List handler.
+type ListResponse struct {
+	Tags []Response `json:"tags"`
+}
+
+// Request is the body of the Patch handler request.
+type Request struct {
+	Address swarm.Address `json:"address"`
+}
+
+// progressEvent is the data of the tag stream events, with the estimated
+// time of the completion when it can be calculated.
+type progressEvent struct {
+	Response
+	ETA *time.Time `json:"eta,omitempty"`
+}
+
+// NewResponse returns the response of the tag.
+func NewResponse(tag *tags.Tag) Response {
+	return Response{
+		Total:     tag.Get(tags.TotalChunks),
+		Split:     tag.Get(tags.StateSplit),
+		Seen:      tag.Get(tags.StateSeen),
+		Stored:    tag.Get(tags.StateStored),
+		Sent:      tag.Get(tags.StateSent),
+		Synced:    tag.Get(tags.StateSynced),
+		Uid:       tag.Uid,
+		Anonymous: tag.Anonymous,
+		Name:      tag.Name,
+		Address:   tag.GetAddress(),
+		StartedAt: tag.StartedAt,
+	}
+}
+
+func newProgressEvent(tag *tags.Tag) progressEvent {
+	e := progressEvent{
+		Response: NewResponse(tag),
+	}
+	if eta, err := tag.ETA(completionState(tag)); err == nil {
+		e.ETA = &eta
+	}
+	return e
+}
+
+// completionState returns the state in which all the chunks of the tag have
+// reached the network. Anonymous tags are not push synced, so they are done
+// once their chunks are sent.
+func completionState(tag *tags.Tag) tags.State {
+	if tag.Anonymous {
+		return tags.StateSent
+	}
+	return tags.StateSynced
+}
+
+// Handler serves the tag endpoints.
+type Handler struct {
+	tags   *tags.Tags
+	logger logging.Logger
+	quit   <-chan struct{} // ends the running event streams
+}
+
+// New creates the handlers of the tags. The running event streams are ended
+// when the quit channel is closed.
+func New(t *tags.Tags, logger logging.Logger, quit <-chan struct{}) *Handler {
+	return &Handler{
+		tags:   t,
+		logger: logger,
+		quit:   quit,
+	}
+}
+
+// Create creates a new tag with the name from the query, or a random name if
+// it is not given.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		b := make([]byte, 4)
+		_, err := crand.Read(b)
+		if err != nil {
+			h.logger.Debugf("create tag: read random bytes %v", err)
+			h.logger.Error("create tag: read random bytes error")
+			jsonhttp.InternalServerError(w, nil)
+			return
+		}
+		name = fmt.Sprintf("tag-%v-%x", time.Now().UnixNano(), b)
+	}
+
+	tag, err := h.tags.Create(name, 0, false)
+	if err != nil {
+		h.logger.Debugf("create tag: %s %v", name, err)
+		h.logger.Errorf("create tag: %s error", name)
+		jsonhttp.InternalServerError(w, "cannot create tag")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache, private, max-age=0")
+	jsonhttp.OK(w, NewResponse(tag))
+}
+
+// List returns the page of the tags given by the offset and limit query
+// parameters.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	var (
+		offset = 0
+		limit  = defaultTagsLimit
+		err    error
+	)
+
+	if v := r.URL.Query().Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			h.logger.Debugf("list tags: parse offset %s: %v", v, err)
+			h.logger.Error("list tags: parse offset")
+			jsonhttp.BadRequest(w, "invalid offset")
+			return
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxTagsLimit {
+			h.logger.Debugf("list tags: parse limit %s: %v", v, err)
+			h.logger.Error("list tags: parse limit")
+			jsonhttp.BadRequest(w, "invalid limit")
+			return
+		}
+	}
+
+	list := h.tags.ListAll(offset, limit)
+	resp := ListResponse{
+		Tags: make([]Response, 0, len(list)),
+	}
+	for _, t := range list {
+		resp.Tags = append(resp.Tags, NewResponse(t))
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, private, max-age=0")
+	jsonhttp.OK(w, resp)
+}
+
+// Get returns the tag.
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	tag, ok := h.tagFromRequest(w, r, "get tag")
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache, private, max-age=0")
+	jsonhttp.OK(w, NewResponse(tag))
+}
+
+// Patch sets the address of the tag.
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) {
+	tag, ok := h.tagFromRequest(w, r, "patch tag")
+	if !ok {
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.logger.Debugf("patch tag: read request body: %v", err)
+		h.logger.Error("patch tag: read request body")
+		jsonhttp.InternalServerError(w, "cannot read request")
+		return
+	}
+	var req Request
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.logger.Debugf("patch tag: unmarshal tag request: %v", err)
+		h.logger.Error("patch tag: unmarshal tag request")
+		jsonhttp.BadRequest(w, "invalid request")
+		return
+	}
+	if req.Address.IsZero() {
+		h.logger.Debug("patch tag: empty address")
+		h.logger.Error("patch tag: empty address")
+		jsonhttp.BadRequest(w, "invalid address")
+		return
+	}
+
+	tag.SetAddress(req.Address)
+
+	w.Header().Set("Cache-Control", "no-cache, private, max-age=0")
+	jsonhttp.OK(w, NewResponse(tag))
+}
+
+// Delete removes the tag.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	tag, ok := h.tagFromRequest(w, r, "delete tag")
+	if !ok {
+		return
+	}
+
+	h.tags.Delete(tag.Uid)
+	jsonhttp.OK(w, nil)
+}
+
+// Stream sends the progress of the tag as server-sent events, whenever the
+// counters of the tag change. The stream ends with the done event when all
+// the chunks of the tag have reached the network.
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	tag, ok := h.tagFromRequest(w, r, "stream tag")
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.logger.Error("stream tag: streaming not supported")
+		jsonhttp.InternalServerError(w, "streaming not supported")
+		return
+	}
+
+	changes, unsubscribe := tag.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	ctx := r.Context()
+	for {
+		event := "progress"
+		if tag.Done(completionState(tag)) {
+			event = "done"
+		}
+		data, err := json.Marshal(newProgressEvent(tag))
+		if err != nil {
+			h.logger.Debugf("stream tag: marshal event: %v", err)
+			h.logger.Error("stream tag: marshal event")
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			h.logger.Debugf("stream tag: write event: %v", err)
+			return
+		}
+		flusher.Flush()
+
+		if event == "done" {
+			return
+		}
+
+		// limit the rate of the events, the changes in between are sent
+		// with the next event
+		select {
+		case <-time.After(streamInterval):
+		case <-ctx.Done():
+			return
+		case <-h.quit:
+			return
+		}
+		select {
+		case <-changes:
+		case <-ctx.Done():
+			return
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+// tagFromRequest returns the tag with the uid from the request path. If the
+// tag cannot be returned, the error response is written.
+func (h *Handler) tagFromRequest(w http.ResponseWriter, r *http.Request, op string) (*tags.Tag, bool) {
+	uidStr := mux.Vars(r)["uid"]
+
+	uid, err := strconv.ParseUint(uidStr, 10, 32)
+	if err != nil {
+		h.logger.Debugf("%s: parse uid %s: %v", op, uidStr, err)
+		h.logger.Errorf("%s: parse uid", op)
+		jsonhttp.BadRequest(w, "invalid uid")
+		return nil, false
+	}
+
+	tag, err := h.tags.Get(uint32(uid))
+	if err != nil {
+		if errors.Is(err, tags.ErrNotFound) {
+			h.logger.Debugf("%s: tag %v: %v", op, uid, err)
+			h.logger.Errorf("%s: tag %v not present", op, uid)
+			jsonhttp.NotFound(w, "tag not present")
+			return nil, false
+		}
+		h.logger.Debugf("%s: tag %v: %v", op, uid, err)
+		h.logger.Errorf("%s: tag %v", op, uid)
+		jsonhttp.InternalServerError(w, nil)
+		return nil, false
+	}
+	return tag, true
+}
@@ -18,6 +18,7 @@ type mock struct {
 	closestPeerErr  error
 	addPeerErr      error
 	marshalJSONFunc func() ([]byte, error)
+	depth           uint8
 	mtx             sync.Mutex
 }
 
@@ -39,6 +40,12 @@ func WithClosestPeerErr(err error) Option {
 	})
 }
 
+func WithNeighborhoodDepth(depth uint8) Option {
+	return optionFunc(func(d *mock) {
+		d.depth = depth
+	})
+}
+
 func WithMarshalJSONFunc(f func() ([]byte, error)) Option {
 	return optionFunc(func(d *mock) {
 		d.marshalJSONFunc = f
@@ -83,8 +90,8 @@ func (d *mock) SubscribePeersChange() (c <-chan struct{}, unsubscribe func()) {
 	return c, unsubscribe
 }
 
-func (_ *mock) NeighborhoodDepth() uint8 {
-	return 0
+func (d *mock) NeighborhoodDepth() uint8 {
+	return d.depth
 }
 
 // EachPeer iterates from closest bin to farthest
@@ -22,7 +22,7 @@ type Driver interface {
 	ClosestPeerer
 	EachPeerer
 	Notifier
-	NeighborhoodDepth() uint8
+	NeighborhoodDepther
 	SubscribePeersChange() (c <-chan struct{}, unsubscribe func())
 	io.Closer
 }
@@ -52,6 +52,12 @@ type ClosestPeerer interface {
 	ClosestPeer(addr swarm.Address) (peerAddr swarm.Address, err error)
 }
 
+type NeighborhoodDepther interface {
+	// NeighborhoodDepth returns the proximity order from which the peers
+	// are in the neighborhood of the node.
+	NeighborhoodDepth() uint8
+}
+
 type EachPeerer interface {
 	// EachPeer iterates from closest bin to farthest
 	EachPeer(EachPeerFunc) error
@@ -0,0 +1,340 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package traversal enumerates the addresses of all chunks under a
+// reference: the intermediate and data chunks of the raw data, the chunks of
+// the file entries with their metadata and data, and the chunks of the
+// manifests with all of their nodes and the files they reference.
+//
+// The chunks are fetched in parallel, while the addresses are always passed
+// to the callback in the same, depth first, order. An address is passed more
+// than once if its chunk is shared between the parts of the traversed tree.
+package traversal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethersphere/bee/pkg/collection/entry"
+	"github.com/ethersphere/bee/pkg/encryption"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/redundancy"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/swarm"
+)
+
+// fetchConcurrency is the maximal number of chunks fetched in parallel by a
+// single traversal.
+const fetchConcurrency = 16
+
+var (
+	// ErrInvalidChunk is returned when a chunk of the tree cannot be
+	// interpreted.
+	ErrInvalidChunk = errors.New("traversal: invalid chunk")
+	// ErrInvalidReference is returned when the reference is neither a plain
+	// nor an encrypted chunk reference.
+	ErrInvalidReference = errors.New("traversal: invalid reference")
+)
+
+// AddressFunc is called with the address of every traversed chunk. The
+// traversal stops with the error returned by the function.
+type AddressFunc func(addr swarm.Address) error
+
+// Traverser enumerates the addresses of the chunks under a reference.
+type Traverser interface {
+	// TraverseBytesAddresses calls fn with the addresses of all chunks of
+	// the data with the reference, both intermediate and data chunks.
+	TraverseBytesAddresses(ctx context.Context, reference swarm.Address, fn AddressFunc) error
+	// TraverseFileAddresses calls fn with the addresses of all chunks of the
+	// file entry with the reference, of its metadata and of its data. If the
+	// entry references a manifest, the manifest is traversed as well.
+	TraverseFileAddresses(ctx context.Context, reference swarm.Address, fn AddressFunc) error
+	// TraverseManifestAddresses calls fn with the addresses of all chunks of
+	// the manifest with the root node reference and of all the files it
+	// references.
+	TraverseManifestAddresses(ctx context.Context, reference swarm.Address, fn AddressFunc) error
+}
+
+type traverser struct {
+	getter storage.Getter
+}
+
+// New creates a new Traverser of the chunks provided by the getter.
+func New(getter storage.Getter) Traverser {
+	return &traverser{
+		getter: getter,
+	}
+}
+
+// TraverseBytesAddresses implements Traverser.
+func (t *traverser) TraverseBytesAddresses(ctx context.Context, reference swarm.Address, fn AddressFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	refSize := len(reference.Bytes())
+	if refSize != swarm.HashSize && refSize != encryption.ReferenceSize {
+		return fmt.Errorf("%w: length %d", ErrInvalidReference, refSize)
+	}
+
+	w := &walker{
+		getter:  t.getter,
+		refSize: refSize,
+		spans:   file.GenerateSpanSizes(9, swarm.ChunkSize/refSize),
+		sem:     make(chan struct{}, fetchConcurrency),
+		fn:      fn,
+	}
+	return w.walk(ctx, w.fetch(ctx, reference), node{last: true}, false)
+}
+
+// TraverseFileAddresses implements Traverser.
+//
+// The chunks of the entry are traversed first, followed by the chunks of the
+// metadata and the chunks of the data.
+func (t *traverser) TraverseFileAddresses(ctx context.Context, reference swarm.Address, fn AddressFunc) error {
+	if err := t.TraverseBytesAddresses(ctx, reference, fn); err != nil {
+		return err
+	}
+
+	b, err := readAll(t.getter, reference)
+	if err != nil {
+		return fmt.Errorf("read entry %s: %w", reference, err)
+	}
+	e := &entry.Entry{}
+	if err := e.UnmarshalBinary(b); err != nil {
+		return fmt.Errorf("%w: entry %s: %v", ErrInvalidChunk, reference, err)
+	}
+
+	if err := t.TraverseBytesAddresses(ctx, e.Metadata(), fn); err != nil {
+		return fmt.Errorf("metadata %s: %w", e.Metadata(), err)
+	}
+	b, err = readAll(t.getter, e.Metadata())
+	if err != nil {
+		return fmt.Errorf("read metadata %s: %w", e.Metadata(), err)
+	}
+	m := &entry.Metadata{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return fmt.Errorf("%w: metadata %s: %v", ErrInvalidChunk, e.Metadata(), err)
+	}
+
+	if m.MimeType == manifest.ContentType {
+		return t.TraverseManifestAddresses(ctx, e.Reference(), fn)
+	}
+	if err := t.TraverseBytesAddresses(ctx, e.Reference(), fn); err != nil {
+		return fmt.Errorf("data %s: %w", e.Reference(), err)
+	}
+	return nil
+}
+
+// TraverseManifestAddresses implements Traverser.
+//
+// The chunks of all the manifest nodes are traversed first, followed by the
+// chunks of the files in the lexicographic order of their paths.
+func (t *traverser) TraverseManifestAddresses(ctx context.Context, reference swarm.Address, fn AddressFunc) error {
+	m := manifest.NewFromReference(&loader{getter: t.getter}, reference)
+
+	if err := m.WalkNodes(ctx, func(node swarm.Address) error {
+		if err := t.TraverseBytesAddresses(ctx, node, fn); err != nil {
+			return fmt.Errorf("manifest node %s: %w", node, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return m.Walk(ctx, func(path string, e swarm.Address) error {
+		if err := t.TraverseFileAddresses(ctx, e, fn); err != nil {
+			return fmt.Errorf("manifest path %q: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// loader loads the manifest nodes with the getter.
+type loader struct {
+	getter storage.Getter
+}
+
+// Load implements manifest.LoadSaver.
+func (l *loader) Load(ctx context.Context, reference swarm.Address) ([]byte, error) {
+	return readAll(l.getter, reference)
+}
+
+// Save implements manifest.LoadSaver. The traversed manifests are never
+// modified.
+func (l *loader) Save(ctx context.Context, data []byte) (swarm.Address, error) {
+	return swarm.ZeroAddress, errors.New("traversal: read only manifest")
+}
+
+// readAll returns the data with the reference.
+func readAll(getter storage.Getter, reference swarm.Address) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	if _, err := file.JoinReadAll(joiner.NewSimpleJoiner(getter), reference, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fetched is the result of fetching a chunk of the tree.
+type fetched struct {
+	addr swarm.Address
+	data []byte // decrypted chunk data with the span
+	err  error
+}
+
+// walker traverses a single tree of chunks.
+type walker struct {
+	getter  storage.Getter
+	refSize int
+	spans   []int64       // maximum span lengths per level represented by one reference
+	levels  []int         // levels of the chunks on the path to the last data chunk, if the tree has no redundancy
+	sem     chan struct{} // limits the number of parallel fetches
+	fn      AddressFunc
+}
+
+// node is the position of a chunk in the tree.
+type node struct {
+	depth int  // 0 for the root chunk
+	level int  // level in the trees without redundancy, 0 for data chunks
+	last  bool // whether the chunk is on the path to the last data chunk
+}
+
+// fetch starts fetching the chunk with the reference, returning the channel
+// with the result.
+func (w *walker) fetch(ctx context.Context, ref swarm.Address) <-chan fetched {
+	c := make(chan fetched, 1)
+	go func() {
+		select {
+		case w.sem <- struct{}{}:
+		case <-ctx.Done():
+			c <- fetched{err: ctx.Err()}
+			return
+		}
+		defer func() { <-w.sem }()
+
+		data, addr, err := w.chunkData(ctx, ref)
+		c <- fetched{addr: addr, data: data, err: err}
+	}()
+	return c
+}
+
+// walk calls fn with the address of the fetched chunk at the position and
+// walks its children, if it is an intermediate chunk. All the children are
+// fetched in parallel, but walked in the order of their references. The
+// parity chunks of the trees with redundancy are leaves, as they hold no data
+// or references.
+func (w *walker) walk(ctx context.Context, c <-chan fetched, n node, leaf bool) error {
+	var f fetched
+	select {
+	case f = <-c:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if f.err != nil {
+		return f.err
+	}
+	if err := w.fn(f.addr); err != nil {
+		return err
+	}
+	if leaf {
+		return nil
+	}
+
+	level, span := redundancy.DecodeSpan(f.data[:8])
+	if level != redundancy.None && !level.Valid() {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidChunk, f.addr, redundancy.ErrInvalidLevel)
+	}
+	if n.depth == 0 {
+		if level == redundancy.None {
+			w.levels = file.LastChunkLevels(span, w.refSize)
+		} else {
+			w.spans = file.GenerateSpanSizes(9, level.MaxShards())
+		}
+	}
+	isData, err := w.isDataChunk(level, &n)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidChunk, f.addr, err)
+	}
+	if isData {
+		return nil
+	}
+
+	// the number of the children follows from the span, as the decrypted
+	// chunks may hold more data than the references, followed by the
+	// parity references in the trees with redundancy
+	var childSpan int64
+	if level == redundancy.None {
+		childSpan = w.spans[n.level-1] * swarm.ChunkSize
+	} else {
+		childSpan = file.ChildSpan(span, w.spans)
+	}
+	shards := int((span + childSpan - 1) / childSpan)
+	refs := f.data[8:]
+	count := shards + level.Parities(shards)
+	if count*w.refSize > len(refs) {
+		return fmt.Errorf("%w: %s: %d references in %d bytes", ErrInvalidChunk, f.addr, count, len(refs))
+	}
+	refs = refs[:count*w.refSize]
+	children := make([]<-chan fetched, 0, len(refs)/w.refSize)
+	for i := 0; i < len(refs); i += w.refSize {
+		children = append(children, w.fetch(ctx, swarm.NewAddress(refs[i:i+w.refSize])))
+	}
+	for i, c := range children {
+		child := node{
+			depth: n.depth + 1,
+			level: n.level - 1,
+			last:  n.last && i == shards-1,
+		}
+		if err := w.walk(ctx, c, child, i >= shards); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkData returns the data of the chunk with the reference, decrypted for
+// the encrypted references, together with the chunk address.
+func (w *walker) chunkData(ctx context.Context, ref swarm.Address) (data []byte, addr swarm.Address, err error) {
+	b := ref.Bytes()
+	addr = swarm.NewAddress(b[:swarm.HashSize])
+
+	ch, err := w.getter.Get(ctx, storage.ModeGetRequest, addr)
+	if err != nil {
+		return nil, addr, fmt.Errorf("get chunk %s: %w", addr, err)
+	}
+	data = ch.Data()
+	if len(b) == encryption.ReferenceSize {
+		data, err = encryption.DecryptChunkData(data, encryption.Key(b[swarm.HashSize:]))
+		if err != nil {
+			return nil, addr, fmt.Errorf("%w: %s: %v", ErrInvalidChunk, addr, err)
+		}
+	}
+	if len(data) < 8 {
+		return nil, addr, fmt.Errorf("%w: %s: %d bytes", ErrInvalidChunk, addr, len(data))
+	}
+	return data, addr, nil
+}
+
+// isDataChunk returns true if the chunk at the position holds the file data
+// rather than references, setting the level of the chunks on the path to the
+// last data chunk. In the trees with redundancy, only the intermediate chunks
+// have the redundancy level in their spans, while in the other trees the
+// level of the chunk follows from its position and the total length of the
+// data.
+func (w *walker) isDataChunk(level redundancy.Level, n *node) (bool, error) {
+	if w.levels == nil {
+		return level == redundancy.None, nil
+	}
+	if n.last {
+		if n.depth >= len(w.levels) {
+			return false, fmt.Errorf("tree deeper than %d levels", len(w.levels))
+		}
+		n.level = w.levels[n.depth]
+	}
+	return n.level == 0, nil
+}
@@ -0,0 +1,373 @@
+// Copyright 2020 The Swarm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package traversal_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/ethersphere/bee/pkg/collection/entry"
+	"github.com/ethersphere/bee/pkg/file"
+	"github.com/ethersphere/bee/pkg/file/joiner"
+	"github.com/ethersphere/bee/pkg/file/redundancy"
+	"github.com/ethersphere/bee/pkg/file/splitter"
+	"github.com/ethersphere/bee/pkg/manifest"
+	"github.com/ethersphere/bee/pkg/storage"
+	"github.com/ethersphere/bee/pkg/storage/mock"
+	"github.com/ethersphere/bee/pkg/swarm"
+	"github.com/ethersphere/bee/pkg/traversal"
+)
+
+func TestTraverseBytesAddresses(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		size    int
+		encrypt bool
+	}{
+		{name: "single chunk", size: 100},
+		{name: "two levels", size: 5*swarm.ChunkSize + 10},
+		{name: "dangling chunk", size: 129 * swarm.ChunkSize},
+		{name: "dangling chunk of the reference size", size: 128*swarm.ChunkSize + swarm.HashSize},
+		{name: "three levels", size: 130*swarm.ChunkSize + 1},
+		{name: "encrypted", size: 5*swarm.ChunkSize + 10, encrypt: true},
+		{name: "encrypted three levels", size: 70*swarm.ChunkSize + 1, encrypt: true},
+		{name: "encrypted single reference", size: 256*swarm.ChunkSize + 10, encrypt: true},
+		{name: "encrypted dangling chunk after two intermediate chunks", size: 129*swarm.ChunkSize - 1, encrypt: true},
+		{name: "encrypted dangling full chunk after two intermediate chunks", size: 129 * swarm.ChunkSize, encrypt: true},
+		{name: "encrypted dangling chunk after three intermediate chunks", size: 193*swarm.ChunkSize - 1, encrypt: true},
+		{name: "encrypted dangling full chunk after three intermediate chunks", size: 193 * swarm.ChunkSize, encrypt: true},
+		{name: "encrypted dangling chunk after four intermediate chunks", size: 257*swarm.ChunkSize - 1, encrypt: true},
+		{name: "encrypted dangling full chunk after four intermediate chunks", size: 257 * swarm.ChunkSize, encrypt: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			storer := mock.NewStorer()
+			root := split(t, storer, randomData(t, tc.size), tc.encrypt)
+
+			tr := traversal.New(&retrievalGetter{Getter: storer})
+			got := traverse(t, func(fn traversal.AddressFunc) error {
+				return tr.TraverseBytesAddresses(context.Background(), root, fn)
+			})
+
+			if !got[0].Equal(swarm.NewAddress(root.Bytes()[:swarm.HashSize])) {
+				t.Fatalf("got first address %s, want root %s", got[0], root)
+			}
+			checkAddresses(t, got, joinedAddresses(t, storer, root))
+		})
+	}
+}
+
+// TestTraverseBytesAddressesRedundancy verifies that the parity chunks of the
+// trees with redundancy are traversed, as all the stored chunks.
+func TestTraverseBytesAddressesRedundancy(t *testing.T) {
+	level := redundancy.Paranoid
+	for _, tc := range []struct {
+		name string
+		size int
+	}{
+		{name: "two levels", size: 5*swarm.ChunkSize + 10},
+		{name: "dangling chunk", size: (level.MaxShards() + 1) * swarm.ChunkSize},
+		{name: "three levels", size: level.MaxShards()*level.MaxShards()*swarm.ChunkSize + 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			storer := &recordingPutter{
+				Storer: mock.NewStorer(),
+				put:    make(map[string]struct{}),
+			}
+			data := randomData(t, tc.size)
+			root, err := file.SplitWriteAll(context.Background(), redundancy.NewSplitter(storer, level), bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tr := traversal.New(storer)
+			got := traverse(t, func(fn traversal.AddressFunc) error {
+				return tr.TraverseBytesAddresses(context.Background(), root, fn)
+			})
+			checkAddresses(t, got, storer.put)
+		})
+	}
+}
+
+func TestTraverseFileAddresses(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		encrypt bool
+	}{
+		{name: "plain"},
+		{name: "encrypted", encrypt: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			storer := mock.NewStorer()
+			root, refs := storeFile(t, storer, randomData(t, 3*swarm.ChunkSize), tc.encrypt)
+
+			tr := traversal.New(storer)
+			got := traverse(t, func(fn traversal.AddressFunc) error {
+				return tr.TraverseFileAddresses(context.Background(), root, fn)
+			})
+
+			// the entry, the metadata, three data chunks and their parent
+			if len(got) != 6 {
+				t.Fatalf("got %v addresses, want 6", len(got))
+			}
+			checkAddresses(t, got, joinedAddresses(t, storer, refs...))
+		})
+	}
+}
+
+func TestTraverseManifestAddresses(t *testing.T) {
+	ctx := context.Background()
+	storer := mock.NewStorer()
+	ls := manifest.NewLoadSaver(storer)
+
+	var refs []swarm.Address
+	m := manifest.New(ls)
+	for _, p := range []string{"index.html", "img/1.png", "img/2.png", "robots.txt"} {
+		root, fileRefs := storeFile(t, storer, randomData(t, 2*swarm.ChunkSize+rand.Intn(100)), false)
+		if err := m.Add(ctx, p, root); err != nil {
+			t.Fatal(err)
+		}
+		refs = append(refs, fileRefs...)
+	}
+	manifestRef, err := m.Store(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manifest.NewFromReference(ls, manifestRef).WalkNodes(ctx, func(node swarm.Address) error {
+		refs = append(refs, node)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := traversal.New(storer)
+
+	t.Run("manifest", func(t *testing.T) {
+		got := traverse(t, func(fn traversal.AddressFunc) error {
+			return tr.TraverseManifestAddresses(ctx, manifestRef, fn)
+		})
+		if !got[0].Equal(manifestRef) {
+			t.Fatalf("got first address %s, want manifest root %s", got[0], manifestRef)
+		}
+		checkAddresses(t, got, joinedAddresses(t, storer, refs...))
+	})
+
+	t.Run("manifest entry", func(t *testing.T) {
+		// the entry of the manifest is traversed with the whole collection
+		root, entryRefs := storeEntry(t, storer, manifestRef, manifest.ContentType, false)
+		got := traverse(t, func(fn traversal.AddressFunc) error {
+			return tr.TraverseFileAddresses(ctx, root, fn)
+		})
+		checkAddresses(t, got, joinedAddresses(t, storer, append(refs, entryRefs...)...))
+	})
+}
+
+func TestTraverseErrors(t *testing.T) {
+	ctx := context.Background()
+	storer := mock.NewStorer()
+	root := split(t, storer, randomData(t, 5*swarm.ChunkSize), false)
+	tr := traversal.New(storer)
+
+	t.Run("callback error", func(t *testing.T) {
+		errStop := errors.New("stop")
+		var count int
+		err := tr.TraverseBytesAddresses(ctx, root, func(swarm.Address) error {
+			count++
+			if count == 2 {
+				return errStop
+			}
+			return nil
+		})
+		if !errors.Is(err, errStop) {
+			t.Fatalf("got error %v, want %v", err, errStop)
+		}
+		if count != 2 {
+			t.Fatalf("got %v calls, want 2", count)
+		}
+	})
+
+	t.Run("missing chunk", func(t *testing.T) {
+		missing := swarm.MustParseHexAddress("ca8d2d29466e017cba46d383e7e0794d99a141185ec525086037f25fc2093155")
+		err := tr.TraverseBytesAddresses(ctx, missing, func(swarm.Address) error { return nil })
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("got error %v, want %v", err, storage.ErrNotFound)
+		}
+	})
+
+	t.Run("invalid reference", func(t *testing.T) {
+		err := tr.TraverseBytesAddresses(ctx, swarm.MustParseHexAddress("abcd"), func(swarm.Address) error { return nil })
+		if !errors.Is(err, traversal.ErrInvalidReference) {
+			t.Fatalf("got error %v, want %v", err, traversal.ErrInvalidReference)
+		}
+	})
+}
+
+// traverse runs the traversal twice, validating that the order of the
+// addresses is stable, and returns the traversed addresses.
+func traverse(t *testing.T, f func(fn traversal.AddressFunc) error) []swarm.Address {
+	t.Helper()
+
+	var addrs [2][]swarm.Address
+	for i := range addrs {
+		if err := f(func(addr swarm.Address) error {
+			addrs[i] = append(addrs[i], addr)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(addrs[0]) != len(addrs[1]) {
+		t.Fatalf("got %v and %v addresses", len(addrs[0]), len(addrs[1]))
+	}
+	for i := range addrs[0] {
+		if !addrs[0][i].Equal(addrs[1][i]) {
+			t.Fatalf("address %v: got %s and %s", i, addrs[0][i], addrs[1][i])
+		}
+	}
+	return addrs[0]
+}
+
+// joinedAddresses returns the addresses of all chunks retrieved by the joiner
+// when the data with the references is read. The splitter may store chunks
+// which are not part of the tree, so the stored chunks cannot be used.
+func joinedAddresses(t *testing.T, getter storage.Getter, refs ...swarm.Address) map[string]struct{} {
+	t.Helper()
+
+	g := &recordingGetter{
+		Getter: getter,
+		got:    make(map[string]struct{}),
+	}
+	for _, ref := range refs {
+		if _, err := file.JoinReadAll(joiner.NewSimpleJoiner(g), ref, ioutil.Discard); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g.got
+}
+
+// recordingGetter records the addresses of all retrieved chunks.
+type recordingGetter struct {
+	storage.Getter
+	mu  sync.Mutex
+	got map[string]struct{}
+}
+
+func (g *recordingGetter) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	ch, err := g.Getter.Get(ctx, mode, addr)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	g.got[addr.String()] = struct{}{}
+	g.mu.Unlock()
+	return ch, nil
+}
+
+// retrievalGetter fails to retrieve the chunks missing from the underlying
+// getter with an error other than storage.ErrNotFound, as the retrieval from
+// the network does.
+type retrievalGetter struct {
+	storage.Getter
+}
+
+func (g *retrievalGetter) Get(ctx context.Context, mode storage.ModeGet, addr swarm.Address) (swarm.Chunk, error) {
+	ch, err := g.Getter.Get(ctx, mode, addr)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve chunk %s: %v", addr, err)
+	}
+	return ch, nil
+}
+
+// recordingPutter records the addresses of all stored chunks.
+type recordingPutter struct {
+	storage.Storer
+	put map[string]struct{}
+}
+
+func (p *recordingPutter) Put(ctx context.Context, mode storage.ModePut, chs ...swarm.Chunk) ([]bool, error) {
+	for _, ch := range chs {
+		p.put[ch.Address().String()] = struct{}{}
+	}
+	return p.Storer.Put(ctx, mode, chs...)
+}
+
+// checkAddresses validates that the traversed addresses are exactly the
+// wanted ones.
+func checkAddresses(t *testing.T, addrs []swarm.Address, want map[string]struct{}) {
+	t.Helper()
+
+	traversed := make(map[string]struct{})
+	for _, a := range addrs {
+		if _, ok := want[a.String()]; !ok {
+			t.Fatalf("unexpected traversed address %s", a)
+		}
+		traversed[a.String()] = struct{}{}
+	}
+	if len(traversed) != len(want) {
+		t.Fatalf("got %v traversed addresses, want %v", len(traversed), len(want))
+	}
+}
+
+func split(t *testing.T, storer storage.Storer, data []byte, encrypt bool) swarm.Address {
+	t.Helper()
+
+	var s file.Splitter = splitter.NewSimpleSplitter(storer)
+	if encrypt {
+		s = splitter.NewEncryptingSplitter(storer)
+	}
+	addr, err := file.SplitWriteAll(context.Background(), s, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+// storeFile stores the data with its metadata and the entry which joins them,
+// returning the reference of the entry and the references of all the parts of
+// the file.
+func storeFile(t *testing.T, storer storage.Storer, data []byte, encrypt bool) (swarm.Address, []swarm.Address) {
+	t.Helper()
+
+	ref := split(t, storer, data, encrypt)
+	root, refs := storeEntry(t, storer, ref, "", encrypt)
+	return root, append(refs, ref)
+}
+
+// storeEntry stores the metadata with the mime type and the entry which joins
+// it with the reference, returning the reference of the entry and the
+// references of the entry and the metadata.
+func storeEntry(t *testing.T, storer storage.Storer, reference swarm.Address, mimeType string, encrypt bool) (swarm.Address, []swarm.Address) {
+	t.Helper()
+
+	m := entry.NewMetadata("file.bin")
+	m.MimeType = mimeType
+	metadata, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadataRef := split(t, storer, metadata, encrypt)
+	b, err := entry.New(reference, metadataRef).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := split(t, storer, b, encrypt)
+	return root, []swarm.Address{root, metadataRef}
+}
+
+func randomData(t *testing.T, size int) []byte {
+	t.Helper()
+
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
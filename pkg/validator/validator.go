@@ -38,6 +38,9 @@ func (v *ContentAddressValidator) Validate(ch swarm.Chunk) (valid bool) {
 
 	// prepare data
 	data := ch.Data()
+	if len(data) < 8 || len(data) > swarm.ChunkSize+8 {
+		return false
+	}
 	address := ch.Address()
 	span := binary.LittleEndian.Uint64(data[:8])
 